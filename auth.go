@@ -0,0 +1,465 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// authErrorCode is the MongoDB AuthenticationFailed error code
+const authErrorCode = 18
+
+// unauthorizedErrorCode is the MongoDB Unauthorized error code, returned when
+// an authenticated user lacks privileges for an operation
+const unauthorizedErrorCode = 13
+
+// passwordPlaceholder stands for the password in a saved connection string
+// whose password is asked for when connecting instead of stored
+const passwordPlaceholder = "{{password}}"
+
+// maxAuthBackoff caps the delay between repeated reconnect attempts
+const maxAuthBackoff = 60 * time.Second
+
+// reauthClientMsg carries the client connected with new credentials, or why
+// connecting failed
+type reauthClientMsg struct {
+	client *mongo.Client
+	err    error
+}
+
+// AuthRetryOp identifies the operation to re-run after re-authenticating
+type AuthRetryOp int
+
+const (
+	AuthRetryNone AuthRetryOp = iota
+	AuthRetryCollections
+	AuthRetryDocuments
+)
+
+// isAuthError reports whether err is a MongoDB authentication failure
+func isAuthError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) && serverErr.HasErrorCode(authErrorCode) {
+		return true
+	}
+	// Handshake failures are wrapped in connection errors without a code
+	return strings.Contains(err.Error(), "AuthenticationFailed")
+}
+
+// isUnauthorizedError reports whether err is a missing-privilege error, such
+// as reading a collection the user has no read access to
+func isUnauthorizedError(err error) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorCode(unauthorizedErrorCode)
+}
+
+// splitConnCredentials splits a MongoDB URI into its scheme prefix, the
+// userinfo section (without the trailing '@') and everything after it
+func splitConnCredentials(connStr string) (prefix, userinfo, rest string) {
+	for _, p := range []string{"mongodb://", "mongodb+srv://"} {
+		if strings.HasPrefix(connStr, p) {
+			prefix = p
+			break
+		}
+	}
+	s := connStr[len(prefix):]
+	hostEnd := strings.IndexAny(s, "/?")
+	if hostEnd == -1 {
+		hostEnd = len(s)
+	}
+	if at := strings.LastIndex(s[:hostEnd], "@"); at != -1 {
+		return prefix, s[:at], s[at+1:]
+	}
+	return prefix, "", s
+}
+
+// connUsername returns the (unescaped) username embedded in a connection string
+func connUsername(connStr string) string {
+	_, userinfo, _ := splitConnCredentials(connStr)
+	user, _, _ := strings.Cut(userinfo, ":")
+	if unescaped, err := url.PathUnescape(user); err == nil {
+		return unescaped
+	}
+	return user
+}
+
+// needsPassword reports whether connStr leaves its password to be asked for
+// when connecting: it names a user with the placeholder or an empty password
+// (mongodb://user:@host)
+func needsPassword(connStr string) bool {
+	_, userinfo, _ := splitConnCredentials(connStr)
+	user, password, hasPassword := strings.Cut(userinfo, ":")
+	return user != "" && hasPassword && (password == "" || password == passwordPlaceholder)
+}
+
+// withCredentials returns connStr with its credentials replaced by user/password
+func withCredentials(connStr, user, password string) string {
+	prefix, _, rest := splitConnCredentials(connStr)
+	if user == "" {
+		return prefix + rest
+	}
+	return prefix + url.UserPassword(user, password).String() + "@" + rest
+}
+
+// authBackoff returns how long to wait before the next reconnect attempt
+func authBackoff(failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+	delay := time.Second << (failures - 1)
+	if delay > maxAuthBackoff || delay <= 0 {
+		delay = maxAuthBackoff
+	}
+	return delay
+}
+
+// newAuthInputs creates the username and password inputs for the credential prompt
+func newAuthInputs() (textinput.Model, textinput.Model) {
+	userInput := textinput.New()
+	userInput.Placeholder = "username"
+	userInput.CharLimit = 100
+	userInput.Width = 40
+
+	passInput := textinput.New()
+	passInput.Placeholder = "new password"
+	passInput.CharLimit = 200
+	passInput.Width = 40
+	passInput.EchoMode = textinput.EchoPassword
+	passInput.EchoCharacter = '•'
+
+	return userInput, passInput
+}
+
+// openAuthPrompt opens the credential prompt after an authentication failure,
+// remembering which operation to retry once reconnected
+func (m *Model) openAuthPrompt(retry AuthRetryOp, err error) tea.Cmd {
+	m.authPromptActive = true
+	m.authRetryOp = retry
+	m.authFocusField = 1
+	m.authUserInput.SetValue(connUsername(m.connectionString))
+	m.authPassInput.SetValue("")
+	m.authUserInput.Blur()
+	m.authPassInput.Focus()
+	if err != nil {
+		m.authError = err.Error()
+	}
+	return textinput.Blink
+}
+
+// openPasswordPrompt asks for the password before connecting, for a
+// connection that doesn't store it or whose password was refused on connect
+func (m *Model) openPasswordPrompt(err error) tea.Cmd {
+	m.authConnecting = true
+	m.authError = ""
+	m.loading = false
+	return m.openAuthPrompt(AuthRetryNone, err)
+}
+
+// connect connects to the connection named by connectionName,
+// connectionString and sshAlias, through its SSH tunnel when it has one,
+// asking for the password first when the connection string leaves it out
+func (m *Model) connect() tea.Cmd {
+	m.passwordAsked = needsPassword(m.connectionString)
+	if m.passwordAsked {
+		return m.openPasswordPrompt(nil)
+	}
+	if m.sshAlias != "" {
+		return establishSSHTunnel(m.sshAlias, m.connectionString)
+	}
+	m.activeConnString = m.connectionString
+	return connectToMongo(m.connectionString)
+}
+
+// closeAuthPrompt hides the credential prompt
+func (m *Model) closeAuthPrompt() {
+	m.authPromptActive = false
+	m.authUserInput.Blur()
+	m.authPassInput.Blur()
+	m.authPassInput.SetValue("")
+}
+
+// handleAuthPromptKey handles keyboard input in the credential prompt
+func (m *Model) handleAuthPromptKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit, true
+	case "esc", "ctrl+g":
+		m.closeAuthPrompt()
+		m.authRetryOp = AuthRetryNone
+		m.authError = ""
+		if m.authConnecting {
+			// Nothing to show without connecting
+			m.authConnecting = false
+			if m.sshTunnel != nil {
+				m.sshTunnel.Close()
+				m.sshTunnel = nil
+			}
+			m.screen = ScreenConnections
+			m.connectionName = ""
+			m.connectionString = ""
+			m.activeConnString = ""
+			m.sshAlias = ""
+			m.connectionDatabase = ""
+			m.connectionEnv = ""
+			m.connectionColor = ""
+			m.passwordAsked = false
+			m.authFailures = 0
+			return m.startWarmup(), true
+		}
+		return nil, true
+	case "tab", "shift+tab":
+		m.authFocusField = 1 - m.authFocusField
+		if m.authFocusField == 0 {
+			m.authPassInput.Blur()
+			m.authUserInput.Focus()
+		} else {
+			m.authUserInput.Blur()
+			m.authPassInput.Focus()
+		}
+		return nil, true
+	case "ctrl+s":
+		if m.canSaveCredentials() {
+			m.authSaveToConn = !m.authSaveToConn
+		}
+		return nil, true
+	case "enter":
+		user := strings.TrimSpace(m.authUserInput.Value())
+		newConnString := withCredentials(m.connectionString, user, m.authPassInput.Value())
+		m.closeAuthPrompt()
+
+		// Tear down the client using the stale credentials
+		if m.client != nil {
+			m.client.Disconnect(context.Background())
+			m.client = nil
+		}
+		// The password lives in memory only: the saved connection keeps
+		// its placeholder
+		m.connectionString = newConnString
+		if m.authConnecting && m.sshAlias != "" && m.sshTunnel == nil {
+			m.authConnecting = false
+			m.loading = true
+			return establishSSHTunnel(m.sshAlias, newConnString), true
+		}
+		m.activeConnString = newConnString
+		if m.sshTunnel != nil {
+			m.activeConnString = BuildTunneledConnectionString(newConnString, m.sshTunnel.LocalAddr())
+		}
+		// A password asked for before connecting connects for the first
+		// time; a rotated one reconnects, keeping the selection
+		m.authReconnecting = !m.authConnecting
+		m.loading = m.authConnecting
+		m.authConnecting = false
+
+		// Back off on repeated failures to avoid locking the account
+		delay := authBackoff(m.authFailures)
+		if delay == 0 {
+			return tea.Batch(m.setStatus("reconnecting..."), connectToMongo(m.activeConnString)), true
+		}
+		connStr := m.activeConnString
+		return tea.Batch(
+			m.setStatus(fmt.Sprintf("reconnecting in %s...", delay)),
+			tea.Tick(delay, func(time.Time) tea.Msg { return connectToMongo(connStr)() }),
+		), true
+	default:
+		var cmd tea.Cmd
+		if m.authFocusField == 0 {
+			m.authUserInput, cmd = m.authUserInput.Update(msg)
+		} else {
+			m.authPassInput, cmd = m.authPassInput.Update(msg)
+		}
+		return cmd, true
+	}
+}
+
+// retryAfterReauth re-runs the operation that failed with an auth error
+func (m *Model) retryAfterReauth() tea.Cmd {
+	retry := m.authRetryOp
+	m.authRetryOp = AuthRetryNone
+	switch retry {
+	case AuthRetryCollections:
+		if m.selectedDatabase != "" {
+			return loadCollections(m.client, m.selectedDatabase)
+		}
+	case AuthRetryDocuments:
+		if m.selectedCollection != "" {
+			m.loadingDocs = true
+			return loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, m.currentPage, m.docsPerPage, m.queryFilter, m.sortSpec())
+		}
+	}
+	return nil
+}
+
+// canSaveCredentials reports whether the credential prompt may write the
+// password to the saved connection: never for one asked for at connect time
+func (m Model) canSaveCredentials() bool {
+	return !m.authConnecting && !m.passwordAsked
+}
+
+// saveRotatedCredentials writes the new connection string back to the stored connection
+func (m *Model) saveRotatedCredentials() error {
+	if m.passwordAsked {
+		return nil
+	}
+	for i, conn := range m.connections {
+		if conn.Name != m.connectionName {
+			continue
+		}
+		conn.ConnectionString = m.connectionString
+		if err := updateConnection(conn.Name, conn); err != nil {
+			return err
+		}
+		m.connections[i] = conn
+		m.updateFilteredConnections()
+		return nil
+	}
+	return nil
+}
+
+// renderAuthPrompt renders the credential prompt modal
+func (m Model) renderAuthPrompt(background string) string {
+	modalWidth := m.modalWidth(55)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("252"))
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+
+	errorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("196"))
+
+	target := m.connectionName
+	if target == "" {
+		target = "this connection"
+	}
+	title := "Authentication Failed"
+	message := fmt.Sprintf("Authentication to %s failed. The password may have rotated.", target)
+	switch {
+	case m.authConnecting && m.authError == "":
+		title = "Password"
+		message = fmt.Sprintf("%s doesn't store its password: it's asked for on each connect and kept in memory only.", target)
+	case m.authConnecting:
+		message = fmt.Sprintf("Authentication to %s failed. Check the password.", target)
+	}
+
+	saveBox := "[ ]"
+	if m.authSaveToConn {
+		saveBox = "[x]"
+	}
+
+	lines := []string{
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("196")).Render(title),
+		"",
+		labelStyle.Render(message),
+		"",
+		labelStyle.Render("Username:"),
+		fitInput(m.authUserInput, modalWidth-4),
+		"",
+		labelStyle.Render("Password:"),
+		fitInput(m.authPassInput, modalWidth-4),
+	}
+	hint := "tab: switch field • enter: connect • esc: cancel"
+	if m.canSaveCredentials() {
+		lines = append(lines, "", labelStyle.Render(saveBox+" Update saved connection"))
+		hint = "tab: switch field • ctrl+s: toggle save • enter: reconnect • esc: cancel"
+	} else if !m.authConnecting {
+		hint = "tab: switch field • enter: reconnect • esc: cancel"
+	}
+	if m.authError != "" {
+		lines = append(lines, "", errorStyle.Render(truncate(m.authError, modalWidth-6)))
+	}
+	if delay := authBackoff(m.authFailures); delay > 0 {
+		lines = append(lines, hintStyle.Render(fmt.Sprintf("(%d failed attempts, next retry waits %s)", m.authFailures, delay)))
+	}
+	lines = append(lines, "", hintStyle.Render(hint))
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("196")).
+		Padding(1, 2).
+		Width(modalWidth)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
+
+// handleReauthResult handles the result of reconnecting with new credentials
+func (m *Model) handleReauthResult(msg databasesLoadedMsg) tea.Cmd {
+	if msg.err != nil {
+		if isAuthError(msg.err) {
+			m.authFailures++
+			m.authReconnecting = false
+			return m.openAuthPrompt(m.authRetryOp, msg.err)
+		}
+		m.authReconnecting = false
+		m.authRetryOp = AuthRetryNone
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Reconnect failed: %v", msg.err)
+		return nil
+	}
+
+	m.authReconnecting = false
+	m.authFailures = 0
+	m.authError = ""
+
+	// Keep the current selection; only refresh the database list
+	m.databases = msg.databases
+	m.updateFilteredDatabases()
+
+	if msg.client != nil {
+		return m.handleReauthClient(reauthClientMsg{client: msg.client})
+	}
+	return connectReauthClient(m.activeConnString)
+}
+
+// connectReauthClient connects the client that replaces the one torn down
+// with the stale credentials
+func connectReauthClient(connectionString string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		client, err := mongo.Connect(ctx, options.Client().ApplyURI(connectionString))
+		return reauthClientMsg{client: client, err: err}
+	}
+}
+
+// handleReauthClient keeps the reconnected client, then saves the new
+// credentials if asked and retries the operation that failed
+func (m *Model) handleReauthClient(msg reauthClientMsg) tea.Cmd {
+	if msg.err != nil {
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Reconnect failed: %v", msg.err)
+		return nil
+	}
+	m.client = msg.client
+
+	status := "reconnected"
+	if m.authSaveToConn && m.canSaveCredentials() {
+		if err := m.saveRotatedCredentials(); err != nil {
+			status = fmt.Sprintf("reconnected (failed to update saved connection: %v)", err)
+		} else {
+			status = "reconnected, saved connection updated"
+		}
+	}
+	return tea.Batch(m.setStatus(status), m.retryAfterReauth())
+}
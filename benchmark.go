@@ -0,0 +1,409 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// defaultBenchmarkRuns is how many times a benchmark runs the query unless
+// the prompt says otherwise
+const defaultBenchmarkRuns = 5
+
+// maxBenchmarkRuns caps the runs of one benchmark
+const maxBenchmarkRuns = 100
+
+// benchmarkJobPrefix starts the name of benchmark jobs, which run one at a
+// time so they don't time each other
+const benchmarkJobPrefix = "benchmark "
+
+// benchmarkSummary is what a benchmark of a query measured. Latencies are of
+// the warm runs: the first run may find the working set out of cache.
+type benchmarkSummary struct {
+	runs         int
+	min          time.Duration
+	median       time.Duration
+	max          time.Duration
+	docsExamined int64
+	keysExamined int64
+	plan         string
+	at           time.Time
+}
+
+// benchmarkDoneMsg carries the runs of a finished benchmark
+type benchmarkDoneMsg struct {
+	namespace string
+	query     string          // benchmarkQuery of what was run
+	latencies []time.Duration // In order; the first is the cold run
+	returned  int64
+	summary   benchmarkSummary
+}
+
+// writeStageKeys are the operators that make a query write
+var writeStageKeys = map[string]bool{"$out": true, "$merge": true}
+
+// benchmarkGuard refuses anything but a read-only find. Benchmarks run the
+// query many times, so a query that writes must never get there.
+func benchmarkGuard(provenance DocProvenance, filter bson.M) error {
+	if provenance == ProvenanceAggregated {
+		return errors.New("only find queries can be benchmarked: pipelines may write with $out or $merge")
+	}
+	if key := findWriteStage(filter); key != "" {
+		return fmt.Errorf("the query contains %s, which writes: benchmarks only run read-only queries", key)
+	}
+	return nil
+}
+
+// findWriteStage returns the first write operator anywhere in value, or ""
+func findWriteStage(value interface{}) string {
+	switch v := value.(type) {
+	case bson.M:
+		for key, child := range v {
+			if writeStageKeys[key] {
+				return key
+			}
+			if found := findWriteStage(child); found != "" {
+				return found
+			}
+		}
+	case bson.D:
+		for _, e := range v {
+			if writeStageKeys[e.Key] {
+				return e.Key
+			}
+			if found := findWriteStage(e.Value); found != "" {
+				return found
+			}
+		}
+	case bson.A:
+		for _, child := range v {
+			if found := findWriteStage(child); found != "" {
+				return found
+			}
+		}
+	}
+	return ""
+}
+
+// benchmarkQuery identifies a query across benchmarks: its filter, sort and
+// limit as canonical Extended JSON, with keys in a stable order
+func benchmarkQuery(filter bson.M, sortSpec bson.D, limit int) string {
+	if filter == nil {
+		filter = bson.M{}
+	}
+	query := bson.D{{Key: "filter", Value: sortedDocument(filter)}}
+	if len(sortSpec) > 0 {
+		query = append(query, bson.E{Key: "sort", Value: sortSpec})
+	}
+	query = append(query, bson.E{Key: "limit", Value: int32(limit)})
+	data, err := bson.MarshalExtJSON(query, true, false)
+	if err != nil {
+		return fmt.Sprint(query)
+	}
+	return string(data)
+}
+
+// summarizeLatencies returns the min, median and max of the warm runs, or of
+// the only run
+func summarizeLatencies(latencies []time.Duration) (time.Duration, time.Duration, time.Duration) {
+	if len(latencies) == 0 {
+		return 0, 0, 0
+	}
+	warm := latencies
+	if len(latencies) > 1 {
+		warm = latencies[1:]
+	}
+	sorted := append([]time.Duration{}, warm...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + median) / 2
+	}
+	return sorted[0], median, sorted[len(sorted)-1]
+}
+
+// explainCount reads a counter from explain's executionStats
+func explainCount(stats bson.M, key string) int64 {
+	switch n := stats[key].(type) {
+	case int32:
+		return int64(n)
+	case int64:
+		return n
+	case float64:
+		return int64(n)
+	}
+	return 0
+}
+
+// runBenchmark is the job that runs a find runs times, one after the other,
+// reading the first limit results each time as the documents view does,
+// then explains it once for the documents and keys it examines
+func runBenchmark(ctx context.Context, report func(string), coll *mongo.Collection, filter bson.M, sortSpec bson.D, limit, runs int) (tea.Msg, error) {
+	if filter == nil {
+		filter = bson.M{}
+	}
+	opts := options.Find().SetLimit(int64(limit))
+	if len(sortSpec) > 0 {
+		opts.SetSort(sortSpec)
+	}
+
+	msg := benchmarkDoneMsg{
+		namespace: namespaceOf(coll.Database().Name(), coll.Name()),
+		query:     benchmarkQuery(filter, sortSpec, limit),
+	}
+	for i := 0; i < runs; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		report(fmt.Sprintf("run %d/%d", i+1, runs))
+		start := time.Now()
+		cursor, err := coll.Find(ctx, filter, opts)
+		if err != nil {
+			return nil, err
+		}
+		var returned int64
+		for cursor.Next(ctx) {
+			returned++
+		}
+		err = cursor.Err()
+		cursor.Close(ctx)
+		if err != nil {
+			return nil, err
+		}
+		msg.latencies = append(msg.latencies, time.Since(start))
+		msg.returned = returned
+	}
+
+	report("explaining")
+	find := bson.D{{Key: "find", Value: coll.Name()}, {Key: "filter", Value: filter}}
+	if len(sortSpec) > 0 {
+		find = append(find, bson.E{Key: "sort", Value: sortSpec})
+	}
+	find = append(find, bson.E{Key: "limit", Value: int64(limit)})
+	var explain bson.M
+	err := coll.Database().RunCommand(ctx, bson.D{{Key: "explain", Value: find}, {Key: "verbosity", Value: "executionStats"}}).Decode(&explain)
+	if err != nil {
+		return nil, fmt.Errorf("explain: %w", err)
+	}
+
+	s := &msg.summary
+	s.runs = runs
+	s.min, s.median, s.max = summarizeLatencies(msg.latencies)
+	stats, _ := explain["executionStats"].(bson.M)
+	s.docsExamined = explainCount(stats, "totalDocsExamined")
+	s.keysExamined = explainCount(stats, "totalKeysExamined")
+	if plan := winningPlan(explain); plan != nil {
+		s.plan = summarizePlanStages(plan)
+	}
+	s.at = time.Now()
+	return msg, nil
+}
+
+// newBenchmarkRunsInput creates the input for the number of runs
+func newBenchmarkRunsInput() textinput.Model {
+	ti := textinput.New()
+	ti.CharLimit = 3
+	ti.Width = 10
+	return ti
+}
+
+// benchmarkRunning reports whether a benchmark job is queued or running
+func (m Model) benchmarkRunning() bool {
+	for _, job := range m.jobs {
+		if strings.HasPrefix(job.Name, benchmarkJobPrefix) && (job.State == JobQueued || job.State == JobRunning) {
+			return true
+		}
+	}
+	return false
+}
+
+// openBenchmarkPrompt opens the prompt benchmarking the current query
+func (m *Model) openBenchmarkPrompt() tea.Cmd {
+	if m.client == nil || m.selectedCollection == "" || m.schemaActive {
+		return nil
+	}
+	if err := benchmarkGuard(m.docProvenance, m.queryFilter); err != nil {
+		return m.setStatus(err.Error())
+	}
+	m.benchmarkPromptActive = true
+	m.benchmarkErr = ""
+	m.benchmarkRunsInput.SetValue(strconv.Itoa(defaultBenchmarkRuns))
+	m.benchmarkRunsInput.CursorEnd()
+	m.benchmarkRunsInput.Focus()
+	return textinput.Blink
+}
+
+// handleBenchmarkPromptKey handles keyboard input in the benchmark prompt
+func (m *Model) handleBenchmarkPromptKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "ctrl+g":
+		m.benchmarkPromptActive = false
+		m.benchmarkRunsInput.Blur()
+		return nil
+	case "enter":
+		return m.startBenchmark()
+	}
+	var cmd tea.Cmd
+	m.benchmarkRunsInput, cmd = m.benchmarkRunsInput.Update(msg)
+	m.benchmarkErr = ""
+	return cmd
+}
+
+// startBenchmark starts benchmarking the current query as a background job,
+// which the jobs overlay can cancel between or during runs
+func (m *Model) startBenchmark() tea.Cmd {
+	runs, err := strconv.Atoi(strings.TrimSpace(m.benchmarkRunsInput.Value()))
+	if err != nil || runs < 1 || runs > maxBenchmarkRuns {
+		m.benchmarkErr = fmt.Sprintf("runs must be between 1 and %d", maxBenchmarkRuns)
+		return nil
+	}
+	if err := benchmarkGuard(m.docProvenance, m.queryFilter); err != nil {
+		m.benchmarkErr = err.Error()
+		return nil
+	}
+	if m.benchmarkRunning() {
+		m.benchmarkErr = "another benchmark is running: wait for it or cancel it from the jobs list (J)"
+		return nil
+	}
+	m.benchmarkPromptActive = false
+	m.benchmarkRunsInput.Blur()
+
+	coll := m.client.Database(m.selectedDatabase).Collection(m.selectedCollection)
+	filter, sortSpec, limit := m.queryFilter, m.sortSpec(), m.docsPerPage
+	return m.startJob(fmt.Sprintf("%s%s ×%d", benchmarkJobPrefix, namespaceOf(m.selectedDatabase, m.selectedCollection), runs), func(ctx context.Context, report func(string)) (tea.Msg, error) {
+		return runBenchmark(ctx, report, coll, filter, sortSpec, limit, runs)
+	})
+}
+
+// handleBenchmarkDone compares a finished benchmark with the previous one of
+// the same query, records it and shows the report
+func (m *Model) handleBenchmarkDone(msg benchmarkDoneMsg) tea.Cmd {
+	previous, err := loadLastBenchmark(m.connectionName, msg.namespace, msg.query)
+	if err != nil {
+		previous = nil
+	}
+	var cmd tea.Cmd
+	if err := saveBenchmark(m.connectionName, msg.namespace, msg.query, msg.summary); err != nil {
+		cmd = m.setStatus("benchmark not saved for comparison: " + err.Error())
+	}
+	m.openViewer(ViewerBenchmark, "Benchmark of "+msg.namespace, benchmarkReport(msg, previous))
+	return cmd
+}
+
+// formatLatency formats a latency in milliseconds
+func formatLatency(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
+
+// formatChange formats the change from before to after as a percentage
+func formatChange(before, after float64) string {
+	if before == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%+.0f%%", (after-before)/before*100)
+}
+
+// benchmarkReport describes a benchmark: every run marked cold or warm, the
+// warm latencies, what the plan examined, and how it compares with the
+// previous benchmark of the query
+func benchmarkReport(msg benchmarkDoneMsg, previous *benchmarkSummary) string {
+	s := msg.summary
+	var b strings.Builder
+	fmt.Fprintf(&b, "Query:    %s\n", msg.query)
+	plan := s.plan
+	if plan == "" {
+		plan = "(no plan information)"
+	}
+	fmt.Fprintf(&b, "Plan:     %s\n", plan)
+	fmt.Fprintf(&b, "Examined: %s, %s keys for %s returned\n\n",
+		pluralDocuments(s.docsExamined), formatCount(s.keysExamined), formatCount(msg.returned))
+
+	b.WriteString("Runs:\n")
+	for i, latency := range msg.latencies {
+		kind := "warm"
+		if i == 0 {
+			kind = "cold"
+		}
+		fmt.Fprintf(&b, "  %3d  %s  %s\n", i+1, kind, formatLatency(latency))
+	}
+	runs := "warm runs"
+	if len(msg.latencies) == 1 {
+		runs = "one cold run"
+	}
+	fmt.Fprintf(&b, "\nLatency (%s): min %s • median %s • max %s\n\n", runs, formatLatency(s.min), formatLatency(s.median), formatLatency(s.max))
+
+	if previous == nil {
+		b.WriteString("No earlier benchmark of this query to compare with.\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "Compared with the benchmark of %s:\n", previous.at.Local().Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, "  median         %s → %s (%s)\n", formatLatency(previous.median), formatLatency(s.median),
+		formatChange(float64(previous.median), float64(s.median)))
+	fmt.Fprintf(&b, "  docs examined  %s → %s (%s)\n", formatCount(previous.docsExamined), formatCount(s.docsExamined),
+		formatChange(float64(previous.docsExamined), float64(s.docsExamined)))
+	fmt.Fprintf(&b, "  keys examined  %s → %s\n", formatCount(previous.keysExamined), formatCount(s.keysExamined))
+	if previous.plan != s.plan {
+		fmt.Fprintf(&b, "  plan           %s → %s\n", previous.plan, plan)
+	}
+	return b.String()
+}
+
+// renderBenchmarkPrompt renders the benchmark prompt modal
+func (m Model) renderBenchmarkPrompt(background string) string {
+	width := m.modalWidth(70)
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+
+	query := strings.TrimSpace(m.queryText)
+	if query == "" {
+		query = "{}"
+	}
+	lines := []string{
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render("Benchmark query on " + truncateMiddle(m.selectedCollection, maxToastNameWidth)),
+		"",
+		truncate("Filter: "+query, width-4),
+	}
+	if label := m.sortLabel(); label != "" {
+		lines = append(lines, truncate("Sort: "+label, width-4))
+	}
+	lines = append(lines, fmt.Sprintf("Limit: %d (a page)", m.docsPerPage))
+	lines = append(lines,
+		"",
+		"Runs (the first one is cold):",
+		fitInput(m.benchmarkRunsInput, width-4),
+	)
+	if m.benchmarkErr != "" {
+		lines = append(lines, "", lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Width(width-4).Render(m.benchmarkErr))
+	}
+	lines = append(lines, "", hintStyle.Render("Each run reads one page • J: cancel from the jobs list"), hintStyle.Render("enter: run • esc: cancel"))
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("205")).
+		Padding(1, 2).
+		Width(width)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
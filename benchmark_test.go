@@ -0,0 +1,133 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestBenchmarkGuardRefusesWrites(t *testing.T) {
+	if err := benchmarkGuard(ProvenanceFind, bson.M{"status": "open"}); err != nil {
+		t.Errorf("plain filter refused: %v", err)
+	}
+	if err := benchmarkGuard(ProvenanceAggregated, bson.M{}); err == nil {
+		t.Error("pipeline output accepted")
+	}
+	nested := bson.M{"$or": bson.A{bson.M{"a": 1}, bson.M{"b": bson.D{{Key: "$merge", Value: "other"}}}}}
+	if err := benchmarkGuard(ProvenanceFind, nested); err == nil || !strings.Contains(err.Error(), "$merge") {
+		t.Errorf("nested $merge: %v", err)
+	}
+}
+
+func TestSummarizeLatencies(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		latencies           []time.Duration
+		wantMin, wantMedian time.Duration
+		wantMax             time.Duration
+	}{
+		// The cold first run is left out
+		{[]time.Duration{900 * ms, 30 * ms, 10 * ms, 20 * ms}, 10 * ms, 20 * ms, 30 * ms},
+		{[]time.Duration{900 * ms, 40 * ms, 10 * ms, 20 * ms, 30 * ms}, 10 * ms, 25 * ms, 40 * ms},
+		{[]time.Duration{900 * ms}, 900 * ms, 900 * ms, 900 * ms},
+	}
+	for _, tt := range tests {
+		min, median, max := summarizeLatencies(tt.latencies)
+		if min != tt.wantMin || median != tt.wantMedian || max != tt.wantMax {
+			t.Errorf("summarizeLatencies(%v) = %v %v %v, want %v %v %v", tt.latencies, min, median, max, tt.wantMin, tt.wantMedian, tt.wantMax)
+		}
+	}
+}
+
+func TestBenchmarkQueryIsStable(t *testing.T) {
+	a := benchmarkQuery(bson.M{"status": "open", "age": bson.M{"$gt": 30}}, nil, 20)
+	for i := 0; i < 20; i++ {
+		if b := benchmarkQuery(bson.M{"age": bson.M{"$gt": 30}, "status": "open"}, nil, 20); b != a {
+			t.Fatalf("same query, different keys: %s and %s", a, b)
+		}
+	}
+	if sorted := benchmarkQuery(bson.M{"status": "open", "age": bson.M{"$gt": 30}}, bson.D{{Key: "age", Value: -1}}, 20); sorted == a {
+		t.Error("sort not part of the query")
+	}
+	if paged := benchmarkQuery(bson.M{"status": "open", "age": bson.M{"$gt": 30}}, nil, 50); paged == a || !strings.Contains(paged, `"limit":{"$numberInt":"50"}`) {
+		t.Errorf("limit not part of the query: %s", paged)
+	}
+}
+
+func TestBenchmarkComparesWithThePreviousOne(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := initDB(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		closeDB()
+		db = nil
+	}()
+
+	m := newTestModel(120, 30)
+	m.connectionName = "prod"
+	ms := time.Millisecond
+	before := benchmarkDoneMsg{
+		namespace: "shop.orders",
+		query:     benchmarkQuery(bson.M{"status": "open"}, nil, 20),
+		latencies: []time.Duration{500 * ms, 300 * ms, 340 * ms},
+		returned:  120,
+		summary:   benchmarkSummary{runs: 3, min: 300 * ms, median: 320 * ms, max: 340 * ms, docsExamined: 48120, keysExamined: 0, plan: "COLLSCAN"},
+	}
+	m.handleBenchmarkDone(before)
+	if m.viewerKind != ViewerBenchmark || !strings.Contains(m.viewerText, "No earlier benchmark") || !strings.Contains(m.viewerText, "  1  cold  500.0ms") {
+		t.Fatalf("first report:\n%s", m.viewerText)
+	}
+
+	after := before
+	after.latencies = []time.Duration{40 * ms, 12 * ms, 14 * ms}
+	after.summary = benchmarkSummary{runs: 3, min: 12 * ms, median: 13 * ms, max: 14 * ms, docsExamined: 120, keysExamined: 120, plan: "FETCH <- IXSCAN status_1"}
+	m.handleBenchmarkDone(after)
+	for _, want := range []string{"median         320.0ms → 13.0ms (-96%)", "docs examined  48,120 → 120 (-100%)", "plan           COLLSCAN → FETCH <- IXSCAN status_1"} {
+		if !strings.Contains(m.viewerText, want) {
+			t.Errorf("second report lacks %q:\n%s", want, m.viewerText)
+		}
+	}
+
+	// Other queries aren't compared
+	other := before
+	other.query = benchmarkQuery(bson.M{"status": "closed"}, nil, 20)
+	m.handleBenchmarkDone(other)
+	if !strings.Contains(m.viewerText, "No earlier benchmark") {
+		t.Errorf("different query compared:\n%s", m.viewerText)
+	}
+}
+
+func TestBenchmarkPrompt(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m.openBenchmarkPrompt()
+	if !m.benchmarkPromptActive || m.benchmarkRunsInput.Value() != "5" {
+		t.Fatalf("prompt = %v, runs %q", m.benchmarkPromptActive, m.benchmarkRunsInput.Value())
+	}
+
+	m.benchmarkRunsInput.SetValue("0")
+	m.startBenchmark()
+	if !m.benchmarkPromptActive || !strings.Contains(m.benchmarkErr, "runs must be") {
+		t.Errorf("0 runs: active = %v, err = %q", m.benchmarkPromptActive, m.benchmarkErr)
+	}
+
+	m.benchmarkRunsInput.SetValue("3")
+	m.jobs = []*Job{{ID: 1, Name: benchmarkJobPrefix + "shop.orders ×5", State: JobRunning}}
+	m.startBenchmark()
+	if !m.benchmarkPromptActive || !strings.Contains(m.benchmarkErr, "another benchmark is running") {
+		t.Errorf("concurrent benchmark: active = %v, err = %q", m.benchmarkPromptActive, m.benchmarkErr)
+	}
+	if view := normalizeRender(m.View()); !strings.Contains(view, "Benchmark query on orders") || !strings.Contains(view, "Limit: 10 (a page)") || !strings.Contains(view, "another benchmark") {
+		t.Errorf("prompt view:\n%s", view)
+	}
+
+	m.docProvenance = ProvenanceAggregated
+	m.benchmarkPromptActive = false
+	m.openBenchmarkPrompt()
+	if m.benchmarkPromptActive || !strings.Contains(m.statusMessage, "only find queries") {
+		t.Errorf("pipeline output: active = %v, status = %q", m.benchmarkPromptActive, m.statusMessage)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// maxTreeChildren caps the children built at once for one object or array.
+// The rest wait behind a "show more" node until it is opened.
+const maxTreeChildren = 1000
+
+// treeBuildBudget is how long building the tree of one document may take
+// before the document is shown as raw Extended JSON instead
+const treeBuildBudget = 250 * time.Millisecond
+
+// treeBudgetCheckInterval is how many nodes are built between looks at the
+// clock, which costs more than building a node
+const treeBudgetCheckInterval = 1024
+
+// treeBuilder builds document trees, optionally within a time budget. Once
+// the deadline passes it stops building and sets expired.
+type treeBuilder struct {
+	deadline time.Time // Zero for no budget
+	all      bool      // Build every child, without "show more" nodes
+	built    int
+	expired  bool
+}
+
+// valueNode creates a node for any value type, with the children of
+// objects and arrays capped at maxTreeChildren
+func (b *treeBuilder) valueNode(key string, value interface{}, depth int) *JSONNode {
+	node := &JSONNode{
+		Key:       key,
+		Value:     value,
+		Depth:     depth,
+		Collapsed: true,
+	}
+	if b.overBudget() {
+		return node
+	}
+
+	switch v := value.(type) {
+	case bson.M:
+		node.IsObject = true
+		node.Children = make([]*JSONNode, 0)
+		// Sorted keys for consistent ordering
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b.appendChildren(node, keys, 0)
+	case bson.A:
+		node.IsArray = true
+		node.Children = make([]*JSONNode, 0)
+		b.appendChildren(node, nil, 0)
+	}
+	return node
+}
+
+// appendChildren builds up to maxTreeChildren more children of an object or
+// array, then a "show more" node if any are left. Objects pass the sorted
+// keys still to build and from 0; arrays pass the index to continue from.
+func (b *treeBuilder) appendChildren(node *JSONNode, keys []string, from int) {
+	total := len(keys)
+	if arr, ok := node.Value.(bson.A); ok {
+		total = len(arr)
+	}
+	end := from + maxTreeChildren
+	if b.all || end > total {
+		end = total
+	}
+	for i := from; i < end; i++ {
+		var child *JSONNode
+		switch v := node.Value.(type) {
+		case bson.A:
+			child = b.valueNode(fmt.Sprintf("[%d]", i), v[i], node.Depth+1)
+		case bson.M:
+			child = b.valueNode(keys[i], v[keys[i]], node.Depth+1)
+		}
+		child.Parent = node
+		node.Children = append(node.Children, child)
+		if b.expired {
+			return
+		}
+	}
+	if end < total {
+		more := &JSONNode{Parent: node, Depth: node.Depth + 1, More: total - end}
+		if node.IsObject {
+			more.MoreKeys = keys[end:]
+		}
+		node.Children = append(node.Children, more)
+	}
+}
+
+// overBudget counts a node and reports whether the deadline has passed
+func (b *treeBuilder) overBudget() bool {
+	if b.expired {
+		return true
+	}
+	b.built++
+	if !b.deadline.IsZero() && b.built%treeBudgetCheckInterval == 0 && time.Now().After(b.deadline) {
+		b.expired = true
+	}
+	return b.expired
+}
+
+// newDocumentTree builds the tree of a loaded document with its root
+// expanded. A document whose tree takes longer than budget to build is only
+// shown as raw Extended JSON.
+func newDocumentTree(doc bson.M, budget time.Duration) *JSONNode {
+	b := &treeBuilder{deadline: time.Now().Add(budget)}
+	root := b.valueNode("", doc, 0)
+	root.Collapsed = false
+	root.Size = documentSize(doc)
+	if !b.expired {
+		return root
+	}
+
+	root = &JSONNode{Value: doc, IsObject: true, Size: root.Size, Raw: true, RawOnly: true}
+	if err := setRawLines(root); err != nil {
+		root.RawLines = []*JSONNode{{RawText: fmt.Sprintf("can't render raw JSON: %v", err), Parent: root, Depth: 1}}
+	}
+	return root
+}
+
+// newDocumentTrees builds the trees of a page of documents. It runs in the
+// command that loaded them, off the event loop.
+func newDocumentTrees(docs []bson.M) []*JSONNode {
+	trees := make([]*JSONNode, len(docs))
+	for i, doc := range docs {
+		trees[i] = newDocumentTree(doc, treeBuildBudget)
+	}
+	return trees
+}
+
+// childCount is the number of children of an object or array, including
+// those behind its "show more" node
+func childCount(node *JSONNode) int {
+	n := len(node.Children)
+	if n > 0 && node.Children[n-1].More > 0 {
+		return n - 1 + node.Children[n-1].More
+	}
+	return n
+}
+
+// showMore replaces a "show more" node with the next children of its parent.
+// The cursor stays on the line, which is now the first of them.
+func (m *Model) showMore(more *JSONNode) tea.Cmd {
+	parent := more.Parent
+	parent.Children = parent.Children[:len(parent.Children)-1]
+	from := len(parent.Children)
+	if parent.IsObject {
+		from = 0 // The remaining keys start where the shown ones end
+	}
+	(&treeBuilder{}).appendChildren(parent, more.MoreKeys, from)
+	m.rebuildFlattenedTree()
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestWideArrayIsBuiltInChunks(t *testing.T) {
+	items := make(bson.A, 2500)
+	for i := range items {
+		items[i] = int32(i)
+	}
+	m := newTestModel(120, 30)
+	m.documents = []bson.M{{"_id": "wide", "items": items}}
+	m.docTree = []*JSONNode{m.buildDocumentTree(m.documents[0])}
+	arr := m.docTree[0].Children[1]
+	arr.Collapsed = false
+	m.rebuildFlattenedTree()
+
+	more := arr.Children[len(arr.Children)-1]
+	if len(arr.Children) != maxTreeChildren+1 || more.More != 1500 || childCount(arr) != 2500 {
+		t.Fatalf("children = %d, more = %d, count = %d", len(arr.Children), more.More, childCount(arr))
+	}
+	if line := normalizeRender(m.renderNode(more, 100)); !strings.Contains(line, "… 1500 more • enter: show more") {
+		t.Errorf("show more line = %q", line)
+	}
+
+	line := m.flattenedIndex(more)
+	m.moveDocCursor(line)
+	m = pressKey(m, "enter")
+	if m.docCursor != line || m.nodeAtCursor().Key != "[1000]" {
+		t.Errorf("cursor on %q, want the first new element", m.nodeAtCursor().Key)
+	}
+	m.moveDocCursor(m.flattenedIndex(arr.Children[len(arr.Children)-1]))
+	m = pressKey(m, " ")
+	if last := arr.Children[len(arr.Children)-1]; len(arr.Children) != 2500 || last.Key != "[2499]" || last.Value != int32(2499) {
+		t.Errorf("children = %d, last = %s: %v", len(arr.Children), last.Key, last.Value)
+	}
+}
+
+func TestWideObjectContinuesInKeyOrder(t *testing.T) {
+	doc := bson.M{}
+	for i := 0; i < 1200; i++ {
+		doc[fmt.Sprintf("k%04d", i)] = int32(i)
+	}
+	root := newDocumentTree(doc, treeBuildBudget)
+	more := root.Children[maxTreeChildren]
+	if more.More != 200 || root.Children[maxTreeChildren-1].Key != "k0999" {
+		t.Fatalf("more = %d after %q", more.More, root.Children[maxTreeChildren-1].Key)
+	}
+
+	m := newTestModel(120, 30)
+	m.docTree = []*JSONNode{root}
+	m.showMore(more)
+	if len(root.Children) != 1200 || root.Children[1000].Key != "k1000" || root.Children[1199].Key != "k1199" {
+		t.Errorf("children = %d: %s..%s", len(root.Children), root.Children[1000].Key, root.Children[1199].Key)
+	}
+}
+
+func TestSlowTreeFallsBackToRawView(t *testing.T) {
+	doc := largeDocument(2000)
+	root := newDocumentTree(doc, 0)
+	if !root.RawOnly || !root.Raw || root.Collapsed || len(root.Children) != 0 {
+		t.Fatalf("root: rawOnly = %v, raw = %v, %d children", root.RawOnly, root.Raw, len(root.Children))
+	}
+	if root.Size != documentSize(doc) || len(root.RawLines) == 0 || !strings.Contains(root.RawLines[0].RawText, `"_id"`) {
+		t.Errorf("size = %d, raw lines = %d", root.Size, len(root.RawLines))
+	}
+
+	m := newTestModel(120, 30)
+	m.documents = []bson.M{doc}
+	m.docTree = []*JSONNode{root}
+	m.rebuildFlattenedTree()
+	m.toggleRawView()
+	if !root.Raw || !strings.Contains(m.statusMessage, "too large for the tree view") {
+		t.Errorf("raw = %v, status = %q", root.Raw, m.statusMessage)
+	}
+	if view := normalizeRender(m.View()); !strings.Contains(view, "too large for the tree view") {
+		t.Errorf("view:\n%s", view)
+	}
+}
+
+func TestLoadedTreesAreUsedAsBuilt(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	docs := []bson.M{{"_id": 1, "name": "Ada"}}
+	trees := newDocumentTrees(docs)
+	m, _ = update(t, m, documentsLoadedMsg{namespace: m.currentNamespace(), documents: docs, trees: trees, totalCount: 1})
+	if len(m.docTree) != 1 || m.docTree[0] != trees[0] {
+		t.Error("the handler rebuilt the tree instead of using the loaded one")
+	}
+}
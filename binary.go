@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// maxHexDumpBytes caps the hex dump shown by the binary inspector; w still
+	// writes the whole payload
+	maxHexDumpBytes = 64 * 1024
+	// base64LineWidth wraps the base64 preview like PEM does
+	base64LineWidth = 64
+)
+
+// binaryUUID returns the canonical form of a UUID stored as binary subtype 3
+// (legacy) or 4. The bytes are shown in stored order; legacy drivers that
+// shuffled them decode to a different string than they were created from.
+func binaryUUID(b primitive.Binary) (string, bool) {
+	if (b.Subtype != 3 && b.Subtype != 4) || len(b.Data) != 16 {
+		return "", false
+	}
+	h := hex.EncodeToString(b.Data)
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32], true
+}
+
+// binaryLabel renders a binary value for the tree, e.g. Binary(0, 12 bytes)
+// or UUID("…") for UUID subtypes
+func binaryLabel(b primitive.Binary) string {
+	if uuid, ok := binaryUUID(b); ok {
+		return fmt.Sprintf("UUID(%q)", uuid)
+	}
+	if len(b.Data) == 1 {
+		return fmt.Sprintf("Binary(%d, 1 byte)", b.Subtype)
+	}
+	return fmt.Sprintf("Binary(%d, %d bytes)", b.Subtype, len(b.Data))
+}
+
+// binaryInspection renders a binary payload for the viewer: a hex dump
+// alongside its base64 encoding
+func binaryInspection(b primitive.Binary) string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "Subtype %d • %s\n", b.Subtype, formatBytes(len(b.Data)))
+	if uuid, ok := binaryUUID(b); ok {
+		fmt.Fprintf(&s, "UUID %s\n", uuid)
+	}
+
+	s.WriteString("\nHex:\n")
+	data := b.Data
+	if len(data) > maxHexDumpBytes {
+		data = data[:maxHexDumpBytes]
+	}
+	s.WriteString(hex.Dump(data))
+	if len(data) < len(b.Data) {
+		fmt.Fprintf(&s, "… %d more bytes\n", len(b.Data)-len(data))
+	}
+
+	s.WriteString("\nBase64:\n")
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > base64LineWidth {
+		s.WriteString(encoded[:base64LineWidth] + "\n")
+		encoded = encoded[base64LineWidth:]
+	}
+	s.WriteString(encoded + "\n")
+	if len(data) < len(b.Data) {
+		s.WriteString("…\n")
+	}
+	return s.String()
+}
+
+// inspectBinary opens the binary value under the cursor in the viewer
+func (m *Model) inspectBinary() tea.Cmd {
+	node := m.nodeAtCursor()
+	if node == nil {
+		return nil
+	}
+	b, ok := node.Value.(primitive.Binary)
+	if !ok {
+		return m.setStatus("not a binary value")
+	}
+	path := formatFieldPath(nodePathSegments(node))
+	m.openViewer(ViewerBinary, "Binary "+path, binaryInspection(b))
+	m.viewerBinary = b.Data
+	m.viewerSavePath = fileNamePart(m.selectedCollection+"-"+path) + ".bin"
+	return nil
+}
+
+// fileNamePart replaces the characters of s that don't belong in a file name
+func fileNamePart(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			return r
+		}
+		return '-'
+	}, s)
+}
+
+// saveViewerBinary writes the inspected payload to the viewer's save path
+func (m *Model) saveViewerBinary() tea.Cmd {
+	path := m.viewerSavePath
+	if err := os.WriteFile(path, m.viewerBinary, 0644); err != nil {
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Failed to write %s: %v", path, err)
+		return nil
+	}
+	size := len(m.viewerBinary)
+	m.closeViewer()
+	return m.setStatus(fmt.Sprintf("wrote %s to %s", formatBytes(size), path))
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestBinaryLabel(t *testing.T) {
+	uuid := []byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}
+	tests := []struct {
+		value primitive.Binary
+		want  string
+	}{
+		{primitive.Binary{Subtype: 0, Data: []byte("hello world!")}, "Binary(0, 12 bytes)"},
+		{primitive.Binary{Subtype: 128, Data: []byte{0}}, "Binary(128, 1 byte)"},
+		{primitive.Binary{Subtype: 4, Data: uuid}, `UUID("123e4567-e89b-12d3-a456-426614174000")`},
+		{primitive.Binary{Subtype: 3, Data: uuid}, `UUID("123e4567-e89b-12d3-a456-426614174000")`},
+		{primitive.Binary{Subtype: 4, Data: []byte{1, 2}}, "Binary(4, 2 bytes)"},
+	}
+	for _, tt := range tests {
+		if got := binaryLabel(tt.value); got != tt.want {
+			t.Errorf("binaryLabel(%v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestBinaryInspection(t *testing.T) {
+	text := binaryInspection(primitive.Binary{Subtype: 0, Data: []byte("hello world!")})
+	for _, want := range []string{
+		"Subtype 0 • 12 B",
+		"00000000  68 65 6c 6c 6f 20 77 6f  72 6c 64 21              |hello world!|",
+		"Base64:\naGVsbG8gd29ybGQh\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("inspection doesn't contain %q:\n%s", want, text)
+		}
+	}
+
+	large := binaryInspection(primitive.Binary{Data: make([]byte, maxHexDumpBytes+10)})
+	if !strings.Contains(large, "… 10 more bytes") {
+		t.Error("inspection of a large payload isn't capped")
+	}
+}
+
+func TestInspectAndSaveBinary(t *testing.T) {
+	payload := []byte{0xde, 0xad, 0xbe, 0xef}
+	m := newTestModel(120, 30)
+	m.documents = []bson.M{{"_id": int32(1), "blob": primitive.Binary{Data: payload}}}
+	m.docTree = []*JSONNode{buildJSONTree(m.documents[0], 0)}
+	m.rebuildFlattenedTree()
+	m.docCursor = 2 // _id, then blob
+
+	m.inspectBinary()
+	if m.viewerKind != ViewerBinary || m.viewerSavePath != "orders-blob.bin" {
+		t.Fatalf("viewer = %v saving to %q, want the binary viewer saving to orders-blob.bin", m.viewerKind, m.viewerSavePath)
+	}
+
+	m.viewerSavePath = filepath.Join(t.TempDir(), "blob.bin")
+	path := m.viewerSavePath
+	m.saveViewerBinary()
+	got, err := os.ReadFile(path)
+	if err != nil || !bytes.Equal(got, payload) {
+		t.Errorf("saved %x (%v), want %x", got, err, payload)
+	}
+	if m.viewerKind != ViewerNone {
+		t.Error("viewer still open after saving")
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// serverVersion is the major and minor version of the connected server. The
+// zero value means the version isn't known, and every feature is assumed to
+// be available.
+type serverVersion struct {
+	Major, Minor int
+}
+
+func (v serverVersion) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// known reports whether the version was detected
+func (v serverVersion) known() bool {
+	return v != serverVersion{}
+}
+
+// atLeast reports whether v is the same as or newer than other
+func (v serverVersion) atLeast(other serverVersion) bool {
+	if v.Major != other.Major {
+		return v.Major > other.Major
+	}
+	return v.Minor >= other.Minor
+}
+
+// parseServerVersion reads the major and minor version from a buildInfo
+// version string such as "4.0.28" or "7.0.2-rc1"
+func parseServerVersion(s string) (serverVersion, bool) {
+	parts := strings.SplitN(s, ".", 3)
+	if len(parts) < 2 {
+		return serverVersion{}, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return serverVersion{}, false
+	}
+	minor, err := strconv.Atoi(strings.TrimRightFunc(parts[1], func(r rune) bool { return r < '0' || r > '9' }))
+	if err != nil {
+		return serverVersion{}, false
+	}
+	return serverVersion{major, minor}, true
+}
+
+// Feature is a server feature mbongo relies on that older servers lack
+type Feature int
+
+const (
+	FeatureSample         Feature = iota // $sample, for schema analysis and server search
+	FeatureFacet                         // $facet and $sortByCount, for value counts
+	FeaturePipelineUpdate                // aggregation pipelines as updates
+	FeatureMerge                         // $merge, for cloning collections on the server
+	FeatureCurrentOp                     // $currentOp, for the operations in progress
+)
+
+// capability describes when a feature appeared and what mbongo does without it
+type capability struct {
+	name     string
+	since    serverVersion
+	fallback string // empty when the feature is disabled instead
+}
+
+// capabilities is the one place features are gated on the server version
+var capabilities = map[Feature]capability{
+	FeatureSample:         {name: "$sample", since: serverVersion{3, 2}, fallback: "sampling the first documents instead"},
+	FeatureFacet:          {name: "value counts", since: serverVersion{3, 4}},
+	FeaturePipelineUpdate: {name: "update pipelines", since: serverVersion{4, 2}},
+	FeatureMerge:          {name: "$merge", since: serverVersion{4, 2}, fallback: "cloning collections in batches instead"},
+	FeatureCurrentOp:      {name: "$currentOp", since: serverVersion{3, 6}},
+}
+
+// supports reports whether the server has a feature
+func (v serverVersion) supports(f Feature) bool {
+	return !v.known() || v.atLeast(capabilities[f].since)
+}
+
+// check explains why a feature isn't available, or returns nil when it is
+func (v serverVersion) check(f Feature) error {
+	if v.supports(f) {
+		return nil
+	}
+	c := capabilities[f]
+	return fmt.Errorf("%s need MongoDB %s or newer; the server is %s", c.name, c.since, v)
+}
+
+// missingFeatures lists the features the server lacks, in Feature order,
+// noting the fallback used for each one that has one
+func (v serverVersion) missingFeatures() []string {
+	var missing []string
+	for f := FeatureSample; f <= FeatureCurrentOp; f++ {
+		if v.supports(f) {
+			continue
+		}
+		c := capabilities[f]
+		if c.fallback != "" {
+			missing = append(missing, fmt.Sprintf("%s (%s)", c.name, c.fallback))
+		} else {
+			missing = append(missing, c.name)
+		}
+	}
+	return missing
+}
+
+// unsupported shows why a feature is unavailable on the connected server and
+// returns the toast, or returns nil when the server supports it
+func (m *Model) unsupported(f Feature) tea.Cmd {
+	if err := m.serverVersion.check(f); err != nil {
+		return m.setStatus(err.Error())
+	}
+	return nil
+}
+
+// serverVersionMsg carries the version reported by buildInfo
+type serverVersionMsg struct {
+	version string
+	err     error
+}
+
+// loadServerVersion asks the server for its version
+func loadServerVersion(client *mongo.Client) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var buildInfo struct {
+			Version string `bson:"version"`
+		}
+		err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&buildInfo)
+		return serverVersionMsg{version: buildInfo.Version, err: err}
+	}
+}
+
+// handleServerVersion records the server version and warns about the
+// features it lacks. A version that can't be read leaves every feature on.
+func (m *Model) handleServerVersion(msg serverVersionMsg) tea.Cmd {
+	if msg.err != nil {
+		return nil
+	}
+	version, ok := parseServerVersion(msg.version)
+	if !ok {
+		return nil
+	}
+	m.serverVersion = version
+	if missing := version.missingFeatures(); len(missing) > 0 {
+		return m.setStatus(fmt.Sprintf("MongoDB %s lacks %s", msg.version, strings.Join(missing, ", ")))
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseServerVersion(t *testing.T) {
+	for s, want := range map[string]serverVersion{
+		"4.0.28":      {4, 0},
+		"7.0.2-rc1":   {7, 0},
+		"3.6":         {3, 6},
+		"8.1.0-alpha": {8, 1},
+	} {
+		if got, ok := parseServerVersion(s); !ok || got != want {
+			t.Errorf("parseServerVersion(%q) = %v, %v, want %v", s, got, ok, want)
+		}
+	}
+	for _, bad := range []string{"", "4", "x.y.z"} {
+		if _, ok := parseServerVersion(bad); ok {
+			t.Errorf("parseServerVersion(%q) accepted", bad)
+		}
+	}
+}
+
+func TestCapabilitiesPerVersion(t *testing.T) {
+	tests := []struct {
+		version                       serverVersion
+		sample, facet, pipelineUpdate bool
+	}{
+		{serverVersion{}, true, true, true},
+		{serverVersion{3, 0}, false, false, false},
+		{serverVersion{3, 2}, true, false, false},
+		{serverVersion{3, 6}, true, true, false},
+		{serverVersion{4, 0}, true, true, false},
+		{serverVersion{4, 2}, true, true, true},
+		{serverVersion{7, 0}, true, true, true},
+		{serverVersion{10, 0}, true, true, true},
+	}
+	for _, tt := range tests {
+		got := []bool{tt.version.supports(FeatureSample), tt.version.supports(FeatureFacet), tt.version.supports(FeaturePipelineUpdate)}
+		if want := []bool{tt.sample, tt.facet, tt.pipelineUpdate}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%v supports $sample, $facet, pipeline updates = %v, want %v", tt.version, got, want)
+		}
+	}
+}
+
+func TestServerVersionWarnsAboutMissingFeatures(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.handleServerVersion(serverVersionMsg{version: "3.0.15"})
+	if m.serverVersion != (serverVersion{3, 0}) {
+		t.Errorf("serverVersion = %v", m.serverVersion)
+	}
+	want := "MongoDB 3.0.15 lacks $sample (sampling the first documents instead), value counts, update pipelines, $merge (cloning collections in batches instead), $currentOp"
+	if m.statusMessage != want {
+		t.Errorf("status = %q, want %q", m.statusMessage, want)
+	}
+
+	m = newTestModel(120, 30)
+	m.handleServerVersion(serverVersionMsg{version: "6.0.4"})
+	if m.statusMessage != "" {
+		t.Errorf("status = %q, want no warning on a current server", m.statusMessage)
+	}
+}
+
+func TestOldServerDisablesFeatures(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m.serverVersion = serverVersion{3, 2}
+	m.moveDocCursor(1)
+	m.openValueFrequency()
+	if m.freqActive || !strings.Contains(m.statusMessage, "value counts need MongoDB 3.4 or newer; the server is 3.2") {
+		t.Errorf("value counts: active = %v, status = %q", m.freqActive, m.statusMessage)
+	}
+
+	m.previewUpdate(`[{"$set": {"total": {"$add": ["$total", 1]}}}]`)
+	if m.pendingUpdate != nil || !strings.Contains(m.errorMessage, "update pipelines need MongoDB 4.2") {
+		t.Errorf("pipeline update: pending = %v, error = %q", m.pendingUpdate, m.errorMessage)
+	}
+}
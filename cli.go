@@ -0,0 +1,396 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
+)
+
+// launchOptions is the connection given on the command line, which skips
+// the connections screen, and where to open it
+type launchOptions struct {
+	uri        string
+	ssh        string // SSH alias to tunnel through
+	db         string
+	collection string
+	query      string // Initial filter of the collection, in query syntax
+}
+
+// parseLaunchOptions reads the command line of the TUI: a connection string
+// given with --uri or as the only argument, the SSH alias to reach it
+// through, and the database, collection and filter to open. Usage is
+// printed on errors.
+func parseLaunchOptions(args []string, stderr io.Writer) (launchOptions, error) {
+	var opts launchOptions
+	flags := flag.NewFlagSet("mbongo", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&opts.uri, "uri", "", "connection string to connect to, skipping the connections screen")
+	flags.StringVar(&opts.ssh, "ssh", "", "SSH alias from ~/.ssh/config to tunnel the connection through")
+	flags.StringVar(&opts.db, "db", "", "database to open once connected")
+	flags.StringVar(&opts.collection, "collection", "", "collection of --db to show the documents of")
+	flags.StringVar(&opts.query, "query", "", `filter of the collection, e.g. '{status: "failed"}'`)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: mbongo [--uri] [mongodb://...] [--ssh alias] [--db database [--collection collection [--query filter]]]")
+		fmt.Fprintln(stderr, "       mbongo get [--conn name] --db database --collection collection <_id>")
+		flags.PrintDefaults()
+	}
+	// Flags may follow the connection string, where flag.Parse stops
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return opts, err
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+	fail := func(err error) (launchOptions, error) {
+		fmt.Fprintf(stderr, "mbongo: %v\n", err)
+		flags.Usage()
+		return opts, err
+	}
+	switch {
+	case len(positional) > 1:
+		return fail(fmt.Errorf("unexpected arguments: %s", strings.Join(positional[1:], " ")))
+	case len(positional) == 1 && opts.uri != "":
+		return fail(errors.New("give the connection string either with --uri or as an argument, not both"))
+	case len(positional) == 1:
+		opts.uri = positional[0]
+	}
+	switch {
+	case opts.uri == "" && opts.ssh != "":
+		return fail(errors.New("--ssh needs a connection string to tunnel"))
+	case opts.uri == "" && opts.db != "":
+		return fail(errors.New("--db needs a connection string"))
+	case opts.db == "" && opts.collection != "":
+		return fail(errors.New("--collection needs --db"))
+	case opts.collection == "" && opts.query != "":
+		return fail(errors.New("--query needs --collection"))
+	}
+	if opts.uri == "" {
+		return opts, nil
+	}
+	if _, err := connstring.ParseAndValidate(opts.uri); err != nil {
+		return fail(fmt.Errorf("invalid connection string: %w", err))
+	}
+	if opts.query != "" {
+		if _, err := parseQueryFilter(opts.query); err != nil {
+			return fail(fmt.Errorf("invalid --query: %w", err))
+		}
+	}
+	return opts, nil
+}
+
+// connectFromCommandLine connects to the connection given on the command
+// line. A saved connection to the same cluster lends its name, so its
+// settings apply; otherwise the connection is named after its hosts.
+func (m *Model) connectFromCommandLine() tea.Cmd {
+	opts := m.launch
+	// The database and collection are opened once listed
+	m.launch.uri = ""
+	m.launch.ssh = ""
+	m.connectionName = strings.TrimSuffix(normalizeConnectionString(opts.uri), "/")
+	if i := m.findDuplicateConnection(opts.uri, -1); i >= 0 {
+		m.connectionName = m.connections[i].Name
+		m.connectionEnv = m.connections[i].Environment
+		m.connectionColor = m.connections[i].Color
+	}
+	m.connectionString = opts.uri
+	m.sshAlias = opts.ssh
+	m.screen = ScreenMain
+	m.loading = true
+	return m.connect()
+}
+
+// isTerminal reports whether f is a terminal rather than a pipe or a file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// mergeConnections returns the default connections followed by the saved
+// ones, skipping saved connections named like a default
+func mergeConnections(saved []Connection) []Connection {
+	connections := append([]Connection{}, defaultConnections...)
+	for _, conn := range saved {
+		// Don't duplicate if name matches a default
+		isDuplicate := false
+		for _, def := range defaultConnections {
+			if conn.Name == def.Name {
+				isDuplicate = true
+				break
+			}
+		}
+		if !isDuplicate {
+			connections = append(connections, conn)
+		}
+	}
+	return connections
+}
+
+// findConnection returns the saved connection with the given name
+func findConnection(name string) (Connection, error) {
+	// Reading connections is safe in a database from a newer mbongo
+	var tooNew *schemaTooNewError
+	if err := initDB(); err != nil && !errors.As(err, &tooNew) {
+		return Connection{}, err
+	}
+	// The TUI opens the store again for itself
+	defer func() {
+		closeDB()
+		db = nil
+	}()
+	if err := unlockFromEnv(); err != nil {
+		return Connection{}, err
+	}
+	saved, err := loadConnections()
+	if err != nil {
+		return Connection{}, err
+	}
+	for _, conn := range mergeConnections(saved) {
+		if conn.Name == name {
+			return conn, nil
+		}
+	}
+	return Connection{}, fmt.Errorf("no saved connection named %q", name)
+}
+
+// openConnection connects to a saved connection the way the connections
+// screen does, through its SSH tunnel when it has one. It returns the
+// connection string actually used.
+func openConnection(ctx context.Context, conn Connection) (*mongo.Client, *SSHTunnel, string, error) {
+	connStr := conn.ConnectionString
+	if needsPassword(connStr) {
+		return nil, nil, "", fmt.Errorf("%s asks for its password when connecting, which only the TUI does", conn.Name)
+	}
+	var tunnel *SSHTunnel
+	if conn.SSHAlias != "" {
+		var err error
+		tunnel, err = NewSSHTunnel(conn.SSHAlias, ParseMongoHostPort(connStr))
+		if err != nil {
+			return nil, nil, "", err
+		}
+		connStr = BuildTunneledConnectionString(connStr, tunnel.LocalAddr())
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(connStr))
+	if err == nil {
+		err = client.Ping(ctx, nil)
+	}
+	if err != nil {
+		if tunnel != nil {
+			tunnel.Close()
+		}
+		return nil, nil, "", err
+	}
+	return client, tunnel, connStr, nil
+}
+
+// closeConnection disconnects a client opened by openConnection and closes
+// its tunnel. Either may be nil.
+func closeConnection(client *mongo.Client, tunnel *SSHTunnel) {
+	if client != nil {
+		client.Disconnect(context.Background())
+	}
+	if tunnel != nil {
+		tunnel.Close()
+	}
+}
+
+// openLaunchDatabase opens the database given on the command line once the
+// databases are listed. A database that isn't listed is explained in the
+// error modal and nil is returned, leaving the usual first selection. Users
+// who may not list databases get the database they asked for.
+func (m *Model) openLaunchDatabase(listDenied bool) tea.Cmd {
+	dbName := m.launch.db
+	if listDenied && indexOfString(m.databases, dbName) < 0 {
+		m.databases = append(m.databases, dbName)
+		sort.Strings(m.databases)
+	}
+	if indexOfString(m.databases, dbName) < 0 {
+		m.launch = launchOptions{}
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Database %q not found on %s: it doesn't exist or you can't list it", dbName, m.connectionName)
+		return nil
+	}
+	m.selectedDatabase = dbName
+	// The open database stays listed when system databases are hidden
+	m.updateFilteredDatabases()
+	m.dbCursor = clampIndex(indexOfString(m.dbFiltered, dbName), len(m.dbFiltered))
+	m.focus = FocusCollections
+	m.explicitDBSelect = true
+	if m.launch.collection == "" {
+		m.launch = launchOptions{}
+	}
+	return loadCollections(m.client, dbName)
+}
+
+// openLaunchCollection shows the documents of the collection given on the
+// command line once the collections of its database are listed, filtered
+// by --query. A collection that isn't listed is explained in the error modal.
+func (m *Model) openLaunchCollection() tea.Cmd {
+	opts := m.launch
+	m.launch = launchOptions{}
+	if indexOfString(m.collections, opts.collection) < 0 {
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Collection %q not found in %s", opts.collection, opts.db)
+		return nil
+	}
+	m.selectedCollection = opts.collection
+	// The open collection stays listed when system collections are hidden
+	m.updateFilteredCollections()
+	m.collCursor = clampIndex(indexOfString(m.collFiltered, opts.collection), len(m.collFiltered))
+	m.acknowledgeWatch(m.currentNamespace())
+	m.loadCollectionSettings()
+	m.queryFilter = bson.M{}
+	m.queryText = "{}"
+	if opts.query != "" {
+		// Checked when the command line was read
+		m.queryFilter, _ = parseQueryFilter(opts.query)
+		m.queryText = opts.query
+	}
+	m.queryCursor = len(m.queryText)
+	m.loadingDocs = true
+	m.docScrollOffset = 0
+	m.docCursor = 0
+	m.currentPage = 0
+	m.focus = FocusDocuments
+	return loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, 0, m.docsPerPage, m.queryFilter, m.sortSpec())
+}
+
+// documentIDCandidates returns the _id values an _id typed on the command
+// line may stand for, most likely first. Bare ObjectIds and UUIDs, as they
+// appear in log lines, may also be stored as strings; other text is read as
+// a value in query syntax (42, "abc", ObjectId("...")) and otherwise taken
+// as a plain string.
+func documentIDCandidates(text string) []interface{} {
+	text = strings.TrimSpace(text)
+	if objectIDPattern.MatchString(text) {
+		id, _ := primitive.ObjectIDFromHex(text)
+		return []interface{}{id, text}
+	}
+	if uuidPattern.MatchString(text) {
+		wrapper, _ := parseQueryFilter(`{"_id": UUID("` + text + `")}`)
+		return []interface{}{wrapper["_id"], text}
+	}
+	if wrapper, err := parseQueryFilter(`{"_id": ` + text + `}`); err == nil {
+		return []interface{}{wrapper["_id"]}
+	}
+	return []interface{}{text}
+}
+
+// fetchDocumentByID finds the document with one of the candidate _ids
+func fetchDocumentByID(ctx context.Context, coll *mongo.Collection, candidates []interface{}) (interface{}, bson.M, error) {
+	for _, id := range candidates {
+		var doc bson.M
+		err := coll.FindOne(ctx, bson.M{"_id": id}).Decode(&doc)
+		if err == nil {
+			return id, doc, nil
+		}
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil, err
+		}
+	}
+	return nil, nil, fmt.Errorf("no document with _id %v in %s", candidates[0], namespaceOf(coll.Database().Name(), coll.Name()))
+}
+
+// runGet implements "mbongo get": fetch one document by _id and print it as
+// Extended JSON, or open the TUI on it when stdout is a terminal
+func runGet(args []string, stdout, stderr io.Writer, tty bool) error {
+	flags := flag.NewFlagSet("get", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	connName := flags.String("conn", defaultConnections[0].Name, "name of a saved connection")
+	dbName := flags.String("db", "", "database")
+	collName := flags.String("collection", "", "collection")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: mbongo get [--conn name] --db database --collection collection <_id>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *dbName == "" || *collName == "" || flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("--db, --collection and one _id are required")
+	}
+
+	conn, err := findConnection(*connName)
+	if err != nil {
+		return err
+	}
+	client, tunnel, connStr, err := openConnection(context.Background(), conn)
+	if err != nil {
+		return fmt.Errorf("connecting to %s: %w", conn.Name, err)
+	}
+	defer closeConnection(client, tunnel)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	id, doc, err := fetchDocumentByID(ctx, client.Database(*dbName).Collection(*collName), documentIDCandidates(flags.Arg(0)))
+	if err != nil {
+		return err
+	}
+
+	if !tty {
+		// Relaxed Extended JSON keeps ObjectIds and dates intact while staying readable
+		jsonBytes, err := bson.MarshalExtJSONIndent(doc, false, false, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(stdout, "%s\n", jsonBytes)
+		return err
+	}
+
+	m := initialModel()
+	m.showDocument(conn, client, tunnel, connStr, *dbName, *collName, id, doc)
+	final, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+	removeKeptEdits(final)
+	return err
+}
+
+// showDocument starts the TUI connected and showing one document full
+// screen, as if it had been opened from the collection
+func (m *Model) showDocument(conn Connection, client *mongo.Client, tunnel *SSHTunnel, connStr, dbName, collName string, id interface{}, doc bson.M) {
+	m.screen = ScreenMain
+	m.useConnection(conn)
+	m.activeConnString = connStr
+	m.client = client
+	m.sshTunnel = tunnel
+
+	m.databases = []string{dbName}
+	m.updateFilteredDatabases()
+	m.selectedDatabase = dbName
+	m.collections = []string{collName}
+	m.updateFilteredCollections()
+	m.selectedCollection = collName
+	m.loadCollectionSettings()
+
+	m.queryFilter = bson.M{"_id": id}
+	if text, err := bson.MarshalExtJSON(m.queryFilter, false, false); err == nil {
+		m.queryText = string(text)
+		m.queryCursor = len(m.queryText)
+	}
+	m.documents = []bson.M{doc}
+	m.totalDocs = 1
+	m.docTree = []*JSONNode{m.buildDocumentTree(doc)}
+	m.focus = FocusDocuments
+	m.rebuildFlattenedTree()
+	m.toggleFullscreen()
+}
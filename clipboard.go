@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// copyToClipboard writes text to the system clipboard.
+// Native utilities (pbcopy/xclip/xsel/wl-copy) are tried first; when none are
+// available (e.g. running over SSH) we fall back to an OSC52 escape sequence,
+// which asks the terminal emulator itself to set the clipboard.
+func copyToClipboard(text string) error {
+	if !clipboard.Unsupported {
+		if err := clipboard.WriteAll(text); err == nil {
+			return nil
+		}
+	}
+
+	seq := osc52.New(text)
+	if os.Getenv("TMUX") != "" {
+		seq = seq.Tmux()
+	} else if strings.HasPrefix(os.Getenv("TERM"), "screen") {
+		seq = seq.Screen()
+	}
+	_, err := seq.WriteTo(os.Stderr)
+	return err
+}
+
+// copyTextCmd copies text to the clipboard in the background
+func copyTextCmd(text string) tea.Cmd {
+	return func() tea.Msg {
+		if err := copyToClipboard(text); err != nil {
+			return clipboardCopiedMsg{err: err}
+		}
+		return clipboardCopiedMsg{size: len(text)}
+	}
+}
+
+// formatBytes formats a byte count for display (e.g. "1.2 KB")
+func formatBytes(n int) string {
+	switch {
+	case n < 1024:
+		return fmt.Sprintf("%d B", n)
+	case n < 1024*1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	}
+}
+
+// rawValueString returns a value as plain text for copying: strings are
+// unquoted, ObjectIds are bare hex, and objects/arrays become Extended JSON
+func rawValueString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case primitive.ObjectID:
+		return v.Hex()
+	case primitive.DateTime:
+		return v.Time().UTC().Format(time.RFC3339Nano)
+	case primitive.Decimal128:
+		return v.String()
+	case primitive.Binary:
+		if uuid, ok := binaryUUID(v); ok {
+			return uuid
+		}
+		return base64.StdEncoding.EncodeToString(v.Data)
+	case bson.M:
+		jsonBytes, err := bson.MarshalExtJSONIndent(v, false, false, "", "  ")
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(jsonBytes)
+	case bson.A:
+		// Arrays can't be marshaled on their own, so wrap them in a document
+		jsonBytes, err := bson.MarshalExtJSON(bson.M{"v": v}, false, false)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		var wrapper map[string]json.RawMessage
+		if err := json.Unmarshal(jsonBytes, &wrapper); err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, wrapper["v"], "", "  "); err != nil {
+			return string(wrapper["v"])
+		}
+		return indented.String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
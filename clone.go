@@ -0,0 +1,316 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// cloneProgressInterval is how often a $merge clone counts the documents
+// that arrived so far
+const cloneProgressInterval = time.Second
+
+// cloneDoneMsg is sent when a collection clone finishes
+type cloneDoneMsg struct {
+	dbName   string
+	source   string
+	target   string
+	copied   int64 // Documents in the clone
+	indexes  int   // Indexes created besides _id
+	indexErr error // Why the indexes weren't all created, with the documents copied
+	err      error
+}
+
+// newCloneNameInput creates the textinput for the name of a clone
+func newCloneNameInput() textinput.Model {
+	ti := textinput.New()
+	ti.CharLimit = 255
+	ti.Width = 50
+	return ti
+}
+
+// openClonePrompt opens the prompt to clone the collection under the cursor,
+// suggesting a name for the clone
+func (m *Model) openClonePrompt() tea.Cmd {
+	if len(m.collFiltered) == 0 || m.selectedDatabase == "" || m.client == nil {
+		return nil
+	}
+	m.cloneActive = true
+	m.cloneSource = m.collFiltered[m.collCursor]
+	m.cloneIndexes = m.collSpecs[m.cloneSource].Kind != KindView
+	m.cloneErr = ""
+	m.cloneNameInput.SetValue(m.cloneSource + "_copy")
+	m.cloneNameInput.CursorEnd()
+	m.cloneNameInput.Focus()
+	return textinput.Blink
+}
+
+// handleClonePromptKey handles keyboard input in the clone prompt
+func (m *Model) handleClonePromptKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "ctrl+g":
+		m.cloneActive = false
+		m.cloneNameInput.Blur()
+		return nil
+	case "tab":
+		// Views have no indexes to copy
+		if m.collSpecs[m.cloneSource].Kind != KindView {
+			m.cloneIndexes = !m.cloneIndexes
+		}
+		return nil
+	case "enter":
+		name := strings.TrimSpace(m.cloneNameInput.Value())
+		if err := validateCollectionName(m.selectedDatabase, name, m.collections, m.serverVersion); err != nil {
+			m.cloneErr = err.Error()
+			return nil
+		}
+		m.cloneActive = false
+		m.cloneNameInput.Blur()
+		return m.startClone(name)
+	}
+	var cmd tea.Cmd
+	m.cloneNameInput, cmd = m.cloneNameInput.Update(msg)
+	m.cloneErr = ""
+	return cmd
+}
+
+// startClone copies the prompt's collection to target in a background job
+func (m *Model) startClone(target string) tea.Cmd {
+	db := m.client.Database(m.selectedDatabase)
+	source, indexes := m.cloneSource, m.cloneIndexes
+	merge := m.serverVersion.supports(FeatureMerge)
+	name := fmt.Sprintf("clone %s to %s", namespaceOf(db.Name(), source), target)
+	return m.startJob(name, func(ctx context.Context, report func(string)) (tea.Msg, error) {
+		return runClone(ctx, report, db, source, target, indexes, merge)
+	})
+}
+
+// runClone is the job that copies the documents of source into the new
+// collection target, then its indexes if asked. A cancelled clone drops
+// what it copied so far, so no half copy is left behind under the new name.
+func runClone(ctx context.Context, report func(string), db *mongo.Database, source, target string, indexes, merge bool) (tea.Msg, error) {
+	msg := cloneDoneMsg{dbName: db.Name(), source: source, target: target}
+	from, to := db.Collection(source), db.Collection(target)
+
+	// The prompt checked the name against a list that may be stale. The
+	// server has the last word, since dropping on cancel is only safe for
+	// a target this clone created.
+	names, err := db.ListCollectionNames(ctx, bson.D{{Key: "name", Value: target}})
+	if err == nil && len(names) > 0 {
+		err = fmt.Errorf("collection %q already exists", target)
+	}
+	if err != nil {
+		msg.err = err
+		return msg, err
+	}
+
+	if merge {
+		msg.err = mergeClone(ctx, report, from, to)
+	} else {
+		msg.err = batchClone(ctx, report, from, to, &msg.copied)
+	}
+	if msg.err != nil {
+		if ctx.Err() != nil {
+			dropCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			to.Drop(dropCtx)
+		}
+		return msg, msg.err
+	}
+	if merge {
+		msg.copied, _ = to.CountDocuments(ctx, bson.D{})
+	}
+
+	if indexes {
+		report("building indexes")
+		msg.indexes, msg.indexErr = cloneIndexes(ctx, db, from, target)
+	}
+	return msg, nil
+}
+
+// mergeClone copies the documents with a $merge aggregation, which the
+// server runs without sending them through mbongo. The documents in the
+// target are counted while it runs to show progress. Cancelling closes the
+// connection, which makes servers with $merge stop the aggregation.
+func mergeClone(ctx context.Context, report func(string), from, to *mongo.Collection) error {
+	pipeline := mongo.Pipeline{{{Key: "$merge", Value: bson.D{
+		{Key: "into", Value: to.Name()},
+		{Key: "whenMatched", Value: "fail"},
+		{Key: "whenNotMatched", Value: "insert"},
+	}}}}
+	done := make(chan error, 1)
+	go func() {
+		cursor, err := from.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+		if err == nil {
+			cursor.Close(ctx)
+		}
+		done <- err
+	}()
+
+	ticker := time.NewTicker(cloneProgressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-done:
+			return err
+		case <-ticker.C:
+			if n, err := to.EstimatedDocumentCount(ctx); err == nil {
+				report(pluralDocuments(n) + " copied")
+			}
+		}
+	}
+}
+
+// batchClone copies the documents by reading them and inserting them in
+// batches, for servers without $merge
+func batchClone(ctx context.Context, report func(string), from, to *mongo.Collection, copied *int64) error {
+	cursor, err := from.Find(ctx, bson.D{}, options.Find().SetBatchSize(importBatchSize))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	batch := make([]interface{}, 0, importBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if _, err := to.InsertMany(ctx, batch); err != nil {
+			return err
+		}
+		*copied += int64(len(batch))
+		batch = batch[:0]
+		report(pluralDocuments(*copied) + " copied")
+		return nil
+	}
+	for cursor.Next(ctx) {
+		// Current is only valid until the next call to Next
+		batch = append(batch, append(bson.Raw(nil), cursor.Current...))
+		if len(batch) == importBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// cloneIndexes creates the indexes of from, all but _id, on the collection
+// target. The specs are sent back as listIndexes returns them, less the
+// fields that name the source, so every option carries over.
+func cloneIndexes(ctx context.Context, db *mongo.Database, from *mongo.Collection, target string) (int, error) {
+	cursor, err := from.Indexes().List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var specs []bson.D
+	if err := cursor.All(ctx, &specs); err != nil {
+		return 0, err
+	}
+
+	indexes := bson.A{}
+	for _, spec := range specs {
+		copied := bson.D{}
+		isID := false
+		for _, e := range spec {
+			switch e.Key {
+			case "v", "ns":
+				continue
+			case "name":
+				isID = e.Value == "_id_"
+			}
+			copied = append(copied, e)
+		}
+		if !isID {
+			indexes = append(indexes, copied)
+		}
+	}
+	if len(indexes) == 0 {
+		return 0, nil
+	}
+	cmd := bson.D{{Key: "createIndexes", Value: target}, {Key: "indexes", Value: indexes}}
+	if err := db.RunCommand(ctx, cmd).Err(); err != nil {
+		return 0, err
+	}
+	return len(indexes), nil
+}
+
+// handleCloneDone reports a finished clone and lists the new collection,
+// which a failed clone leaves with the documents it got to
+func (m *Model) handleCloneDone(msg cloneDoneMsg) tea.Cmd {
+	var refresh tea.Cmd
+	if msg.dbName == m.selectedDatabase && m.client != nil {
+		refresh = refreshCollections(m.client, msg.dbName)
+	}
+	if msg.err != nil {
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Cloning %s to %s failed: %v", namespaceOf(msg.dbName, msg.source), msg.target, friendlyNamespaceError(msg.err))
+		return refresh
+	}
+	summary := fmt.Sprintf("cloned %s to %s: %s", truncateMiddle(msg.source, maxToastNameWidth), truncateMiddle(msg.target, maxToastNameWidth), pluralDocuments(msg.copied))
+	switch {
+	case msg.indexErr != nil:
+		summary += fmt.Sprintf(", indexes not copied: %v", msg.indexErr)
+	case msg.indexes > 0:
+		summary += fmt.Sprintf(", %d %s", msg.indexes, pluralize(int64(msg.indexes), "index", "indexes"))
+	}
+	return tea.Batch(m.setStatus(summary), refresh)
+}
+
+// renderClonePrompt renders the clone prompt modal
+func (m Model) renderClonePrompt(background string) string {
+	width := m.modalWidth(70)
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+
+	indexes := "[ ] Copy indexes"
+	if m.cloneIndexes {
+		indexes = "[x] Copy indexes"
+	}
+	if m.collSpecs[m.cloneSource].Kind == KindView {
+		indexes = "The view's documents are copied into a plain collection"
+	}
+
+	lines := []string{
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render(truncate("Clone "+namespaceOf(m.selectedDatabase, m.cloneSource), width-4)),
+		"",
+		"New collection name:",
+		fitInput(m.cloneNameInput, width-4),
+		"",
+		indexes,
+	}
+	if m.cloneErr != "" {
+		lines = append(lines, "", lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Width(width-4).Render(m.cloneErr))
+	}
+	lines = append(lines, "", hintStyle.Render("enter: clone • tab: toggle indexes • esc: cancel"))
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("205")).
+		Padding(1, 2).
+		Width(width)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClonePrompt(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m.focus = FocusCollections
+
+	m = pressKey(m, "C")
+	if !m.cloneActive || m.cloneSource != "orders" || m.cloneNameInput.Value() != "orders_copy" || !m.cloneIndexes {
+		t.Fatalf("active = %v, source %q, name %q, indexes %v", m.cloneActive, m.cloneSource, m.cloneNameInput.Value(), m.cloneIndexes)
+	}
+	m = pressKey(m, "tab")
+	if view := normalizeRender(m.View()); m.cloneIndexes || !strings.Contains(view, "[ ] Copy indexes") {
+		t.Errorf("tab didn't turn off copying indexes:\n%s", view)
+	}
+
+	m.cloneNameInput.SetValue("customers")
+	m = pressKey(m, "enter")
+	if !m.cloneActive || !strings.Contains(m.cloneErr, `collection "customers" already exists`) {
+		t.Fatalf("taken name: active = %v, error %q", m.cloneActive, m.cloneErr)
+	}
+
+	m.cloneNameInput.SetValue("orders_2024")
+	m = pressKey(m, "enter")
+	if m.cloneActive || len(m.jobs) != 1 || m.jobs[0].Name != "clone shop.orders to orders_2024" {
+		t.Fatalf("active = %v, jobs %+v", m.cloneActive, m.jobs)
+	}
+}
+
+func TestCloneOfViewCopiesNoIndexes(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m.focus = FocusCollections
+	m.collSpecs = map[string]collectionSpec{"orders": {Kind: KindView}}
+
+	m = pressKey(m, "C")
+	m = pressKey(m, "tab")
+	if view := normalizeRender(m.View()); m.cloneIndexes || !strings.Contains(view, "copied into a plain collection") {
+		t.Errorf("indexes = %v:\n%s", m.cloneIndexes, view)
+	}
+}
+
+func TestCloneDone(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+
+	m, cmd := update(t, m, cloneDoneMsg{dbName: "shop", source: "orders", target: "orders_2024", copied: 1200, indexes: 2})
+	if cmd == nil || m.statusMessage != "cloned orders to orders_2024: 1,200 documents, 2 indexes" {
+		t.Errorf("status %q", m.statusMessage)
+	}
+
+	m, _ = update(t, m, cloneDoneMsg{dbName: "shop", source: "orders", target: "orders_2024", copied: 1200, indexErr: errors.New("index key too large")})
+	if !strings.Contains(m.statusMessage, "indexes not copied: index key too large") {
+		t.Errorf("status %q", m.statusMessage)
+	}
+
+	m, cmd = update(t, m, cloneDoneMsg{dbName: "shop", source: "orders", target: "orders_2024", err: errors.New("connection reset")})
+	if cmd == nil || !m.errorModal || !strings.Contains(m.errorMessage, "Cloning shop.orders to orders_2024 failed") {
+		t.Errorf("modal = %v %q", m.errorModal, m.errorMessage)
+	}
+}
+
+func TestCancelledCloneLeavesUncheckedTargetAlone(t *testing.T) {
+	db := offlineClient(t).Database("shop")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Dropping would wait for the unreachable server until its timeout
+	start := time.Now()
+	msg, err := runClone(ctx, func(string) {}, db, "orders", "customers", true, true)
+	if done, ok := msg.(cloneDoneMsg); !ok || done.err == nil || err == nil {
+		t.Fatalf("clone = %+v, %v", msg, err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("clone took %v: dropped a target it didn't create", elapsed)
+	}
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"strings"
+
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"go.mongodb.org/mongo-driver/bson"
@@ -9,22 +11,17 @@ import (
 // updateFilteredCollections updates the filtered collections based on search input
 func (m *Model) updateFilteredCollections() {
 	query := m.collSearchInput.Value()
-	if query == "" {
-		// No filter - show all collections
-		m.collFiltered = m.collections
-		m.collFilteredIndices = make([]int, len(m.collections))
-		for i := range m.collections {
-			m.collFilteredIndices[i] = i
+	// Filter collections by fuzzy match, leaving out the hidden system.*
+	// ones. collFilteredIndices maps the shown entries back to m.collections.
+	m.collFiltered = []string{}
+	m.collFilteredIndices = []int{}
+	for i, coll := range m.collections {
+		if m.hiddenCollection(coll) {
+			continue
 		}
-	} else {
-		// Filter collections by fuzzy match
-		m.collFiltered = []string{}
-		m.collFilteredIndices = []int{}
-		for i, coll := range m.collections {
-			if fuzzyMatch(query, coll) {
-				m.collFiltered = append(m.collFiltered, coll)
-				m.collFilteredIndices = append(m.collFilteredIndices, i)
-			}
+		if query == "" || fuzzyMatch(query, coll) {
+			m.collFiltered = append(m.collFiltered, coll)
+			m.collFilteredIndices = append(m.collFilteredIndices, i)
 		}
 	}
 	// Reset cursor if out of bounds
@@ -61,10 +58,17 @@ func (m *Model) handleCollectionSearchKey(msg tea.KeyMsg) (tea.Cmd, bool) {
 			m.collCursor--
 		}
 		return nil, true
+	case "pgup", "pgdown":
+		// Page through the filtered list (home/end stay with the input)
+		m.collCursor, _ = pageKeyTarget(msg.String(), m.collCursor, m.getListHeight()-1, len(m.collFiltered))
+		return nil, true
 	case "enter":
 		// Select the highlighted collection
 		if len(m.collFiltered) > 0 && m.client != nil && m.selectedDatabase != "" {
 			m.selectedCollection = m.collFiltered[m.collCursor]
+			m.loadCollectionSettings()
+			m.acknowledgeWatch(namespaceOf(m.selectedDatabase, m.selectedCollection))
+			m.pinboardActive = false
 			m.loadingDocs = true
 			m.docScrollOffset = 0
 			m.docCursor = 0
@@ -85,7 +89,7 @@ func (m *Model) handleCollectionSearchKey(msg tea.KeyMsg) (tea.Cmd, bool) {
 					break
 				}
 			}
-			return loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, 0, m.queryFilter), true
+			return loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, 0, m.docsPerPage, m.queryFilter, m.sortSpec()), true
 		}
 		return nil, true
 	default:
@@ -109,11 +113,30 @@ func (m Model) renderCollectionPanel(innerHeight int) string {
 	if m.collSearchActive {
 		// Show search input at top, reduce list height
 		collListHeight -= 1
-		collContent = m.collSearchInput.View() + "\n" + m.renderList(m.collFiltered, m.collCursor, true, collListHeight)
+		collContent = m.collSearchInput.View() + "\n" + m.renderListWithSuffixes(m.collFiltered, m.collectionSuffixes(), m.collCursor, true, collListHeight, true)
 	} else {
-		collContent = m.renderList(m.collFiltered, m.collCursor, m.focus == FocusCollections, collListHeight)
+		collContent = m.renderListWithSuffixes(m.collFiltered, m.collectionSuffixes(), m.collCursor, m.focus == FocusCollections, collListHeight, true)
+	}
+	refreshed := ""
+	if m.collRefreshedDB == m.selectedDatabase {
+		refreshed = refreshedSuffix(m.collRefreshedAt)
+	}
+	info := strings.TrimSpace(hiddenSuffix(m.collections, m.hiddenCollection) + " " + refreshed)
+	return m.renderPanel("Collections", info, collContent, m.focus == FocusCollections || m.collSearchActive, leftPanelWidth, innerHeight)
+}
+
+// collectionSuffixes returns the badges, kind and document count shown after
+// each filtered collection
+func (m Model) collectionSuffixes() []string {
+	suffixes := make([]string, len(m.collFiltered))
+	for i, coll := range m.collFiltered {
+		suffixes[i] = m.watchSuffix(coll)
+		if m.deniedNamespaces[namespaceOf(m.selectedDatabase, coll)] {
+			suffixes[i] = strings.TrimSpace("🔒 " + suffixes[i])
+		}
+		suffixes[i] = strings.Join(strings.Fields(suffixes[i]+" "+m.collSpecs[coll].tag()+" "+m.countSuffix(coll)), " ")
 	}
-	return m.renderPanel("Collections", "", collContent, m.focus == FocusCollections || m.collSearchActive, leftPanelWidth, innerHeight)
+	return suffixes
 }
 
 // newCollectionSearchInput creates a new textinput for collection search
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// CollectionKind is what listCollections reports a collection to be, which
+// decides the operations that apply to it
+type CollectionKind int
+
+const (
+	KindCollection CollectionKind = iota
+	KindView                      // Computed from a pipeline, read-only
+	KindTimeSeries                // Measurements bucketed by time
+	KindCapped                    // Fixed size, oldest documents overwritten
+)
+
+// collectionSpec is the listCollections entry of a collection
+type collectionSpec struct {
+	Kind    CollectionKind
+	Options bson.D // Creation options: viewOn and pipeline, capped size, timeseries fields
+}
+
+// tag returns the short label shown after the collection's name, "" for
+// plain collections
+func (s collectionSpec) tag() string {
+	switch s.Kind {
+	case KindView:
+		return "view"
+	case KindTimeSeries:
+		return "ts"
+	case KindCapped:
+		return "capped"
+	}
+	return ""
+}
+
+// option returns the creation option called key, or nil
+func (s collectionSpec) option(key string) interface{} {
+	for _, e := range s.Options {
+		if e.Key == key {
+			return e.Value
+		}
+	}
+	return nil
+}
+
+// newCollectionSpec classifies a listCollections entry
+func newCollectionSpec(spec *mongo.CollectionSpecification) collectionSpec {
+	var opts bson.D
+	if len(spec.Options) > 0 {
+		bson.Unmarshal(spec.Options, &opts)
+	}
+	s := collectionSpec{Options: opts}
+	switch {
+	case spec.Type == "view":
+		s.Kind = KindView
+	case spec.Type == "timeseries":
+		s.Kind = KindTimeSeries
+	case s.option("capped") == true:
+		s.Kind = KindCapped
+	}
+	return s
+}
+
+// listCollections lists the collections of dbName, sorted, with their specs
+func listCollections(ctx context.Context, client *mongo.Client, dbName string) ([]string, map[string]collectionSpec, error) {
+	specs, err := client.Database(dbName).ListCollectionSpecifications(ctx, bson.D{})
+	if err != nil {
+		return nil, nil, err
+	}
+	names := make([]string, len(specs))
+	byName := make(map[string]collectionSpec, len(specs))
+	for i, spec := range specs {
+		names[i] = spec.Name
+		byName[spec.Name] = newCollectionSpec(spec)
+	}
+	sort.Strings(names)
+	return names, byName, nil
+}
+
+// readOnlyReason explains why the documents of collName can't be written,
+// or returns "" when they can
+func (m Model) readOnlyReason(collName string) string {
+	spec := m.collSpecs[collName]
+	if spec.Kind != KindView {
+		return ""
+	}
+	if source, ok := spec.option("viewOn").(string); ok {
+		return fmt.Sprintf("%s is a read-only view of %s: change the documents there", collName, source)
+	}
+	return collName + " is a read-only view"
+}
+
+// guardWritable returns a toast explaining why the open collection is
+// read-only, or nil when it can be written
+func (m *Model) guardWritable(collName string) tea.Cmd {
+	if reason := m.readOnlyReason(collName); reason != "" {
+		return m.setStatus(reason)
+	}
+	return nil
+}
+
+// openCollectionInfo shows the kind and creation options of the collection
+// under the cursor: the pipeline of a view, the size of a capped collection
+func (m *Model) openCollectionInfo() tea.Cmd {
+	if len(m.collFiltered) == 0 || m.selectedDatabase == "" {
+		return nil
+	}
+	collName := m.collFiltered[m.collCursor]
+	m.openViewer(ViewerCollectionInfo, "Collection "+namespaceOf(m.selectedDatabase, collName), collectionInfoText(m.collSpecs[collName]))
+	return nil
+}
+
+// collectionInfoText describes a collection spec for the viewer
+func collectionInfoText(spec collectionSpec) string {
+	var b strings.Builder
+	switch spec.Kind {
+	case KindView:
+		fmt.Fprintf(&b, "Type: view of %v (read-only)\n", spec.option("viewOn"))
+	case KindTimeSeries:
+		b.WriteString("Type: time series\n")
+	case KindCapped:
+		b.WriteString("Type: capped collection\n")
+		if size, ok := wholeNumber(spec.option("size")); ok {
+			fmt.Fprintf(&b, "Max size: %s\n", formatBytes(int(size)))
+		}
+		if max, ok := wholeNumber(spec.option("max")); ok && max > 0 {
+			fmt.Fprintf(&b, "Max documents: %s\n", formatCount(max))
+		}
+	default:
+		b.WriteString("Type: collection\n")
+	}
+	if len(spec.Options) == 0 {
+		b.WriteString("\nCreated with default options\n")
+		return b.String()
+	}
+	b.WriteString("\nOptions:\n")
+	b.WriteString(shellValue(spec.Options, ""))
+	b.WriteString("\n")
+	return b.String()
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func rawOptions(t *testing.T, opts bson.D) bson.Raw {
+	t.Helper()
+	raw, err := bson.Marshal(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestCollectionKinds(t *testing.T) {
+	view := newCollectionSpec(&mongo.CollectionSpecification{Name: "open_orders", Type: "view", Options: rawOptions(t, bson.D{
+		{Key: "viewOn", Value: "orders"},
+		{Key: "pipeline", Value: bson.A{bson.D{{Key: "$match", Value: bson.D{{Key: "status", Value: "open"}}}}}},
+	})})
+	capped := newCollectionSpec(&mongo.CollectionSpecification{Name: "log", Type: "collection", Options: rawOptions(t, bson.D{
+		{Key: "capped", Value: true}, {Key: "size", Value: int64(1048576)}, {Key: "max", Value: int32(5000)},
+	})})
+	metrics := newCollectionSpec(&mongo.CollectionSpecification{Name: "metrics", Type: "timeseries"})
+	plain := newCollectionSpec(&mongo.CollectionSpecification{Name: "orders", Type: "collection"})
+
+	if view.Kind != KindView || capped.Kind != KindCapped || metrics.Kind != KindTimeSeries || plain.Kind != KindCollection {
+		t.Fatalf("kinds = %v %v %v %v", view.Kind, capped.Kind, metrics.Kind, plain.Kind)
+	}
+	if text := collectionInfoText(view); !strings.Contains(text, "view of orders (read-only)") || !strings.Contains(text, `$match: {`) {
+		t.Errorf("view info:\n%s", text)
+	}
+	if text := collectionInfoText(capped); !strings.Contains(text, "Max size: 1.0 MB") || !strings.Contains(text, "Max documents: 5,000") {
+		t.Errorf("capped info:\n%s", text)
+	}
+	if text := collectionInfoText(plain); !strings.Contains(text, "default options") {
+		t.Errorf("plain info:\n%s", text)
+	}
+}
+
+func TestViewsAreReadOnly(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m.collSpecs = map[string]collectionSpec{
+		"orders":    {Kind: KindView, Options: bson.D{{Key: "viewOn", Value: "orders_raw"}}},
+		"customers": {Kind: KindCapped},
+	}
+	if suffixes := m.collectionSuffixes(); suffixes[0] != "capped" || suffixes[1] != "view" {
+		t.Errorf("suffixes = %q", suffixes)
+	}
+
+	m = pressKey(m, "e")
+	if m.editorActive || m.pendingKey != "" || !strings.Contains(m.statusMessage, "orders is a read-only view of orders_raw") {
+		t.Errorf("edit of a view: pending %q, status %q", m.pendingKey, m.statusMessage)
+	}
+	m.openUpdatePrompt()
+	if m.updatePromptActive {
+		t.Error("update prompt opened on a view")
+	}
+
+	m.focus = FocusCollections
+	m = pressKey(m, "x")
+	if m.indexesActive || !strings.Contains(m.statusMessage, "views have no indexes") {
+		t.Errorf("indexes of a view: status %q", m.statusMessage)
+	}
+	m = pressKey(m, "i")
+	if m.viewerKind != ViewerCollectionInfo || !strings.Contains(m.viewerText, "view of orders_raw") {
+		t.Errorf("info = %q", m.viewerText)
+	}
+	if cmd := m.countCollections(); cmd == nil || m.collCounts["shop.orders"].pending {
+		t.Error("view counted")
+	}
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Severity is how much damage a confirmed action can do, which decides the
+// color of its confirmation modal
+type Severity int
+
+const (
+	SeverityInfo     Severity = iota // Reversible or harmless
+	SeverityWarning                  // Changes data that can be restored
+	SeverityDanger                   // Irreversible
+	SeverityCritical                 // Destroys a whole collection or database
+)
+
+// color returns the border and title color of the severity
+func (s Severity) color() lipgloss.Color {
+	switch s {
+	case SeverityWarning:
+		return lipgloss.Color("214")
+	case SeverityDanger, SeverityCritical:
+		return lipgloss.Color("196")
+	default:
+		return lipgloss.Color("205")
+	}
+}
+
+// border returns the modal border of the severity, heavier for the actions
+// that destroy the most
+func (s Severity) border() lipgloss.Border {
+	if s == SeverityCritical {
+		return lipgloss.ThickBorder()
+	}
+	return lipgloss.RoundedBorder()
+}
+
+// renderTitle renders a modal title in the severity's color, as a banner for
+// the actions that destroy the most
+func (s Severity) renderTitle(title string) string {
+	style := lipgloss.NewStyle().Bold(true).Foreground(s.color())
+	if s == SeverityCritical {
+		style = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("231")).Background(s.color()).Padding(0, 1)
+	}
+	return style.Render(title)
+}
+
+// confirmation describes an action waiting for the user to confirm it. The
+// message names the exact target; bulk actions also carry their filter,
+// whose live match count is shown once countConfirmMatches returns.
+type confirmation struct {
+	title       string
+	message     string   // What will be affected, e.g. `Delete document _id=65ab… from prod ▸ shop ▸ orders?`
+	filter      string   // Filter text of a bulk action, empty otherwise
+	severity    Severity // Style of the modal
+	requireText string   // Text to type before enter confirms, for the most destructive actions
+	destructive bool     // Overwrites or drops data, so prod connections confirm it once more
+	onConfirm   func(m *Model) tea.Cmd
+	onCancel    func(m *Model) tea.Cmd // Optional
+
+	// An alternative to confirming, e.g. i to insert an edit as a new
+	// document rather than replace the original. Optional.
+	confirmLabel string // What enter does, "confirm" if empty
+	altKey       string
+	altLabel     string
+	onAlt        func(m *Model) tea.Cmd
+
+	scopes []BulkScope // Scopes tab switches between, for bulk actions that offer several
+	target bulkTarget  // Documents the bulk action runs on, in one of scopes
+
+	counting   bool  // Whether the match count of filter is being fetched
+	matchCount int64 // Documents matching filter
+	countErr   error
+}
+
+// confirmCountMsg carries the live match count of a bulk action's filter
+type confirmCountMsg struct {
+	confirm *confirmation // The confirmation the count was fetched for
+	scope   BulkScope     // Its scope when the count was fetched
+	count   int64
+	err     error
+}
+
+// targetLabel names a target as connection ▸ database ▸ collection, leaving
+// out the parts that are empty
+func (m Model) targetLabel(parts ...string) string {
+	labels := []string{}
+	if m.connectionName != "" {
+		labels = append(labels, m.connectionName)
+	}
+	for _, part := range parts {
+		if part != "" {
+			labels = append(labels, part)
+		}
+	}
+	return strings.Join(labels, " ▸ ")
+}
+
+// newConfirmInput creates the input for confirmations that require typing
+func newConfirmInput() textinput.Model {
+	ti := textinput.New()
+	ti.CharLimit = 200
+	ti.Width = 40
+	return ti
+}
+
+// openConfirm shows a confirmation modal for c
+func (m *Model) openConfirm(c *confirmation) tea.Cmd {
+	m.confirm = c
+	m.confirmInput.SetValue("")
+	if c.requireText == "" {
+		m.confirmInput.Blur()
+		return nil
+	}
+	m.confirmInput.Placeholder = c.requireText
+	m.confirmInput.Focus()
+	return textinput.Blink
+}
+
+// countConfirmMatches counts the documents a bulk action's filter matches
+// for its confirmation modal
+func countConfirmMatches(c *confirmation, client *mongo.Client, dbName, collName string, filter bson.M) tea.Cmd {
+	c.counting = true
+	scope := c.target.scope
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		count, err := client.Database(dbName).Collection(collName).CountDocuments(ctx, filter)
+		return confirmCountMsg{confirm: c, scope: scope, count: count, err: err}
+	}
+}
+
+// handleConfirmCount shows a match count, unless its modal was closed or
+// switched to another scope since
+func (m *Model) handleConfirmCount(msg confirmCountMsg) {
+	if m.confirm != msg.confirm || m.confirm.target.scope != msg.scope {
+		return
+	}
+	m.confirm.counting = false
+	m.confirm.matchCount = msg.count
+	m.confirm.countErr = msg.err
+}
+
+// setConfirmTarget points a bulk confirmation at target, showing the filter
+// that selects it and counting what that filter matches now
+func (m *Model) setConfirmTarget(c *confirmation, target bulkTarget) tea.Cmd {
+	c.target = target
+	c.filter = m.queryText
+	if target.scope != ScopeFilter || c.filter == "" {
+		jsonBytes, _ := bson.MarshalExtJSON(target.filter, false, false)
+		c.filter = string(jsonBytes)
+	}
+	return countConfirmMatches(c, m.client, m.selectedDatabase, m.selectedCollection, target.filter)
+}
+
+// switchConfirmScope moves a bulk confirmation to the next scope in the
+// direction of step that holds any documents
+func (m *Model) switchConfirmScope(c *confirmation, step int) tea.Cmd {
+	for scope := cycleScope(c.scopes, c.target.scope, step); scope != c.target.scope; scope = cycleScope(c.scopes, scope, step) {
+		if target, err := m.bulkTarget(scope); err == nil {
+			return m.setConfirmTarget(c, target)
+		}
+	}
+	return nil
+}
+
+// handleConfirmKey handles keyboard input in the confirmation modal. When
+// text is required, enter only confirms once it has been typed exactly and
+// y/n are ordinary characters.
+func (m *Model) handleConfirmKey(msg tea.KeyMsg) tea.Cmd {
+	c := m.confirm
+	key := msg.String()
+	if c.requireText != "" && (key == "y" || key == "n") {
+		key = ""
+	}
+	if c.onAlt != nil && c.requireText == "" && key == c.altKey {
+		m.confirm = nil
+		return c.onAlt(m)
+	}
+	switch key {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "ctrl+g", "n":
+		m.confirm = nil
+		m.confirmInput.Blur()
+		if c.onCancel != nil {
+			return c.onCancel(m)
+		}
+		return nil
+	case "tab", "shift+tab":
+		if len(c.scopes) < 2 {
+			return nil
+		}
+		if key == "tab" {
+			return m.switchConfirmScope(c, 1)
+		}
+		return m.switchConfirmScope(c, -1)
+	case "enter", "y":
+		if c.requireText != "" && m.confirmInput.Value() != c.requireText {
+			return nil
+		}
+		m.confirm = nil
+		m.confirmInput.Blur()
+		if c.destructive {
+			return m.guardProduction(c.title, c.onConfirm, c.onCancel)
+		}
+		return c.onConfirm(m)
+	}
+	if c.requireText == "" {
+		return nil
+	}
+	var cmd tea.Cmd
+	m.confirmInput, cmd = m.confirmInput.Update(msg)
+	return cmd
+}
+
+// renderConfirmModal renders the confirmation modal overlay
+func (m Model) renderConfirmModal(background string) string {
+	c := m.confirm
+	width := m.modalWidth(60)
+	color := c.severity.color()
+	textStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("252")).
+		Width(width - 4)
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+
+	lines := []string{
+		c.severity.renderTitle(c.title),
+		"",
+		textStyle.Render(c.message),
+	}
+	if len(c.scopes) > 0 {
+		lines = append(lines,
+			"",
+			lipgloss.NewStyle().Bold(true).Foreground(color).Render(truncate("Scope: "+c.target.describe(), width-4)),
+			m.renderScopeChoice(c.scopes, c.target.scope, width-4),
+		)
+	}
+	if c.filter != "" {
+		count := fmt.Sprintf("%d documents match", c.matchCount)
+		if c.matchCount == 1 {
+			count = "1 document matches"
+		}
+		switch {
+		case c.counting:
+			count = "counting matches..."
+		case c.countErr != nil:
+			count = "match count unavailable: " + c.countErr.Error()
+		}
+		lines = append(lines,
+			"",
+			textStyle.Render("Filter: "+c.filter),
+			lipgloss.NewStyle().Bold(true).Foreground(color).Render(truncate(count, width-4)),
+		)
+	}
+
+	confirmLabel := c.confirmLabel
+	if confirmLabel == "" {
+		confirmLabel = "confirm"
+	}
+	help := "enter/y: " + confirmLabel + " • esc/n: cancel"
+	if c.onAlt != nil {
+		help = fmt.Sprintf("enter/y: %s • %s: %s • esc/n: cancel", confirmLabel, c.altKey, c.altLabel)
+	}
+	if len(c.scopes) > 1 {
+		help = "tab: change scope • " + help
+	}
+	if c.requireText != "" {
+		lines = append(lines,
+			"",
+			textStyle.Render(fmt.Sprintf("Type %q to confirm:", c.requireText)),
+			fitInput(m.confirmInput, width-4),
+		)
+		help = "enter: confirm • esc: cancel"
+		if m.confirmInput.Value() != c.requireText {
+			help = "esc: cancel"
+		}
+	}
+	lines = append(lines, "", hintStyle.Render(help))
+
+	modalStyle := lipgloss.NewStyle().
+		Border(c.severity.border()).
+		BorderForeground(color).
+		Padding(1, 2).
+		Width(width)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
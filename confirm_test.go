@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestConfirmRequiresTypedText(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.connectionName = "prod-replica"
+	confirmed := 0
+	c := &confirmation{
+		title:       "Drop Collection",
+		message:     "Drop " + m.targetLabel("shop", "orders") + "?",
+		severity:    SeverityDanger,
+		requireText: "orders",
+		onConfirm:   func(m *Model) tea.Cmd { confirmed++; return nil },
+	}
+	if c.message != "Drop prod-replica ▸ shop ▸ orders?" {
+		t.Errorf("message = %q", c.message)
+	}
+	m.openConfirm(c)
+
+	m = pressKey(m, "y")
+	m = pressKey(m, "enter")
+	if confirmed != 0 || m.confirm == nil {
+		t.Fatal("confirmed without typing the collection name")
+	}
+	for _, key := range []string{"o", "r", "d", "e", "r", "s"} {
+		m = pressKey(m, key)
+	}
+	if m.confirmInput.Value() != "yorders" {
+		t.Fatalf("typed %q, want y to be typed like any character", m.confirmInput.Value())
+	}
+	m.confirmInput.SetValue("orders")
+	m = pressKey(m, "enter")
+	if confirmed != 1 || m.confirm != nil {
+		t.Errorf("confirmed %d times, modal open=%v", confirmed, m.confirm != nil)
+	}
+}
+
+func TestConfirmCountIgnoredAfterClose(t *testing.T) {
+	m := newTestModel(120, 30)
+	c := &confirmation{title: "Delete Documents", filter: "{}", counting: true, onConfirm: func(m *Model) tea.Cmd { return nil }}
+	m.openConfirm(c)
+	m.handleConfirmCount(confirmCountMsg{confirm: c, count: 12})
+	if c.counting || c.matchCount != 12 {
+		t.Errorf("count not applied: counting=%v count=%d", c.counting, c.matchCount)
+	}
+	m = pressKey(m, "esc")
+	m.handleConfirmCount(confirmCountMsg{confirm: c, count: 99})
+	if c.matchCount != 12 {
+		t.Error("count of a closed modal was applied")
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// documentSave is an edited document on its way back to MongoDB, or the
+// new value of one of its fields when path is set
+type documentSave struct {
+	docID    interface{}
+	original bson.M // Document as it was loaded for editing, nil to skip the conflict check
+	newDoc   bson.M
+	path     []string    // Field set with $set instead of replacing the document
+	value    interface{} // New value of path
+	tempFile string      // The edit it comes from, kept until the save succeeds
+	undo     bool        // Reverts the latest save rather than recording a new one
+}
+
+// saveConflict is a save refused because the document changed on the
+// server after it was loaded for editing
+type saveConflict struct {
+	save    documentSave
+	current bson.M // Document now on the server, nil if it was deleted
+}
+
+// versionFields are fields applications bump on every write. When the
+// original document has one, the replace only matches while it still holds
+// the loaded value, so a write landing between the check and the replace is
+// caught too.
+var versionFields = []string{"__v", "_version", "version", "updatedAt", "updated_at", "lastModified"}
+
+// saveFilter returns the filter replacing the document only if it still is
+// the version that was edited: by its version field when it has one, and
+// otherwise by every value the edit was compared on, so a write landing
+// between the check and the replace still makes it match nothing
+func (s documentSave) saveFilter() bson.M {
+	filter := bson.M{"_id": s.docID}
+	for _, field := range versionFields {
+		if value, ok := s.original[field]; ok {
+			filter[field] = value
+			return filter
+		}
+	}
+	if s.path != nil {
+		value, ok := valueAtPath(s.original, s.path)
+		if !ok {
+			filter[strings.Join(s.path, ".")] = bson.M{"$exists": false}
+			return filter
+		}
+		addValueConditions(filter, strings.Join(s.path, "."), value)
+		return filter
+	}
+	for key, value := range s.original {
+		if key != "_id" && addressable(key) {
+			addValueConditions(filter, key, value)
+		}
+	}
+	return filter
+}
+
+// addValueConditions adds the conditions matching value at path to filter.
+// Documents are matched field by field, since comparing them whole depends
+// on the order of their fields, and arrays element by element and by their
+// length. $eq keeps regexes and documents from being read as queries.
+func addValueConditions(filter bson.M, path string, value interface{}) {
+	switch v := value.(type) {
+	case bson.M:
+		if len(v) == 0 {
+			filter[path] = bson.M{"$eq": bson.M{}}
+		}
+		for key, child := range v {
+			if addressable(key) {
+				addValueConditions(filter, path+"."+key, child)
+			}
+		}
+	case bson.D:
+		if len(v) == 0 {
+			filter[path] = bson.M{"$eq": bson.M{}}
+		}
+		for _, e := range v {
+			if addressable(e.Key) {
+				addValueConditions(filter, path+"."+e.Key, e.Value)
+			}
+		}
+	case bson.A:
+		filter[path] = bson.M{"$size": len(v)}
+		for i, item := range v {
+			addValueConditions(filter, fmt.Sprintf("%s.%d", path, i), item)
+		}
+	default:
+		filter[path] = bson.M{"$eq": v}
+	}
+}
+
+// addressable reports whether a field can be named in a dotted query path
+func addressable(key string) bool {
+	return key != "" && !strings.HasPrefix(key, "$") && !strings.Contains(key, ".")
+}
+
+// write applies the save to the document matching filter, upserting it when
+// forced, and returns the document as saved, or nil if none matched
+func (s documentSave) write(ctx context.Context, coll *mongo.Collection, filter bson.M, force bool) (bson.M, error) {
+	if s.path != nil {
+		var saved bson.M
+		update := bson.M{"$set": bson.M{strings.Join(s.path, "."): s.value}}
+		err := coll.FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&saved)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return saved, err
+	}
+	result, err := coll.ReplaceOne(ctx, filter, s.newDoc, options.Replace().SetUpsert(force))
+	if err != nil || result.MatchedCount+result.UpsertedCount == 0 {
+		return nil, err
+	}
+	return s.newDoc, nil
+}
+
+// unchangedSince reports whether the document on the server is still the one
+// loaded for editing. Both go through the same Extended JSON round trip so
+// they hold the same Go types.
+func unchangedSince(original, current bson.M) bool {
+	original, err := roundTripDocument(original)
+	if err != nil {
+		return false
+	}
+	current, err = roundTripDocument(current)
+	return err == nil && reflect.DeepEqual(original, current)
+}
+
+// unchangedIn reports whether current still has what the save was edited
+// from: the whole document, or for a field, just that field, since $set
+// leaves the rest alone
+func (s documentSave) unchangedIn(current bson.M) bool {
+	if s.path == nil {
+		return unchangedSince(s.original, current)
+	}
+	before, _ := valueAtPath(s.original, s.path)
+	after, _ := valueAtPath(current, s.path)
+	return unchangedSince(bson.M{"v": before}, bson.M{"v": after})
+}
+
+// roundTripDocument returns doc as it reads back from the editor
+func roundTripDocument(doc bson.M) (bson.M, error) {
+	data, err := editorJSON(doc)
+	if err != nil {
+		return nil, err
+	}
+	return parseEditedDocument(data)
+}
+
+// handleSaveConflictKey handles keyboard input while the save conflict
+// modal is open
+func (m *Model) handleSaveConflictKey(msg tea.KeyMsg) tea.Cmd {
+	c := m.saveConflict
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "v":
+		m.openViewer(ViewerServerDocument, "Server Version", serverVersionText(c))
+	case "o":
+		m.saveConflict = nil
+		return m.guardProduction("Overwrite Document", func(m *Model) tea.Cmd {
+			return m.saveDocument(c.save, true)
+		}, func(m *Model) tea.Cmd {
+			os.Remove(c.save.tempFile)
+			return m.setStatus("edit abandoned: document was NOT saved")
+		})
+	case "esc", "ctrl+g", "a":
+		m.saveConflict = nil
+		os.Remove(c.save.tempFile)
+		return m.setStatus("edit abandoned: document was NOT saved")
+	}
+	return nil
+}
+
+// serverVersionText describes what changed on the server since the edit
+// began, followed by the document now stored
+func serverVersionText(c *saveConflict) string {
+	if c.current == nil && c.save.path != nil {
+		return "The document has been deleted from the server, so the field can't be saved."
+	}
+	if c.current == nil {
+		return "The document has been deleted from the server.\n\nOverwriting saves your edit as a new document with the same _id."
+	}
+	var b strings.Builder
+	b.WriteString("Changed on the server since you started editing:\n")
+	diff := documentDiff(c.save.original, c.current)
+	if len(diff) == 0 {
+		diff = []string{"  (field order or types only)"}
+	}
+	b.WriteString(strings.Join(diff, "\n"))
+	b.WriteString("\n\nCurrent document:\n")
+	if data, err := bson.MarshalExtJSONIndent(sortedDocument(c.current), false, false, "", "  "); err == nil {
+		b.Write(data)
+	} else {
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// renderSaveConflict renders the save conflict modal overlay
+func (m Model) renderSaveConflict(background string) string {
+	c := m.saveConflict
+	width := m.modalWidth(60)
+	color := SeverityWarning.color()
+	textStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("252")).
+		Width(width - 4)
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+
+	message := fmt.Sprintf("_id=%s in %s was changed by someone else after you started editing it. Saving now would undo their changes.",
+		shortID(c.save.docID), m.targetLabel(m.selectedDatabase, m.selectedCollection))
+	if c.current == nil {
+		message = fmt.Sprintf("_id=%s in %s was deleted after you started editing it.",
+			shortID(c.save.docID), m.targetLabel(m.selectedDatabase, m.selectedCollection))
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Bold(true).Foreground(color).Render("Document Changed on the Server"),
+		"",
+		textStyle.Render(message),
+		"",
+		hintStyle.Render("v: view server version • o: overwrite anyway • esc: abandon"),
+	)
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(color).
+		Padding(1, 2).
+		Width(width)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(content),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
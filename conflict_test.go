@@ -0,0 +1,121 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSaveFilterUsesVersionField(t *testing.T) {
+	versioned := documentSave{docID: int32(1), original: bson.M{"_id": int32(1), "__v": int32(4), "updatedAt": "x"}}
+	if got, want := versioned.saveFilter(), (bson.M{"_id": int32(1), "__v": int32(4)}); !reflect.DeepEqual(got, want) {
+		t.Errorf("saveFilter() = %v, want %v", got, want)
+	}
+}
+
+func TestSaveFilterWithoutVersionFieldMatchesEveryValue(t *testing.T) {
+	plain := documentSave{docID: int32(1), original: bson.M{
+		"_id":     int32(1),
+		"name":    "Ada",
+		"pattern": primitive.Regex{Pattern: "^a"},
+		"address": bson.M{"city": "London", "geo": bson.M{}},
+		"tags":    bson.A{"a", bson.M{"k": int32(2)}},
+		"a.b":     "unaddressable",
+	}}
+	want := bson.M{
+		"_id":          int32(1),
+		"name":         bson.M{"$eq": "Ada"},
+		"pattern":      bson.M{"$eq": primitive.Regex{Pattern: "^a"}},
+		"address.city": bson.M{"$eq": "London"},
+		"address.geo":  bson.M{"$eq": bson.M{}},
+		"tags":         bson.M{"$size": 2},
+		"tags.0":       bson.M{"$eq": "a"},
+		"tags.1.k":     bson.M{"$eq": int32(2)},
+	}
+	if got := plain.saveFilter(); !reflect.DeepEqual(got, want) {
+		t.Errorf("saveFilter() = %v, want %v", got, want)
+	}
+
+	// A field save only depends on the field
+	field := documentSave{docID: int32(1), original: plain.original, path: []string{"address", "city"}}
+	if got, want := field.saveFilter(), (bson.M{"_id": int32(1), "address.city": bson.M{"$eq": "London"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("field saveFilter() = %v, want %v", got, want)
+	}
+	added := documentSave{docID: int32(1), original: plain.original, path: []string{"born"}}
+	if got, want := added.saveFilter(), (bson.M{"_id": int32(1), "born": bson.M{"$exists": false}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("new field saveFilter() = %v, want %v", got, want)
+	}
+}
+
+func TestUnchangedSinceComparesLoadedTypes(t *testing.T) {
+	loaded := bson.M{
+		"_id":     primitive.NewObjectID(),
+		"at":      primitive.NewDateTimeFromTime(time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)),
+		"n":       int64(7),
+		"address": bson.M{"city": "London"},
+		"tags":    bson.A{"a", int32(1)},
+	}
+	data, _ := editorJSON(loaded)
+	original, _ := parseEditedDocument(data)
+	if !unchangedSince(original, loaded) {
+		t.Error("document reported changed after an Extended JSON round trip")
+	}
+	loaded["n"] = int64(8)
+	if unchangedSince(original, loaded) {
+		t.Error("changed document reported unchanged")
+	}
+}
+
+func TestSaveConflictModal(t *testing.T) {
+	m := newTestModel(120, 30)
+	path := filepath.Join(t.TempDir(), "edit.json")
+	os.WriteFile(path, []byte(`{}`), 0600)
+	conflict := &saveConflict{
+		save:    documentSave{docID: int32(1), original: bson.M{"_id": int32(1), "name": "Ada"}, newDoc: bson.M{"name": "Ada L"}, tempFile: path},
+		current: bson.M{"_id": int32(1), "name": "Ada King"},
+	}
+	m, _ = update(t, m, documentSavedMsg{docID: int32(1), tempFile: path, conflict: conflict})
+	if m.saveConflict != conflict {
+		t.Fatal("conflict not shown")
+	}
+	if view := normalizeRender(m.View()); !strings.Contains(view, "Document Changed on the Server") || !strings.Contains(view, "o: overwrite anyway") {
+		t.Errorf("conflict view:\n%s", view)
+	}
+
+	// The server version opens over the modal and closes back to it
+	m = pressKey(m, "v")
+	if m.viewerKind != ViewerServerDocument || !strings.Contains(m.viewerText, `"Ada King"`) {
+		t.Errorf("viewer = %v:\n%s", m.viewerKind, m.viewerText)
+	}
+	m, _ = update(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.viewerKind != ViewerNone || m.saveConflict == nil {
+		t.Errorf("esc in the viewer: viewer = %v, conflict = %v", m.viewerKind, m.saveConflict)
+	}
+
+	// Overwriting saves again, skipping the check
+	m.client = offlineClient(t)
+	overwrite, cmd := update(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	if overwrite.saveConflict != nil || cmd == nil {
+		t.Errorf("overwrite: conflict = %v, cmd = %v", overwrite.saveConflict, cmd)
+	}
+
+	// Abandoning removes the edit
+	m, _ = update(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if _, err := os.Stat(path); m.saveConflict != nil || !os.IsNotExist(err) || !strings.Contains(m.statusMessage, "abandoned") {
+		t.Errorf("abandon: conflict = %v, stat err = %v, status = %q", m.saveConflict, err, m.statusMessage)
+	}
+}
+
+func TestServerVersionTextForDeletedDocument(t *testing.T) {
+	text := serverVersionText(&saveConflict{save: documentSave{docID: int32(1)}})
+	if !strings.Contains(text, "deleted") {
+		t.Errorf("text = %q", text)
+	}
+}
@@ -1,18 +1,26 @@
 package main
 
 import (
+	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
 )
 
 // Connection represents a saved MongoDB connection
 type Connection struct {
 	Name             string
 	ConnectionString string
-	SSHAlias         string // SSH alias from ~/.ssh/config (empty for direct connection)
+	SSHAlias         string    // SSH alias from ~/.ssh/config (empty for direct connection)
+	DefaultDatabase  string    // Database opened once connected (empty for the connection string's)
+	Environment      string    // Environment label, e.g. dev, staging or prod (empty for none)
+	Color            string    // Color of the environment (empty for the environment's default)
+	LastUsed         time.Time // When it was last selected (zero if never)
 }
 
 // Default connections list
@@ -31,6 +39,12 @@ func (m Model) renderConnectionsScreen() string {
 		Foreground(lipgloss.Color("205")).
 		MarginBottom(1).
 		Render("Select a Connection")
+	if dbReadOnly {
+		title = lipgloss.JoinVertical(lipgloss.Left, title, lipgloss.NewStyle().
+			Foreground(SeverityWarning.color()).
+			MarginBottom(1).
+			Render("settings are read-only: they were saved by a newer mbongo"))
+	}
 
 	// Determine which list to render
 	displayList := m.connFiltered
@@ -48,6 +62,15 @@ func (m Model) renderConnectionsScreen() string {
 
 	for i, conn := range displayList {
 		item := conn.Name
+		if env := renderEnvironmentLabel(conn.Environment, conn.Color); env != "" {
+			item += " " + env
+		}
+		if !conn.LastUsed.IsZero() {
+			item += " " + lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render(relativeTime(conn.LastUsed, time.Now()))
+		}
+		if label := m.warmupLabel(conn); label != "" {
+			item += " " + paginationStyle.Render("("+label+")")
+		}
 		if i == m.connCursor {
 			listContent += selectedStyle.Render(item) + "\n"
 		} else {
@@ -59,8 +82,24 @@ func (m Model) renderConnectionsScreen() string {
 		listContent += normalStyle.Render("(no matches)") + "\n"
 	}
 
+	if details := m.renderConnectionDetails(); details != "" {
+		listContent += "\n" + details + "\n"
+	}
+
 	// Help text
-	helpText := "↑/↓: navigate • /: search • enter: connect • c: new • e: edit • d: delete • q: quit"
+	warmup := "on"
+	if m.warmupOff {
+		warmup = "off"
+	}
+	order := "recent"
+	if m.connSortByName {
+		order = "name"
+	}
+	encryption := "off"
+	if m.storeEncrypted {
+		encryption = "on"
+	}
+	helpText := "↑/↓: navigate • /: search • enter: connect • c: new • e: edit • d: delete • s: sort " + order + " • w: warm-up " + warmup + " • E: encryption " + encryption + " • !: check environment • q: quit"
 	if m.connSearchActive {
 		helpText = "↑/↓: navigate • enter: select • esc: cancel search"
 	}
@@ -81,20 +120,87 @@ func (m Model) renderConnectionsScreen() string {
 	)
 
 	// Overlay the modal if it's open
+	if m.passphraseActive {
+		return m.renderPassphrasePrompt(baseScreen)
+	}
+
 	if m.newConnModal {
 		return m.renderNewConnectionModal(baseScreen)
 	}
 
-	if m.deleteConnModal {
-		return m.renderDeleteConnectionModal(baseScreen)
+	if m.confirm != nil {
+		return m.renderConfirmModal(baseScreen)
+	}
+
+	if m.errorModal {
+		return m.renderErrorModal(baseScreen)
+	}
+
+	if m.healthPanelActive {
+		return m.renderHealthPanel(baseScreen)
 	}
 
 	return baseScreen
 }
 
+// uriDefaults returns the default database from a connection string's path
+// and the database credentials are checked against. Without an explicit
+// authSource the driver authenticates against the default database, then admin.
+func uriDefaults(connStr string) (database, authSource string) {
+	cs, err := connstring.Parse(connStr)
+	if err != nil {
+		return "", ""
+	}
+	authSource = cs.AuthSource
+	if authSource == "" {
+		authSource = cs.Database
+	}
+	if authSource == "" {
+		authSource = "admin"
+	}
+	return cs.Database, authSource
+}
+
+// openDefaultDatabase opens the default database of the connection once
+// connected, with its collections focused. When it's no longer listed a
+// notice is returned and opened is false; when databases can't be listed
+// it's trusted.
+func (m *Model) openDefaultDatabase(listDenied bool) (cmd tea.Cmd, opened bool) {
+	dbName := m.connectionDatabase
+	if listDenied && indexOfString(m.databases, dbName) < 0 {
+		m.databases = append(m.databases, dbName)
+		sort.Strings(m.databases)
+	}
+	if indexOfString(m.databases, dbName) < 0 {
+		return m.setStatus(fmt.Sprintf("default database %s no longer exists on %s", truncateMiddle(dbName, maxToastNameWidth), m.connectionName)), false
+	}
+	m.selectedDatabase = dbName
+	// The open database stays listed when system databases are hidden
+	m.updateFilteredDatabases()
+	m.dbCursor = clampIndex(indexOfString(m.dbFiltered, dbName), len(m.dbFiltered))
+	m.focus = FocusCollections
+	return loadCollections(m.client, dbName), true
+}
+
+// renderConnectionDetails renders a summary line for the highlighted connection
+func (m Model) renderConnectionDetails() string {
+	if m.connCursor >= len(m.connFiltered) {
+		return ""
+	}
+	conn := m.connFiltered[m.connCursor]
+	database, authSource := uriDefaults(conn.ConnectionString)
+	if conn.DefaultDatabase != "" {
+		database = conn.DefaultDatabase
+	}
+	if database == "" {
+		return ""
+	}
+	return paginationStyle.Render(fmt.Sprintf("default db: %s • authSource: %s", database, authSource))
+}
+
 // renderNewConnectionModal renders the new connection modal overlay
 func (m Model) renderNewConnectionModal(background string) string {
-	modalWidth := 55
+	modalWidth := m.modalWidth(55)
 
 	// Labels
 	labelStyle := lipgloss.NewStyle().
@@ -109,18 +215,35 @@ func (m Model) renderNewConnectionModal(background string) string {
 	nameLabel := labelStyle.Render("Name:")
 	sshLabel := labelStyle.Render("SSH Alias:")
 	connLabel := labelStyle.Render("Connection String:")
+	databaseLabel := labelStyle.Render("Default Database:")
+	envLabel := labelStyle.Render("Environment:")
+	colorLabel := labelStyle.Render("Color:")
+
+	// Environment and color share a row
+	columnWidth := (modalWidth - 4 - 2) / 2
+	environmentRow := lipgloss.JoinHorizontal(lipgloss.Top,
+		lipgloss.NewStyle().Width(columnWidth).Render(lipgloss.JoinVertical(lipgloss.Left, envLabel, fitInput(m.newConnEnvInput, columnWidth))),
+		"  ",
+		lipgloss.JoinVertical(lipgloss.Left, colorLabel, fitInput(m.newConnColorInput, columnWidth)),
+	)
 
 	// Build the form
 	formContent := lipgloss.JoinVertical(lipgloss.Left,
 		nameLabel,
-		m.newConnNameInput.View(),
+		fitInput(m.newConnNameInput, modalWidth-4),
 		"",
 		sshLabel,
-		m.newConnSSHAliasInput.View(),
+		fitInput(m.newConnSSHAliasInput, modalWidth-4),
 		hintStyle.Render("(blank = direct connection)"),
 		"",
 		connLabel,
-		m.newConnStringInput.View(),
+		fitInput(m.newConnStringInput, modalWidth-4),
+		"",
+		databaseLabel,
+		fitInput(m.newConnDatabaseInput, modalWidth-4),
+		"",
+		environmentRow,
+		hintStyle.Render("(prod asks again before saves and drops)"),
 	)
 
 	// Help text
@@ -129,6 +252,23 @@ func (m Model) renderNewConnectionModal(background string) string {
 		MarginTop(1).
 		Italic(true)
 	helpText := helpStyle.Render("tab: switch field • enter: save • esc: cancel")
+	if m.duplicateConnIndex >= 0 && m.duplicateConnIndex < len(m.connections) {
+		noticeStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("214")).
+			MarginTop(1)
+		name := m.connections[m.duplicateConnIndex].Name
+		helpText = lipgloss.JoinVertical(lipgloss.Left,
+			noticeStyle.Render(fmt.Sprintf("Same cluster as '%s'", name)),
+			helpStyle.Render(fmt.Sprintf("enter: save anyway • ctrl+e: edit '%s' • esc: cancel", name)),
+		)
+	}
+	if m.newConnErr != "" {
+		helpText = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("196")).
+			MarginTop(1).
+			Width(modalWidth - 4).
+			Render(m.newConnErr)
+	}
 
 	// Modal title based on whether we're editing or creating
 	modalTitle := "New Connection"
@@ -163,51 +303,6 @@ func (m Model) renderNewConnectionModal(background string) string {
 	)
 }
 
-// renderDeleteConnectionModal renders the delete confirmation modal overlay
-func (m Model) renderDeleteConnectionModal(background string) string {
-	modalWidth := 45
-
-	connName := ""
-	if m.deleteConnIndex >= 0 && m.deleteConnIndex < len(m.connections) {
-		connName = m.connections[m.deleteConnIndex].Name
-	}
-
-	message := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("252")).
-		Render("Delete connection \"" + connName + "\"?")
-
-	helpStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("241")).
-		MarginTop(1).
-		Italic(true)
-	helpText := helpStyle.Render("enter/y: confirm • esc/n: cancel")
-
-	modalContent := lipgloss.JoinVertical(lipgloss.Left,
-		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("196")).Render("Delete Connection"),
-		"",
-		message,
-		helpText,
-	)
-
-	modalStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("196")).
-		Padding(1, 2).
-		Width(modalWidth)
-
-	modal := modalStyle.Render(modalContent)
-
-	return lipgloss.Place(
-		m.width,
-		m.height,
-		lipgloss.Center,
-		lipgloss.Center,
-		modal,
-		lipgloss.WithWhitespaceChars(" "),
-		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
-	)
-}
-
 // handleConnectionsKey handles keyboard input on the connections screen
 // Returns (command, shouldContinue)
 func (m *Model) handleConnectionsKey(key string) bool {
@@ -314,6 +409,9 @@ func (m *Model) updateConnModalFocus() {
 	m.newConnNameInput.Blur()
 	m.newConnSSHAliasInput.Blur()
 	m.newConnStringInput.Blur()
+	m.newConnDatabaseInput.Blur()
+	m.newConnEnvInput.Blur()
+	m.newConnColorInput.Blur()
 	switch m.newConnFocusField {
 	case 0:
 		m.newConnNameInput.Focus()
@@ -321,7 +419,76 @@ func (m *Model) updateConnModalFocus() {
 		m.newConnSSHAliasInput.Focus()
 	case 2:
 		m.newConnStringInput.Focus()
+	case 3:
+		m.newConnDatabaseInput.Focus()
+	case 4:
+		m.newConnEnvInput.Focus()
+	case 5:
+		m.newConnColorInput.Focus()
+	}
+}
+
+// connectionOrderSetting is "name" when saved connections are listed by
+// name rather than most recently used first
+const connectionOrderSetting = "connection_order"
+
+// sortConnections orders the saved connections, most recently used first
+// unless sorted by name, and filters them again. The default connections
+// stay on top, localhost first, as it can't be edited or deleted.
+func (m *Model) sortConnections() {
+	if len(m.connections) > len(defaultConnections) {
+		saved := m.connections[len(defaultConnections):]
+		sort.SliceStable(saved, func(i, j int) bool {
+			a, b := saved[i], saved[j]
+			if !m.connSortByName && !a.LastUsed.Equal(b.LastUsed) {
+				return a.LastUsed.After(b.LastUsed)
+			}
+			return a.Name < b.Name
+		})
+	}
+	m.updateFilteredConnections()
+}
+
+// markConnectionUsed records that the connection called name was just
+// selected, moving it up when sorted by recency. The cursor follows it, so
+// coming back to the list highlights the same connection.
+func (m *Model) markConnectionUsed(name string) {
+	now := time.Now()
+	for i := range m.connections {
+		if m.connections[i].Name == name {
+			m.connections[i].LastUsed = now
+		}
 	}
+	touchConnection(name, now)
+	m.sortConnections()
+	m.moveConnCursorTo(name)
+}
+
+// moveConnCursorTo puts the connections cursor on the connection called
+// name, if it's listed
+func (m *Model) moveConnCursorTo(name string) {
+	for i, conn := range m.connFiltered {
+		if conn.Name == name {
+			m.connCursor = i
+		}
+	}
+}
+
+// toggleConnectionOrder switches between listing saved connections by
+// recency and by name, keeping the cursor on the same connection
+func (m *Model) toggleConnectionOrder() {
+	var selected string
+	if m.connCursor < len(m.connFiltered) {
+		selected = m.connFiltered[m.connCursor].Name
+	}
+	m.connSortByName = !m.connSortByName
+	if m.connSortByName {
+		saveSetting(connectionOrderSetting, "name")
+	} else {
+		saveSetting(connectionOrderSetting, "recent")
+	}
+	m.sortConnections()
+	m.moveConnCursorTo(selected)
 }
 
 // updateFilteredConnections updates the filtered connections based on search input
@@ -378,6 +545,10 @@ func (m *Model) handleConnSearchKeyMsg(msg tea.KeyMsg) (tea.Cmd, bool) {
 			m.connCursor--
 		}
 		return nil, true
+	case "pgup", "pgdown":
+		// Page through the filtered list (home/end stay with the input)
+		m.connCursor, _ = pageKeyTarget(msg.String(), m.connCursor, m.getConnectionsListHeight(), len(m.connFiltered))
+		return nil, true
 	case "enter":
 		// Select the highlighted connection
 		if len(m.connFiltered) > 0 {
@@ -388,8 +559,7 @@ func (m *Model) handleConnSearchKeyMsg(msg tea.KeyMsg) (tea.Cmd, bool) {
 			m.connSearchInput.SetValue("")
 			m.updateFilteredConnections()
 			// Connect
-			m.connectionString = conn.ConnectionString
-			m.sshAlias = conn.SSHAlias
+			m.useConnection(conn)
 			m.screen = ScreenMain
 			m.loading = true
 		}
@@ -415,9 +585,6 @@ func (m *Model) handleConnectionsKeyMsg(msg tea.KeyMsg) (tea.Cmd, bool) {
 	if m.newConnModal {
 		return m.handleNewConnModalKeyMsg(msg)
 	}
-	if m.deleteConnModal {
-		return m.handleDeleteConnModalKeyMsg(msg)
-	}
 	// Handle search mode
 	if m.connSearchActive {
 		return m.handleConnSearchKeyMsg(msg)
@@ -441,12 +608,14 @@ func (m *Model) handleConnectionsKeyMsg(msg tea.KeyMsg) (tea.Cmd, bool) {
 			m.connCursor++
 		}
 		return nil, true
+	case "pgup", "pgdown", "home", "end":
+		m.connCursor, _ = pageKeyTarget(msg.String(), m.connCursor, m.getConnectionsListHeight(), len(m.connFiltered))
+		return nil, true
 	case "enter":
 		if len(m.connFiltered) > 0 {
 			// Set the selected connection and move to main screen
 			conn := m.connFiltered[m.connCursor]
-			m.connectionString = conn.ConnectionString
-			m.sshAlias = conn.SSHAlias
+			m.useConnection(conn)
 			m.screen = ScreenMain
 			m.loading = true
 		}
@@ -458,29 +627,21 @@ func (m *Model) handleConnectionsKeyMsg(msg tea.KeyMsg) (tea.Cmd, bool) {
 		m.newConnNameInput.SetValue("")
 		m.newConnSSHAliasInput.SetValue("")
 		m.newConnStringInput.SetValue("")
-		m.newConnNameInput.Focus()
-		m.newConnSSHAliasInput.Blur()
-		m.newConnStringInput.Blur()
+		m.newConnDatabaseInput.SetValue("")
+		m.newConnEnvInput.SetValue("")
+		m.newConnColorInput.SetValue("")
+		m.newConnErr = ""
+		m.newConnFocusField = 0
+		m.updateConnModalFocus()
 		m.editingConnIndex = -1 // Creating new, not editing
 		m.editingConnOldName = ""
+		m.duplicateConnIndex = -1
 		return textinput.Blink, true
 	case "e":
 		// Edit selected connection (but not the first one - localhost)
 		if m.connCursor > 0 && m.connCursor < len(m.connFiltered) {
-			conn := m.connFiltered[m.connCursor]
 			// Find the actual index in the full list
-			actualIndex := m.connFilteredIndices[m.connCursor]
-			m.newConnModal = true
-			m.newConnFocusField = 0
-			m.newConnNameInput.SetValue(conn.Name)
-			m.newConnSSHAliasInput.SetValue(conn.SSHAlias)
-			m.newConnStringInput.SetValue(conn.ConnectionString)
-			m.newConnNameInput.Focus()
-			m.newConnSSHAliasInput.Blur()
-			m.newConnStringInput.Blur()
-			m.editingConnIndex = actualIndex
-			m.editingConnOldName = conn.Name
-			return textinput.Blink, true
+			return m.openConnectionEditor(m.connFilteredIndices[m.connCursor]), true
 		}
 		return nil, true
 	case "d":
@@ -489,46 +650,75 @@ func (m *Model) handleConnectionsKeyMsg(msg tea.KeyMsg) (tea.Cmd, bool) {
 			// Find the actual index in the full list
 			actualIndex := m.connFilteredIndices[m.connCursor]
 			if actualIndex > 0 {
-				m.deleteConnModal = true
-				m.deleteConnIndex = actualIndex
+				return m.confirmDeleteConnection(actualIndex), true
 			}
 		}
 		return nil, true
+	case "!":
+		// Check the environment for problems
+		return m.checkHealth(), true
+	case "w":
+		return m.toggleWarmup(), true
+	case "s":
+		m.toggleConnectionOrder()
+		return nil, true
+	case "E":
+		return m.toggleEncryption(), true
 	case "q", "ctrl+c":
+		m.cancelWarmup()
 		return tea.Quit, false
 	}
 	return nil, true
 }
 
-// handleDeleteConnModalKeyMsg handles keyboard input in the delete confirmation modal
-func (m *Model) handleDeleteConnModalKeyMsg(msg tea.KeyMsg) (tea.Cmd, bool) {
-	switch msg.String() {
-	case "esc", "ctrl+g", "n":
-		// Cancel deletion
-		m.deleteConnModal = false
-		m.deleteConnIndex = -1
-		return nil, true
-	case "enter", "y":
-		// Confirm deletion
-		if m.deleteConnIndex > 0 && m.deleteConnIndex < len(m.connections) {
-			connName := m.connections[m.deleteConnIndex].Name
-			// Delete from database
-			if err := deleteConnection(connName); err == nil {
-				// Remove from list
-				m.connections = append(m.connections[:m.deleteConnIndex], m.connections[m.deleteConnIndex+1:]...)
-				// Adjust cursor if needed
-				if m.connCursor >= len(m.connections) {
-					m.connCursor = len(m.connections) - 1
-				}
-			}
+// openConnectionEditor opens the connection modal to edit the connection at index
+func (m *Model) openConnectionEditor(index int) tea.Cmd {
+	conn := m.connections[index]
+	m.newConnModal = true
+	m.newConnFocusField = 0
+	m.newConnNameInput.SetValue(conn.Name)
+	m.newConnSSHAliasInput.SetValue(conn.SSHAlias)
+	m.newConnStringInput.SetValue(conn.ConnectionString)
+	m.newConnDatabaseInput.SetValue(conn.DefaultDatabase)
+	m.newConnEnvInput.SetValue(conn.Environment)
+	m.newConnColorInput.SetValue(conn.Color)
+	m.newConnErr = ""
+	m.updateConnModalFocus()
+	m.editingConnIndex = index
+	m.editingConnOldName = conn.Name
+	m.duplicateConnIndex = -1
+	return textinput.Blink
+}
+
+// confirmDeleteConnection asks to confirm deleting a saved connection
+func (m *Model) confirmDeleteConnection(index int) tea.Cmd {
+	conn := m.connections[index]
+	return m.openConfirm(&confirmation{
+		title:    "Delete Connection",
+		message:  fmt.Sprintf("Delete saved connection %q (%s)?", conn.Name, ParseMongoHostPort(conn.ConnectionString)),
+		severity: SeverityDanger,
+		onConfirm: func(m *Model) tea.Cmd {
+			m.deleteConnectionAt(index)
+			return nil
+		},
+	})
+}
+
+// deleteConnectionAt deletes the saved connection at index
+func (m *Model) deleteConnectionAt(index int) {
+	if index <= 0 || index >= len(m.connections) {
+		return
+	}
+	// Delete from database
+	if err := deleteConnection(m.connections[index].Name); err == nil {
+		// Remove from list
+		m.connections = append(m.connections[:index], m.connections[index+1:]...)
+		m.updateFilteredConnections()
+		// Adjust cursor if needed
+		if m.connCursor >= len(m.connFiltered) {
+			m.connCursor = len(m.connFiltered) - 1
 		}
-		m.deleteConnModal = false
-		m.deleteConnIndex = -1
-		return nil, true
-	case "ctrl+c":
-		return tea.Quit, false
 	}
-	return nil, true
 }
 
 // handleNewConnModalKeyMsg handles keyboard input in the new connection modal
@@ -536,29 +726,53 @@ func (m *Model) handleNewConnModalKeyMsg(msg tea.KeyMsg) (tea.Cmd, bool) {
 	switch msg.String() {
 	case "esc", "ctrl+g":
 		// Close modal without saving
-		m.newConnModal = false
-		m.newConnNameInput.Blur()
-		m.newConnSSHAliasInput.Blur()
-		m.newConnStringInput.Blur()
+		m.closeConnectionModal()
 		return nil, true
 	case "tab":
-		// Cycle focus forward between fields (0=name, 1=ssh, 2=conn)
-		m.newConnFocusField = (m.newConnFocusField + 1) % 3
+		// Cycle focus forward between fields (0=name, 1=ssh, 2=conn, 3=database, 4=environment, 5=color)
+		m.newConnFocusField = (m.newConnFocusField + 1) % 6
 		m.updateConnModalFocus()
 		return nil, true
 	case "shift+tab":
 		// Cycle focus backward between fields
-		m.newConnFocusField = (m.newConnFocusField + 2) % 3
+		m.newConnFocusField = (m.newConnFocusField + 5) % 6
 		m.updateConnModalFocus()
 		return nil, true
+	case "ctrl+e":
+		// Edit the existing connection to the same cluster instead
+		if m.duplicateConnIndex >= 0 && m.duplicateConnIndex < len(m.connections) {
+			return m.openConnectionEditor(m.duplicateConnIndex), true
+		}
+		return nil, true
 	case "enter":
 		// Save the connection
 		name := strings.TrimSpace(m.newConnNameInput.Value())
 		sshAlias := strings.TrimSpace(m.newConnSSHAliasInput.Value())
 		connString := strings.TrimSpace(m.newConnStringInput.Value())
+		database := strings.TrimSpace(m.newConnDatabaseInput.Value())
+		env := strings.TrimSpace(m.newConnEnvInput.Value())
+		color := strings.TrimSpace(m.newConnColorInput.Value())
+		if err := validateColor(color); err != nil {
+			m.newConnErr = err.Error()
+			return nil, true
+		}
+		if database != "" {
+			if err := validateDatabaseName(database, nil); err != nil {
+				m.newConnErr = err.Error()
+				return nil, true
+			}
+		}
 		if name != "" && connString != "" {
-			conn := Connection{Name: name, ConnectionString: connString, SSHAlias: sshAlias}
+			// Point out a saved connection to the same cluster once; a
+			// second enter saves anyway
+			if dup := m.findDuplicateConnection(connString, m.editingConnIndex); dup >= 0 && dup != m.duplicateConnIndex {
+				m.duplicateConnIndex = dup
+				return nil, true
+			}
+			m.duplicateConnIndex = -1
+			conn := Connection{Name: name, ConnectionString: connString, SSHAlias: sshAlias, DefaultDatabase: database, Environment: env, Color: color}
 			if m.editingConnIndex >= 0 {
+				conn.LastUsed = m.connections[m.editingConnIndex].LastUsed
 				// Update existing connection
 				if err := updateConnection(m.editingConnOldName, conn); err == nil {
 					m.connections[m.editingConnIndex] = conn
@@ -569,11 +783,7 @@ func (m *Model) handleNewConnModalKeyMsg(msg tea.KeyMsg) (tea.Cmd, bool) {
 					m.connections = append(m.connections, conn)
 				}
 			}
-			// Close modal
-			m.newConnModal = false
-			m.newConnNameInput.Blur()
-			m.newConnSSHAliasInput.Blur()
-			m.newConnStringInput.Blur()
+			m.closeConnectionModal()
 		}
 		return nil, true
 	case "ctrl+c":
@@ -588,7 +798,82 @@ func (m *Model) handleNewConnModalKeyMsg(msg tea.KeyMsg) (tea.Cmd, bool) {
 			m.newConnSSHAliasInput, cmd = m.newConnSSHAliasInput.Update(msg)
 		case 2:
 			m.newConnStringInput, cmd = m.newConnStringInput.Update(msg)
+			m.duplicateConnIndex = -1 // Re-check the edited string on save
+		case 3:
+			m.newConnDatabaseInput, cmd = m.newConnDatabaseInput.Update(msg)
+		case 4:
+			m.newConnEnvInput, cmd = m.newConnEnvInput.Update(msg)
+		case 5:
+			m.newConnColorInput, cmd = m.newConnColorInput.Update(msg)
+			m.newConnErr = ""
 		}
 		return cmd, true
 	}
 }
+
+// closeConnectionModal closes the connection modal
+func (m *Model) closeConnectionModal() {
+	m.newConnModal = false
+	m.newConnErr = ""
+	m.newConnNameInput.Blur()
+	m.newConnSSHAliasInput.Blur()
+	m.newConnStringInput.Blur()
+	m.newConnDatabaseInput.Blur()
+	m.newConnEnvInput.Blur()
+	m.newConnColorInput.Blur()
+}
+
+// normalizeConnectionString reduces a MongoDB URI to what identifies the
+// cluster: the scheme, the sorted host set with default ports, and the sorted
+// options. Credentials and the default database are dropped, and hosts and
+// option names are lowercased since both are case-insensitive.
+func normalizeConnectionString(connStr string) string {
+	prefix, _, rest := splitConnCredentials(strings.TrimSpace(connStr))
+	hostPart, query := rest, ""
+	if i := strings.IndexAny(rest, "/?"); i != -1 {
+		hostPart = rest[:i]
+		if q := strings.Index(rest, "?"); q != -1 {
+			query = rest[q+1:]
+		}
+	}
+
+	var hosts []string
+	for _, host := range strings.Split(hostPart, ",") {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host == "" {
+			continue
+		}
+		// SRV records carry their own ports
+		if prefix != "mongodb+srv://" && !strings.Contains(host, ":") {
+			host += ":27017"
+		}
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	var options []string
+	for _, option := range strings.FieldsFunc(query, func(r rune) bool { return r == '&' || r == ';' }) {
+		key, value, _ := strings.Cut(option, "=")
+		options = append(options, strings.ToLower(key)+"="+value)
+	}
+	sort.Strings(options)
+
+	normalized := prefix + strings.Join(hosts, ",") + "/"
+	if len(options) > 0 {
+		normalized += "?" + strings.Join(options, "&")
+	}
+	return normalized
+}
+
+// findDuplicateConnection returns the index of another saved connection to
+// the same cluster as connStr, or -1. The connection at skip (the one being
+// edited) is ignored.
+func (m Model) findDuplicateConnection(connStr string, skip int) int {
+	normalized := normalizeConnectionString(connStr)
+	for i, conn := range m.connections {
+		if i != skip && normalizeConnectionString(conn.ConnectionString) == normalized {
+			return i
+		}
+	}
+	return -1
+}
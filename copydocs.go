@@ -0,0 +1,436 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// copyTargetsMsg carries the collections of every database, listed when the
+// copy picker is widened to other databases
+type copyTargetsMsg struct {
+	namespaces []string
+	err        error
+}
+
+// copyDoneMsg is sent when a copy to another collection finishes
+type copyDoneMsg struct {
+	target     string // Namespace the documents were copied to
+	copied     int    // Inserted with their own _id
+	renumbered int    // Inserted with a new _id after a duplicate key
+	skipped    int    // Left out because their _id was taken
+	failed     int    // Rejected for any other reason
+	firstError string // Message of the first other rejection
+	err        error
+}
+
+// newCopyInput creates the textinput filtering the copy targets
+func newCopyInput() textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = "collection"
+	ti.CharLimit = 255
+	ti.Width = 50
+	return ti
+}
+
+// copySource returns the documents a copy takes: the pinned documents of
+// the collection, or the document under the cursor when none are pinned
+func (m Model) copySource() (bulkTarget, error) {
+	if len(m.pinnedIDs()) > 0 {
+		return m.bulkTarget(ScopePinned)
+	}
+	return m.bulkTarget(ScopeDocument)
+}
+
+// openCopyPicker opens the picker of the collection to copy the pinned
+// documents, or the one under the cursor, to
+func (m *Model) openCopyPicker() tea.Cmd {
+	if m.client == nil || m.selectedCollection == "" || m.schemaActive {
+		return nil
+	}
+	source, err := m.copySource()
+	if err != nil {
+		return m.setStatus(err.Error())
+	}
+	m.copyActive = true
+	m.copySourceTarget = source
+	m.copyAllDBs = false
+	m.copyTargets = nil
+	for _, coll := range m.collections {
+		if coll == m.selectedCollection || m.collSpecs[coll].Kind == KindView || strings.HasPrefix(coll, "system.") {
+			continue
+		}
+		m.copyTargets = append(m.copyTargets, namespaceOf(m.selectedDatabase, coll))
+	}
+	m.copyInput.SetValue("")
+	m.copyInput.Focus()
+	m.filterCopyTargets()
+	return textinput.Blink
+}
+
+// filterCopyTargets fuzzy-matches the targets against the typed text
+func (m *Model) filterCopyTargets() {
+	query := strings.TrimSpace(m.copyInput.Value())
+	m.copyFiltered = nil
+	for _, ns := range m.copyTargets {
+		if query == "" || fuzzyMatch(query, ns) {
+			m.copyFiltered = append(m.copyFiltered, ns)
+		}
+	}
+	m.copyCursor = clampIndex(m.copyCursor, len(m.copyFiltered))
+}
+
+// listCopyTargets lists the collections of every database but views, skipping
+// databases that can't be listed
+func listCopyTargets(client *mongo.Client, databases []string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		var namespaces []string
+		var firstErr error
+		for _, dbName := range databases {
+			colls, specs, err := listCollections(ctx, client, dbName)
+			if err != nil {
+				if firstErr == nil && !isUnauthorizedError(err) {
+					firstErr = err
+				}
+				continue
+			}
+			for _, coll := range colls {
+				if specs[coll].Kind != KindView && !strings.HasPrefix(coll, "system.") {
+					namespaces = append(namespaces, namespaceOf(dbName, coll))
+				}
+			}
+		}
+		if namespaces == nil {
+			return copyTargetsMsg{err: firstErr}
+		}
+		return copyTargetsMsg{namespaces: namespaces}
+	}
+}
+
+// handleCopyTargets widens the open picker to the collections of every database
+func (m *Model) handleCopyTargets(msg copyTargetsMsg) tea.Cmd {
+	if !m.copyActive || !m.copyAllDBs {
+		return nil
+	}
+	if msg.err != nil {
+		m.copyAllDBs = false
+		return m.setStatus(fmt.Sprintf("couldn't list the other databases: %v", msg.err))
+	}
+	source := m.currentNamespace()
+	m.copyTargets = nil
+	for _, ns := range msg.namespaces {
+		if ns != source {
+			m.copyTargets = append(m.copyTargets, ns)
+		}
+	}
+	sort.Strings(m.copyTargets)
+	m.filterCopyTargets()
+	return nil
+}
+
+// copyDestination returns the namespace enter copies to: the highlighted
+// target, or a new collection of the selected database named by the typed
+// text when nothing matches it
+func (m Model) copyDestination() (string, error) {
+	if len(m.copyFiltered) > 0 {
+		return m.copyFiltered[m.copyCursor], nil
+	}
+	name := strings.TrimSpace(m.copyInput.Value())
+	if name == "" {
+		return "", errors.New("no collection to copy to")
+	}
+	if err := validateCollectionName(m.selectedDatabase, name, m.collections, m.serverVersion); err != nil {
+		return "", err
+	}
+	return namespaceOf(m.selectedDatabase, name), nil
+}
+
+// handleCopyPickerKey handles keyboard input in the copy picker
+func (m *Model) handleCopyPickerKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "ctrl+g":
+		m.copyActive = false
+		m.copyInput.Blur()
+		return nil
+	case "up", "ctrl+p":
+		m.copyCursor = clampIndex(m.copyCursor-1, len(m.copyFiltered))
+		return nil
+	case "down", "ctrl+n":
+		m.copyCursor = clampIndex(m.copyCursor+1, len(m.copyFiltered))
+		return nil
+	case "tab":
+		if m.copyAllDBs {
+			return nil
+		}
+		m.copyAllDBs = true
+		return listCopyTargets(m.client, m.databases)
+	case "ctrl+t":
+		m.copyNewIDs = !m.copyNewIDs
+		return nil
+	case "enter":
+		target, err := m.copyDestination()
+		if err != nil {
+			return m.setStatus(err.Error())
+		}
+		m.copyActive = false
+		m.copyInput.Blur()
+		return m.startCopy(target)
+	}
+	var cmd tea.Cmd
+	m.copyInput, cmd = m.copyInput.Update(msg)
+	m.filterCopyTargets()
+	return cmd
+}
+
+// startCopy copies the picked documents to target in a background job
+func (m *Model) startCopy(target string) tea.Cmd {
+	source := m.copySourceTarget
+	from := m.client.Database(m.selectedDatabase).Collection(m.selectedCollection)
+	dbName, collName := splitNamespace(target)
+	to := m.client.Database(dbName).Collection(collName)
+	newIDs := m.copyNewIDs
+	name := fmt.Sprintf("copy to %s", target)
+	return m.startBulkJob(name, source, func(ctx context.Context, report func(string)) (tea.Msg, error) {
+		msg := copyDoneMsg{target: target}
+		msg.err = copyDocuments(ctx, report, from, to, source.filter, newIDs, &msg)
+		return msg, msg.err
+	})
+}
+
+// copyDocuments reads the documents matching filter from one collection and
+// inserts them into another. They are read again rather than taken from the
+// page, whose documents may be cut short for display. Documents whose _id is
+// taken in the target are skipped, or inserted with a new _id if newIDs is set.
+func copyDocuments(ctx context.Context, report func(string), from, to *mongo.Collection, filter bson.M, newIDs bool, result *copyDoneMsg) error {
+	cursor, err := from.Find(ctx, filter)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	batch := make([]interface{}, 0, importBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := insertCopies(ctx, to, batch, newIDs, result)
+		batch = batch[:0]
+		report(fmt.Sprintf("%d copied, %d skipped", result.copied+result.renumbered, result.skipped+result.failed))
+		return err
+	}
+	for cursor.Next(ctx) {
+		var doc bson.D
+		if err := cursor.Decode(&doc); err != nil {
+			return err
+		}
+		batch = append(batch, doc)
+		if len(batch) == importBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		flush()
+		return err
+	}
+	return flush()
+}
+
+// insertCopies inserts one batch unordered, so a taken _id doesn't stop the
+// rest. The documents rejected for a duplicate key are inserted again
+// without their _id when newIDs is set, which makes the server assign one.
+func insertCopies(ctx context.Context, to *mongo.Collection, batch []interface{}, newIDs bool, result *copyDoneMsg) error {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	_, err := to.InsertMany(ctx, batch, options.InsertMany().SetOrdered(false))
+	if err == nil {
+		result.copied += len(batch)
+		return nil
+	}
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || len(bulkErr.WriteErrors) == 0 {
+		return err
+	}
+	result.copied += len(batch) - len(bulkErr.WriteErrors)
+
+	var retry []interface{}
+	for _, we := range bulkErr.WriteErrors {
+		switch {
+		case !mongo.IsDuplicateKeyError(we):
+			result.failed++
+			if result.firstError == "" {
+				result.firstError = we.Message
+			}
+		case newIDs:
+			retry = append(retry, withoutID(batch[we.Index].(bson.D)))
+		default:
+			result.skipped++
+		}
+	}
+	if len(retry) == 0 {
+		return nil
+	}
+	// A duplicate now is on another unique index, which a new _id can't fix
+	_, err = to.InsertMany(ctx, retry, options.InsertMany().SetOrdered(false))
+	if err == nil {
+		result.renumbered += len(retry)
+		return nil
+	}
+	if !errors.As(err, &bulkErr) || len(bulkErr.WriteErrors) == 0 {
+		return err
+	}
+	result.renumbered += len(retry) - len(bulkErr.WriteErrors)
+	result.failed += len(bulkErr.WriteErrors)
+	if result.firstError == "" {
+		result.firstError = bulkErr.WriteErrors[0].Message
+	}
+	return nil
+}
+
+// withoutID returns doc without its _id field
+func withoutID(doc bson.D) bson.D {
+	out := make(bson.D, 0, len(doc))
+	for _, e := range doc {
+		if e.Key != "_id" {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// copySummary states the outcome of a copy, e.g. "copied 3 documents to
+// shop.orders_archive, 1 skipped (_id taken)"
+func copySummary(msg copyDoneMsg) string {
+	summary := fmt.Sprintf("copied %s to %s", pluralDocuments(int64(msg.copied+msg.renumbered)), truncateMiddle(msg.target, maxToastNameWidth))
+	var notes []string
+	if msg.renumbered > 0 {
+		notes = append(notes, fmt.Sprintf("%d with a new _id", msg.renumbered))
+	}
+	if msg.skipped > 0 {
+		notes = append(notes, fmt.Sprintf("%d skipped (_id taken)", msg.skipped))
+	}
+	if msg.failed > 0 {
+		notes = append(notes, fmt.Sprintf("%d failed: %s", msg.failed, msg.firstError))
+	}
+	if len(notes) > 0 {
+		summary += ", " + strings.Join(notes, ", ")
+	}
+	return summary
+}
+
+// handleCopyDone reports a finished copy and shows a new target collection
+// in the list
+func (m *Model) handleCopyDone(msg copyDoneMsg) tea.Cmd {
+	if msg.err != nil && msg.copied+msg.renumbered+msg.skipped+msg.failed == 0 {
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Copy to %s failed: %v", msg.target, msg.err)
+		return nil
+	}
+	summary := copySummary(msg)
+	if msg.err != nil {
+		summary += fmt.Sprintf(", then stopped: %v", msg.err)
+	}
+	cmds := []tea.Cmd{m.setStatus(summary)}
+
+	delete(m.collCounts, msg.target)
+	if dbName, collName := splitNamespace(msg.target); dbName == m.selectedDatabase && m.client != nil {
+		if indexOfString(m.collections, collName) < 0 {
+			cmds = append(cmds, refreshCollections(m.client, dbName))
+		} else {
+			cmds = append(cmds, m.countCollections())
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// renderCopyPicker renders the picker of the collection to copy to
+func (m Model) renderCopyPicker(background string) string {
+	width := m.modalWidth(70)
+	contentWidth := width - 4
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+
+	var lines []string
+	switch {
+	case len(m.copyFiltered) > 0:
+		// Keep the cursor in a window of the list the height allows
+		visible := m.height - 18
+		if visible < 3 {
+			visible = 3
+		}
+		start := 0
+		if m.copyCursor >= visible {
+			start = m.copyCursor - visible + 1
+		}
+		end := start + visible
+		if end > len(m.copyFiltered) {
+			end = len(m.copyFiltered)
+		}
+		for i := start; i < end; i++ {
+			line := truncate(m.copyFiltered[i], contentWidth)
+			if i == m.copyCursor {
+				line = selectedStyle.Render(line)
+			}
+			lines = append(lines, line)
+		}
+	case strings.TrimSpace(m.copyInput.Value()) != "":
+		lines = append(lines, normalStyle.Render(truncate("new collection "+namespaceOf(m.selectedDatabase, strings.TrimSpace(m.copyInput.Value())), contentWidth)))
+	default:
+		lines = append(lines, normalStyle.Render("(no other collections)"))
+	}
+
+	duplicates := "skip documents whose _id is taken"
+	if m.copyNewIDs {
+		duplicates = "give documents whose _id is taken a new one"
+	}
+	widen := "tab: other databases • "
+	if m.copyAllDBs {
+		widen = ""
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render(truncate("Copy "+m.copySourceTarget.describe()+" to", contentWidth)),
+		"",
+		fitInput(m.copyInput, contentWidth),
+		"",
+		strings.Join(lines, "\n"),
+		"",
+		truncate("On duplicate _id: "+duplicates, contentWidth),
+		"",
+		hintStyle.Render(truncate("enter: copy • "+widen+"ctrl+t: duplicates • esc: cancel", contentWidth)),
+	)
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("205")).
+		Padding(1, 2).
+		Width(width)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(content),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestCopyPicker(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m.collections = append(m.collections, "orders_archive", "open_orders")
+	m.collSpecs = map[string]collectionSpec{"open_orders": {Kind: KindView}}
+
+	m = pressKey(m, "ctrl+x")
+	m = pressKey(m, "o")
+	if !m.copyActive || m.copySourceTarget.scope != ScopeDocument {
+		t.Fatalf("active = %v, scope %v", m.copyActive, m.copySourceTarget.scope)
+	}
+	want := []string{"shop.customers", "shop.events_v2_partitioned_2024_06_eu_west_1", "shop.orders_archive"}
+	if strings.Join(m.copyFiltered, " ") != strings.Join(want, " ") {
+		t.Errorf("targets = %q, want %q without the source and the view", m.copyFiltered, want)
+	}
+
+	for _, key := range []string{"a", "r", "c"} {
+		m = pressKey(m, key)
+	}
+	if len(m.copyFiltered) != 1 || m.copyFiltered[0] != "shop.orders_archive" {
+		t.Errorf("filtered = %q", m.copyFiltered)
+	}
+
+	// Text matching nothing names a new collection of the database
+	m.copyInput.SetValue("orders_2023")
+	m.filterCopyTargets()
+	if ns, err := m.copyDestination(); err != nil || ns != "shop.orders_2023" {
+		t.Errorf("destination = %q, %v", ns, err)
+	}
+	m.copyInput.SetValue("system.zzz")
+	m.filterCopyTargets()
+	if _, err := m.copyDestination(); err == nil {
+		t.Error("reserved name accepted")
+	}
+
+	m.copyInput.SetValue("arc")
+	m.filterCopyTargets()
+	m = pressKey(m, "ctrl+t")
+	m = pressKey(m, "enter")
+	if m.copyActive || len(m.jobs) != 1 || m.jobs[0].Name != "copy to shop.orders_archive" || m.jobs[0].Scope != "the document under the cursor" {
+		t.Fatalf("active = %v, jobs %+v", m.copyActive, m.jobs)
+	}
+}
+
+func TestCopyPrefersPinnedDocuments(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m.pins["shop.orders"] = []interface{}{"a", "b"}
+	m.openCopyPicker()
+	if m.copySourceTarget.scope != ScopePinned || !strings.Contains(normalizeRender(m.View()), "Copy 2 pinned documents to") {
+		t.Errorf("scope %v:\n%s", m.copySourceTarget.scope, normalizeRender(m.View()))
+	}
+
+	// Other databases replace the list once listed
+	m, _ = update(t, m, copyTargetsMsg{namespaces: []string{"shop.orders", "archive.orders", "shop.customers"}})
+	if len(m.copyTargets) != 2 {
+		t.Fatalf("targets listed before tab: %q", m.copyTargets)
+	}
+	m.copyAllDBs = true
+	m, _ = update(t, m, copyTargetsMsg{namespaces: []string{"shop.orders", "archive.orders", "shop.customers"}})
+	if strings.Join(m.copyFiltered, " ") != "archive.orders shop.customers" {
+		t.Errorf("targets = %q", m.copyFiltered)
+	}
+}
+
+func TestCopyDone(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m.collCounts["shop.customers"] = collectionCount{count: 10}
+
+	m, cmd := update(t, m, copyDoneMsg{target: "shop.customers", copied: 2, renumbered: 1, skipped: 1})
+	if cmd == nil || m.collCounts["shop.customers"].count == 10 {
+		t.Error("count of the target not fetched again")
+	}
+	if want := "copied 3 documents to shop.customers, 1 with a new _id, 1 skipped (_id taken)"; m.statusMessage != want {
+		t.Errorf("status %q, want %q", m.statusMessage, want)
+	}
+
+	m, _ = update(t, m, copyDoneMsg{target: "shop.customers", err: errors.New("connection refused")})
+	if !m.errorModal || !strings.Contains(m.errorMessage, "Copy to shop.customers failed") {
+		t.Errorf("modal = %v %q", m.errorModal, m.errorMessage)
+	}
+
+	if doc := withoutID(bson.D{{Key: "_id", Value: 1}, {Key: "a", Value: 2}}); len(doc) != 1 || doc[0].Key != "a" {
+		t.Errorf("withoutID = %v", doc)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// countTimeout bounds one estimated count once it has a server slot
+const countTimeout = 5 * time.Second
+
+// countSlotWait is how long a count waits for a server slot before it is
+// shown as failed
+const countSlotWait = time.Minute
+
+// collectionCount is the estimated document count of a collection, fetched
+// once per session and shown beside its name
+type collectionCount struct {
+	pending bool // Still being fetched
+	count   int64
+	err     error
+}
+
+// collectionCountMsg carries the count of one collection
+type collectionCountMsg struct {
+	client    *mongo.Client // Client the count was fetched with, to drop results of an old connection
+	namespace string
+	count     int64
+	err       error
+}
+
+// countCollections fetches the counts of the collections of the selected
+// database that aren't cached yet. Each count waits for a slot of the
+// server limiter, so large databases don't flood the server, and arrives
+// on its own so the list fills in as they come.
+func (m *Model) countCollections() tea.Cmd {
+	if m.client == nil || m.selectedDatabase == "" {
+		return nil
+	}
+	var cmds []tea.Cmd
+	for _, coll := range m.collections {
+		ns := namespaceOf(m.selectedDatabase, coll)
+		if _, ok := m.collCounts[ns]; ok || m.collSpecs[coll].Kind == KindView {
+			continue // Counting a view would run its pipeline
+		}
+		m.collCounts[ns] = collectionCount{pending: true}
+		cmds = append(cmds, countCollection(m.client, ns))
+	}
+	return tea.Batch(cmds...)
+}
+
+// countCollection fetches the estimated document count of ns
+func countCollection(client *mongo.Client, ns string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), countSlotWait)
+		defer cancel()
+		release, err := serverCommands.acquire(ctx, "count "+ns)
+		if err != nil {
+			return collectionCountMsg{client: client, namespace: ns, err: err}
+		}
+		defer release()
+
+		ctx, cancel = context.WithTimeout(context.Background(), countTimeout)
+		defer cancel()
+		dbName, collName := splitNamespace(ns)
+		count, err := client.Database(dbName).Collection(collName).EstimatedDocumentCount(ctx)
+		return collectionCountMsg{client: client, namespace: ns, count: count, err: err}
+	}
+}
+
+// handleCollectionCount caches a count, unless it was fetched before a
+// reconnect
+func (m *Model) handleCollectionCount(msg collectionCountMsg) {
+	if msg.client != m.client {
+		return
+	}
+	m.collCounts[msg.namespace] = collectionCount{count: msg.count, err: msg.err}
+}
+
+// recountCollections drops the cached counts of the selected database and
+// fetches them again
+func (m *Model) recountCollections() tea.Cmd {
+	if m.client == nil || m.selectedDatabase == "" || len(m.collections) == 0 {
+		return nil
+	}
+	for _, coll := range m.collections {
+		delete(m.collCounts, namespaceOf(m.selectedDatabase, coll))
+	}
+	return tea.Batch(m.countCollections(), m.setStatus("recounting documents in "+truncateMiddle(m.selectedDatabase, maxToastNameWidth)))
+}
+
+// countSuffix returns the count shown beside a collection: compact, "—"
+// when it couldn't be fetched and empty while it loads
+func (m Model) countSuffix(collName string) string {
+	c, ok := m.collCounts[namespaceOf(m.selectedDatabase, collName)]
+	switch {
+	case !ok || c.pending:
+		return ""
+	case c.err != nil:
+		return "—"
+	}
+	return compactCount(c.count)
+}
+
+// compactCount formats a count in at most five columns, e.g. "999",
+// "12.3k", "4M"
+func compactCount(n int64) string {
+	if n < 1000 {
+		return strconv.FormatInt(n, 10)
+	}
+	units := []struct {
+		size   float64
+		suffix string
+	}{{1e3, "k"}, {1e6, "M"}, {1e9, "B"}}
+	for i, unit := range units {
+		v := float64(n) / unit.size
+		if v >= 999.5 && i < len(units)-1 {
+			continue // Would round up to the next unit
+		}
+		if v >= 99.95 {
+			return fmt.Sprintf("%.0f%s", v, unit.suffix)
+		}
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", v), ".0") + unit.suffix
+	}
+	return ""
+}
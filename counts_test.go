@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCompactCount(t *testing.T) {
+	for n, want := range map[int64]string{
+		0:             "0",
+		999:           "999",
+		1000:          "1k",
+		12345:         "12.3k",
+		99949:         "99.9k",
+		123456:        "123k",
+		999999:        "1M",
+		4000000:       "4M",
+		2500000000:    "2.5B",
+		1234000000000: "1234B",
+	} {
+		if got := compactCount(n); got != want {
+			t.Errorf("compactCount(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestCollectionCountsFillInAsTheyArrive(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m.focus = FocusCollections
+
+	m, cmd := update(t, m, collectionsLoadedMsg{collections: []string{"customers", "orders"}})
+	if cmd == nil || !m.collCounts["shop.orders"].pending {
+		t.Fatalf("counts not requested: %+v", m.collCounts)
+	}
+	if suffixes := m.collectionSuffixes(); suffixes[0] != "" || suffixes[1] != "" {
+		t.Errorf("pending suffixes = %q", suffixes)
+	}
+
+	m, _ = update(t, m, collectionCountMsg{client: m.client, namespace: "shop.orders", count: 48210})
+	m, _ = update(t, m, collectionCountMsg{client: m.client, namespace: "shop.customers", err: context.DeadlineExceeded})
+	if suffixes := m.collectionSuffixes(); suffixes[0] != "—" || suffixes[1] != "48.2k" {
+		t.Errorf("suffixes = %q", suffixes)
+	}
+	if panel := normalizeRender(m.renderCollectionPanel(10)); !strings.Contains(panel, "48.2k") {
+		t.Errorf("count not shown:\n%s", panel)
+	}
+
+	// Loading the list again reuses what is cached
+	if m, cmd = update(t, m, collectionsLoadedMsg{collections: []string{"customers", "orders"}}); cmd != nil {
+		t.Error("cached counts fetched again")
+	}
+
+	// Counts of an old connection are dropped
+	m, _ = update(t, m, collectionCountMsg{client: nil, namespace: "shop.orders", count: 1})
+	if m.collCounts["shop.orders"].count != 48210 {
+		t.Error("count from another client applied")
+	}
+
+	m = pressKey(m, "#")
+	if !m.collCounts["shop.orders"].pending || !strings.Contains(m.statusMessage, "recounting") {
+		t.Errorf("# didn't recount: %+v, status %q", m.collCounts, m.statusMessage)
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// databaseCreatedMsg reports the end of creating a database with its first
+// collection
+type databaseCreatedMsg struct {
+	dbName   string
+	collName string
+	err      error
+}
+
+// newCreateDatabaseInputs creates the inputs for the database name and the
+// name of its first collection
+func newCreateDatabaseInputs() (textinput.Model, textinput.Model) {
+	dbInput := textinput.New()
+	dbInput.Placeholder = "database"
+	dbInput.CharLimit = maxDatabaseNameBytes
+	dbInput.Width = 50
+
+	collInput := textinput.New()
+	collInput.Placeholder = "collection"
+	collInput.CharLimit = 255
+	collInput.Width = 50
+	return dbInput, collInput
+}
+
+// openCreateDatabasePrompt opens the prompt for a new database. The server
+// only creates a database with its first collection, so the prompt asks for
+// both.
+func (m *Model) openCreateDatabasePrompt() tea.Cmd {
+	if m.client == nil {
+		return nil
+	}
+	m.createDBActive = true
+	m.createDBCollFocused = false
+	m.createDBErr = ""
+	m.createDBInput.SetValue("")
+	m.createDBCollInput.SetValue("")
+	m.createDBCollInput.Blur()
+	m.createDBInput.Focus()
+	return textinput.Blink
+}
+
+// validateNewDatabase checks the names typed in the prompt before anything
+// is sent to the server
+func (m Model) validateNewDatabase(dbName, collName string) error {
+	if err := validateDatabaseName(dbName, m.databases); err != nil {
+		return err
+	}
+	if indexOfString(m.databases, dbName) >= 0 {
+		return fmt.Errorf("database %q already exists", dbName)
+	}
+	return validateCollectionName(dbName, collName, nil, m.serverVersion)
+}
+
+// handleCreateDatabasePromptKey handles keyboard input in the new database prompt
+func (m *Model) handleCreateDatabasePromptKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "ctrl+g":
+		m.createDBActive = false
+		m.createDBInput.Blur()
+		m.createDBCollInput.Blur()
+		return nil
+	case "tab", "shift+tab":
+		m.createDBCollFocused = !m.createDBCollFocused
+		if m.createDBCollFocused {
+			m.createDBInput.Blur()
+			m.createDBCollInput.Focus()
+		} else {
+			m.createDBCollInput.Blur()
+			m.createDBInput.Focus()
+		}
+		return textinput.Blink
+	case "enter":
+		dbName := strings.TrimSpace(m.createDBInput.Value())
+		collName := strings.TrimSpace(m.createDBCollInput.Value())
+		if err := m.validateNewDatabase(dbName, collName); err != nil {
+			m.createDBErr = err.Error()
+			return nil
+		}
+		m.createDBActive = false
+		m.createDBInput.Blur()
+		m.createDBCollInput.Blur()
+		return createDatabase(m.client, dbName, collName)
+	}
+	var cmd tea.Cmd
+	if m.createDBCollFocused {
+		m.createDBCollInput, cmd = m.createDBCollInput.Update(msg)
+	} else {
+		m.createDBInput, cmd = m.createDBInput.Update(msg)
+	}
+	m.createDBErr = ""
+	return cmd
+}
+
+// createDatabase creates dbName by creating its first collection
+func createDatabase(client *mongo.Client, dbName, collName string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		err := client.Database(dbName).CreateCollection(ctx, collName)
+		return databaseCreatedMsg{dbName: dbName, collName: collName, err: err}
+	}
+}
+
+// handleDatabaseCreated lists a new database and opens it
+func (m *Model) handleDatabaseCreated(msg databaseCreatedMsg) tea.Cmd {
+	if msg.err != nil {
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Failed to create database %s: %v", msg.dbName, friendlyNamespaceError(msg.err))
+		return nil
+	}
+	if indexOfString(m.databases, msg.dbName) < 0 {
+		m.databases = append(m.databases, msg.dbName)
+		sort.Strings(m.databases)
+	}
+	m.dbSearchActive = false
+	m.dbSearchInput.Blur()
+	m.dbSearchInput.SetValue("")
+	m.updateFilteredDatabases()
+	m.dbCursor = indexOfString(m.dbFiltered, msg.dbName)
+	m.selectedDatabase = msg.dbName
+	m.focus = FocusCollections
+	m.explicitDBSelect = true
+	return tea.Batch(
+		m.setStatus(fmt.Sprintf("created database %s with collection %s", truncateMiddle(msg.dbName, maxToastNameWidth), truncateMiddle(msg.collName, maxToastNameWidth))),
+		loadCollections(m.client, msg.dbName),
+		refreshDatabases(m.client),
+	)
+}
+
+// renderCreateDatabasePrompt renders the new database prompt modal
+func (m Model) renderCreateDatabasePrompt(background string) string {
+	width := m.modalWidth(64)
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+
+	lines := []string{
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render("New database"),
+		"",
+		"Database name:",
+		fitInput(m.createDBInput, width-4),
+		"",
+		"First collection (a database exists once it has one):",
+		fitInput(m.createDBCollInput, width-4),
+	}
+	if m.createDBErr != "" {
+		lines = append(lines, "", lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Width(width-4).Render(m.createDBErr))
+	}
+	lines = append(lines, "", hintStyle.Render("tab: switch field • enter: create • esc: cancel"))
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("205")).
+		Padding(1, 2).
+		Width(width)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCreateDatabasePrompt(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m.focus = FocusDatabases
+
+	m = pressKey(m, "n")
+	if !m.createDBActive {
+		t.Fatal("n didn't open the prompt")
+	}
+	m.createDBInput.SetValue("sales.eu")
+	m.createDBCollInput.SetValue("orders")
+	m = pressKey(m, "enter")
+	if !m.createDBActive || !strings.Contains(m.createDBErr, `can't contain '.'`) {
+		t.Fatalf("dotted name: active = %v, error %q", m.createDBActive, m.createDBErr)
+	}
+
+	for name, want := range map[string]string{
+		"shop":  `database "shop" already exists`,
+		"Shop":  "already exists with different case",
+		"sales": "the collection name is empty",
+	} {
+		m.createDBInput.SetValue(name)
+		m.createDBCollInput.SetValue("")
+		m = pressKey(m, "enter")
+		if !strings.Contains(m.createDBErr, want) {
+			t.Errorf("%s: error %q, want %q", name, m.createDBErr, want)
+		}
+	}
+
+	m.createDBInput.SetValue("sales")
+	m = pressKey(m, "tab")
+	for _, key := range []string{"l", "e", "a", "d", "s"} {
+		m = pressKey(m, key)
+	}
+	m = pressKey(m, "enter")
+	if m.createDBActive || m.createDBErr != "" {
+		t.Errorf("active = %v, error %q", m.createDBActive, m.createDBErr)
+	}
+}
+
+func TestCreatedDatabaseIsOpened(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m.focus = FocusDatabases
+
+	m, cmd := update(t, m, databaseCreatedMsg{dbName: "sales", collName: "leads"})
+	if cmd == nil || m.selectedDatabase != "sales" || m.dbFiltered[m.dbCursor] != "sales" || m.focus != FocusCollections {
+		t.Errorf("selected %q, cursor on %q, focus %v", m.selectedDatabase, m.dbFiltered[m.dbCursor], m.focus)
+	}
+	if strings.Join(m.databases, ",") != "admin,analytics,sales,shop" {
+		t.Errorf("databases = %v", m.databases)
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// indexTemplate seeds $EDITOR when creating an index. null options are left
+// unset; an empty name lets the server name the index after its keys.
+const indexTemplate = `{
+  "key": {"field": 1},
+  "name": "",
+  "unique": false,
+  "sparse": false,
+  "expireAfterSeconds": null,
+  "partialFilterExpression": null
+}
+`
+
+// indexJobPrefix starts the name of index build jobs, which the indexes
+// overlay lists while they run
+const indexJobPrefix = "build index "
+
+// indexEditedMsg reports that $EDITOR closed on the spec of a new index
+type indexEditedMsg struct {
+	tempFile  string
+	namespace string // Collection the index is for
+	err       error
+}
+
+// indexCreatedMsg reports the end of an index build
+type indexCreatedMsg struct {
+	namespace string
+	name      string // Name of the built index, or of the requested one when the build failed
+	err       error
+}
+
+// parseIndexSpec parses the spec of a new index as written in $EDITOR. It
+// accepts the same relaxed syntax as the query panel.
+func parseIndexSpec(text string) (mongo.IndexModel, error) {
+	var spec bson.D
+	if err := bson.UnmarshalExtJSON([]byte(relaxedJSONToStrict(expandShellHelpers(text))), false, &spec); err != nil {
+		return mongo.IndexModel{}, err
+	}
+
+	var model mongo.IndexModel
+	opts := options.Index()
+	for _, field := range spec {
+		if field.Value == nil {
+			continue
+		}
+		var ok bool
+		switch field.Key {
+		case "key":
+			var keys bson.D
+			if keys, ok = field.Value.(bson.D); ok {
+				if err := validateIndexKeys(keys); err != nil {
+					return mongo.IndexModel{}, err
+				}
+				model.Keys = keys
+			}
+		case "name":
+			var name string
+			if name, ok = field.Value.(string); ok && name != "" {
+				opts.SetName(name)
+			}
+		case "unique":
+			var unique bool
+			if unique, ok = field.Value.(bool); ok {
+				opts.SetUnique(unique)
+			}
+		case "sparse":
+			var sparse bool
+			if sparse, ok = field.Value.(bool); ok {
+				opts.SetSparse(sparse)
+			}
+		case "expireAfterSeconds":
+			var seconds int64
+			if seconds, ok = wholeNumber(field.Value); ok && seconds >= 0 {
+				opts.SetExpireAfterSeconds(int32(seconds))
+			} else if ok {
+				return mongo.IndexModel{}, errors.New("expireAfterSeconds can't be negative")
+			}
+		case "partialFilterExpression":
+			var filter bson.D
+			if filter, ok = field.Value.(bson.D); ok {
+				opts.SetPartialFilterExpression(filter)
+			}
+		default:
+			return mongo.IndexModel{}, fmt.Errorf("unknown index option %q", field.Key)
+		}
+		if !ok {
+			return mongo.IndexModel{}, fmt.Errorf("%s has the wrong type", field.Key)
+		}
+	}
+	if model.Keys == nil {
+		return mongo.IndexModel{}, errors.New(`the index has no "key"`)
+	}
+	model.Options = opts
+	return model, nil
+}
+
+// validateIndexKeys checks that every key of an index is ascending (1),
+// descending (-1) or a special index type such as "text" or "2dsphere"
+func validateIndexKeys(keys bson.D) error {
+	if len(keys) == 0 {
+		return errors.New(`"key" names no fields`)
+	}
+	for _, key := range keys {
+		if key.Key == "field" {
+			return errors.New(`replace "field" in "key" with the field to index`)
+		}
+		if n, ok := wholeNumber(key.Value); ok && (n == 1 || n == -1) {
+			continue
+		}
+		if kind, ok := key.Value.(string); ok && kind != "" {
+			continue
+		}
+		return fmt.Errorf("%s: index keys are 1, -1 or an index type such as \"text\"", key.Key)
+	}
+	return nil
+}
+
+// wholeNumber returns a number parsed from JSON as an int64, if it is whole
+func wholeNumber(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v), true
+		}
+	}
+	return 0, false
+}
+
+// openIndexEditor writes the spec of a new index for the collection of the
+// indexes overlay in $EDITOR, starting from the last one that failed
+func (m *Model) openIndexEditor() tea.Cmd {
+	text := m.indexDraft
+	if text == "" {
+		text = indexTemplate
+	}
+	tmpFile, err := os.CreateTemp("", "mbongo-index-*.json")
+	if err != nil {
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Failed to create the index file: %v", err)
+		return nil
+	}
+	_, err = tmpFile.WriteString(text)
+	tmpFile.Close()
+	if err != nil {
+		os.Remove(tmpFile.Name())
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Failed to write the index file: %v", err)
+		return nil
+	}
+
+	m.editorActive = true
+	path, ns := tmpFile.Name(), m.indexesNamespace
+	return tea.ExecProcess(editorCommand(path), func(err error) tea.Msg {
+		return indexEditedMsg{tempFile: path, namespace: ns, err: err}
+	})
+}
+
+// handleIndexEdited starts building the index written in $EDITOR as a
+// background job, so browsing goes on while the server builds it. Leaving
+// the template unchanged cancels.
+func (m *Model) handleIndexEdited(msg indexEditedMsg) tea.Cmd {
+	m.editorActive = false
+	defer os.Remove(msg.tempFile)
+	if msg.err != nil {
+		if exit, ok := editorExit(msg.err); ok {
+			return m.setStatus(fmt.Sprintf("editor %s — index discarded, nothing was created", exit))
+		}
+		m.errorModal = true
+		m.errorMessage = editorErrorMessage(msg.err)
+		return nil
+	}
+	data, err := os.ReadFile(msg.tempFile)
+	if err != nil {
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Failed to read the index file: %v", err)
+		return nil
+	}
+	text := strings.TrimSpace(string(data))
+	if text == "" || text == strings.TrimSpace(indexTemplate) {
+		return m.setStatus("index cancelled: nothing was changed")
+	}
+	// Kept until the index is built so a mistake can be fixed with c
+	m.indexDraft = text + "\n"
+	model, err := parseIndexSpec(text)
+	if err != nil {
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Invalid index: %v\n\nNo index was created. Press c to fix it.", err)
+		return nil
+	}
+	return m.buildIndex(msg.namespace, model)
+}
+
+// buildIndex creates an index as a background job. The build goes on on the
+// server when the job is cancelled; only the wait for it stops.
+func (m *Model) buildIndex(ns string, model mongo.IndexModel) tea.Cmd {
+	client := m.client
+	dbName, collName := splitNamespace(ns)
+	label := keySpecText(model.Keys.(bson.D))
+	if opts := model.Options; opts != nil && opts.Name != nil {
+		label = *opts.Name
+	}
+	return m.startJob(fmt.Sprintf("%s%s on %s", indexJobPrefix, label, ns), func(ctx context.Context, report func(string)) (tea.Msg, error) {
+		name, err := client.Database(dbName).Collection(collName).Indexes().CreateOne(ctx, model)
+		if err != nil {
+			name = label
+		}
+		return indexCreatedMsg{namespace: ns, name: name, err: err}, err
+	})
+}
+
+// indexBuilds returns the names of the index build jobs on ns that haven't
+// finished
+func (m Model) indexBuilds(ns string) []string {
+	var builds []string
+	for _, job := range m.jobs {
+		if strings.HasPrefix(job.Name, indexJobPrefix) && strings.HasSuffix(job.Name, " on "+ns) && (job.State == JobQueued || job.State == JobRunning) {
+			builds = append(builds, strings.TrimSuffix(strings.TrimPrefix(job.Name, indexJobPrefix), " on "+ns))
+		}
+	}
+	return builds
+}
+
+// handleIndexCreated announces the end of an index build and refreshes the
+// indexes overlay when it shows the collection
+func (m *Model) handleIndexCreated(msg indexCreatedMsg) tea.Cmd {
+	ns := truncateMiddle(msg.namespace, maxToastNameWidth)
+	if msg.err != nil {
+		return m.setStatus(fmt.Sprintf("building index %s on %s failed: %v", msg.name, ns, msg.err))
+	}
+	m.indexDraft = ""
+	status := m.setStatus(fmt.Sprintf("built index %s on %s", msg.name, ns))
+	if m.indexesActive && m.indexesNamespace == msg.namespace {
+		return tea.Batch(status, m.refreshIndexes())
+	}
+	return status
+}
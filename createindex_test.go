@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestParseIndexSpec(t *testing.T) {
+	model, err := parseIndexSpec(`{key: {status: 1, createdAt: -1}, name: "recent", unique: true, sparse: false,
+		expireAfterSeconds: 3600, partialFilterExpression: {archived: false}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := model.Options
+	if keySpecText(model.Keys.(bson.D)) != `{"status":1,"createdAt":-1}` || *opts.Name != "recent" || !*opts.Unique || *opts.ExpireAfterSeconds != 3600 {
+		t.Errorf("model = %v, options = %+v", model.Keys, opts)
+	}
+	if opts.PartialFilterExpression == nil {
+		t.Error("partial filter dropped")
+	}
+
+	// null options and an empty name are left to the server
+	model, err = parseIndexSpec(`{"key": {"bio": "text"}, "name": "", "expireAfterSeconds": null}`)
+	if err != nil || model.Options.Name != nil || model.Options.ExpireAfterSeconds != nil {
+		t.Errorf("err = %v, options = %+v", err, model.Options)
+	}
+
+	for spec, want := range map[string]string{
+		indexTemplate:                                 `replace "field"`,
+		`{"unique": true}`:                            `no "key"`,
+		`{"key": {}}`:                                 "names no fields",
+		`{"key": {"a": 2}}`:                           "a: index keys are 1, -1",
+		`{"key": {"a": 1}, "unique": "y"}`:            "unique has the wrong type",
+		`{"key": {"a": 1}, "background": true}`:       `unknown index option "background"`,
+		`{"key": {"a": 1}, "expireAfterSeconds": -5}`: "can't be negative",
+	} {
+		if _, err := parseIndexSpec(spec); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("parseIndexSpec(%s) = %v, want %q", spec, err, want)
+		}
+	}
+}
+
+func TestEditedIndexIsBuiltInTheBackground(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m.indexesActive = true
+	m.indexesNamespace = "shop.orders"
+
+	path := filepath.Join(t.TempDir(), "index.json")
+	os.WriteFile(path, []byte(`{"key": {"status": 1}, "unique": "yes"}`), 0o600)
+	m, _ = update(t, m, indexEditedMsg{tempFile: path, namespace: "shop.orders"})
+	if !m.errorModal || !strings.Contains(m.errorMessage, "No index was created") || !strings.Contains(m.indexDraft, `"unique": "yes"`) {
+		t.Fatalf("modal = %v %q, draft %q", m.errorModal, m.errorMessage, m.indexDraft)
+	}
+	if _, err := os.Stat(path); err == nil {
+		t.Error("index file left behind")
+	}
+	m.errorModal = false
+
+	os.WriteFile(path, []byte(`{"key": {"status": 1}, "name": "by_status"}`), 0o600)
+	m, _ = update(t, m, indexEditedMsg{tempFile: path, namespace: "shop.orders"})
+	if len(m.jobs) != 1 || m.jobs[0].Name != "build index by_status on shop.orders" {
+		t.Fatalf("jobs = %+v", m.jobs)
+	}
+	if builds := m.indexBuilds("shop.orders"); len(builds) != 1 || builds[0] != "by_status" {
+		t.Errorf("builds = %q", builds)
+	}
+	if view := normalizeRender(m.View()); !strings.Contains(view, "building by_status...") {
+		t.Errorf("build not shown in the overlay:\n%s", view)
+	}
+
+	// Failures are announced without a modal and keep the draft
+	m, _ = update(t, m, indexCreatedMsg{namespace: "shop.orders", name: "by_status", err: errors.New("index build aborted")})
+	if m.errorModal || !strings.Contains(m.statusMessage, "building index by_status on shop.orders failed") || m.indexDraft == "" {
+		t.Errorf("modal = %v, status %q, draft %q", m.errorModal, m.statusMessage, m.indexDraft)
+	}
+
+	m, cmd := update(t, m, indexCreatedMsg{namespace: "shop.orders", name: "by_status"})
+	if cmd == nil || !m.indexesLoading || m.indexDraft != "" || !strings.Contains(m.statusMessage, "built index by_status") {
+		t.Errorf("loading = %v, draft %q, status %q", m.indexesLoading, m.indexDraft, m.statusMessage)
+	}
+}
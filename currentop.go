@@ -0,0 +1,329 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// currentOp is an operation in progress as $currentOp reports it
+type currentOp struct {
+	// OpID is a number on mongod and "shard:number" on mongos
+	OpID         interface{} `bson:"opid"`
+	Op           string      `bson:"op"`
+	Namespace    string      `bson:"ns"`
+	Microsecs    int64       `bson:"microsecs_running"`
+	Command      bson.Raw    `bson:"command"`
+	Desc         string      `bson:"desc"`
+	Client       string      `bson:"client"`
+	ClientMongos string      `bson:"client_s"`
+}
+
+// running returns how long the operation has been running
+func (op currentOp) running() time.Duration {
+	return time.Duration(op.Microsecs) * time.Microsecond
+}
+
+// commandText returns the operation's command as relaxed Extended JSON, or
+// its description for internal operations that have none
+func (op currentOp) commandText() string {
+	if len(op.Command) > 0 {
+		if text, err := bson.MarshalExtJSON(op.Command, false, false); err == nil {
+			return string(text)
+		}
+	}
+	return op.Desc
+}
+
+// clientAddress returns the address of the client that started the operation
+func (op currentOp) clientAddress() string {
+	if op.Client != "" {
+		return op.Client
+	}
+	return op.ClientMongos
+}
+
+// currentOpsMsg carries the operations in progress
+type currentOpsMsg struct {
+	ops     []currentOp
+	ownOnly bool // Listing every user's operations wasn't permitted
+	err     error
+}
+
+// loadCurrentOps lists the active operations, longest running first. Seeing
+// other users' operations needs the inprog privilege; without it the user's
+// own operations are listed.
+func loadCurrentOps(client *mongo.Client) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		list := func(allUsers bool) ([]currentOp, error) {
+			pipeline := mongo.Pipeline{
+				{{Key: "$currentOp", Value: bson.D{{Key: "allUsers", Value: allUsers}}}},
+				{{Key: "$match", Value: bson.D{{Key: "active", Value: true}}}},
+				{{Key: "$sort", Value: bson.D{{Key: "microsecs_running", Value: -1}}}},
+			}
+			cursor, err := client.Database("admin").Aggregate(ctx, pipeline)
+			if err != nil {
+				return nil, err
+			}
+			var ops []currentOp
+			err = cursor.All(ctx, &ops)
+			return ops, err
+		}
+		ops, err := list(true)
+		if isUnauthorizedError(err) {
+			ops, err = list(false)
+			return currentOpsMsg{ops: ops, ownOnly: true, err: err}
+		}
+		return currentOpsMsg{ops: ops, err: err}
+	}
+}
+
+// openCurrentOps shows the operations in progress on the server
+func (m *Model) openCurrentOps() tea.Cmd {
+	if m.client == nil {
+		return nil
+	}
+	if cmd := m.unsupported(FeatureCurrentOp); cmd != nil {
+		return cmd
+	}
+	m.opsActive = true
+	m.ops = nil
+	m.opsCursor = 0
+	m.opsScroll = 0
+	return m.refreshCurrentOps()
+}
+
+// refreshCurrentOps lists the operations in progress again
+func (m *Model) refreshCurrentOps() tea.Cmd {
+	m.opsLoading = true
+	m.opsErr = nil
+	return loadCurrentOps(m.client)
+}
+
+// handleCurrentOps shows the operations in progress, unless the overlay was
+// closed meanwhile
+func (m *Model) handleCurrentOps(msg currentOpsMsg) {
+	if !m.opsActive {
+		return
+	}
+	m.opsLoading = false
+	m.opsErr = msg.err
+	m.ops = msg.ops
+	m.opsOwnOnly = msg.ownOnly
+	m.moveOpsCursor(m.opsCursor)
+}
+
+// getOpsListHeight returns the number of operation rows visible in the overlay
+func (m Model) getOpsListHeight() int {
+	// Border (2) + padding (2) + title and blank (2) + header (1) + blank
+	// and up to four lines of details (5) + blank and hint (2)
+	height := m.height - 4 - 14
+	if height < 3 {
+		height = 3
+	}
+	return height
+}
+
+// moveOpsCursor moves the overlay cursor, scrolling to keep it visible
+func (m *Model) moveOpsCursor(target int) {
+	visible := m.getOpsListHeight()
+	m.opsCursor = clampIndex(target, len(m.ops))
+	if m.opsCursor < m.opsScroll {
+		m.opsScroll = m.opsCursor
+	} else if m.opsCursor >= m.opsScroll+visible {
+		m.opsScroll = m.opsCursor - visible + 1
+	}
+}
+
+// handleOpsKey handles keyboard input while the operations overlay is open.
+// k kills, so only the arrows and ctrl+p/ctrl+n move the cursor.
+func (m *Model) handleOpsKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "ctrl+g", "q":
+		m.opsActive = false
+	case "up", "ctrl+p":
+		m.moveOpsCursor(m.opsCursor - 1)
+	case "down", "ctrl+n":
+		m.moveOpsCursor(m.opsCursor + 1)
+	case "pgup", "pgdown", "home", "end":
+		target, _ := pageKeyTarget(msg.String(), m.opsCursor, m.getOpsListHeight(), len(m.ops))
+		m.moveOpsCursor(target)
+	case "r":
+		if !m.opsLoading {
+			return m.refreshCurrentOps()
+		}
+	case "k":
+		return m.confirmKillOp()
+	}
+	return nil
+}
+
+// formatRunning formats how long an operation has been running, precisely
+// for short ones
+func formatRunning(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	}
+	return d.Round(time.Second).String()
+}
+
+// renderOpsOverlay renders the operations in progress
+func (m Model) renderOpsOverlay(background string) string {
+	width := m.modalWidth(110)
+	contentWidth := width - 4
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+	title := "Operations in progress"
+	var lines []string
+	switch {
+	case m.opsLoading && m.ops == nil:
+		lines = append(lines, normalStyle.Render("Loading..."))
+	case isUnauthorizedError(m.opsErr):
+		lines = append(lines, normalStyle.Render("No permission to list operations: it needs the inprog privilege, e.g. from the clusterMonitor role"))
+	case m.opsErr != nil:
+		lines = append(lines, textWrapStyle(contentWidth).Render("Failed to list operations: "+m.opsErr.Error()))
+	case len(m.ops) == 0:
+		lines = append(lines, normalStyle.Render("(no active operations)"))
+	default:
+		title += fmt.Sprintf(" (%d)", len(m.ops))
+		// Rows leave a column each side for the padding of the selection
+		opidWidth, opWidth, nsWidth, timeWidth := 14, 9, 28, 8
+		commandWidth := contentWidth - opidWidth - opWidth - nsWidth - timeWidth - 4 - 2
+		if commandWidth < 10 {
+			commandWidth = 10
+		}
+		row := func(opid, op, ns, running, command string) string {
+			return fmt.Sprintf("%-*s %-*s %-*s %*s %s",
+				opidWidth, truncate(opid, opidWidth),
+				opWidth, truncate(op, opWidth),
+				nsWidth, truncateMiddle(ns, nsWidth),
+				timeWidth, running,
+				truncate(command, commandWidth))
+		}
+		lines = append(lines, dimStyle.Render(" "+row("OPID", "OP", "NAMESPACE", "RUNNING", "COMMAND")))
+		end := m.opsScroll + m.getOpsListHeight()
+		if end > len(m.ops) {
+			end = len(m.ops)
+		}
+		for i := m.opsScroll; i < end; i++ {
+			op := m.ops[i]
+			line := " " + row(fmt.Sprint(op.OpID), op.Op, op.Namespace, formatRunning(op.running()), op.commandText())
+			if i == m.opsCursor {
+				line = selectedStyle.Render(strings.TrimPrefix(line, " "))
+			}
+			lines = append(lines, line)
+		}
+		op := m.ops[m.opsCursor]
+		details := fmt.Sprintf("opid %v from %s: %s", op.OpID, op.clientAddress(), op.commandText())
+		lines = append(lines, "", textWrapStyle(contentWidth).MaxHeight(4).Render(details))
+	}
+	if m.opsOwnOnly && m.opsErr == nil {
+		lines = append(lines, "", dimStyle.Render(truncate("Only your own operations: seeing everyone's needs the inprog privilege", contentWidth)))
+	}
+
+	hint := "k: kill • r: refresh • ↑/↓: select • esc: close"
+	if m.opsLoading && m.ops != nil {
+		hint = "refreshing... • " + hint
+	}
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render(truncate(title, contentWidth)),
+		"",
+		strings.Join(lines, "\n"),
+		"",
+		hintStyle.Render(hint),
+	)
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("205")).
+		Padding(1, 2).
+		Width(width)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(content),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
+
+// opKilledMsg reports the end of killing an operation
+type opKilledMsg struct {
+	opid interface{}
+	err  error
+}
+
+// confirmKillOp asks to confirm killing the operation under the cursor of
+// the operations overlay
+func (m *Model) confirmKillOp() tea.Cmd {
+	if m.opsLoading || m.opsCursor >= len(m.ops) {
+		return nil
+	}
+	op := m.ops[m.opsCursor]
+	target := op.Namespace
+	if target == "" {
+		target = "the server"
+	}
+	return m.openConfirm(&confirmation{
+		title:    "Kill Operation",
+		message:  fmt.Sprintf("Kill operation %v (%s on %s, running for %s)? Its client gets an error and a write may be left half done.", op.OpID, op.Op, target, formatRunning(op.running())),
+		severity: SeverityDanger,
+		onConfirm: func(m *Model) tea.Cmd {
+			return killOp(m.client, op.OpID)
+		},
+	})
+}
+
+// killOp asks the server to stop the operation opid
+func killOp(client *mongo.Client, opid interface{}) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		cmd := bson.D{{Key: "killOp", Value: 1}, {Key: "op", Value: opid}}
+		err := client.Database("admin").RunCommand(ctx, cmd).Err()
+		return opKilledMsg{opid: opid, err: err}
+	}
+}
+
+// handleOpKilled announces a killed operation and refreshes the operations
+// overlay. A failure opens over the overlay, which stays open.
+func (m *Model) handleOpKilled(msg opKilledMsg) tea.Cmd {
+	if isUnauthorizedError(msg.err) {
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("No permission to kill operation %v: it needs the killop privilege, e.g. from the hostManager role, unless the operation is your own", msg.opid)
+		return nil
+	}
+	if msg.err != nil {
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Failed to kill operation %v: %v", msg.opid, msg.err)
+		return nil
+	}
+	// The operation stops at its next interrupt check, which is usually at once
+	status := m.setStatus(fmt.Sprintf("killed operation %v", msg.opid))
+	if m.opsActive {
+		return tea.Batch(status, m.refreshCurrentOps())
+	}
+	return status
+}
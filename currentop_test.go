@@ -0,0 +1,106 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func testCurrentOps(t *testing.T) []currentOp {
+	command, err := bson.Marshal(bson.D{{Key: "find", Value: "orders"}, {Key: "filter", Value: bson.D{{Key: "status", Value: "open"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []currentOp{
+		{OpID: int32(4211), Op: "query", Namespace: "shop.orders", Microsecs: 95_400_000, Command: command, Client: "10.0.0.7:51234"},
+		{OpID: int32(4230), Op: "command", Microsecs: 1200, Desc: "TTLMonitor"},
+	}
+}
+
+func TestCurrentOpsOverlay(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+
+	m = pressKey(m, "ctrl+x")
+	m = pressKey(m, "a")
+	if !m.opsActive || !m.opsLoading {
+		t.Fatalf("active = %v, loading = %v", m.opsActive, m.opsLoading)
+	}
+
+	m, _ = update(t, m, currentOpsMsg{ops: testCurrentOps(t)})
+	view := normalizeRender(m.View())
+	for _, want := range []string{"Operations in progress (2)", "4211", "shop.orders", "1m35s", `{"find":"orders"`, "TTLMonitor", "from 10.0.0.7:51234"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q:\n%s", want, view)
+		}
+	}
+
+	m = pressKey(m, "down")
+	m = pressKey(m, "k")
+	if m.confirm == nil || !strings.Contains(m.confirm.message, "Kill operation 4230 (command on the server") {
+		t.Fatalf("confirm = %+v", m.confirm)
+	}
+	m.confirm = nil
+
+	m = pressKey(m, "esc")
+	if m.opsActive {
+		t.Fatal("esc didn't close the overlay")
+	}
+}
+
+func TestCurrentOpsOwnOnly(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m = pressKey(m, "ctrl+x")
+	m = pressKey(m, "a")
+
+	m, _ = update(t, m, currentOpsMsg{ops: testCurrentOps(t)[:1], ownOnly: true})
+	if view := normalizeRender(m.View()); !strings.Contains(view, "Only your own operations") {
+		t.Errorf("view doesn't say the list is partial:\n%s", view)
+	}
+}
+
+func TestCurrentOpsNeedsServer36(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m.serverVersion = serverVersion{3, 4}
+
+	m = pressKey(m, "ctrl+x")
+	m = pressKey(m, "a")
+	if m.opsActive || !strings.Contains(m.statusMessage, "$currentOp need MongoDB 3.6") {
+		t.Errorf("active = %v, status %q", m.opsActive, m.statusMessage)
+	}
+}
+
+func TestOpKilled(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m.opsActive = true
+
+	m, cmd := update(t, m, opKilledMsg{opid: int32(4211)})
+	if cmd == nil || m.statusMessage != "killed operation 4211" || !m.opsLoading {
+		t.Errorf("status %q, refreshing = %v", m.statusMessage, m.opsLoading)
+	}
+
+	denied := mongo.CommandError{Code: 13, Name: "Unauthorized", Message: "not authorized on admin to execute command"}
+	m, _ = update(t, m, opKilledMsg{opid: int32(4211), err: denied})
+	if !m.errorModal || !strings.Contains(m.errorMessage, "needs the killop privilege") {
+		t.Errorf("modal = %v %q", m.errorModal, m.errorMessage)
+	}
+}
+
+func TestFormatRunning(t *testing.T) {
+	tests := map[time.Duration]string{
+		1200 * time.Microsecond:  "1ms",
+		2500 * time.Millisecond:  "2.5s",
+		95400 * time.Millisecond: "1m35s",
+	}
+	for d, want := range tests {
+		if got := formatRunning(d); got != want {
+			t.Errorf("formatRunning(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"sort"
 	"strings"
 	"time"
 
@@ -28,8 +27,12 @@ func connectToMongo(connectionString string) tea.Cmd {
 			return databasesLoadedMsg{err: err}
 		}
 
-		// List databases
+		// List databases. Users without the privilege to list them can still
+		// open the default database of the connection string.
 		databases, err := client.ListDatabaseNames(ctx, map[string]interface{}{})
+		if uriDB, _ := uriDefaults(connectionString); isUnauthorizedError(err) && uriDB != "" {
+			return databasesLoadedMsg{databases: []string{uriDB}, client: client, listDenied: true}
+		}
 		if err != nil {
 			return databasesLoadedMsg{err: err}
 		}
@@ -65,36 +68,29 @@ func loadCollections(client *mongo.Client, dbName string) tea.Cmd {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		collections, err := client.Database(dbName).ListCollectionNames(ctx, map[string]interface{}{})
+		collections, specs, err := listCollections(ctx, client, dbName)
 		if err != nil {
 			return collectionsLoadedMsg{err: err}
 		}
 
-		sort.Strings(collections)
-
-		return collectionsLoadedMsg{collections: collections}
+		return collectionsLoadedMsg{collections: collections, specs: specs}
 	}
 }
 
 // updateFilteredDatabases updates the filtered databases based on search input
 func (m *Model) updateFilteredDatabases() {
 	query := m.dbSearchInput.Value()
-	if query == "" {
-		// No filter - show all databases
-		m.dbFiltered = m.databases
-		m.dbFilteredIndices = make([]int, len(m.databases))
-		for i := range m.databases {
-			m.dbFilteredIndices[i] = i
+	// Filter databases by fuzzy match, leaving out the hidden system ones.
+	// dbFilteredIndices maps the shown entries back to m.databases.
+	m.dbFiltered = []string{}
+	m.dbFilteredIndices = []int{}
+	for i, db := range m.databases {
+		if m.hiddenDatabase(db) {
+			continue
 		}
-	} else {
-		// Filter databases by fuzzy match
-		m.dbFiltered = []string{}
-		m.dbFilteredIndices = []int{}
-		for i, db := range m.databases {
-			if fuzzyMatch(query, db) {
-				m.dbFiltered = append(m.dbFiltered, db)
-				m.dbFilteredIndices = append(m.dbFilteredIndices, i)
-			}
+		if query == "" || fuzzyMatch(query, db) {
+			m.dbFiltered = append(m.dbFiltered, db)
+			m.dbFilteredIndices = append(m.dbFilteredIndices, i)
 		}
 	}
 	// Reset cursor if out of bounds
@@ -131,6 +127,10 @@ func (m *Model) handleDatabaseSearchKey(msg tea.KeyMsg) (tea.Cmd, bool) {
 			m.dbCursor--
 		}
 		return nil, true
+	case "pgup", "pgdown":
+		// Page through the filtered list (home/end stay with the input)
+		m.dbCursor, _ = pageKeyTarget(msg.String(), m.dbCursor, m.getListHeight()-1, len(m.dbFiltered))
+		return nil, true
 	case "enter":
 		// Select the highlighted database and load its collections
 		if len(m.dbFiltered) > 0 && m.client != nil {
@@ -178,7 +178,8 @@ func (m Model) renderDatabasePanel(innerHeight int) string {
 	} else {
 		dbContent = m.renderList(m.dbFiltered, m.dbCursor, m.focus == FocusDatabases, dbListHeight)
 	}
-	return m.renderPanel("Databases", "", dbContent, m.focus == FocusDatabases || m.dbSearchActive, leftPanelWidth, innerHeight)
+	info := strings.TrimSpace(hiddenSuffix(m.databases, m.hiddenDatabase) + " " + refreshedSuffix(m.dbRefreshedAt))
+	return m.renderPanel("Databases", info, dbContent, m.focus == FocusDatabases || m.dbSearchActive, leftPanelWidth, innerHeight)
 }
 
 // newDatabaseSearchInput creates a new textinput for database search
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// dateDisplaySetting is the settings key the date display is saved under
+const dateDisplaySetting = "date_display"
+
+// DateDisplay is how dates are rendered in the tree and the raw view
+type DateDisplay int
+
+const (
+	DateISO   DateDisplay = iota // ISODate("2024-03-02T11:45:00Z"), as stored
+	DateLocal                    // RFC 3339 in the local time zone, with relative time
+	DateUTC                      // RFC 3339 in UTC, with relative time
+)
+
+// dateDisplayNames are the saved names of the date displays
+var dateDisplayNames = map[DateDisplay]string{
+	DateISO:   "iso",
+	DateLocal: "local",
+	DateUTC:   "utc",
+}
+
+// parseDateDisplay returns the date display with a saved name, DateISO for
+// anything unknown
+func parseDateDisplay(name string) DateDisplay {
+	for display, n := range dateDisplayNames {
+		if n == name {
+			return display
+		}
+	}
+	return DateISO
+}
+
+// relativeTime describes how long ago (or ahead) t is from now, in the
+// largest unit that fits: "3 days ago", "in 2 hours"
+func relativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+	var n int
+	var unit string
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		n, unit = int(d/time.Minute), "minute"
+	case d < 24*time.Hour:
+		n, unit = int(d/time.Hour), "hour"
+	case d < 30*24*time.Hour:
+		n, unit = int(d/(24*time.Hour)), "day"
+	case d < 365*24*time.Hour:
+		n, unit = int(d/(30*24*time.Hour)), "month"
+	default:
+		n, unit = int(d/(365*24*time.Hour)), "year"
+	}
+	if n != 1 {
+		unit += "s"
+	}
+	if future {
+		return fmt.Sprintf("in %d %s", n, unit)
+	}
+	return fmt.Sprintf("%d %s ago", n, unit)
+}
+
+// dateText renders a date in the current display, or "" for DateISO
+func (m Model) dateText(dt primitive.DateTime) string {
+	t := dt.Time()
+	switch m.dateDisplay {
+	case DateLocal:
+		t = t.Local()
+	case DateUTC:
+		t = t.UTC()
+	default:
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// renderDate renders a date leaf as its RFC 3339 time with a dim relative
+// suffix, computed as the line is drawn
+func (m Model) renderDate(dt primitive.DateTime) string {
+	return jsonStringStyle.Render(m.dateText(dt)) + "  " + epochAnnotationStyle.Render("("+relativeTime(dt.Time(), time.Now())+")")
+}
+
+// renderRawDate renders the annotation after the $numberLong of a date in
+// the raw view, which canonical Extended JSON stores as milliseconds
+func (m Model) renderRawDate(dt primitive.DateTime) string {
+	return epochAnnotationStyle.Render(fmt.Sprintf("(%s, %s)", m.dateText(dt), relativeTime(dt.Time(), time.Now())))
+}
+
+// cycleDateDisplay switches between stored, local and UTC dates and saves
+// the choice for the next session
+func (m *Model) cycleDateDisplay() tea.Cmd {
+	m.dateDisplay = (m.dateDisplay + 1) % 3
+	if err := saveSetting(dateDisplaySetting, dateDisplayNames[m.dateDisplay]); err != nil {
+		m.errorModal = true
+		m.errorMessage = "Failed to save the date display: " + err.Error()
+		return nil
+	}
+	switch m.dateDisplay {
+	case DateLocal:
+		return m.setStatus("dates in local time (" + time.Now().Format("MST") + ") with relative time")
+	case DateUTC:
+		return m.setStatus("dates in UTC with relative time")
+	default:
+		return m.setStatus("dates as stored")
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{now.Add(-20 * time.Second), "just now"},
+		{now.Add(-time.Minute), "1 minute ago"},
+		{now.Add(-45 * time.Minute), "45 minutes ago"},
+		{now.Add(-5 * time.Hour), "5 hours ago"},
+		{now.Add(-3 * 24 * time.Hour), "3 days ago"},
+		{now.AddDate(0, -6, 0), "6 months ago"},
+		{now.AddDate(-2, 0, 0), "2 years ago"},
+		{now.Add(2 * time.Hour), "in 2 hours"},
+		{now.AddDate(0, 0, 1), "in 1 day"},
+	}
+	for _, tt := range tests {
+		if got := relativeTime(tt.t, now); got != tt.want {
+			t.Errorf("relativeTime(%v) = %q, want %q", tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestDateDisplay(t *testing.T) {
+	m := newTestModel(120, 30)
+	created := time.Date(2024, 3, 2, 11, 45, 0, 0, time.UTC)
+	node := buildValueNode("created", primitive.NewDateTimeFromTime(created), 1)
+	node.Parent = m.docTree[1]
+
+	if got := normalizeRender(m.renderNode(node, 120)); !strings.HasSuffix(got, `ISODate("2024-03-02T11:45:00Z")`) {
+		t.Errorf("stored display = %q", got)
+	}
+
+	m = pressKey(m, "T")
+	local := created.Local().Format(time.RFC3339)
+	if got := normalizeRender(m.renderNode(node, 120)); !strings.Contains(got, `"created": `+local+"  (") || !strings.HasSuffix(got, " ago)") {
+		t.Errorf("local display = %q, want %s with a relative suffix", got, local)
+	}
+
+	m = pressKey(m, "T")
+	if got := normalizeRender(m.renderNode(node, 120)); !strings.Contains(got, `"created": 2024-03-02T11:45:00Z  (`) {
+		t.Errorf("UTC display = %q", got)
+	}
+	if got := rawValueString(node.Value); got != "2024-03-02T11:45:00Z" {
+		t.Errorf("copied value = %q", got)
+	}
+
+	m = pressKey(m, "T")
+	if m.dateDisplay != DateISO {
+		t.Errorf("T cycled to %v, want back to DateISO", m.dateDisplay)
+	}
+}
+
+func TestRawViewDates(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.docCursor = m.flattenedIndex(m.docTree[1])
+	m.dateDisplay = DateUTC
+	m.toggleRawView()
+
+	var annotated []string
+	for _, node := range m.flattenedTree {
+		if node.RawText != "" {
+			if line := normalizeRender(m.renderNode(node, 120)); strings.Contains(line, "(2024") {
+				annotated = append(annotated, strings.TrimSpace(line))
+			}
+		}
+	}
+	if len(annotated) != 1 || !strings.HasPrefix(annotated[0], `"$numberLong": "1709379900000"  (2024-03-02T11:45:00Z, `) {
+		t.Errorf("annotated raw lines = %q", annotated)
+	}
+}
+
+func TestDateDisplaySettingPersists(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := initDB(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		closeDB()
+		db = nil
+	}()
+
+	m := newTestModel(120, 30)
+	m = pressKey(m, "T")
+	m = pressKey(m, "T")
+	if saved, err := loadSetting(dateDisplaySetting); err != nil || parseDateDisplay(saved) != DateUTC {
+		t.Errorf("saved date display = %q (%v), want utc", saved, err)
+	}
+}
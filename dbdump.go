@@ -0,0 +1,448 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// manifestFile is the name of the manifest in a database export directory
+const manifestFile = "manifest.json"
+
+// dumpManifest describes a database export: what each file holds, the
+// indexes to recreate, and the collections that couldn't be read
+type dumpManifest struct {
+	Database    string           `bson:"database"`
+	Exported    time.Time        `bson:"exported"`
+	Collections []dumpCollection `bson:"collections"`
+	Skipped     []dumpSkipped    `bson:"skipped"`
+}
+
+// dumpCollection is one exported collection
+type dumpCollection struct {
+	Name    string   `bson:"name"`
+	File    string   `bson:"file"`
+	Count   int      `bson:"count"`
+	Indexes []bson.D `bson:"indexes"` // listIndexes specs, as createIndexes takes them
+}
+
+// dumpSkipped is a collection left out of an export
+type dumpSkipped struct {
+	Name  string `bson:"name"`
+	Error string `bson:"error"`
+}
+
+// dumpFileName returns the file a collection is exported to; escaping keeps
+// names with slashes inside the directory
+func dumpFileName(collName string) string {
+	return url.PathEscape(collName) + ".json.gz"
+}
+
+// newDumpInputs creates the directory and target database inputs of the
+// database export/import prompt
+func newDumpInputs() (textinput.Model, textinput.Model) {
+	dir := textinput.New()
+	dir.CharLimit = 300
+	dir.Width = 50
+	target := textinput.New()
+	target.CharLimit = 64
+	target.Width = 50
+	return dir, target
+}
+
+// openDumpPrompt opens the prompt to export the database under the cursor
+// to a directory, or with restore set to import a directory into it
+func (m *Model) openDumpPrompt(restore bool) tea.Cmd {
+	if len(m.dbFiltered) == 0 || m.client == nil {
+		return nil
+	}
+	m.dumpPromptActive = true
+	m.dumpImport = restore
+	m.dumpDatabase = m.dbFiltered[m.dbCursor]
+	m.dumpTargetFocused = false
+	m.dumpDirInput.SetValue("")
+	if !restore {
+		m.dumpDirInput.SetValue(fmt.Sprintf("%s-%s", m.dumpDatabase, time.Now().Format("20060102-150405")))
+	}
+	m.dumpDirInput.CursorEnd()
+	m.dumpDirInput.Focus()
+	m.dumpTargetInput.SetValue(m.dumpDatabase)
+	m.dumpTargetInput.CursorEnd()
+	m.dumpTargetInput.Blur()
+	return textinput.Blink
+}
+
+// handleDumpPromptKey handles keyboard input in the database export/import
+// prompt; tab moves between the directory and the target database
+func (m *Model) handleDumpPromptKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "ctrl+g":
+		m.dumpPromptActive = false
+		m.dumpDirInput.Blur()
+		m.dumpTargetInput.Blur()
+		return nil
+	case "tab", "shift+tab":
+		if !m.dumpImport {
+			return nil
+		}
+		m.dumpTargetFocused = !m.dumpTargetFocused
+		if m.dumpTargetFocused {
+			m.dumpDirInput.Blur()
+			m.dumpTargetInput.Focus()
+		} else {
+			m.dumpTargetInput.Blur()
+			m.dumpDirInput.Focus()
+		}
+		return textinput.Blink
+	case "enter":
+		dir := strings.TrimSpace(m.dumpDirInput.Value())
+		target := strings.TrimSpace(m.dumpTargetInput.Value())
+		if dir == "" || target == "" || m.dumpTargetError() != nil {
+			return nil
+		}
+		m.dumpPromptActive = false
+		m.dumpDirInput.Blur()
+		m.dumpTargetInput.Blur()
+		dir = expandTilde(dir)
+
+		client, dbName := m.client, m.dumpDatabase
+		if m.dumpImport {
+			return m.startJob("import "+dir+" into "+target, func(ctx context.Context, report func(string)) (tea.Msg, error) {
+				return runDatabaseImport(ctx, report, client, dir, target)
+			})
+		}
+		return m.startJob("export database "+dbName, func(ctx context.Context, report func(string)) (tea.Msg, error) {
+			return runDatabaseExport(ctx, report, client, dbName, dir)
+		})
+	}
+	var cmd tea.Cmd
+	if m.dumpTargetFocused {
+		m.dumpTargetInput, cmd = m.dumpTargetInput.Update(msg)
+	} else {
+		m.dumpDirInput, cmd = m.dumpDirInput.Update(msg)
+	}
+	return cmd
+}
+
+// dumpTargetError reports why the database name typed to import into isn't
+// valid, shown in the prompt as it is typed
+func (m Model) dumpTargetError() error {
+	target := strings.TrimSpace(m.dumpTargetInput.Value())
+	if !m.dumpImport || target == "" {
+		return nil
+	}
+	return validateDatabaseName(target, m.databases)
+}
+
+// runDatabaseExport is the job that streams every collection of dbName to
+// its own gzipped NDJSON file of canonical Extended JSON in dir, then writes
+// the manifest. Collections the user may not read are skipped and listed.
+func runDatabaseExport(ctx context.Context, report func(string), client *mongo.Client, dbName, dir string) (tea.Msg, error) {
+	db := client.Database(dbName)
+	names, err := db.ListCollectionNames(ctx, bson.M{"type": "collection"})
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	manifest := dumpManifest{Database: dbName, Exported: time.Now().UTC()}
+	for i, name := range names {
+		if strings.HasPrefix(name, "system.") {
+			continue
+		}
+		progress := func(count int) {
+			report(fmt.Sprintf("%d/%d collections • %s: %d documents", i+1, len(names), name, count))
+		}
+		progress(0)
+		exported, err := exportCollection(ctx, db.Collection(name), filepath.Join(dir, dumpFileName(name)), progress)
+		if isUnauthorizedError(err) {
+			os.Remove(filepath.Join(dir, dumpFileName(name)))
+			manifest.Skipped = append(manifest.Skipped, dumpSkipped{Name: name, Error: err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("exporting %s: %w", name, err)
+		}
+		manifest.Collections = append(manifest.Collections, exported)
+	}
+
+	if err := writeManifest(filepath.Join(dir, manifestFile), manifest); err != nil {
+		return nil, err
+	}
+	return databaseExportDoneMsg{dir: dir, manifest: manifest}, nil
+}
+
+// exportCollection writes one collection to path and lists its indexes
+func exportCollection(ctx context.Context, coll *mongo.Collection, path string, progress func(int)) (dumpCollection, error) {
+	exported := dumpCollection{Name: coll.Name(), File: filepath.Base(path)}
+
+	cursor, err := coll.Indexes().List(ctx)
+	if err != nil {
+		return exported, err
+	}
+	if err := cursor.All(ctx, &exported.Indexes); err != nil {
+		return exported, err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return exported, err
+	}
+	defer file.Close()
+	gz := gzip.NewWriter(file)
+	w := bufio.NewWriter(gz)
+
+	cursor, err = coll.Find(ctx, bson.M{})
+	if err != nil {
+		return exported, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		// Canonical mode keeps int32/int64/double apart for the restore
+		jsonBytes, err := bson.MarshalExtJSON(cursor.Current, true, false)
+		if err != nil {
+			return exported, err
+		}
+		w.Write(jsonBytes)
+		w.WriteByte('\n')
+		exported.Count++
+		if exported.Count%importBatchSize == 0 {
+			progress(exported.Count)
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return exported, err
+	}
+	progress(exported.Count)
+
+	if err := w.Flush(); err != nil {
+		return exported, err
+	}
+	if err := gz.Close(); err != nil {
+		return exported, err
+	}
+	return exported, file.Close()
+}
+
+// writeManifest writes the manifest as indented Extended JSON
+func writeManifest(path string, manifest dumpManifest) error {
+	jsonBytes, err := bson.MarshalExtJSONIndent(manifest, false, false, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(jsonBytes, '\n'), 0644)
+}
+
+// readManifest reads the manifest of an export directory
+func readManifest(dir string) (dumpManifest, error) {
+	var manifest dumpManifest
+	jsonBytes, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if err != nil {
+		return manifest, err
+	}
+	err = bson.UnmarshalExtJSON(jsonBytes, false, &manifest)
+	return manifest, err
+}
+
+// restoredCollection is the outcome of importing one collection
+type restoredCollection struct {
+	name     string
+	result   importResult
+	indexErr error
+	err      error
+}
+
+// runDatabaseImport is the job that restores an export directory into
+// target, which may be named differently from the exported database. Each
+// collection is imported like a single file, then its indexes are created.
+func runDatabaseImport(ctx context.Context, report func(string), client *mongo.Client, dir, target string) (tea.Msg, error) {
+	manifest, err := readManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	db := client.Database(target)
+	done := databaseImportDoneMsg{dir: dir, target: target, skipped: manifest.Skipped}
+	for i, exported := range manifest.Collections {
+		restored := restoredCollection{name: exported.Name}
+		progress := func(line string) {
+			report(fmt.Sprintf("%d/%d collections • %s: %s", i+1, len(manifest.Collections), exported.Name, line))
+		}
+		progress("starting")
+		restored.err = friendlyNamespaceError(importDocuments(ctx, progress, db.Collection(exported.Name), filepath.Join(dir, exported.File), &restored.result))
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if restored.err == nil {
+			restored.indexErr = createIndexes(ctx, db, exported)
+		}
+		done.collections = append(done.collections, restored)
+	}
+	return done, nil
+}
+
+// createIndexes recreates the exported indexes of a collection, except the
+// _id index every collection already has
+func createIndexes(ctx context.Context, db *mongo.Database, exported dumpCollection) error {
+	specs := bson.A{}
+	for _, index := range exported.Indexes {
+		spec := bson.D{}
+		name := ""
+		for _, field := range index {
+			switch field.Key {
+			case "v", "ns":
+				// Set by the server
+			case "name":
+				name, _ = field.Value.(string)
+				spec = append(spec, field)
+			default:
+				spec = append(spec, field)
+			}
+		}
+		if name != "_id_" {
+			specs = append(specs, spec)
+		}
+	}
+	if len(specs) == 0 {
+		return nil
+	}
+	return db.RunCommand(ctx, bson.D{{Key: "createIndexes", Value: exported.Name}, {Key: "indexes", Value: specs}}).Err()
+}
+
+// databaseExportSummary lists the collections of a finished export, with
+// the ones that were skipped
+func databaseExportSummary(msg databaseExportDoneMsg) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Directory: %s\n\n", msg.dir)
+	for _, coll := range msg.manifest.Collections {
+		fmt.Fprintf(&b, "%s: %d documents, %d indexes\n", coll.Name, coll.Count, len(coll.Indexes))
+	}
+	if len(msg.manifest.Skipped) > 0 {
+		b.WriteString("\nSkipped:\n")
+		for _, skipped := range msg.manifest.Skipped {
+			fmt.Fprintf(&b, "%s: %s\n", skipped.Name, skipped.Error)
+		}
+	}
+	return b.String()
+}
+
+// databaseImportSummary lists the outcome per collection of a restore
+func databaseImportSummary(msg databaseImportDoneMsg) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Directory: %s\n\n", msg.dir)
+	for _, restored := range msg.collections {
+		fmt.Fprintf(&b, "%s: %d inserted, %d failed\n", restored.name, restored.result.inserted, restored.result.failed)
+		if restored.err != nil {
+			fmt.Fprintf(&b, "  stopped early: %v\n", restored.err)
+		}
+		if restored.indexErr != nil {
+			fmt.Fprintf(&b, "  indexes: %v\n", restored.indexErr)
+		}
+		for _, line := range restored.result.errors {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+	if len(msg.skipped) > 0 {
+		b.WriteString("\nNot in the export (skipped when exporting):\n")
+		for _, skipped := range msg.skipped {
+			fmt.Fprintf(&b, "%s: %s\n", skipped.Name, skipped.Error)
+		}
+	}
+	return b.String()
+}
+
+// handleDatabaseExportDone reports a finished export, listing the skipped
+// collections in the viewer when there are any
+func (m *Model) handleDatabaseExportDone(msg databaseExportDoneMsg) tea.Cmd {
+	if len(msg.manifest.Skipped) > 0 {
+		m.openViewer(ViewerDatabaseSummary, "Export of "+msg.manifest.Database, databaseExportSummary(msg))
+		return nil
+	}
+	documents := 0
+	for _, coll := range msg.manifest.Collections {
+		documents += coll.Count
+	}
+	return m.setStatus(fmt.Sprintf("exported %d collections (%d documents) to %s", len(msg.manifest.Collections), documents, msg.dir))
+}
+
+// handleDatabaseImportDone shows the outcome of a restore and lists the
+// target database when the restore created it
+func (m *Model) handleDatabaseImportDone(msg databaseImportDoneMsg) {
+	m.openViewer(ViewerDatabaseSummary, "Import into "+msg.target, databaseImportSummary(msg))
+	for _, db := range m.databases {
+		if db == msg.target {
+			return
+		}
+	}
+	m.databases = append(m.databases, msg.target)
+	sort.Strings(m.databases)
+	m.updateFilteredDatabases()
+}
+
+// renderDumpPrompt renders the database export/import prompt modal
+func (m Model) renderDumpPrompt(background string) string {
+	width := m.modalWidth(70)
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+
+	var content string
+	if m.dumpImport {
+		lines := []string{
+			titleStyle.Render("Import a database export"),
+			"",
+			"Export directory (with " + manifestFile + "):",
+			fitInput(m.dumpDirInput, width-4),
+			"",
+			"Into database:",
+			fitInput(m.dumpTargetInput, width-4),
+		}
+		if err := m.dumpTargetError(); err != nil {
+			lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Width(width-4).Render(err.Error()))
+		}
+		lines = append(lines, "", hintStyle.Render("tab: switch field • enter: import • esc: cancel"))
+		content = lipgloss.JoinVertical(lipgloss.Left, lines...)
+	} else {
+		content = lipgloss.JoinVertical(lipgloss.Left,
+			titleStyle.Render("Export database "+truncateMiddle(m.dumpDatabase, maxToastNameWidth)),
+			"",
+			"Directory (one .json.gz per collection and "+manifestFile+"):",
+			fitInput(m.dumpDirInput, width-4),
+			"",
+			hintStyle.Render("enter: export • esc: cancel"),
+		)
+	}
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("205")).
+		Padding(1, 2).
+		Width(width)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(content),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestDumpFileNameStaysInDirectory(t *testing.T) {
+	for name, want := range map[string]string{
+		"orders":        "orders.json.gz",
+		"logs/2024":     "logs%2F2024.json.gz",
+		"events.recent": "events.recent.json.gz",
+	} {
+		if got := dumpFileName(name); got != want {
+			t.Errorf("dumpFileName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestManifestRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	manifest := dumpManifest{
+		Database: "shop",
+		Exported: time.Date(2024, 3, 2, 11, 45, 0, 0, time.UTC),
+		Collections: []dumpCollection{{
+			Name:  "orders",
+			File:  "orders.json.gz",
+			Count: 3,
+			Indexes: []bson.D{
+				{{Key: "v", Value: int32(2)}, {Key: "key", Value: bson.D{{Key: "_id", Value: int32(1)}}}, {Key: "name", Value: "_id_"}},
+				{{Key: "v", Value: int32(2)}, {Key: "key", Value: bson.D{{Key: "email", Value: int32(1)}}}, {Key: "name", Value: "email_1"}, {Key: "unique", Value: true}},
+			},
+		}},
+		Skipped: []dumpSkipped{{Name: "audit", Error: "not authorized"}},
+	}
+	if err := writeManifest(dir+"/"+manifestFile, manifest); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, manifest) {
+		t.Errorf("readManifest() = %+v, want %+v", got, manifest)
+	}
+}
+
+func TestDatabaseImportSummary(t *testing.T) {
+	msg := databaseImportDoneMsg{
+		dir:    "shop-backup",
+		target: "shop_restore",
+		collections: []restoredCollection{
+			{name: "orders", result: importResult{inserted: 2, failed: 1, errors: []string{"document 3: duplicate key"}}},
+		},
+		skipped: []dumpSkipped{{Name: "audit", Error: "not authorized"}},
+	}
+	summary := databaseImportSummary(msg)
+	for _, want := range []string{"orders: 2 inserted, 1 failed", "  document 3: duplicate key", "audit: not authorized"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary missing %q:\n%s", want, summary)
+		}
+	}
+}
+
+func TestDatabaseImportListsNewTarget(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.databases = []string{"admin", "shop"}
+	m.updateFilteredDatabases()
+	m.handleDatabaseImportDone(databaseImportDoneMsg{dir: "shop-backup", target: "restore"})
+	if want := []string{"admin", "restore", "shop"}; !reflect.DeepEqual(m.dbFiltered, want) {
+		t.Errorf("databases = %v, want %v", m.dbFiltered, want)
+	}
+	if m.viewerKind != ViewerDatabaseSummary {
+		t.Errorf("viewer = %v, want the summary", m.viewerKind)
+	}
+}
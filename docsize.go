@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/charmbracelet/lipgloss"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// defaultDocSizeWarningKB is the document size above which the size after
+// the root line turns warning-colored, a sixteenth of MongoDB's 16 MB limit
+const defaultDocSizeWarningKB = 1024
+
+// docSizeWarningFromEnv returns the warning threshold in bytes from
+// DOC_SIZE_WARNING_KB, or the default
+func docSizeWarningFromEnv() int {
+	if kb, err := strconv.Atoi(os.Getenv("DOC_SIZE_WARNING_KB")); err == nil && kb > 0 {
+		return kb * 1024
+	}
+	return defaultDocSizeWarningKB * 1024
+}
+
+// documentSize returns the BSON size of a document as the server stores it.
+// Field order is lost in bson.M but doesn't change the size.
+func documentSize(doc bson.M) int {
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
+
+// renderDocSize renders the size shown after a document's root line
+func (m Model) renderDocSize(node *JSONNode) string {
+	style := paginationStyle
+	if node.Size > m.docSizeWarning {
+		style = lipgloss.NewStyle().Foreground(SeverityWarning.color())
+	}
+	return style.Render(" · " + formatBytes(node.Size))
+}
+
+// pageBytes returns the total BSON size of the documents on the page
+func (m Model) pageBytes() int {
+	total := 0
+	for _, root := range m.docTree {
+		total += root.Size
+	}
+	return total
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestDocumentSizeMatchesBSON(t *testing.T) {
+	// int32 header + (type, "a\x00", int32) + terminator
+	if got := documentSize(bson.M{"a": int32(1)}); got != 12 {
+		t.Errorf("documentSize = %d, want 12", got)
+	}
+}
+
+func TestDocSizeWarningFromEnv(t *testing.T) {
+	t.Setenv("DOC_SIZE_WARNING_KB", "")
+	if got := docSizeWarningFromEnv(); got != defaultDocSizeWarningKB*1024 {
+		t.Errorf("default threshold = %d", got)
+	}
+	t.Setenv("DOC_SIZE_WARNING_KB", "64")
+	if got := docSizeWarningFromEnv(); got != 64*1024 {
+		t.Errorf("threshold = %d, want %d", got, 64*1024)
+	}
+}
+
+func TestDocSizeTurnsWarningAboveThreshold(t *testing.T) {
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	defer lipgloss.SetColorProfile(termenv.Ascii)
+	m := newTestModel(120, 30)
+	root := m.docTree[0]
+	below := m.renderDocSize(root)
+	m.docSizeWarning = root.Size - 1
+	above := m.renderDocSize(root)
+	if !strings.Contains(normalizeRender(below), formatBytes(root.Size)) {
+		t.Errorf("size label %q doesn't show %s", below, formatBytes(root.Size))
+	}
+	if below == above {
+		t.Error("size label isn't styled differently above the threshold")
+	}
+}
@@ -3,7 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
-	"sort"
+	"reflect"
 	"strconv"
 	"strings"
 	"time"
@@ -19,121 +19,156 @@ import (
 // JSONNode represents a node in the JSON tree
 type JSONNode struct {
 	Key       string      // Key name (empty for array elements or root)
-	Value     interface{} // The actual value (for leaf nodes)
+	Value     interface{} // The actual value (the whole subtree for objects/arrays)
+	Parent    *JSONNode   // Enclosing object/array (nil for document roots)
 	Children  []*JSONNode // Child nodes (for objects/arrays)
 	IsObject  bool        // True if this is an object
 	IsArray   bool        // True if this is an array
 	Collapsed bool        // True if collapsed
 	Depth     int         // Indentation depth
+	Raw       bool        // Document roots: show canonical Extended JSON instead of the tree
+	RawLines  []*JSONNode // Document roots: one node per raw JSON line while Raw is set
+	RawText   string      // Raw JSON line nodes: the text of the line
+	Wrapped   bool        // String leaves: show the whole value across several lines
+	WrapOf    *JSONNode   // Wrapped continuation line nodes: the leaf they continue
+	WrapText  string      // Wrapped leaves and their continuation lines: the text of the line
+	Ref       *JSONNode   // Reference leaves: the grafted referenced document, once fetched
+	Foreign   bool        // Part of a grafted referenced document (read-only)
+	Size      int         // Document roots: BSON size in bytes
+	RawOnly   bool        // Document roots: the tree took too long to build, so only the raw view is shown
+	More      int         // "Show more" nodes: the children of the parent not built yet
+	MoreKeys  []string    // "Show more" nodes of objects: the keys of those children, sorted
 }
 
-func loadDocuments(client *mongo.Client, dbName, collName string, page int, filter bson.M) func() tea.Msg {
+// DocProvenance records how the documents on screen were produced, which
+// decides whether they can be edited and saved back as they are
+type DocProvenance int
+
+const (
+	ProvenanceFind       DocProvenance = iota // Whole documents returned by find
+	ProvenanceProjected                       // find with a projection; unfetched fields are missing
+	ProvenanceAggregated                      // Pipeline output with no 1:1 source document
+)
+
+func loadDocuments(client *mongo.Client, dbName, collName string, page, pageSize int, filter bson.M, sort bson.D) func() tea.Msg {
 	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
+		msg := loadPage(client, dbName, collName, page, pageSize, filter, sort)
+		msg.namespace = namespaceOf(dbName, collName)
+		// Building trees here keeps pathological documents off the event loop
+		msg.trees = newDocumentTrees(msg.documents)
+		return msg
+	}
+}
 
-		coll := client.Database(dbName).Collection(collName)
+// loadPage counts the documents matching filter and fetches one page of them
+func loadPage(client *mongo.Client, dbName, collName string, page, pageSize int, filter bson.M, sort bson.D) documentsLoadedMsg {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	defer serverCommands.beginForeground()()
 
-		// Use provided filter or empty filter
-		if filter == nil {
-			filter = bson.M{}
-		}
+	coll := client.Database(dbName).Collection(collName)
 
-		// Get total count matching filter
-		totalCount, err := coll.CountDocuments(ctx, filter)
-		if err != nil {
-			return documentsLoadedMsg{err: err}
-		}
+	// Use provided filter or empty filter
+	if filter == nil {
+		filter = bson.M{}
+	}
 
-		// Fetch documents for the current page
-		skip := int64(page * docsPerPage)
-		cursor, err := coll.Find(ctx, filter, options.Find().SetSkip(skip).SetLimit(docsPerPage))
-		if err != nil {
-			return documentsLoadedMsg{err: err}
-		}
-		defer cursor.Close(ctx)
+	// Get total count matching filter
+	totalCount, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return documentsLoadedMsg{err: err}
+	}
+
+	// Fetch documents for the current page
+	documents, err := fetchPage(ctx, coll, filter, sort, page, pageSize)
+	if err != nil {
+		return documentsLoadedMsg{err: err}
+	}
+	if pageMatchesCount(page, pageSize, len(documents), totalCount) {
+		return documentsLoadedMsg{documents: documents, totalCount: totalCount, page: page, provenance: ProvenanceFind}
+	}
 
-		var documents []bson.M
-		if err := cursor.All(ctx, &documents); err != nil {
+	// Documents were inserted or deleted between the count and the fetch:
+	// recount, and move to the new last page if this one is now past the end
+	totalCount, err = coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return documentsLoadedMsg{err: err}
+	}
+	if len(documents) == 0 && totalCount > 0 && page > 0 {
+		page = int((totalCount - 1) / int64(pageSize))
+		documents, err = fetchPage(ctx, coll, filter, sort, page, pageSize)
+		if err != nil {
 			return documentsLoadedMsg{err: err}
 		}
-
-		return documentsLoadedMsg{documents: documents, totalCount: totalCount}
 	}
+
+	return documentsLoadedMsg{documents: documents, totalCount: totalCount, page: page, countRefreshed: true, provenance: ProvenanceFind}
 }
 
-// buildJSONTree converts a BSON document to a tree structure
-func buildJSONTree(doc bson.M, depth int) *JSONNode {
-	node := &JSONNode{
-		IsObject:  true,
-		Collapsed: depth > 0, // Collapse all except root
-		Depth:     depth,
-		Children:  make([]*JSONNode, 0),
+// fetchPage returns one page of the documents matching filter
+func fetchPage(ctx context.Context, coll *mongo.Collection, filter bson.M, sort bson.D, page, pageSize int) ([]bson.M, error) {
+	skip := int64(page * pageSize)
+	opts := options.Find().SetSkip(skip).SetLimit(int64(pageSize))
+	if len(sort) > 0 {
+		opts.SetSort(sort)
 	}
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
 
-	// Get sorted keys for consistent ordering
-	keys := make([]string, 0, len(doc))
-	for k := range doc {
-		keys = append(keys, k)
+	var documents []bson.M
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, err
 	}
-	sort.Strings(keys)
+	return documents, nil
+}
 
-	for _, key := range keys {
-		value := doc[key]
-		child := buildValueNode(key, value, depth+1)
-		node.Children = append(node.Children, child)
+// pageMatchesCount reports whether the number of documents returned for a page
+// agrees with the total count: only the last page may be short, and no page
+// may reach past the count
+func pageMatchesCount(page, pageSize, returned int, total int64) bool {
+	start := int64(page * pageSize)
+	end := start + int64(returned)
+	if end > total {
+		return false
+	}
+	if returned < pageSize && end < total {
+		return false
 	}
+	return returned > 0 || page == 0
+}
 
+// buildJSONTree converts a BSON document to a tree structure
+func buildJSONTree(doc bson.M, depth int) *JSONNode {
+	node := (&treeBuilder{}).valueNode("", doc, depth)
+	node.Collapsed = depth > 0 // Collapse all except root
 	return node
 }
 
 // buildValueNode creates a node for any value type
 func buildValueNode(key string, value interface{}, depth int) *JSONNode {
-	node := &JSONNode{
-		Key:       key,
-		Depth:     depth,
-		Collapsed: true,
-	}
-
-	switch v := value.(type) {
-	case bson.M:
-		node.IsObject = true
-		node.Children = make([]*JSONNode, 0)
-		keys := make([]string, 0, len(v))
-		for k := range v {
-			keys = append(keys, k)
-		}
-		sort.Strings(keys)
-		for _, k := range keys {
-			child := buildValueNode(k, v[k], depth+1)
-			node.Children = append(node.Children, child)
-		}
-	case bson.A:
-		node.IsArray = true
-		node.Children = make([]*JSONNode, 0)
-		for i, item := range v {
-			child := buildValueNode(fmt.Sprintf("[%d]", i), item, depth+1)
-			node.Children = append(node.Children, child)
-		}
-	default:
-		node.Value = value
-	}
-
-	return node
+	return (&treeBuilder{}).valueNode(key, value, depth)
 }
 
 // flattenTree creates a flat list of visible nodes for rendering
 func flattenTree(nodes []*JSONNode) []*JSONNode {
 	var result []*JSONNode
 	for i, node := range nodes {
-		result = append(result, flattenNode(node, i > 0)...)
+		result = appendFlattened(result, node, i > 0)
 	}
 	return result
 }
 
 func flattenNode(node *JSONNode, addSeparator bool) []*JSONNode {
-	var result []*JSONNode
+	return appendFlattened(nil, node, addSeparator)
+}
 
+// appendFlattened appends the visible nodes of node's subtree to result.
+// Appending into one slice keeps flattening linear in the number of visible
+// nodes instead of copying every subtree once per level.
+func appendFlattened(result []*JSONNode, node *JSONNode, addSeparator bool) []*JSONNode {
 	if addSeparator && node.Depth == 0 {
 		// Add a separator node between documents
 		result = append(result, &JSONNode{Key: "---", Depth: -1})
@@ -141,45 +176,257 @@ func flattenNode(node *JSONNode, addSeparator bool) []*JSONNode {
 
 	result = append(result, node)
 
+	if node.Ref != nil && !node.Collapsed {
+		result = appendFlattened(result, node.Ref, false)
+	}
+
+	if node.Raw {
+		if !node.Collapsed {
+			result = append(result, node.RawLines...)
+		}
+		return result
+	}
+
 	if !node.Collapsed && (node.IsObject || node.IsArray) {
 		for _, child := range node.Children {
-			result = append(result, flattenNode(child, false)...)
+			result = appendFlattened(result, child, false)
 		}
 	}
 
 	return result
 }
 
+// nodePathSegments returns the field path from the document root to node,
+// with array elements represented by their numeric index
+func nodePathSegments(node *JSONNode) []string {
+	var segments []string
+	for n := node; n != nil && n.Parent != nil; n = n.Parent {
+		segment := n.Key
+		if n.Parent.IsArray {
+			segment = strings.TrimSuffix(strings.TrimPrefix(n.Key, "["), "]")
+		}
+		segments = append([]string{segment}, segments...)
+	}
+	return segments
+}
+
+// formatFieldPath joins path segments in Mongo dot-notation (orders.3.items.0.sku).
+// Keys that themselves contain dots can't be expressed in dot-notation, so they
+// are bracket-quoted (meta["a.b"].c) to keep the path unambiguous.
+func formatFieldPath(segments []string) string {
+	var b strings.Builder
+	for i, segment := range segments {
+		if strings.Contains(segment, ".") {
+			fmt.Fprintf(&b, "[%q]", segment)
+			continue
+		}
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(segment)
+	}
+	return b.String()
+}
+
+// replaceDocument swaps the document at docIndex and rebuilds its tree,
+// keeping it expanded as it was and the cursor on the same field
+func (m *Model) replaceDocument(docIndex int, doc bson.M) {
+	cursorID, cursorPath := m.cursorPath()
+	cursorRow := m.docCursor - m.docScrollOffset
+	m.saveExpandState()
+	m.documents[docIndex] = doc
+	m.docTree[docIndex] = m.buildDocumentTree(doc)
+	m.refreshOccurrences()
+	m.rebuildFlattenedTree()
+	m.restoreCursorToDocument(cursorID, cursorPath, cursorRow)
+}
+
+// appendDocument adds a document after the last one of the page, leaving
+// the cursor where it is
+func (m *Model) appendDocument(doc bson.M) {
+	m.documents = append(m.documents, doc)
+	m.docTree = append(m.docTree, m.buildDocumentTree(doc))
+	m.refreshOccurrences()
+	m.rebuildFlattenedTree()
+}
+
 // rebuildFlattenedTree rebuilds the flattened view from the tree
 func (m *Model) rebuildFlattenedTree() {
-	m.flattenedTree = flattenTree(m.docTree)
+	if m.docFullscreen && m.fullscreenDocIndex < len(m.docTree) {
+		m.flattenedTree = m.wrapFlattened(flattenNode(m.docTree[m.fullscreenDocIndex], false))
+	} else {
+		m.flattenedTree = m.wrapFlattened(flattenTree(m.docTree))
+	}
+	m.flattenedDocs = documentIndexes(m.flattenedTree)
+}
+
+// documentIndexes returns the index of the document each flattened line
+// belongs to. A separator belongs to the document above it.
+func documentIndexes(nodes []*JSONNode) []int {
+	indexes := make([]int, len(nodes))
+	doc := -1
+	for i, node := range nodes {
+		if node.Depth == 0 && node.IsObject {
+			doc++
+		}
+		if doc >= 0 {
+			indexes[i] = doc
+		}
+	}
+	return indexes
+}
+
+// toggleFullscreen switches between the split layout and a full-screen view
+// of the document under the cursor. Leaving full screen restores the cursor
+// and scroll position from before it was entered.
+func (m *Model) toggleFullscreen() {
+	m.docHScroll = 0
+	if m.docFullscreen {
+		m.docFullscreen = false
+		m.rebuildFlattenedTree()
+		m.docCursor = m.fullscreenSavedCursor
+		if m.docCursor >= len(m.flattenedTree) {
+			m.docCursor = len(m.flattenedTree) - 1
+		}
+		if m.docCursor < 0 {
+			m.docCursor = 0
+		}
+		m.docScrollOffset = m.fullscreenSavedScroll
+		m.adjustScrollForCursor()
+		return
+	}
+
+	docIndex := m.getDocumentIndexAtCursor()
+	if docIndex < 0 || docIndex >= len(m.docTree) {
+		return
+	}
+	anchor := m.nodeAtCursor()
+	if anchor == nil || anchor.Depth < 0 {
+		anchor = m.docTree[docIndex]
+	}
+	row := m.docCursor - m.docScrollOffset
+	m.fullscreenSavedCursor = m.docCursor
+	m.fullscreenSavedScroll = m.docScrollOffset
+	m.fullscreenDocIndex = docIndex
+	m.docFullscreen = true
+	m.rebuildFlattenedTree()
+	m.anchorCursor(anchor, row)
+}
+
+// nodeAtCursor returns the flattened node under the cursor, or nil. On a
+// continuation line of a wrapped value it returns the wrapped leaf.
+func (m Model) nodeAtCursor() *JSONNode {
+	if m.docCursor < 0 || m.docCursor >= len(m.flattenedTree) {
+		return nil
+	}
+	if node := m.flattenedTree[m.docCursor]; node.WrapOf != nil {
+		return node.WrapOf
+	}
+	return m.flattenedTree[m.docCursor]
+}
+
+// setCollapsedRecursive expands or collapses node and every container below it
+func setCollapsedRecursive(node *JSONNode, collapsed bool) {
+	if node.IsObject || node.IsArray {
+		node.Collapsed = collapsed
+	}
+	for _, child := range node.Children {
+		setCollapsedRecursive(child, collapsed)
+	}
+}
+
+// setTreeCollapsed expands or collapses the whole document under the cursor,
+// or every document on the page when allDocs is set
+func (m *Model) setTreeCollapsed(collapsed, allDocs bool) {
+	anchor := m.nodeAtCursor()
+	if anchor == nil || anchor.Depth < 0 {
+		return
+	}
+	row := m.docCursor - m.docScrollOffset
+
+	if allDocs {
+		for _, root := range m.docTree {
+			setCollapsedRecursive(root, collapsed)
+		}
+	} else {
+		root := anchor
+		for root.Parent != nil {
+			root = root.Parent
+		}
+		setCollapsedRecursive(root, collapsed)
+	}
+	m.rebuildFlattenedTree()
+	m.anchorCursor(anchor, row)
+}
+
+// anchorCursor moves the cursor back onto node after the tree was rebuilt,
+// falling back to its nearest visible ancestor, and keeps it on the same
+// screen row where possible
+func (m *Model) anchorCursor(node *JSONNode, row int) {
+	for n := node; n != nil; n = n.Parent {
+		for i, visible := range m.flattenedTree {
+			if visible == n {
+				m.docCursor = i
+				m.docScrollOffset = i - row
+				if m.docScrollOffset < 0 {
+					m.docScrollOffset = 0
+				}
+				m.adjustScrollForCursor()
+				return
+			}
+		}
+	}
 }
 
 // getDocumentIndexAtCursor returns the index of the document the cursor is on
 func (m Model) getDocumentIndexAtCursor() int {
-	if len(m.flattenedTree) == 0 || m.docCursor >= len(m.flattenedTree) {
+	if m.docFullscreen {
+		return m.fullscreenDocIndex
+	}
+	if m.docCursor < 0 || m.docCursor >= len(m.flattenedTree) || len(m.flattenedDocs) != len(m.flattenedTree) {
 		return -1
 	}
+	return m.flattenedDocs[m.docCursor]
+}
 
-	// Walk backwards from cursor to find the root node (depth 0)
-	// Count how many root nodes we pass
-	docIndex := 0
-	for i := m.docCursor; i >= 0; i-- {
-		node := m.flattenedTree[i]
-		if node.Depth == 0 && node.IsObject {
-			// This is a document root
-			// Count how many doc roots are before this position
-			docIndex = 0
-			for j := 0; j <= i; j++ {
-				n := m.flattenedTree[j]
-				if n.Depth == 0 && n.IsObject {
-					docIndex++
-				}
-			}
-			return docIndex - 1
+// documentIndexByID returns the index of the loaded document with the given
+// _id, or -1 when it isn't on the current page
+func (m Model) documentIndexByID(id interface{}) int {
+	if id == nil {
+		return -1
+	}
+	for i, doc := range m.documents {
+		if reflect.DeepEqual(doc["_id"], id) {
+			return i
 		}
 	}
-	return 0
+	return -1
+}
+
+// documentIDAtCursor returns the _id of the document under the cursor
+func (m Model) documentIDAtCursor() interface{} {
+	docIndex := m.getDocumentIndexAtCursor()
+	if docIndex < 0 || docIndex >= len(m.documents) {
+		return nil
+	}
+	return m.documents[docIndex]["_id"]
+}
+
+// restoreCursorToDocument puts the cursor back on the node at path in the
+// document with the given _id at the same screen row, falling back to its
+// nearest visible ancestor, or on the first line when the document is no
+// longer in the results
+func (m *Model) restoreCursorToDocument(id interface{}, path string, row int) {
+	if docIndex := m.documentIndexByID(id); docIndex >= 0 {
+		node := findNodeByPath(m.docTree[docIndex], path)
+		if node == nil {
+			node = m.docTree[docIndex]
+		}
+		m.anchorCursor(node, row)
+		return
+	}
+	m.docCursor = 0
+	m.docScrollOffset = 0
 }
 
 // getDocPanelHeight returns the visible height of the documents content area
@@ -188,6 +435,14 @@ func (m Model) getDocPanelHeight() int {
 	if availableHeight < 10 {
 		availableHeight = 10
 	}
+	if m.docFullscreen {
+		// Borders (2) and header + blank line (2)
+		height := availableHeight - 4
+		if m.docSearchActive {
+			height--
+		}
+		return height
+	}
 	leftPanelTotalHeight := availableHeight / 2
 	rightTotalHeight := leftPanelTotalHeight * 2
 
@@ -206,6 +461,19 @@ func (m Model) getDocPanelHeight() int {
 	return height
 }
 
+// getDocPanelWidth returns the inner width of the documents panel, matching
+// the layout in View
+func (m Model) getDocPanelWidth() int {
+	if m.docFullscreen {
+		return m.width - 2
+	}
+	width := m.width - (leftPanelWidth + 4) - 1 - 4
+	if width < 20 {
+		width = 20
+	}
+	return width
+}
+
 // adjustScrollForCursor ensures the cursor is visible
 func (m *Model) adjustScrollForCursor() {
 	visibleHeight := m.getDocPanelHeight()
@@ -227,18 +495,40 @@ func (m Model) renderDocumentsPanel(width, height int) string {
 		searchBarHeight = 1
 	}
 
+	// Shorten long collection names in the middle, leaving room in the
+	// title for the search, sort and scroll details
+	collName := truncateMiddle(m.selectedCollection, width/2)
+
 	if m.selectedCollection == "" {
 		title = "Documents"
 		content = normalStyle.Render("Select a collection to view documents")
 	} else if m.loadingDocs {
-		title = fmt.Sprintf("Documents in %s", m.selectedCollection)
-		content = normalStyle.Render("Loading...")
+		title = fmt.Sprintf("Documents in %s", collName)
+		content = normalStyle.Render(m.querySpinner.View() + "Loading...")
+	} else if ns := m.currentNamespace(); m.deniedNamespaces[ns] {
+		title = fmt.Sprintf("Documents in %s", collName)
+		content = normalStyle.Render(fmt.Sprintf("🔒 You don't have read access to %s", ns))
 	} else {
-		title = fmt.Sprintf("Documents in %s", m.selectedCollection)
+		title = fmt.Sprintf("Documents in %s", collName)
+		if m.pinboardActive {
+			title = fmt.Sprintf("Pinned documents in %s", collName)
+		}
+		if m.serverSearchQuery != "" {
+			title += fmt.Sprintf(" • search: %q", m.serverSearchQuery)
+		}
+		if sort := m.sortLabel(); sort != "" {
+			title += " • sort: " + sort
+		}
+		if m.docHScroll > 0 {
+			title += fmt.Sprintf(" • col %d", m.docHScroll+1)
+		}
+		if m.schemaActive {
+			title = fmt.Sprintf("Schema of %s", collName)
+		}
 
 		// Calculate pagination info based on current page
-		startDoc := int64(m.currentPage*docsPerPage) + 1
-		endDoc := int64((m.currentPage + 1) * docsPerPage)
+		startDoc := int64(m.currentPage*m.docsPerPage) + 1
+		endDoc := int64((m.currentPage + 1) * m.docsPerPage)
 		if endDoc > m.totalDocs {
 			endDoc = m.totalDocs
 		}
@@ -246,8 +536,16 @@ func (m Model) renderDocumentsPanel(width, height int) string {
 			startDoc = 0
 		}
 		rightInfo = fmt.Sprintf("%d-%d of %d", startDoc, endDoc, m.totalDocs)
+		if len(m.docTree) > 0 {
+			rightInfo += " • " + formatBytes(m.pageBytes())
+		}
+		if m.schemaActive {
+			rightInfo = fmt.Sprintf("%d sampled • enter: filter on field • esc: documents", m.schemaSampled)
+		} else if m.docFullscreen {
+			rightInfo = fmt.Sprintf("%d of %d • z/esc: exit full screen", int64(m.currentPage*m.docsPerPage+m.fullscreenDocIndex)+1, m.totalDocs)
+		}
 
-		if len(m.documents) == 0 {
+		if len(m.documents) == 0 && !m.schemaActive {
 			content = normalStyle.Render("(no documents)")
 		} else {
 			// width is inner width passed to Width(), but padding(0,1) takes 2 more chars
@@ -274,11 +572,18 @@ func (m Model) renderDocSearchBar(width int) string {
 
 	// Add match count info
 	var matchInfo string
+	if m.docSearchMode != SearchPlain {
+		matchInfo = " [" + m.docSearchMode.String() + "]"
+	}
+	if m.docSearchErr != "" {
+		errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+		return inputView + paginationStyle.Render(matchInfo) + " " + errorStyle.Render(truncate(m.docSearchErr, width-lipgloss.Width(inputView)-lipgloss.Width(matchInfo)-1))
+	}
 	if m.docSearchInput.Value() != "" {
 		if len(m.docSearchMatches) == 0 {
-			matchInfo = " [no matches]"
+			matchInfo += " [no matches • ctrl+o: all pages]"
 		} else {
-			matchInfo = fmt.Sprintf(" [%d/%d]", m.docSearchCurrent+1, len(m.docSearchMatches))
+			matchInfo += fmt.Sprintf(" [%d/%d]", m.docSearchCurrent+1, len(m.docSearchMatches))
 		}
 	}
 
@@ -324,7 +629,12 @@ func (m Model) renderDocumentTree(maxWidth, maxHeight int) string {
 
 	for i := start; i < end; i++ {
 		node := m.flattenedTree[i]
-		line := m.renderNode(node, maxWidth)
+		// Render the columns scrolled off to the left too, then cut them
+		line := m.renderNode(node, maxWidth+m.docHScroll)
+		if m.docSearchActive && matchSet[i] {
+			line = m.highlightSearchMatches(node, line)
+		}
+		line = cutColumns(line, m.docHScroll)
 
 		// Determine highlight style
 		if m.docSearchActive && i == currentMatchIdx {
@@ -351,10 +661,42 @@ func (m Model) renderNode(node *JSONNode, maxWidth int) string {
 		return strings.Repeat("─", maxWidth)
 	}
 
-	indent := strings.Repeat("  ", node.Depth)
+	if node.RawText != "" {
+		line := renderRawLine(node.RawText, maxWidth)
+		if dt, ok := node.Value.(primitive.DateTime); ok && m.dateDisplay != DateISO {
+			line += "  " + m.renderRawDate(dt)
+		}
+		return line
+	}
+	if node.WrapOf != nil {
+		return nodeIndent(node) + wrapIndent + jsonStringStyle.Render(truncate(node.WrapText, maxWidth-wrapLineIndentWidth(node)))
+	}
+
+	indent := nodeIndent(node)
 	var line string
 
-	if node.IsObject || node.IsArray {
+	if field, ok := node.Value.(*schemaField); ok {
+		line = indent + schemaNodeLine(node, field)
+	} else if node.Foreign && node.Parent != nil && node.Parent.Ref == node {
+		// Pseudo-node holding a referenced document
+		label := foreignStyle.Render(node.Key)
+		switch {
+		case !node.IsObject:
+			line = fmt.Sprintf("%s  %s", indent, label)
+		case node.Collapsed:
+			line = fmt.Sprintf("%s%s %s %s", indent, caretStyle.Render("▶"), label, paginationStyle.Render(fmt.Sprintf("{... %d items}", len(node.Children))))
+		default:
+			line = fmt.Sprintf("%s%s %s %s", indent, caretStyle.Render("▼"), label, jsonBracketStyle.Render("{"))
+		}
+	} else if node.Raw && !node.Collapsed {
+		hint := " raw Extended JSON • r: tree view"
+		if node.RawOnly {
+			hint = " raw Extended JSON • too large for the tree view"
+		}
+		line = caretStyle.Render("▼") + " " + jsonBracketStyle.Render("{") + paginationStyle.Render(hint)
+	} else if node.More > 0 {
+		line = indent + paginationStyle.Render(fmt.Sprintf("… %d more • enter: show more", node.More))
+	} else if node.IsObject || node.IsArray {
 		// Collapsible node
 		caret := "▶"
 		if !node.Collapsed {
@@ -364,21 +706,18 @@ func (m Model) renderNode(node *JSONNode, maxWidth int) string {
 
 		var bracket string
 		var closeBracket string
-		var childCount int
 		if node.IsObject {
 			bracket = "{"
 			closeBracket = "}"
-			childCount = len(node.Children)
 		} else {
 			bracket = "["
 			closeBracket = "]"
-			childCount = len(node.Children)
 		}
 
 		if node.Key != "" {
-			keyStr := jsonKeyStyle.Render(fmt.Sprintf("%q", node.Key))
+			keyStr := m.keyStyle(node).Render(fmt.Sprintf("%q", node.Key)) + m.renderOccurrenceLabel(node)
 			if node.Collapsed {
-				summary := fmt.Sprintf(" %d items", childCount)
+				summary := fmt.Sprintf(" %d items", childCount(node))
 				line = fmt.Sprintf("%s%s %s: %s...%s%s", indent, caret, keyStr,
 					jsonBracketStyle.Render(bracket),
 					paginationStyle.Render(summary),
@@ -389,7 +728,7 @@ func (m Model) renderNode(node *JSONNode, maxWidth int) string {
 		} else {
 			// Root object
 			if node.Collapsed {
-				summary := fmt.Sprintf(" %d items", childCount)
+				summary := fmt.Sprintf(" %d items", childCount(node))
 				line = fmt.Sprintf("%s%s %s...%s%s", indent, caret,
 					jsonBracketStyle.Render(bracket),
 					paginationStyle.Render(summary),
@@ -397,19 +736,28 @@ func (m Model) renderNode(node *JSONNode, maxWidth int) string {
 			} else {
 				line = fmt.Sprintf("%s%s %s", indent, caret, jsonBracketStyle.Render(bracket))
 			}
+			if node.Parent == nil && !node.Foreign {
+				line += m.renderDocSize(node)
+			}
+			if doc, ok := node.Value.(bson.M); ok && m.isPinned(doc["_id"]) {
+				line += paginationStyle.Render(" ★ pinned")
+			}
 		}
 	} else {
 		// Leaf node
 		valueStr := formatValue(node.Value)
-		if node.Key != "" && !strings.HasPrefix(node.Key, "[") {
-			keyStr := jsonKeyStyle.Render(fmt.Sprintf("%q", node.Key))
-			line = fmt.Sprintf("%s  %s: %s", indent, keyStr, valueStr)
-		} else if node.Key != "" {
-			// Array index
-			keyStr := paginationStyle.Render(node.Key)
-			line = fmt.Sprintf("%s  %s: %s", indent, keyStr, valueStr)
-		} else {
-			line = fmt.Sprintf("%s  %s", indent, valueStr)
+		if dt, ok := node.Value.(primitive.DateTime); ok && m.dateDisplay != DateISO {
+			valueStr = m.renderDate(dt)
+		}
+		if node.Wrapped && node.WrapText != "" {
+			valueStr = jsonStringStyle.Render(node.WrapText)
+		}
+		line = m.leafPrefix(node, indent) + valueStr
+		if epoch := m.epochAnnotation(node); epoch != "" {
+			line += "  " + epochAnnotationStyle.Render(epoch)
+		}
+		if m.showTypes {
+			line += " " + typeAnnotationStyle.Render(bsonTypeName(node.Value))
 		}
 	}
 
@@ -426,6 +774,37 @@ func (m Model) renderNode(node *JSONNode, maxWidth int) string {
 	return line
 }
 
+// nodeIndent returns the indentation of a node's line, ending in a gutter
+// for nodes of a referenced document
+func nodeIndent(node *JSONNode) string {
+	if node.Foreign {
+		return strings.Repeat("  ", node.Depth-1) + foreignStyle.Render("┆ ")
+	}
+	return strings.Repeat("  ", node.Depth)
+}
+
+// leafPrefix renders the part of a leaf's line before its value: the
+// indentation, the reference caret and the key
+func (m Model) leafPrefix(node *JSONNode, indent string) string {
+	marker := "  "
+	if node.Ref != nil {
+		// Reference leaf with a grafted document
+		marker = caretStyle.Render("▶") + " "
+		if !node.Collapsed {
+			marker = caretStyle.Render("▼") + " "
+		}
+	}
+	if node.Key != "" && !strings.HasPrefix(node.Key, "[") {
+		keyStr := m.keyStyle(node).Render(fmt.Sprintf("%q", node.Key)) + m.renderOccurrenceLabel(node)
+		return fmt.Sprintf("%s%s%s: ", indent, marker, keyStr)
+	} else if node.Key != "" {
+		// Array index
+		keyStr := paginationStyle.Render(node.Key)
+		return fmt.Sprintf("%s%s%s: ", indent, marker, keyStr)
+	}
+	return indent + marker
+}
+
 func formatValue(value interface{}) string {
 	if value == nil {
 		return jsonNullStyle.Render("null")
@@ -448,7 +827,7 @@ func formatValue(value interface{}) string {
 	case primitive.Decimal128:
 		return jsonNumberStyle.Render(v.String())
 	case primitive.Binary:
-		return jsonStringStyle.Render(fmt.Sprintf("Binary(%q)", v.Subtype))
+		return jsonStringStyle.Render(binaryLabel(v))
 	case primitive.Regex:
 		return jsonStringStyle.Render(fmt.Sprintf("/%s/%s", v.Pattern, v.Options))
 	default:
@@ -462,6 +841,57 @@ func formatValue(value interface{}) string {
 	}
 }
 
+// bsonTypeName returns the BSON type name of a decoded value. The driver
+// decodes each BSON type to a distinct Go type (int32 stays int32, doubles
+// become float64), so the name reflects what is stored, not a guess.
+func bsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case int32:
+		return "int32"
+	case int64:
+		return "int64"
+	case float64:
+		return "double"
+	case bool:
+		return "bool"
+	case primitive.ObjectID:
+		return "objectId"
+	case primitive.DateTime:
+		return "date"
+	case primitive.Timestamp:
+		return "timestamp"
+	case primitive.Decimal128:
+		return "decimal128"
+	case primitive.Binary:
+		return "binData"
+	case primitive.Regex:
+		return "regex"
+	case primitive.JavaScript:
+		return "javascript"
+	case primitive.CodeWithScope:
+		return "javascriptWithScope"
+	case primitive.Symbol:
+		return "symbol"
+	case primitive.DBPointer:
+		return "dbPointer"
+	case primitive.MinKey:
+		return "minKey"
+	case primitive.MaxKey:
+		return "maxKey"
+	case primitive.Undefined:
+		return "undefined"
+	case bson.M, bson.D:
+		return "object"
+	case bson.A:
+		return "array"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
 // handleDocSearchKey handles key events when document search is active
 func (m *Model) handleDocSearchKey(msg tea.KeyMsg) (tea.Cmd, bool) {
 	switch msg.String() {
@@ -485,6 +915,16 @@ func (m *Model) handleDocSearchKey(msg tea.KeyMsg) (tea.Cmd, bool) {
 		}
 		return nil, true
 
+	case "ctrl+o":
+		// Search the whole collection rather than the loaded page
+		return m.startServerSearch(), true
+
+	case "ctrl+t":
+		// Cycle plain → regex → whole-word matching
+		m.docSearchMode = m.docSearchMode.next()
+		m.updateDocSearchMatches()
+		return nil, true
+
 	case "ctrl+r":
 		// Jump to previous match
 		if len(m.docSearchMatches) > 0 {
@@ -512,15 +952,14 @@ func (m *Model) updateDocSearchMatches() {
 	m.docSearchMatches = []int{}
 	m.docSearchCurrent = -1
 
-	searchText := strings.ToLower(m.docSearchInput.Value())
-	if searchText == "" {
+	m.compileDocSearch()
+	if m.docSearchInput.Value() == "" || m.docSearchErr != "" {
 		return
 	}
 
 	// Search through all flattened nodes
 	for i, node := range m.flattenedTree {
-		lineText := m.getNodeSearchText(node)
-		if strings.Contains(strings.ToLower(lineText), searchText) {
+		if m.docSearchMatchesText(m.getNodeSearchText(node)) {
 			m.docSearchMatches = append(m.docSearchMatches, i)
 		}
 	}
@@ -539,6 +978,18 @@ func (m Model) getNodeSearchText(node *JSONNode) string {
 	if node.Depth == -1 {
 		return "" // Separator
 	}
+	if node.RawText != "" {
+		return node.RawText
+	}
+	if node.More > 0 {
+		return ""
+	}
+	if node.WrapOf != nil {
+		return node.WrapText
+	}
+	if field, ok := node.Value.(*schemaField); ok {
+		return node.Key + " " + schemaTypesLabel(field)
+	}
 
 	var parts []string
 
@@ -547,8 +998,10 @@ func (m Model) getNodeSearchText(node *JSONNode) string {
 		parts = append(parts, node.Key)
 	}
 
-	// Include value for leaf nodes
-	if !node.IsObject && !node.IsArray {
+	// Include value for leaf nodes, or the part on the line when wrapped
+	if node.Wrapped && node.WrapText != "" {
+		parts = append(parts, node.WrapText)
+	} else if !node.IsObject && !node.IsArray {
 		parts = append(parts, fmt.Sprintf("%v", node.Value))
 	}
 
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestPageMatchesCount(t *testing.T) {
+	tests := []struct {
+		name     string
+		page     int
+		returned int
+		total    int64
+		want     bool
+	}{
+		{"full page", 0, 10, 25, true},
+		{"short last page", 2, 5, 25, true},
+		{"empty collection", 0, 0, 0, true},
+		{"empty page past the end", 3, 0, 25, false},
+		{"empty last page after deletes", 2, 0, 25, false},
+		{"short page mid-collection", 1, 4, 25, false},
+		{"page reaching past the count", 2, 10, 25, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pageMatchesCount(tt.page, 10, tt.returned, tt.total); got != tt.want {
+				t.Errorf("pageMatchesCount(%d, 10, %d, %d) = %v, want %v", tt.page, tt.returned, tt.total, got, tt.want)
+			}
+		})
+	}
+}
+
+// largeDocument returns a document with n nested objects of a few fields each
+func largeDocument(n int) bson.M {
+	doc := bson.M{"_id": "large"}
+	for i := 0; i < n; i++ {
+		doc[fmt.Sprintf("field%04d", i)] = bson.M{"name": "item", "qty": i, "tags": bson.A{"a", "b"}}
+	}
+	return doc
+}
+
+func newLargeTreeModel(n int) Model {
+	m := newTestModel(120, 40)
+	m.documents = []bson.M{largeDocument(n)}
+	m.docTree = []*JSONNode{buildJSONTree(m.documents[0], 0)}
+	m.docTree[0].Collapsed = false
+	setCollapsedRecursive(m.docTree[0], false)
+	m.rebuildFlattenedTree()
+	m.docCursor = 2 // field0000, after _id
+	return m
+}
+
+func pressKey(m Model, key string) Model {
+	msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	updated, _ := m.Update(msg)
+	return updated.(Model)
+}
+
+func TestToggleKeysCoalesceRebuild(t *testing.T) {
+	m := newLargeTreeModel(20)
+	rows := len(m.flattenedTree)
+	node := m.nodeAtCursor()
+
+	m = pressKey(m, "h")
+	m = pressKey(m, "l")
+	m = pressKey(m, "h")
+	if !m.treeDirty || len(m.flattenedTree) != rows {
+		t.Fatalf("toggle keys rebuilt the tree immediately (dirty=%v, rows %d -> %d)", m.treeDirty, rows, len(m.flattenedTree))
+	}
+
+	updated, _ := m.Update(treeRebuildMsg{})
+	m = updated.(Model)
+	if m.treeDirty || len(m.flattenedTree) >= rows {
+		t.Fatalf("tick didn't rebuild the collapsed tree (dirty=%v, rows %d -> %d)", m.treeDirty, rows, len(m.flattenedTree))
+	}
+	if m.nodeAtCursor() != node {
+		t.Errorf("cursor moved off the toggled node")
+	}
+
+	// A navigation key flushes pending toggles before moving
+	m = pressKey(m, "l")
+	m = pressKey(m, "j")
+	if m.treeDirty || len(m.flattenedTree) != rows {
+		t.Fatalf("navigation didn't flush the tree (dirty=%v, rows %d)", m.treeDirty, len(m.flattenedTree))
+	}
+	if m.docCursor != 3 {
+		t.Errorf("docCursor = %d, want 3", m.docCursor)
+	}
+}
+
+// BenchmarkToggleKeysImmediateRebuild measures expand/collapse keys when every
+// keypress flattens the whole tree, as before rebuilds were deferred
+func BenchmarkToggleKeysImmediateRebuild(b *testing.B) {
+	m := newLargeTreeModel(2000)
+	node := m.nodeAtCursor()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		node.Collapsed = !node.Collapsed
+		m.rebuildFlattenedTree()
+	}
+}
+
+// BenchmarkToggleKeysDeferredRebuild measures the same keys through Update,
+// with one rebuild tick per eight keypresses as during key repeat
+func BenchmarkToggleKeysDeferredRebuild(b *testing.B) {
+	m := newLargeTreeModel(2000)
+	keys := []string{"h", "l"}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m = pressKey(m, keys[i%2])
+		if i%8 == 7 {
+			updated, _ := m.Update(treeRebuildMsg{})
+			m = updated.(Model)
+		}
+	}
+}
+
+func TestUnauthorizedLoadMarksCollection(t *testing.T) {
+	m := newTestModel(120, 30)
+	denied := mongo.CommandError{Code: unauthorizedErrorCode, Message: "not authorized on shop to execute command { find: \"orders\" }"}
+
+	updated, _ := m.Update(documentsLoadedMsg{namespace: "shop.orders", err: denied})
+	m = updated.(Model)
+	if m.errorModal {
+		t.Error("access denied opened the error modal")
+	}
+	if !m.deniedNamespaces["shop.orders"] || len(m.flattenedTree) != 0 {
+		t.Fatalf("collection not marked denied (denied=%v, rows=%d)", m.deniedNamespaces, len(m.flattenedTree))
+	}
+	if panel := m.renderDocumentsPanel(80, 10); !strings.Contains(panel, "read access to shop.orders") {
+		t.Errorf("documents panel doesn't explain the denial:\n%s", panel)
+	}
+	if suffixes := m.collectionSuffixes(); suffixes[1] != "🔒" {
+		t.Errorf("orders suffix = %q, want lock", suffixes[1])
+	}
+}
+
+func TestLoadKeepsCursorOnSameDocument(t *testing.T) {
+	m := newTestModel(120, 30)
+	docs := testDocuments()
+	m.docCursor = len(m.flattenedTree) - 1 // Second document
+	m.queryLoading = true
+
+	updated, _ := m.Update(documentsLoadedMsg{namespace: "shop.orders", documents: []bson.M{docs[1], docs[0]}, totalCount: 2})
+	m = updated.(Model)
+	if id := m.documentIDAtCursor(); id != docs[1]["_id"] {
+		t.Errorf("cursor on %v after reload, want %v", id, docs[1]["_id"])
+	}
+
+	updated, _ = m.Update(documentsLoadedMsg{namespace: "shop.orders", documents: []bson.M{docs[0]}, totalCount: 1})
+	m = updated.(Model)
+	if m.docCursor != 0 || m.docScrollOffset != 0 {
+		t.Errorf("cursor = %d, scroll = %d after the document left the page, want 0, 0", m.docCursor, m.docScrollOffset)
+	}
+}
+
+func TestEditBlockedWhileQueryRuns(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.queryLoading = true
+
+	m = pressKey(m, "e")
+	if m.editorActive || !strings.Contains(m.statusMessage, "wait for the query") {
+		t.Errorf("edit not blocked while loading (editorActive=%v, status=%q)", m.editorActive, m.statusMessage)
+	}
+}
+
+func TestCountMotions(t *testing.T) {
+	m := newLargeTreeModel(50)
+	last := len(m.flattenedTree) - 1
+	for _, tt := range []struct {
+		keys []string
+		want int
+	}{
+		{[]string{"1", "5", "j"}, 17},
+		{[]string{"3", "k"}, 14},
+		{[]string{"G"}, last},
+		{[]string{"g", "g"}, 0},
+		{[]string{"1", "0", "G"}, 9},
+		{[]string{"j"}, 10},
+	} {
+		for _, key := range tt.keys {
+			m = pressKey(m, key)
+		}
+		if m.docCursor != tt.want {
+			t.Errorf("after %v cursor = %d, want %d", tt.keys, m.docCursor, tt.want)
+		}
+	}
+	if m.docCursor < m.docScrollOffset || m.docCursor >= m.docScrollOffset+m.getDocPanelHeight() {
+		t.Errorf("cursor %d outside the viewport at %d", m.docCursor, m.docScrollOffset)
+	}
+}
+
+func TestSiblingAndParentJumps(t *testing.T) {
+	m := newLargeTreeModel(50)
+	field := func() string { return m.flattenedTree[m.docCursor].Key }
+
+	m = pressKey(m, "}")
+	if field() != "field0001" {
+		t.Errorf("} landed on %q, want field0001", field())
+	}
+	m = pressKey(pressKey(m, "3"), "}")
+	if field() != "field0004" {
+		t.Errorf("3} landed on %q, want field0004", field())
+	}
+	m = pressKey(m, "{")
+	if field() != "field0003" {
+		t.Errorf("{ landed on %q, want field0003", field())
+	}
+
+	m = pressKey(pressKey(m, "j"), "j") // field0003.qty
+	m = pressKey(m, "u")
+	if field() != "field0003" {
+		t.Errorf("u landed on %q, want field0003", field())
+	}
+	m = pressKey(m, "[")
+	if m.docCursor != 0 {
+		t.Errorf("[ from a top-level field landed on line %d, want the document root", m.docCursor)
+	}
+}
+
+func TestDocumentIndexAtCursor(t *testing.T) {
+	m := newTestModel(120, 30)
+	for _, root := range m.docTree {
+		setCollapsedRecursive(root, false)
+	}
+	m.rebuildFlattenedTree()
+
+	want := map[string]int{"65ab12cd34ef56ab78cd90ef": 0, "65ab12cd34ef56ab78cd90f0": 1}
+	for i, node := range m.flattenedTree {
+		m.docCursor = i
+		got := m.getDocumentIndexAtCursor()
+		switch {
+		case node.Depth == -1:
+			// A separator belongs to the document above it
+			if got != 0 {
+				t.Errorf("separator at %d maps to document %d, want 0", i, got)
+			}
+		case node.Key == "city":
+			if got != 0 {
+				t.Errorf("nested address.city maps to document %d, want 0", got)
+			}
+		case node.Key == "_id":
+			if id := rawValueString(node.Value); got != want[id] {
+				t.Errorf("_id %s maps to document %d, want %d", id, got, want[id])
+			}
+		}
+	}
+	m.docCursor = len(m.flattenedTree)
+	if got := m.getDocumentIndexAtCursor(); got != -1 {
+		t.Errorf("cursor past the end maps to document %d, want -1", got)
+	}
+}
+
+// BenchmarkDocumentIndexAtCursor measures the lookup on the last line of a
+// large expanded page, which used to rescan every line before the cursor
+func BenchmarkDocumentIndexAtCursor(b *testing.B) {
+	m := newLargeTreeModel(2000)
+	m.docCursor = len(m.flattenedTree) - 1
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.getDocumentIndexAtCursor()
+	}
+}
+
+func TestReloadKeepsExpandState(t *testing.T) {
+	m := newTestModel(120, 30)
+	docs := testDocuments()
+	address := findNodeByPath(m.docTree[0], "address")
+	address.Collapsed = false
+	m.docTree[1].Collapsed = true
+	m.rebuildFlattenedTree()
+	m.docCursor = m.flattenedIndex(address.Children[0]) // address.city
+
+	// The next page holds neither document, the page after holds both again
+	updated, _ := m.Update(documentsLoadedMsg{namespace: "shop.orders", documents: []bson.M{{"_id": "other"}}, totalCount: 3})
+	m = updated.(Model)
+	updated, _ = m.Update(documentsLoadedMsg{namespace: "shop.orders", documents: []bson.M{docs[1], docs[0]}, totalCount: 3})
+	m = updated.(Model)
+	if !m.docTree[0].Collapsed || findNodeByPath(m.docTree[1], "address").Collapsed {
+		t.Error("expand state not restored by _id after paging back")
+	}
+
+	// Saving keeps the cursor on the same field of the edited document
+	m.docCursor = m.flattenedIndex(findNodeByPath(m.docTree[1], "address.zip"))
+	edited := docs[0]
+	edited["name"] = "Ada King"
+	updated, _ = m.Update(documentSavedMsg{docID: edited["_id"], newDoc: edited})
+	m = updated.(Model)
+	if node := m.nodeAtCursor(); node == nil || nodePath(node) != "address.zip" {
+		t.Errorf("cursor on %v after save, want address.zip", node)
+	}
+	if findNodeByPath(m.docTree[1], "address").Collapsed {
+		t.Error("saving collapsed the edited document")
+	}
+}
+
+// offlineClient returns a client that never connects; commands built from
+// it are returned by Update but not run
+func offlineClient(t *testing.T) *mongo.Client {
+	t.Helper()
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Disconnect(context.Background()) })
+	return client
+}
+
+// update runs one message through the Update loop
+func update(t *testing.T, m Model, msg tea.Msg) (Model, tea.Cmd) {
+	t.Helper()
+	updated, cmd := m.Update(msg)
+	return updated.(Model), cmd
+}
+
+func TestSpinnerKeepsTickingAcrossFocusChanges(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.focus = FocusQuery
+	m.queryLoading = true
+
+	// Tabbing away mid-query starts the tick loop rather than stopping it
+	m, cmd := update(t, m, tea.KeyMsg{Type: tea.KeyTab})
+	m, _ = update(t, m, tea.KeyMsg{Type: tea.KeyShiftTab})
+	m, _ = update(t, m, tea.KeyMsg{Type: tea.KeyShiftTab})
+	if m.focus != FocusCollections || cmd == nil || !m.spinnerTicking {
+		t.Fatalf("focus = %v, ticking = %v, cmd = %v", m.focus, m.spinnerTicking, cmd)
+	}
+	frame := m.querySpinner.View()
+	m, cmd = update(t, m, m.querySpinner.Tick())
+	if cmd == nil || m.querySpinner.View() == frame {
+		t.Error("spinner didn't advance with the collections panel focused")
+	}
+	if panel := m.renderQueryPanel(80, 3); !strings.Contains(panel, m.querySpinner.View()) {
+		t.Errorf("query panel doesn't show the spinner without focus:\n%s", panel)
+	}
+
+	// Results arriving while unfocused clear the loading layout everywhere
+	m, _ = update(t, m, documentsLoadedMsg{namespace: "shop.orders", documents: testDocuments(), totalCount: 2})
+	if m.busy() {
+		t.Fatal("still loading after the results arrived")
+	}
+	if panel := m.renderQueryPanel(80, 3); strings.Contains(panel, m.querySpinner.View()) {
+		t.Errorf("query panel still shows the spinner:\n%s", panel)
+	}
+	m, cmd = update(t, m, m.querySpinner.Tick())
+	if cmd != nil || m.spinnerTicking {
+		t.Error("tick loop kept running with nothing loading")
+	}
+}
+
+func TestPageLoadShowsSpinnerAndIgnoresLeftCollection(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.queryLoading = true
+
+	// The user picks another collection before the query on orders returns
+	m.client = offlineClient(t)
+	m.focus = FocusCollections
+	m.collCursor = 0
+	m, _ = update(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.selectedCollection != "customers" || !m.loadingDocs {
+		t.Fatalf("selected %q, loading = %v", m.selectedCollection, m.loadingDocs)
+	}
+	m, _ = update(t, m, documentsLoadedMsg{namespace: "shop.orders", documents: testDocuments(), totalCount: 2})
+	if !m.loadingDocs {
+		t.Fatal("results of the collection left behind ended the load")
+	}
+	panel := m.renderDocumentsPanel(80, 10)
+	if !strings.Contains(panel, "Documents in customers") || !strings.Contains(panel, m.querySpinner.View()+"Loading...") {
+		t.Errorf("documents panel while customers loads:\n%s", panel)
+	}
+
+	m, _ = update(t, m, documentsLoadedMsg{namespace: "shop.customers", documents: []bson.M{{"_id": "c1"}}, totalCount: 1})
+	if m.busy() || len(m.documents) != 1 || m.documents[0]["_id"] != "c1" {
+		t.Errorf("busy = %v, documents = %v", m.busy(), m.documents)
+	}
+}
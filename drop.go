@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// dropCountMsg carries the document count of a collection the user asked to
+// drop, which the confirmation shows
+type dropCountMsg struct {
+	dbName   string
+	collName string
+	count    int64
+	err      error
+}
+
+// collectionDroppedMsg reports the end of dropping a collection
+type collectionDroppedMsg struct {
+	dbName   string
+	collName string
+	err      error
+}
+
+// databaseDropCountMsg carries the number of collections of a database the
+// user asked to drop, which the confirmation shows
+type databaseDropCountMsg struct {
+	dbName      string
+	collections int
+	err         error
+}
+
+// databaseDroppedMsg reports the end of dropping a database
+type databaseDroppedMsg struct {
+	dbName string
+	err    error
+}
+
+// confirmDropCollection counts the documents of the collection under the
+// cursor so the confirmation can say what dropping it destroys
+func (m *Model) confirmDropCollection() tea.Cmd {
+	if len(m.collFiltered) == 0 || m.selectedDatabase == "" || m.client == nil {
+		return nil
+	}
+	client, dbName, collName := m.client, m.selectedDatabase, m.collFiltered[m.collCursor]
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		count, err := client.Database(dbName).Collection(collName).EstimatedDocumentCount(ctx)
+		return dropCountMsg{dbName: dbName, collName: collName, count: count, err: err}
+	}
+}
+
+// handleDropCount opens the confirmation for dropping a collection, which
+// only enables once its name is typed
+func (m *Model) handleDropCount(msg dropCountMsg) tea.Cmd {
+	if msg.dbName != m.selectedDatabase || m.confirm != nil {
+		return nil
+	}
+	contents := "its " + pluralDocuments(msg.count)
+	if msg.err != nil {
+		contents = "all its documents (count unavailable)"
+	}
+	return m.openConfirm(&confirmation{
+		title:       "DROP COLLECTION",
+		message:     fmt.Sprintf("Drop %s with %s and indexes? This cannot be undone.", m.targetLabel(msg.dbName, msg.collName), contents),
+		severity:    SeverityCritical,
+		requireText: msg.collName,
+		destructive: true,
+		onConfirm: func(m *Model) tea.Cmd {
+			return dropCollection(m.client, msg.dbName, msg.collName)
+		},
+	})
+}
+
+// dropCollection drops collName from dbName
+func dropCollection(client *mongo.Client, dbName, collName string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		err := client.Database(dbName).Collection(collName).Drop(ctx)
+		return collectionDroppedMsg{dbName: dbName, collName: collName, err: err}
+	}
+}
+
+// handleCollectionDropped removes a dropped collection from the list and
+// clears the documents panel when it was the one open. Reloading the list
+// instead would also close a different collection that is open.
+func (m *Model) handleCollectionDropped(msg collectionDroppedMsg) tea.Cmd {
+	if msg.err != nil {
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Failed to drop collection %s: %v", msg.collName, msg.err)
+		return nil
+	}
+	status := m.setStatus("dropped " + truncateMiddle(namespaceOf(msg.dbName, msg.collName), maxToastNameWidth))
+	if msg.dbName != m.selectedDatabase {
+		return status
+	}
+	for i, coll := range m.collections {
+		if coll == msg.collName {
+			m.collections = append(m.collections[:i:i], m.collections[i+1:]...)
+			break
+		}
+	}
+	m.updateFilteredCollections()
+	delete(m.collCounts, namespaceOf(msg.dbName, msg.collName))
+	delete(m.collSpecs, msg.collName)
+	if msg.collName == m.selectedCollection {
+		m.documents = []bson.M{}
+		m.selectedCollection = ""
+		m.totalDocs = 0
+		m.docTree = nil
+		m.flattenedTree = nil
+		m.docFullscreen = false
+		m.schemaActive = false
+		m.focus = FocusCollections
+	}
+	return status
+}
+
+// confirmDropDatabase lists the collections of the database under the
+// cursor so the confirmation can say what dropping it destroys. The
+// server's own databases are refused outright.
+func (m *Model) confirmDropDatabase() tea.Cmd {
+	if len(m.dbFiltered) == 0 || m.client == nil {
+		return nil
+	}
+	dbName := m.dbFiltered[m.dbCursor]
+	if isSystemDatabase(dbName) {
+		return m.setStatus(dbName + " is a system database: mbongo won't drop it")
+	}
+	client := m.client
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		names, err := client.Database(dbName).ListCollectionNames(ctx, bson.D{})
+		return databaseDropCountMsg{dbName: dbName, collections: len(names), err: err}
+	}
+}
+
+// handleDatabaseDropCount opens the confirmation for dropping a database,
+// which only enables once its name is typed
+func (m *Model) handleDatabaseDropCount(msg databaseDropCountMsg) tea.Cmd {
+	if m.confirm != nil {
+		return nil
+	}
+	contents := fmt.Sprintf("its %d %s", msg.collections, pluralize(int64(msg.collections), "collection", "collections"))
+	if msg.err != nil {
+		contents = "all its collections (count unavailable)"
+	}
+	return m.openConfirm(&confirmation{
+		title:       "DROP DATABASE",
+		message:     fmt.Sprintf("Drop %s with %s, their documents and indexes? This cannot be undone.", m.targetLabel(msg.dbName), contents),
+		severity:    SeverityCritical,
+		requireText: msg.dbName,
+		destructive: true,
+		onConfirm: func(m *Model) tea.Cmd {
+			return dropDatabase(m.client, msg.dbName)
+		},
+	})
+}
+
+// dropDatabase drops dbName
+func dropDatabase(client *mongo.Client, dbName string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+
+		err := client.Database(dbName).Drop(ctx)
+		return databaseDroppedMsg{dbName: dbName, err: err}
+	}
+}
+
+// handleDatabaseDropped removes a dropped database from the list, clears the
+// collections and documents panels when it was the one open, and lists the
+// databases again
+func (m *Model) handleDatabaseDropped(msg databaseDroppedMsg) tea.Cmd {
+	if msg.err != nil {
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Failed to drop database %s: %v", msg.dbName, msg.err)
+		return nil
+	}
+	if i := indexOfString(m.databases, msg.dbName); i >= 0 {
+		m.databases = append(m.databases[:i:i], m.databases[i+1:]...)
+	}
+	m.updateFilteredDatabases()
+	for ns := range m.collCounts {
+		if dbName, _ := splitNamespace(ns); dbName == msg.dbName {
+			delete(m.collCounts, ns)
+		}
+	}
+	if msg.dbName == m.selectedDatabase {
+		m.selectedDatabase = ""
+		m.collections = []string{}
+		m.collSpecs = nil
+		m.updateFilteredCollections()
+		m.documents = []bson.M{}
+		m.selectedCollection = ""
+		m.totalDocs = 0
+		m.docTree = nil
+		m.flattenedTree = nil
+		m.docFullscreen = false
+		m.schemaActive = false
+		m.focus = FocusDatabases
+	}
+	status := m.setStatus("dropped database " + truncateMiddle(msg.dbName, maxToastNameWidth))
+	if m.client == nil {
+		return status
+	}
+	return tea.Batch(status, refreshDatabases(m.client))
+}
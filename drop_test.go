@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDropCollectionRequiresItsName(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.connectionName = "prod-replica"
+	m.client = offlineClient(t)
+	m.focus = FocusCollections
+
+	m, _ = update(t, m, dropCountMsg{dbName: "shop", collName: "orders", count: 1200})
+	if m.confirm == nil || m.confirm.severity != SeverityCritical || m.confirm.requireText != "orders" {
+		t.Fatalf("confirm = %+v", m.confirm)
+	}
+	view := normalizeRender(m.View())
+	for _, want := range []string{"DROP COLLECTION", "prod-replica ▸ shop ▸ orders with its 1,200", `Type "orders" to confirm`, "┏"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("modal lacks %q:\n%s", want, view)
+		}
+	}
+
+	m = pressKey(m, "enter")
+	if m.confirm == nil {
+		t.Error("enter confirmed before the name was typed")
+	}
+}
+
+func TestDroppedCollectionIsClosed(t *testing.T) {
+	m := newTestModel(120, 30)
+
+	m, _ = update(t, m, collectionDroppedMsg{dbName: "shop", collName: "customers", err: errors.New("not authorized on shop to execute command")})
+	if !m.errorModal || len(m.collections) != 3 {
+		t.Fatalf("failed drop: modal = %v, collections %v", m.errorModal, m.collections)
+	}
+	m.errorModal = false
+
+	// Dropping another collection keeps the open one
+	m, _ = update(t, m, collectionDroppedMsg{dbName: "shop", collName: "customers"})
+	if m.selectedCollection != "orders" || len(m.documents) == 0 || strings.Join(m.collFiltered, ",") != "orders,events_v2_partitioned_2024_06_eu_west_1" {
+		t.Fatalf("selected %q, collections %v", m.selectedCollection, m.collFiltered)
+	}
+
+	m, _ = update(t, m, collectionDroppedMsg{dbName: "shop", collName: "orders"})
+	if m.selectedCollection != "" || len(m.documents) != 0 || m.flattenedTree != nil || m.focus != FocusCollections {
+		t.Errorf("selected %q, %d documents, focus %v", m.selectedCollection, len(m.documents), m.focus)
+	}
+	if len(m.collections) != 1 || !strings.Contains(m.statusMessage, "dropped shop.orders") {
+		t.Errorf("collections %v, status %q", m.collections, m.statusMessage)
+	}
+}
+
+func TestDropDatabase(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m.focus = FocusDatabases
+	m.dbCursor = 0
+
+	m = pressKey(m, "D")
+	if m.confirm != nil || !strings.Contains(m.statusMessage, "admin is a system database") {
+		t.Fatalf("admin: confirm = %v, status %q", m.confirm != nil, m.statusMessage)
+	}
+
+	m, _ = update(t, m, databaseDropCountMsg{dbName: "shop", collections: 3})
+	if m.confirm == nil || m.confirm.severity != SeverityCritical || m.confirm.requireText != "shop" {
+		t.Fatalf("confirm = %+v", m.confirm)
+	}
+	if view := normalizeRender(m.View()); !strings.Contains(view, "DROP DATABASE") || !strings.Contains(view, "shop with its 3 collections") {
+		t.Errorf("modal:\n%s", view)
+	}
+}
+
+func TestDroppedDatabaseIsClosed(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m.collCounts["shop.orders"] = collectionCount{count: 5}
+	m.collCounts["analytics.events"] = collectionCount{count: 7}
+
+	// Dropping another database keeps the open one
+	m, _ = update(t, m, databaseDroppedMsg{dbName: "analytics"})
+	if m.selectedCollection != "orders" || strings.Join(m.dbFiltered, ",") != "admin,shop" || len(m.collCounts) != 1 {
+		t.Fatalf("selected %q, databases %v, counts %v", m.selectedCollection, m.dbFiltered, m.collCounts)
+	}
+
+	m, cmd := update(t, m, databaseDroppedMsg{dbName: "shop"})
+	if cmd == nil || m.selectedDatabase != "" || len(m.collFiltered) != 0 || len(m.documents) != 0 || m.focus != FocusDatabases {
+		t.Errorf("selected %q, collections %v, %d documents, focus %v", m.selectedDatabase, m.collFiltered, len(m.documents), m.focus)
+	}
+	if !strings.Contains(m.statusMessage, "dropped database shop") {
+		t.Errorf("status %q", m.statusMessage)
+	}
+}
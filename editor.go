@@ -1,16 +1,19 @@
 package main
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"reflect"
 	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // expandTilde expands ~ to the user's home directory
@@ -24,36 +27,279 @@ func expandTilde(path string) string {
 	return path
 }
 
-// openInEditor opens the document at the given index in $EDITOR
+// editGuard decides how a displayed document can be edited. Projected results
+// are missing the fields that weren't fetched, so the full document must be
+// refetched by _id before editing or the save would drop them. Aggregation
+// output has no 1:1 source document to save back to.
+func editGuard(provenance DocProvenance, doc bson.M) (refetch bool, err error) {
+	switch provenance {
+	case ProvenanceAggregated:
+		return false, errors.New("aggregation results can't be edited: they have no 1:1 source document")
+	case ProvenanceProjected:
+		if _, ok := doc["_id"]; !ok {
+			return false, errors.New("projected document has no _id, so its source document can't be found")
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// startEdit opens the document at docIndex in $EDITOR, refetching it first
+// when the displayed copy is incomplete
+func (m *Model) startEdit(docIndex int) tea.Cmd {
+	refetch, err := editGuard(m.docProvenance, m.documents[docIndex])
+	if err != nil {
+		return m.setStatus(err.Error())
+	}
+	if refetch {
+		return fetchFullDocument(m.client, m.selectedDatabase, m.selectedCollection, m.documents[docIndex]["_id"])
+	}
+	m.editorActive = true
+	return m.openInEditor(docIndex)
+}
+
+// fetchFullDocument loads the complete source document with the given _id
+func fetchFullDocument(client *mongo.Client, dbName, collName string, id interface{}) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var doc bson.M
+		err := client.Database(dbName).Collection(collName).FindOne(ctx, bson.M{"_id": id}).Decode(&doc)
+		if err != nil {
+			return fullDocumentMsg{err: err}
+		}
+		return fullDocumentMsg{doc: doc}
+	}
+}
+
+// openInEditor opens the document at the given index in $EDITOR. The edit
+// is tracked by _id so a reload while the editor is open can't redirect it.
 func (m Model) openInEditor(docIndex int) tea.Cmd {
+	return m.openInEditorWith(docIndex, nil)
+}
+
+// openInEditorWith edits the document at docIndex starting from text instead
+// of its current JSON (nil for the document itself), as when pasting a
+// register; the document is saved unless the editor leaves it unchanged
+func (m Model) openInEditorWith(docIndex int, text []byte) tea.Cmd {
 	doc := m.documents[docIndex]
+	docID := doc["_id"]
 
-	// Convert to JSON
-	jsonBytes, err := json.MarshalIndent(doc, "", "  ")
+	jsonBytes, err := editorJSON(doc)
 	if err != nil {
 		return func() tea.Msg {
-			return editorFinishedMsg{err: err, docIndex: docIndex}
+			return editorFinishedMsg{err: err, docID: docID}
 		}
 	}
+	return editInEditor(editorFinishedMsg{docID: docID}, jsonBytes, text)
+}
 
+// editInEditor opens jsonBytes, or text when it isn't nil, in $EDITOR. The
+// editor's result is reported as edit with the temp file and the original
+// JSON filled in.
+func editInEditor(edit editorFinishedMsg, jsonBytes, text []byte) tea.Cmd {
 	// Create temp file
 	tmpFile, err := os.CreateTemp("", "mbongo-*.json")
 	if err != nil {
+		edit.err = err
 		return func() tea.Msg {
-			return editorFinishedMsg{err: err, docIndex: docIndex}
+			return edit
 		}
 	}
 	tmpFileName := tmpFile.Name()
 
 	// Write JSON to temp file
-	if _, err := tmpFile.Write(jsonBytes); err != nil {
+	if text == nil {
+		text = jsonBytes
+	}
+	if _, err := tmpFile.Write(text); err != nil {
 		tmpFile.Close()
+		edit.err = err
 		return func() tea.Msg {
-			return editorFinishedMsg{err: err, docIndex: docIndex}
+			return edit
 		}
 	}
 	tmpFile.Close()
 
+	c := editorCommand(tmpFileName)
+
+	// Store original JSON for comparison
+	edit.originalJSON = make([]byte, len(jsonBytes))
+	copy(edit.originalJSON, jsonBytes)
+	edit.tempFile = tmpFileName
+
+	// Use tea.ExecProcess to hand over the terminal to the editor
+	return tea.ExecProcess(c, func(err error) tea.Msg {
+		edit.err = err
+		return edit
+	})
+}
+
+// editorJSON formats a document for editing as canonical Extended JSON, so
+// every value carries its BSON type ($oid, $date, $numberLong, $binary...)
+// through the editor. Keys are in the same sorted order as the tree view.
+func editorJSON(doc bson.M) ([]byte, error) {
+	return bson.MarshalExtJSONIndent(sortedDocument(doc), true, false, "", "  ")
+}
+
+// parseEditedDocument reads a document back from the editor. Typed values
+// come back as they were written out; plain JSON numbers typed in by hand are
+// read as int32, int64 or double the way mongosh would.
+func parseEditedDocument(data []byte) (bson.M, error) {
+	var doc bson.M
+	if err := bson.UnmarshalExtJSON(data, false, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// maxEditDiffLines is the number of changed fields listed when confirming
+// an edit; the rest are counted
+const maxEditDiffLines = 15
+
+// confirmEditedDocument shows the fields an edit changes and saves it once
+// confirmed. The diff is of the parsed documents, so an edit that only
+// reformats the JSON changes nothing. Esc abandons the edit.
+func (m *Model) confirmEditedDocument(msg editorFinishedMsg, newDoc bson.M) tea.Cmd {
+	// The conflict check is skipped if the original can't be read back
+	before, _ := parseEditedDocument(msg.originalJSON)
+	save := documentSave{docID: msg.docID, original: before, newDoc: newDoc, tempFile: msg.tempFile}
+	if before == nil {
+		before = bson.M{}
+	}
+	// saveDocument keeps the original _id, so the diff does too
+	after := bson.M{}
+	for key, value := range newDoc {
+		after[key] = value
+	}
+	after["_id"] = msg.docID
+	diff := documentDiff(before, after)
+
+	idChanged := !reflect.DeepEqual(newDoc["_id"], msg.docID)
+	if len(diff) == 0 && !idChanged {
+		os.Remove(msg.tempFile)
+		return m.setStatus("no changes: document was not saved")
+	}
+	if len(diff) > maxEditDiffLines {
+		diff = append(diff[:maxEditDiffLines], fmt.Sprintf("  … and %d more changes", len(diff)-maxEditDiffLines))
+	}
+	if idChanged {
+		diff = append(diff, "  (_id can't be changed; replacing keeps the original, inserting gets a new one)")
+	}
+
+	return m.openConfirm(&confirmation{
+		title:        "Save Document",
+		message:      fmt.Sprintf("Save these changes to _id=%s in %s, or insert them as a new document?\n\n%s", shortID(msg.docID), m.targetLabel(m.selectedDatabase, m.selectedCollection), strings.Join(diff, "\n")),
+		severity:     SeverityWarning,
+		destructive:  true,
+		confirmLabel: "replace original",
+		altKey:       "i",
+		altLabel:     "insert as new",
+		onConfirm: func(m *Model) tea.Cmd {
+			return m.saveDocument(save, false)
+		},
+		onAlt: func(m *Model) tea.Cmd {
+			return m.insertDocument(newDoc, msg.tempFile)
+		},
+		onCancel: func(m *Model) tea.Cmd {
+			os.Remove(msg.tempFile)
+			return m.setStatus("edit abandoned: document was NOT saved")
+		},
+	})
+}
+
+// editErrorPrefix starts the comment line reporting why an edit didn't
+// parse, at the top of the reopened file
+const editErrorPrefix = "// mbongo: "
+
+// stripEditError removes the parse error comment lines added when an edit
+// is reopened, so they don't count as a change or break the next parse
+func stripEditError(data []byte) []byte {
+	for bytes.HasPrefix(data, []byte(editErrorPrefix)) {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			return nil
+		}
+		data = data[i+1:]
+	}
+	return data
+}
+
+// offerReopenEditor reports an edit that doesn't parse and offers to reopen
+// the editor on it. Cancelling abandons the edit and removes its file.
+func (m *Model) offerReopenEditor(msg editorFinishedMsg, text []byte, parseErr error) tea.Cmd {
+	return m.openConfirm(&confirmation{
+		title:    "Invalid JSON",
+		message:  fmt.Sprintf("%v\n\nReopen the editor to fix it? Cancelling abandons the edit.", parseErr),
+		severity: SeverityWarning,
+		onConfirm: func(m *Model) tea.Cmd {
+			return m.reopenEditor(msg, text, parseErr)
+		},
+		onCancel: func(m *Model) tea.Cmd {
+			os.Remove(msg.tempFile)
+			return m.setStatus("edit abandoned: document was NOT saved")
+		},
+	})
+}
+
+// reopenEditor opens the editor again on an edit that didn't parse, with the
+// error as a comment line at the top
+func (m *Model) reopenEditor(msg editorFinishedMsg, text []byte, parseErr error) tea.Cmd {
+	comment := editErrorPrefix + strings.ReplaceAll(parseErr.Error(), "\n", " ") + " (this line is removed when saving)\n"
+	if err := os.WriteFile(msg.tempFile, append([]byte(comment), text...), 0600); err != nil {
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Failed to reopen the edit: %v\n\nDocument was NOT saved.", err)
+		return nil
+	}
+	m.editorActive = true
+	return tea.ExecProcess(editorCommand(msg.tempFile), func(err error) tea.Msg {
+		msg.err = err
+		return msg
+	})
+}
+
+// editorExit describes how an editor that ran ended with an error: a
+// non-zero status, as vim's :cq leaves, or a crash. It returns false when
+// the editor didn't run at all.
+func editorExit(err error) (string, bool) {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return "", false
+	}
+	if code := exitErr.ExitCode(); code >= 0 {
+		return fmt.Sprintf("exited with status %d", code), true
+	}
+	return "was killed", true
+}
+
+// editorErrorMessage explains in the error modal why the editor didn't run
+func editorErrorMessage(err error) string {
+	if errors.Is(err, exec.ErrNotFound) {
+		return fmt.Sprintf("Editor not found: %v\n\nSet $EDITOR to an installed editor. Nothing was changed.", err)
+	}
+	return fmt.Sprintf("Editor error: %v", err)
+}
+
+// keepEdit keeps the file of an edit whose editor failed until mbongo
+// quits, in case something worth recovering was written to it
+func (m *Model) keepEdit(path string) {
+	if path != "" {
+		m.keptEdits = append(m.keptEdits, path)
+	}
+}
+
+// removeKeptEdits removes the files keepEdit kept, once the program ended
+func removeKeptEdits(final tea.Model) {
+	if m, ok := final.(Model); ok {
+		for _, path := range m.keptEdits {
+			os.Remove(path)
+		}
+	}
+}
+
+// editorCommand returns the $EDITOR command that opens path
+func editorCommand(path string) *exec.Cmd {
 	// Get editor from environment
 	editorEnv := os.Getenv("EDITOR")
 	if editorEnv == "" {
@@ -62,56 +308,104 @@ func (m Model) openInEditor(docIndex int) tea.Cmd {
 
 	// Parse editor command - it may contain arguments (e.g., "emacs --init-directory=~/foo")
 	parts := strings.Fields(editorEnv)
-	editorCmd := parts[0]
 	var editorArgs []string
 	for _, arg := range parts[1:] {
 		editorArgs = append(editorArgs, expandTilde(arg))
 	}
-	editorArgs = append(editorArgs, tmpFileName)
-
-	// Create the editor command
-	c := exec.Command(editorCmd, editorArgs...)
+	return exec.Command(parts[0], append(editorArgs, path)...)
+}
 
-	// Store original JSON for comparison
-	originalJSON := make([]byte, len(jsonBytes))
-	copy(originalJSON, jsonBytes)
+// insertDocument inserts an edited document as a new one, leaving the
+// document it was edited from as it is. Its _id is dropped so the server
+// assigns a new one.
+func (m Model) insertDocument(doc bson.M, tempFile string) tea.Cmd {
+	namespace := namespaceOf(m.selectedDatabase, m.selectedCollection)
+	newDoc := bson.M{}
+	for key, value := range doc {
+		if key != "_id" {
+			newDoc[key] = value
+		}
+	}
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
 
-	// Use tea.ExecProcess to hand over the terminal to the editor
-	return tea.ExecProcess(c, func(err error) tea.Msg {
-		return editorFinishedMsg{
-			err:          err,
-			tempFile:     tmpFileName,
-			originalJSON: originalJSON,
-			docIndex:     docIndex,
+		coll := m.client.Database(m.selectedDatabase).Collection(m.selectedCollection)
+		result, err := coll.InsertOne(ctx, newDoc)
+		if err != nil {
+			return documentInsertedMsg{err: err, tempFile: tempFile}
 		}
-	})
+		newDoc["_id"] = result.InsertedID
+		return documentInsertedMsg{doc: newDoc, namespace: namespace, tempFile: tempFile}
+	}
 }
 
-// saveDocument saves the modified document back to MongoDB
-func (m Model) saveDocument(docIndex int, newDoc bson.M) tea.Cmd {
+// saveDocument saves the modified document back to MongoDB. Unless force
+// is set, the document on the server must still be the one loaded for
+// editing; otherwise nothing is written and the conflict is reported.
+// Forcing overwrites whatever is there, recreating a deleted document.
+func (m Model) saveDocument(save documentSave, force bool) tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		// Get the original document's _id
-		originalDoc := m.documents[docIndex]
-		docID, ok := originalDoc["_id"]
-		if !ok {
-			return documentSavedMsg{err: fmt.Errorf("document has no _id field"), docIndex: docIndex}
+		docID, tempFile := save.docID, save.tempFile
+		if docID == nil {
+			return documentSavedMsg{err: fmt.Errorf("document has no _id field"), tempFile: tempFile}
 		}
 
-		// Ensure the new document has the original _id (MongoDB doesn't allow changing _id)
-		// Remove any _id from the edited doc and use the original
-		delete(newDoc, "_id")
-		newDoc["_id"] = docID
+		if save.path == nil {
+			// Ensure the new document has the original _id (MongoDB doesn't allow changing _id)
+			// Remove any _id from the edited doc and use the original
+			delete(save.newDoc, "_id")
+			save.newDoc["_id"] = docID
+		}
 
-		// Replace the document in MongoDB
 		coll := m.client.Database(m.selectedDatabase).Collection(m.selectedCollection)
-		_, err := coll.ReplaceOne(ctx, bson.M{"_id": docID}, newDoc)
+		conflict := func() tea.Msg {
+			var current bson.M
+			if err := coll.FindOne(ctx, bson.M{"_id": docID}).Decode(&current); err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+				return documentSavedMsg{err: err, docID: docID, tempFile: tempFile}
+			}
+			return documentSavedMsg{docID: docID, tempFile: tempFile, conflict: &saveConflict{save: save, current: current}}
+		}
+
+		filter := bson.M{"_id": docID}
+		if !force && save.original != nil {
+			var current bson.M
+			err := coll.FindOne(ctx, filter).Decode(&current)
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return documentSavedMsg{docID: docID, tempFile: tempFile, conflict: &saveConflict{save: save}}
+			}
+			if err != nil {
+				return documentSavedMsg{err: err, docID: docID, tempFile: tempFile}
+			}
+			if !save.unchangedIn(current) {
+				return documentSavedMsg{docID: docID, tempFile: tempFile, conflict: &saveConflict{save: save, current: current}}
+			}
+			// Only write if it's still the version checked above, as far
+			// as its version field tells
+			filter = save.saveFilter()
+		}
+
+		saved, err := save.write(ctx, coll, filter, force)
 		if err != nil {
-			return documentSavedMsg{err: err, docIndex: docIndex}
+			return documentSavedMsg{err: err, docID: docID, tempFile: tempFile}
+		}
+		if saved == nil && force {
+			return documentSavedMsg{err: errors.New("the document has been deleted"), docID: docID, tempFile: tempFile}
+		}
+		if saved == nil {
+			return conflict()
 		}
 
-		return documentSavedMsg{err: nil, docIndex: docIndex, newDoc: newDoc}
+		return documentSavedMsg{
+			docID:     docID,
+			newDoc:    saved,
+			tempFile:  tempFile,
+			namespace: namespaceOf(m.selectedDatabase, m.selectedCollection),
+			before:    save.original,
+			undo:      save.undo,
+		}
 	}
 }
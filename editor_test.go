@@ -0,0 +1,311 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestEditGuard(t *testing.T) {
+	doc := bson.M{"_id": 1, "name": "x"}
+
+	if refetch, err := editGuard(ProvenanceFind, doc); refetch || err != nil {
+		t.Errorf("find: got refetch=%v err=%v, want edit in place", refetch, err)
+	}
+	if refetch, err := editGuard(ProvenanceProjected, doc); !refetch || err != nil {
+		t.Errorf("projected: got refetch=%v err=%v, want refetch", refetch, err)
+	}
+	if _, err := editGuard(ProvenanceProjected, bson.M{"name": "x"}); err == nil {
+		t.Error("projected without _id: want error")
+	}
+	if _, err := editGuard(ProvenanceAggregated, doc); err == nil {
+		t.Error("aggregated: want error")
+	}
+}
+
+func TestStartEditAggregatedIsDisabled(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.docProvenance = ProvenanceAggregated
+	m.startEdit(0)
+	if m.editorActive {
+		t.Error("editor opened for aggregation output")
+	}
+	if m.statusMessage == "" {
+		t.Error("want an explanatory status message")
+	}
+}
+
+func TestStartEditProjectedRefetches(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir()) // openInEditor writes a temp file
+	m := newTestModel(120, 30)
+	m.docProvenance = ProvenanceProjected
+	if cmd := m.startEdit(0); cmd == nil {
+		t.Fatal("want a refetch command")
+	}
+	if m.editorActive {
+		t.Error("editor opened before the full document was fetched")
+	}
+
+	// The refetched document replaces the projected one before editing
+	full := bson.M{"_id": m.documents[0]["_id"], "name": "Ada Lovelace", "secretField": "kept"}
+	updated, _ := m.Update(fullDocumentMsg{doc: full})
+	m = updated.(Model)
+	if _, ok := m.documents[0]["secretField"]; !ok {
+		t.Error("projected document was not replaced by the full document")
+	}
+	if !m.editorActive {
+		t.Error("editor not opened after refetch")
+	}
+}
+
+func TestEditorRoundTripKeepsTypes(t *testing.T) {
+	oid := primitive.NewObjectID()
+	created := primitive.NewDateTimeFromTime(time.Date(2024, 3, 2, 11, 45, 0, 123e6, time.UTC))
+	price, _ := primitive.ParseDecimal128("19.99")
+	doc := bson.M{
+		"_id":     oid,
+		"created": created,
+		"qty":     int32(3),
+		"views":   int64(9007199254740993),
+		"total":   float64(12),
+		"price":   price,
+		"token":   primitive.Binary{Subtype: 4, Data: make([]byte, 16)},
+		"address": bson.M{"city": "London", "zip": int32(12345)},
+		"tags":    bson.A{"new", int64(7), bson.M{"at": created}},
+	}
+	text, err := editorJSON(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"$oid"`, `"$date"`, `"$numberLong": "9007199254740993"`, `"$numberInt": "3"`, `"$numberDouble": "12.0"`, `"$binary"`, `"$numberDecimal"`} {
+		if !strings.Contains(string(text), want) {
+			t.Errorf("editor JSON missing %s:\n%s", want, text)
+		}
+	}
+
+	got, err := parseEditedDocument(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, doc) {
+		t.Errorf("round trip =\n%#v\nwant\n%#v", got, doc)
+	}
+}
+
+func TestParseEditedDocumentReadsPlainJSON(t *testing.T) {
+	got, err := parseEditedDocument([]byte(`{"_id": {"$oid": "65f1a2b3c4d5e6f708192a3b"}, "qty": 4, "big": 5000000000, "ratio": 0.5, "when": {"$date": "2024-03-02T11:45:00Z"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	oid, _ := primitive.ObjectIDFromHex("65f1a2b3c4d5e6f708192a3b")
+	want := bson.M{
+		"_id":   oid,
+		"qty":   int32(4),
+		"big":   int64(5000000000),
+		"ratio": 0.5,
+		"when":  primitive.NewDateTimeFromTime(time.Date(2024, 3, 2, 11, 45, 0, 0, time.UTC)),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseEditedDocument = %#v, want %#v", got, want)
+	}
+	if _, err := parseEditedDocument([]byte(`{"qty": 4,}`)); err == nil {
+		t.Error("invalid JSON accepted")
+	}
+}
+
+func TestInvalidEditIsKeptAndReopened(t *testing.T) {
+	m := newTestModel(120, 30)
+	original, _ := editorJSON(m.documents[0])
+	path := filepath.Join(t.TempDir(), "edit.json")
+	edit := []byte(`{"_id": 1, "name": "Ada",}`)
+	os.WriteFile(path, edit, 0600)
+	finished := editorFinishedMsg{tempFile: path, originalJSON: original, docID: m.documents[0]["_id"]}
+
+	m, _ = update(t, m, finished)
+	if m.confirm == nil || m.confirm.title != "Invalid JSON" || m.errorModal {
+		t.Fatalf("confirm = %+v, error = %q", m.confirm, m.errorMessage)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("edit removed before it was saved or abandoned: %v", err)
+	}
+
+	// Reopening puts the error on top of the edit, and it is stripped again
+	reopened := m
+	reopened.confirm.onConfirm(&reopened)
+	text, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(text), editErrorPrefix) || !reopened.editorActive {
+		t.Errorf("reopened file:\n%s", text)
+	}
+	if got := stripEditError(text); string(got) != string(edit) {
+		t.Errorf("stripEditError = %q, want the edit back", got)
+	}
+	fixed := append(text[:len(text)-len(edit)], `{"_id": 1, "name": "Ada"}`...)
+	os.WriteFile(path, fixed, 0600)
+	if saved, _ := update(t, reopened, finished); saved.confirm == nil || saved.confirm.title != "Save Document" {
+		t.Error("fixed edit not offered for saving")
+	}
+
+	// Abandoning removes the edit
+	m = pressKey(m, "n")
+	if _, err := os.Stat(path); !os.IsNotExist(err) || !strings.Contains(m.statusMessage, "abandoned") {
+		t.Errorf("abandoned edit: stat err = %v, status = %q", err, m.statusMessage)
+	}
+}
+
+func TestFailedSaveKeepsTheEdit(t *testing.T) {
+	m := newTestModel(120, 30)
+	path := filepath.Join(t.TempDir(), "edit.json")
+	os.WriteFile(path, []byte(`{}`), 0600)
+
+	failed, _ := update(t, m, documentSavedMsg{err: errors.New("document failed validation"), tempFile: path})
+	if _, err := os.Stat(path); err != nil || !strings.Contains(failed.errorMessage, "kept in "+path) {
+		t.Errorf("failed save: stat err = %v, error = %q", err, failed.errorMessage)
+	}
+	update(t, m, documentSavedMsg{docID: m.documents[0]["_id"], newDoc: m.documents[0], tempFile: path})
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("saved edit not removed: %v", err)
+	}
+}
+
+func TestEditorErrorKeepsTheInterface(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.docCursor = 3
+	m, _ = update(t, m, editorFinishedMsg{err: errors.New("exit status 1")})
+	view := normalizeRender(m.View())
+	if m.err != nil || !m.errorModal || !strings.Contains(view, "Editor error: exit status 1") || strings.Contains(view, "Press q to quit") {
+		t.Fatalf("err = %v, modal = %v:\n%s", m.err, m.errorModal, view)
+	}
+	m, _ = update(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.errorModal || m.docCursor != 3 || m.selectedCollection != "orders" {
+		t.Errorf("after dismissing: modal = %v, cursor = %d, collection = %q", m.errorModal, m.docCursor, m.selectedCollection)
+	}
+}
+
+func TestEditorExitStatusCancelsTheEdit(t *testing.T) {
+	m := newTestModel(120, 30)
+	path := filepath.Join(t.TempDir(), "edit.json")
+	os.WriteFile(path, []byte(`{"name": "half-written"}`), 0600)
+
+	exitErr := exec.Command("sh", "-c", "exit 1").Run()
+	m, _ = update(t, m, editorFinishedMsg{err: exitErr, tempFile: path})
+	if m.err != nil || m.errorModal || m.confirm != nil {
+		t.Fatalf("err = %v, modal = %v, confirm = %v", m.err, m.errorModal, m.confirm != nil)
+	}
+	if want := "editor exited with status 1 — edit discarded, kept in " + path + " until you quit • e: retry"; m.statusMessage != want {
+		t.Errorf("status = %q, want %q", m.statusMessage, want)
+	}
+	// Kept until quitting in case something worth keeping was written
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("temp file removed: %v", err)
+	}
+	removeKeptEdits(m)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("temp file kept after quitting: %v", err)
+	}
+}
+
+func TestMissingEditorIsReported(t *testing.T) {
+	m := newTestModel(120, 30)
+	path := filepath.Join(t.TempDir(), "edit.json")
+	os.WriteFile(path, []byte(`{}`), 0600)
+
+	notFound := exec.Command("mbongo-no-such-editor").Run()
+	m, _ = update(t, m, editorFinishedMsg{err: notFound, tempFile: path})
+	if m.err != nil || !m.errorModal || !strings.Contains(m.errorMessage, "Editor not found") || !strings.Contains(m.errorMessage, "Set $EDITOR") {
+		t.Fatalf("err = %v, modal = %v, message = %q", m.err, m.errorModal, m.errorMessage)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("temp file of an edit that never started kept: %v", err)
+	}
+}
+
+func TestEditedDocumentIsConfirmedWithADiff(t *testing.T) {
+	m := newTestModel(120, 30)
+	doc := bson.M{"_id": int32(1), "name": "Ada", "address": bson.M{"city": "London", "zip": "N1"}, "tags": bson.A{"a"}}
+	original, _ := editorJSON(doc)
+	path := filepath.Join(t.TempDir(), "edit.json")
+	finished := editorFinishedMsg{tempFile: path, originalJSON: original, docID: int32(1)}
+
+	// Reformatting alone changes nothing
+	os.WriteFile(path, []byte(`{"tags": ["a"], "address": {"zip": "N1", "city": "London"}, "name": "Ada", "_id": {"$numberInt": "1"}}`), 0600)
+	m, _ = update(t, m, finished)
+	if m.confirm != nil || !strings.Contains(m.statusMessage, "no changes") {
+		t.Errorf("reformatted edit: confirm = %v, status = %q", m.confirm, m.statusMessage)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("unchanged edit not removed")
+	}
+
+	os.WriteFile(path, []byte(`{"_id": 2, "name": "Ada Lovelace", "address": {"city": "London"}, "tags": ["a"], "born": 1815}`), 0600)
+	m, _ = update(t, m, finished)
+	if m.confirm == nil || m.confirm.title != "Save Document" {
+		t.Fatalf("confirm = %+v", m.confirm)
+	}
+	for _, want := range []string{
+		"+ born: 1815",
+		"- address.zip: \"N1\"",
+		"~ name: \"Ada\" → \"Ada Lovelace\"",
+		"_id can't be changed",
+	} {
+		if !strings.Contains(m.confirm.message, want) {
+			t.Errorf("diff missing %q:\n%s", want, m.confirm.message)
+		}
+	}
+
+	// Esc abandons the edit without saving
+	m = pressKey(m, "esc")
+	if m.confirm != nil || !strings.Contains(m.statusMessage, "abandoned") {
+		t.Errorf("esc: confirm = %v, status = %q", m.confirm, m.statusMessage)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("abandoned edit not removed")
+	}
+}
+
+func TestEditedDocumentCanBeInsertedAsNew(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	doc := m.documents[0]
+	original, _ := editorJSON(doc)
+	path := filepath.Join(t.TempDir(), "edit.json")
+	os.WriteFile(path, []byte(`{"_id": 1, "name": "Ada King"}`), 0600)
+
+	m, _ = update(t, m, editorFinishedMsg{tempFile: path, originalJSON: original, docID: doc["_id"]})
+	if m.confirm == nil || m.confirm.altKey != "i" {
+		t.Fatalf("confirm = %+v", m.confirm)
+	}
+	if view := normalizeRender(m.View()); !strings.Contains(view, "enter/y: replace original • i: insert as new") {
+		t.Errorf("confirm view:\n%s", view)
+	}
+	m, cmd := update(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	if m.confirm != nil || cmd == nil {
+		t.Fatalf("i: confirm = %v, cmd = %v", m.confirm != nil, cmd != nil)
+	}
+
+	// A failed insert keeps the edit
+	failed, _ := update(t, m, documentInsertedMsg{err: errors.New("duplicate key"), tempFile: path})
+	if _, err := os.Stat(path); err != nil || !strings.Contains(failed.errorMessage, "kept in "+path) {
+		t.Errorf("failed insert: stat err = %v, error = %q", err, failed.errorMessage)
+	}
+
+	inserted := bson.M{"_id": "new-id", "name": "Ada King"}
+	m, _ = update(t, m, documentInsertedMsg{doc: inserted, namespace: "shop.orders", tempFile: path})
+	if len(m.documents) != 3 || m.documents[2]["_id"] != "new-id" || m.documents[0]["name"] != "Ada Lovelace" || m.totalDocs != 43 {
+		t.Errorf("documents = %v, total = %d", m.documents, m.totalDocs)
+	}
+	if !strings.Contains(m.statusMessage, "inserted as new document") {
+		t.Errorf("status = %q", m.statusMessage)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("inserted edit not removed")
+	}
+}
@@ -0,0 +1,537 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/crypto/argon2"
+)
+
+// sealedPrefix marks a connection string stored encrypted. Plaintext ones
+// start with their mongodb scheme, so both can sit in the same table.
+const sealedPrefix = "enc:"
+
+// passphraseEnv names the variable unlocking encrypted connections without
+// a prompt, for the command line and scripts
+const passphraseEnv = "MBONGO_PASSPHRASE"
+
+// verifierText is sealed with the key when encryption is turned on, so
+// that a wrong passphrase is told apart before any connection is read
+const verifierText = "mbongo"
+
+// kdfParams are the argon2id costs of deriving the key from the
+// passphrase. They are stored with the salt so that they can be raised
+// without locking out existing stores.
+type kdfParams struct {
+	time    uint32
+	memory  uint32 // KiB
+	threads uint8
+}
+
+// defaultKDF follows the second recommended option of RFC 9106
+var defaultKDF = kdfParams{time: 3, memory: 64 * 1024, threads: 4}
+
+var (
+	errStoreLocked     = errors.New("saved connections are encrypted and haven't been unlocked")
+	errWrongPassphrase = errors.New("wrong passphrase")
+)
+
+// storeKey is the AES-256 key of the saved connections once unlocked, nil
+// while they are locked or not encrypted
+var storeKey []byte
+
+// deriveKey derives the AES-256 key of a passphrase
+func deriveKey(passphrase string, salt []byte, p kdfParams) []byte {
+	return argon2.IDKey([]byte(passphrase), salt, p.time, p.memory, p.threads, 32)
+}
+
+// seal encrypts plaintext with AES-GCM under key, prefixed with its nonce
+func seal(key []byte, plaintext string) (string, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// unseal decrypts a value seal produced. Authentication fails with a key
+// other than the one it was sealed with.
+func unseal(key []byte, value string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, sealedPrefix))
+	if err != nil {
+		return "", err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("sealed value is truncated")
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// isSealed reports whether a stored connection string is encrypted
+func isSealed(value string) bool {
+	return strings.HasPrefix(value, sealedPrefix)
+}
+
+// sealConnectionString returns a connection string the way it's stored:
+// encrypted when the store is, refused while it's locked
+func sealConnectionString(connStr string) (string, error) {
+	if storeKey != nil {
+		return seal(storeKey, connStr)
+	}
+	if encrypted, err := encryptionEnabled(); err != nil || encrypted {
+		if err == nil {
+			err = errStoreLocked
+		}
+		return "", err
+	}
+	return connStr, nil
+}
+
+// openConnectionString returns a stored connection string in plaintext
+func openConnectionString(stored string) (string, error) {
+	if !isSealed(stored) {
+		return stored, nil
+	}
+	if storeKey == nil {
+		return "", errStoreLocked
+	}
+	return unseal(storeKey, stored)
+}
+
+// encryptionEnabled reports whether the saved connections are encrypted
+func encryptionEnabled() (bool, error) {
+	if db == nil {
+		return false, nil
+	}
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM store_encryption").Scan(&count)
+	return count > 0, err
+}
+
+// storeLocked reports whether the saved connections are encrypted with a
+// key that wasn't derived yet
+func storeLocked() (bool, error) {
+	if storeKey != nil {
+		return false, nil
+	}
+	return encryptionEnabled()
+}
+
+// unlockStore derives the key of the saved connections from passphrase.
+// Connections still in plaintext, saved before encryption was turned on
+// or by an mbongo without it, are encrypted on the way.
+func unlockStore(passphrase string) error {
+	var salt []byte
+	var verifier string
+	var p kdfParams
+	err := db.QueryRow("SELECT salt, verifier, time_cost, memory_kib, threads FROM store_encryption").
+		Scan(&salt, &verifier, &p.time, &p.memory, &p.threads)
+	if err != nil {
+		return err
+	}
+	key := deriveKey(passphrase, salt, p)
+	if text, err := unseal(key, verifier); err != nil || text != verifierText {
+		return errWrongPassphrase
+	}
+	storeKey = key
+	if dbReadOnly {
+		return nil
+	}
+	return resealConnections(func(stored string) (string, error) {
+		if isSealed(stored) {
+			return stored, nil
+		}
+		return seal(key, stored)
+	})
+}
+
+// unlockFromEnv unlocks the saved connections with the passphrase in
+// MBONGO_PASSPHRASE, if they are locked and it's set
+func unlockFromEnv() error {
+	locked, err := storeLocked()
+	if err != nil || !locked {
+		return err
+	}
+	passphrase := os.Getenv(passphraseEnv)
+	if passphrase == "" {
+		return fmt.Errorf("%w: set %s to the passphrase", errStoreLocked, passphraseEnv)
+	}
+	return unlockStore(passphrase)
+}
+
+// enableEncryption encrypts the saved connections with a key derived from
+// passphrase, which is then asked for at each start
+func enableEncryption(passphrase string) error {
+	if dbReadOnly {
+		return errReadOnlyStore
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	key := deriveKey(passphrase, salt, defaultKDF)
+	verifier, err := seal(key, verifierText)
+	if err != nil {
+		return err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(
+		"INSERT INTO store_encryption (id, salt, verifier, time_cost, memory_kib, threads) VALUES (1, ?, ?, ?, ?, ?)",
+		salt, verifier, defaultKDF.time, defaultKDF.memory, defaultKDF.threads,
+	); err != nil {
+		return err
+	}
+	if err := resealConnectionsTx(tx, func(stored string) (string, error) {
+		return seal(key, stored)
+	}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	storeKey = key
+	return nil
+}
+
+// disableEncryption stores the saved connections in plaintext again
+func disableEncryption() error {
+	if dbReadOnly {
+		return errReadOnlyStore
+	}
+	if storeKey == nil {
+		return errStoreLocked
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := resealConnectionsTx(tx, openConnectionString); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM store_encryption"); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	storeKey = nil
+	return nil
+}
+
+// resealConnections rewrites every stored connection string through
+// convert, all or none of them
+func resealConnections(convert func(stored string) (string, error)) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := resealConnectionsTx(tx, convert); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// resealConnectionsTx rewrites every stored connection string through
+// convert within tx
+func resealConnectionsTx(tx *sql.Tx, convert func(stored string) (string, error)) error {
+	rows, err := tx.Query("SELECT id, connection_string FROM connections")
+	if err != nil {
+		return err
+	}
+	stored := map[int64]string{}
+	for rows.Next() {
+		var id int64
+		var value string
+		if err := rows.Scan(&id, &value); err != nil {
+			rows.Close()
+			return err
+		}
+		stored[id] = value
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for id, value := range stored {
+		converted, err := convert(value)
+		if err != nil {
+			return err
+		}
+		if converted == value {
+			continue
+		}
+		if _, err := tx.Exec("UPDATE connections SET connection_string = ? WHERE id = ?", converted, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storeLockedMsg is sent at startup when the saved connections are
+// encrypted, to ask for the passphrase
+type storeLockedMsg struct{}
+
+// storeUnlockedMsg reports the end of unlocking the saved connections
+type storeUnlockedMsg struct {
+	err error
+}
+
+// storeEncryptedMsg reports the end of turning encryption on
+type storeEncryptedMsg struct {
+	err error
+}
+
+// newPassphraseInputs creates the inputs of the passphrase prompt
+func newPassphraseInputs() (passphrase, confirm textinput.Model) {
+	passphrase = textinput.New()
+	passphrase.Placeholder = "passphrase"
+	passphrase.EchoMode = textinput.EchoPassword
+	passphrase.EchoCharacter = '•'
+	passphrase.Width = 40
+	confirm = textinput.New()
+	confirm.Placeholder = "again"
+	confirm.EchoMode = textinput.EchoPassword
+	confirm.EchoCharacter = '•'
+	confirm.Width = 40
+	return passphrase, confirm
+}
+
+// openPassphrasePrompt asks for the passphrase of the saved connections:
+// a new one, typed twice, when choosing is set
+func (m *Model) openPassphrasePrompt(choosing bool) tea.Cmd {
+	m.passphraseActive = true
+	m.passphraseChoosing = choosing
+	m.passphraseBusy = false
+	m.passphraseErr = ""
+	m.passphraseFocus = 0
+	m.passphraseInput.SetValue("")
+	m.passphraseConfirmInput.SetValue("")
+	m.passphraseInput.Focus()
+	m.passphraseConfirmInput.Blur()
+	return textinput.Blink
+}
+
+// closePassphrasePrompt closes the passphrase prompt, forgetting what was typed
+func (m *Model) closePassphrasePrompt() {
+	m.passphraseActive = false
+	m.passphraseBusy = false
+	m.passphraseInput.SetValue("")
+	m.passphraseConfirmInput.SetValue("")
+	m.passphraseInput.Blur()
+	m.passphraseConfirmInput.Blur()
+}
+
+// handlePassphraseKey handles keyboard input in the passphrase prompt.
+// Nothing can be used before the saved connections are unlocked, so
+// cancelling the unlock quits.
+func (m *Model) handlePassphraseKey(msg tea.KeyMsg) tea.Cmd {
+	if m.passphraseBusy {
+		if msg.String() == "ctrl+c" {
+			return tea.Quit
+		}
+		return nil
+	}
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "ctrl+g":
+		if !m.passphraseChoosing {
+			return tea.Quit
+		}
+		m.closePassphrasePrompt()
+		return nil
+	case "tab", "shift+tab", "up", "down":
+		if m.passphraseChoosing {
+			m.passphraseFocus = 1 - m.passphraseFocus
+			if m.passphraseFocus == 0 {
+				m.passphraseInput.Focus()
+				m.passphraseConfirmInput.Blur()
+			} else {
+				m.passphraseConfirmInput.Focus()
+				m.passphraseInput.Blur()
+			}
+		}
+		return nil
+	case "enter":
+		passphrase := m.passphraseInput.Value()
+		if passphrase == "" {
+			m.passphraseErr = "the passphrase can't be empty"
+			return nil
+		}
+		if !m.passphraseChoosing {
+			m.passphraseBusy = true
+			return func() tea.Msg {
+				return storeUnlockedMsg{err: unlockStore(passphrase)}
+			}
+		}
+		if m.passphraseFocus == 0 {
+			m.passphraseFocus = 1
+			m.passphraseInput.Blur()
+			m.passphraseConfirmInput.Focus()
+			return nil
+		}
+		if m.passphraseConfirmInput.Value() != passphrase {
+			m.passphraseErr = "the passphrases don't match"
+			m.passphraseConfirmInput.SetValue("")
+			return nil
+		}
+		m.passphraseBusy = true
+		return func() tea.Msg {
+			return storeEncryptedMsg{err: enableEncryption(passphrase)}
+		}
+	}
+	var cmd tea.Cmd
+	if m.passphraseFocus == 0 {
+		m.passphraseInput, cmd = m.passphraseInput.Update(msg)
+	} else {
+		m.passphraseConfirmInput, cmd = m.passphraseConfirmInput.Update(msg)
+	}
+	m.passphraseErr = ""
+	return cmd
+}
+
+// handleStoreUnlocked loads the saved connections once unlocked, or asks
+// for the passphrase again
+func (m *Model) handleStoreUnlocked(msg storeUnlockedMsg) tea.Cmd {
+	if errors.Is(msg.err, errWrongPassphrase) {
+		m.passphraseBusy = false
+		m.passphraseErr = "Wrong passphrase, try again"
+		m.passphraseInput.SetValue("")
+		return nil
+	}
+	m.closePassphrasePrompt()
+	if msg.err != nil {
+		return func() tea.Msg {
+			return connectionsLoadedMsg{err: fmt.Errorf("unlocking saved connections: %w", msg.err)}
+		}
+	}
+	return loadStoredConnections
+}
+
+// handleStoreEncrypted announces that the saved connections are encrypted
+func (m *Model) handleStoreEncrypted(msg storeEncryptedMsg) tea.Cmd {
+	m.closePassphrasePrompt()
+	if msg.err != nil {
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Failed to encrypt saved connections: %v", msg.err)
+		return nil
+	}
+	m.storeEncrypted = true
+	return m.setStatus("saved connections encrypted: the passphrase is asked for at each start")
+}
+
+// toggleEncryption encrypts the saved connections, asking for a new
+// passphrase, or stores them in plaintext again after confirming
+func (m *Model) toggleEncryption() tea.Cmd {
+	if dbReadOnly {
+		return m.setStatus(errReadOnlyStore.Error())
+	}
+	if !m.storeEncrypted {
+		return m.openPassphrasePrompt(true)
+	}
+	path, _ := getDBPath()
+	return m.openConfirm(&confirmation{
+		title:    "Decrypt Saved Connections",
+		message:  fmt.Sprintf("Store saved connections in plaintext again? Their connection strings, with any password they hold, become readable by anyone who can read %s.", path),
+		severity: SeverityDanger,
+		onConfirm: func(m *Model) tea.Cmd {
+			if err := disableEncryption(); err != nil {
+				m.errorModal = true
+				m.errorMessage = fmt.Sprintf("Failed to decrypt saved connections: %v", err)
+				return nil
+			}
+			m.storeEncrypted = false
+			return m.setStatus("saved connections stored in plaintext")
+		},
+	})
+}
+
+// renderPassphrasePrompt renders the passphrase prompt modal
+func (m Model) renderPassphrasePrompt(background string) string {
+	width := m.modalWidth(64)
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+	path, _ := getDBPath()
+	title, message := "Unlock Saved Connections", fmt.Sprintf("The connections saved in %s are encrypted.", path)
+	hint := "enter: unlock • esc: quit"
+	if m.passphraseChoosing {
+		title = "Encrypt Saved Connections"
+		message = "Connection strings are encrypted with a key derived from this passphrase, which is asked for at each start. It can't be recovered if forgotten."
+		hint = "tab: switch field • enter: encrypt • esc: cancel"
+	}
+	if m.passphraseBusy {
+		hint = "deriving the key..."
+	}
+
+	lines := []string{
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render(title),
+		"",
+		textWrapStyle(width - 4).Render(message),
+		"",
+		labelStyle.Render("Passphrase:"),
+		fitInput(m.passphraseInput, width-4),
+	}
+	if m.passphraseChoosing {
+		lines = append(lines, labelStyle.Render("Confirm passphrase:"), fitInput(m.passphraseConfirmInput, width-4))
+	}
+	if m.passphraseErr != "" {
+		lines = append(lines, "", lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Width(width-4).Render(m.passphraseErr))
+	}
+	lines = append(lines, "", hintStyle.Render(hint))
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("205")).
+		Padding(1, 2).
+		Width(width)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// environmentColors are the colors of the usual environments when their
+// connection doesn't set one
+var environmentColors = map[string]string{
+	"prod":        "196",
+	"production":  "196",
+	"staging":     "214",
+	"stage":       "214",
+	"test":        "39",
+	"dev":         "42",
+	"development": "42",
+}
+
+// otherEnvironmentColor is the color of free-text environments
+const otherEnvironmentColor = "99"
+
+var hexColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// validateColor checks that a color typed in the connection modal is one
+// lipgloss understands: an ANSI 256 number or a hex color. Blank picks the
+// environment's.
+func validateColor(color string) error {
+	if color == "" || hexColorPattern.MatchString(color) {
+		return nil
+	}
+	if n, err := strconv.Atoi(color); err == nil && n >= 0 && n <= 255 {
+		return nil
+	}
+	return errors.New("color must be an ANSI number from 0 to 255 or #rrggbb")
+}
+
+// environmentColor returns the color an environment is shown in
+func environmentColor(env, color string) lipgloss.Color {
+	if color != "" {
+		return lipgloss.Color(color)
+	}
+	if c, ok := environmentColors[strings.ToLower(env)]; ok {
+		return lipgloss.Color(c)
+	}
+	return lipgloss.Color(otherEnvironmentColor)
+}
+
+// isProduction reports whether an environment label stands for production
+func isProduction(env string) bool {
+	env = strings.ToLower(env)
+	return env == "prod" || env == "production"
+}
+
+// useConnection makes conn the connection to connect to
+func (m *Model) useConnection(conn Connection) {
+	m.connectionName = conn.Name
+	m.connectionString = conn.ConnectionString
+	m.sshAlias = conn.SSHAlias
+	m.connectionDatabase = conn.DefaultDatabase
+	m.connectionEnv = conn.Environment
+	m.connectionColor = conn.Color
+}
+
+// renderEnvironmentLabel renders an environment next to a connection name
+func renderEnvironmentLabel(env, color string) string {
+	if env == "" {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(environmentColor(env, color)).Render("[" + env + "]")
+}
+
+// environmentBadge renders the environment of the active connection as the
+// badge starting the help line, or "" when it has none
+func (m Model) environmentBadge() string {
+	if m.connectionEnv == "" {
+		return ""
+	}
+	return lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("231")).
+		Background(environmentColor(m.connectionEnv, m.connectionColor)).
+		Padding(0, 1).
+		Render(strings.ToUpper(truncate(m.connectionEnv, 20)))
+}
+
+// guardProduction runs onConfirm at once, unless the active connection is
+// tagged prod: then action is confirmed once more by typing the label, so
+// that a habit of pressing enter doesn't write to production
+func (m *Model) guardProduction(action string, onConfirm, onCancel func(m *Model) tea.Cmd) tea.Cmd {
+	if !isProduction(m.connectionEnv) {
+		return onConfirm(m)
+	}
+	return m.openConfirm(&confirmation{
+		title:       strings.ToUpper(action) + " ON " + strings.ToUpper(m.connectionEnv),
+		message:     fmt.Sprintf("%s is tagged %s. Type %s to go ahead.", m.connectionName, m.connectionEnv, m.connectionEnv),
+		severity:    SeverityCritical,
+		requireText: m.connectionEnv,
+		onConfirm:   onConfirm,
+		onCancel:    onCancel,
+	})
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestValidateColor(t *testing.T) {
+	tests := map[string]bool{
+		"":        true,
+		"196":     true,
+		"0":       true,
+		"#f00":    true,
+		"#FF5F87": true,
+		"256":     false,
+		"red":     false,
+		"#ff5f8":  false,
+	}
+	for color, valid := range tests {
+		if err := validateColor(color); (err == nil) != valid {
+			t.Errorf("validateColor(%q) = %v", color, err)
+		}
+	}
+}
+
+func TestConnectionEnvironmentIsSaved(t *testing.T) {
+	m := connectedModel(t, "")
+	m.screen = ScreenConnections
+
+	m = pressKey(m, "c")
+	m.newConnNameInput.SetValue("orders")
+	m.newConnStringInput.SetValue("mongodb://db1:27017")
+	m.newConnEnvInput.SetValue("prod")
+	m.newConnColorInput.SetValue("crimson")
+	m = pressKey(m, "enter")
+	if !m.newConnModal || !strings.Contains(m.newConnErr, "color must be") {
+		t.Fatalf("modal = %v, error %q", m.newConnModal, m.newConnErr)
+	}
+
+	m.newConnColorInput.SetValue("#dc143c")
+	m = pressKey(m, "enter")
+	if m.newConnModal {
+		t.Fatalf("modal still open: %q", m.newConnErr)
+	}
+	saved, err := loadConnections()
+	if err != nil || len(saved) != 1 || saved[0].Environment != "prod" || saved[0].Color != "#dc143c" {
+		t.Fatalf("saved %+v, %v", saved, err)
+	}
+
+	m.connections = mergeConnections(saved)
+	m.updateFilteredConnections()
+	if view := normalizeRender(m.View()); !strings.Contains(view, "orders [prod]") {
+		t.Errorf("list doesn't show the environment:\n%s", view)
+	}
+	m.connCursor = len(m.connFiltered) - 1
+	m = pressKey(m, "enter")
+	if m.connectionEnv != "prod" || m.connectionColor != "#dc143c" {
+		t.Errorf("connected to environment %q (%q)", m.connectionEnv, m.connectionColor)
+	}
+}
+
+func TestEnvironmentBadge(t *testing.T) {
+	m := newTestModel(120, 30)
+	if strings.Contains(normalizeRender(m.View()), "STAGING") {
+		t.Fatal("badge without an environment")
+	}
+	m.connectionEnv = "staging"
+	m.statusMessage = "saved"
+	if view := normalizeRender(m.View()); !strings.Contains(view, " STAGING  saved") {
+		t.Errorf("help line lacks the badge:\n%s", view)
+	}
+}
+
+func TestProductionConfirmsTwice(t *testing.T) {
+	m := newTestModel(120, 30)
+	confirmed := 0
+	open := func(m *Model) {
+		m.openConfirm(&confirmation{
+			title:       "Drop Index",
+			message:     "Drop index status_1?",
+			severity:    SeverityDanger,
+			destructive: true,
+			onConfirm: func(m *Model) tea.Cmd {
+				confirmed++
+				return nil
+			},
+		})
+	}
+
+	open(&m)
+	m = pressKey(m, "enter")
+	if confirmed != 1 || m.confirm != nil {
+		t.Fatalf("dev connection: confirmed %d, confirm = %+v", confirmed, m.confirm)
+	}
+
+	m.connectionEnv = "prod"
+	open(&m)
+	m = pressKey(m, "enter")
+	if confirmed != 1 || m.confirm == nil || m.confirm.title != "DROP INDEX ON PROD" || m.confirm.requireText != "prod" {
+		t.Fatalf("prod connection: confirmed %d, confirm = %+v", confirmed, m.confirm)
+	}
+	m = pressKey(m, "enter")
+	if confirmed != 1 {
+		t.Fatal("confirmed without typing the environment")
+	}
+	m.confirmInput.SetValue("prod")
+	m = pressKey(m, "enter")
+	if confirmed != 2 || m.confirm != nil {
+		t.Errorf("confirmed %d, confirm = %+v", confirmed, m.confirm)
+	}
+}
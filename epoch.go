@@ -0,0 +1,129 @@
+package main
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// defaultEpochFieldPattern matches the names of fields that usually hold
+// timestamps: createdAt, updated_at, ts, expires, lastLoginTime...
+const defaultEpochFieldPattern = `(?i)(at|time|ts|date|stamp|epoch|expires?|created|updated|modified|deleted|seen|login)$`
+
+// epochAnnotationStyle dims the decoded date after the raw number
+var epochAnnotationStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+// epochDetector decides which numbers are epoch timestamps: the field name
+// must match pattern, and the value must fall between From and To read as
+// seconds or as milliseconds
+type epochDetector struct {
+	pattern  *regexp.Regexp
+	From, To time.Time
+}
+
+// epochDetectorFromEnv builds the detector, taking the field pattern from
+// EPOCH_FIELD_PATTERN and the plausible years from EPOCH_MIN_YEAR and
+// EPOCH_MAX_YEAR (2000 to 2100 by default)
+func epochDetectorFromEnv() epochDetector {
+	d := epochDetector{
+		pattern: regexp.MustCompile(defaultEpochFieldPattern),
+		From:    time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:      time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if pattern, err := regexp.Compile(os.Getenv("EPOCH_FIELD_PATTERN")); err == nil && pattern.String() != "" {
+		d.pattern = pattern
+	}
+	if year, err := strconv.Atoi(os.Getenv("EPOCH_MIN_YEAR")); err == nil {
+		d.From = time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	if year, err := strconv.Atoi(os.Getenv("EPOCH_MAX_YEAR")); err == nil {
+		d.To = time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return d
+}
+
+// decode returns the time a number of the named field stands for. Read as
+// seconds and as milliseconds, a plausible span of years gives ranges a
+// thousandfold apart, so the unit follows from the magnitude.
+func (d epochDetector) decode(field string, value interface{}) (time.Time, bool) {
+	var n float64
+	switch v := value.(type) {
+	case int32:
+		n = float64(v)
+	case int64:
+		n = float64(v)
+	case int:
+		n = float64(v)
+	case float64:
+		n = v
+	default:
+		return time.Time{}, false
+	}
+	if !d.pattern.MatchString(field) {
+		return time.Time{}, false
+	}
+	from, to := float64(d.From.Unix()), float64(d.To.Unix())
+	switch {
+	case n >= from && n < to:
+		return time.UnixMilli(int64(n * 1000)).UTC(), true
+	case n >= from*1000 && n < to*1000:
+		return time.UnixMilli(int64(n)).UTC(), true
+	}
+	return time.Time{}, false
+}
+
+// epochAnnotation returns the decoded date shown after a number, or "" when
+// the number isn't taken for a timestamp or its field is suppressed
+func (m Model) epochAnnotation(node *JSONNode) string {
+	if !m.showEpochs || node.IsObject || node.IsArray || node.Parent == nil {
+		return ""
+	}
+	field := node.Key
+	if node.Parent.IsArray {
+		// Elements of an array of timestamps are named after the array
+		field = node.Parent.Key
+	}
+	if m.epochSuppressed[referencePattern(node)] {
+		return ""
+	}
+	t, ok := m.epochDetector.decode(field, node.Value)
+	if !ok {
+		return ""
+	}
+	return "(" + t.Format(time.RFC3339) + ")"
+}
+
+// toggleEpochs shows or hides the decoded dates of epoch numbers
+func (m *Model) toggleEpochs() tea.Cmd {
+	m.showEpochs = !m.showEpochs
+	if m.showEpochs {
+		return m.setStatus("decoding epoch numbers as dates • D: not for this field")
+	}
+	return m.setStatus("epoch dates hidden")
+}
+
+// toggleEpochSuppression stops or resumes decoding the field under the
+// cursor as a date, for numbers that only look like timestamps
+func (m *Model) toggleEpochSuppression() tea.Cmd {
+	node := m.nodeAtCursor()
+	if node == nil || node.Parent == nil || node.IsObject || node.IsArray || node.RawText != "" || node.Foreign {
+		return nil
+	}
+	path := referencePattern(node)
+	suppressed := !m.epochSuppressed[path]
+	if err := saveEpochSuppression(m.connectionName, m.currentNamespace(), path, suppressed); err != nil {
+		m.errorModal = true
+		m.errorMessage = "Failed to save the epoch setting: " + err.Error()
+		return nil
+	}
+	if suppressed {
+		m.epochSuppressed[path] = true
+		return m.setStatus(path + " is no longer decoded as a date")
+	}
+	delete(m.epochSuppressed, path)
+	return m.setStatus(path + " is decoded as a date again")
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestEpochDetectorDecode(t *testing.T) {
+	d := epochDetectorFromEnv()
+	tests := []struct {
+		field string
+		value interface{}
+		want  string // RFC 3339, empty when not a timestamp
+	}{
+		{"createdAt", int64(1718037543219), "2024-06-10T16:39:03Z"},
+		{"updated_at", int64(1718037543), "2024-06-10T16:39:03Z"},
+		{"ts", float64(1718037543.5), "2024-06-10T16:39:03Z"},
+		{"expires", int32(1718037543), "2024-06-10T16:39:03Z"},
+		{"count", int64(1718037543219), ""}, // Name doesn't look like a timestamp
+		{"createdAt", int64(42), ""},        // Too small for either unit
+		{"createdAt", int64(9e15), ""},      // Microseconds aren't guessed
+		{"createdAt", "1718037543219", ""},  // Strings aren't numbers
+		{"lastSeen", int64(946684799), ""},  // 1999, before the default range
+		{"lastSeen", int64(946684800000), "2000-01-01T00:00:00Z"},
+	}
+	for _, tt := range tests {
+		got, ok := d.decode(tt.field, tt.value)
+		switch {
+		case tt.want == "" && ok:
+			t.Errorf("decode(%q, %v) = %v, want no date", tt.field, tt.value, got)
+		case tt.want != "" && (!ok || got.Format(time.RFC3339) != tt.want):
+			t.Errorf("decode(%q, %v) = %v, %v, want %s", tt.field, tt.value, got, ok, tt.want)
+		}
+	}
+}
+
+func TestEpochDetectorFromEnv(t *testing.T) {
+	t.Setenv("EPOCH_FIELD_PATTERN", "^when$")
+	t.Setenv("EPOCH_MIN_YEAR", "1990")
+	d := epochDetectorFromEnv()
+	if _, ok := d.decode("createdAt", int64(1718037543)); ok {
+		t.Error("the default pattern still applies")
+	}
+	if got, ok := d.decode("when", int64(946684799)); !ok || got.Year() != 1999 {
+		t.Errorf("decode of 1999 with EPOCH_MIN_YEAR=1990 = %v, %v", got, ok)
+	}
+}
+
+func TestEpochAnnotation(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.documents = []bson.M{{"_id": int32(1), "createdAt": int64(1718037543219), "events": bson.A{int64(1718037543)}}}
+	m.docTree = []*JSONNode{buildJSONTree(m.documents[0], 0)}
+	setCollapsedRecursive(m.docTree[0], false)
+	m.rebuildFlattenedTree()
+	m.docCursor = 2 // _id, then createdAt
+
+	line := func(row int) string { return normalizeRender(m.renderNode(m.flattenedTree[row], 100)) }
+	if strings.Contains(line(2), "(") {
+		t.Fatalf("annotated with the mode off: %q", line(2))
+	}
+
+	m = pressKey(m, "d")
+	if got := line(2); !strings.HasSuffix(got, `"createdAt": 1718037543219  (2024-06-10T16:39:03Z)`) {
+		t.Errorf("createdAt line = %q", got)
+	}
+	if got := line(4); !strings.HasSuffix(got, "[0]: 1718037543  (2024-06-10T16:39:03Z)") {
+		t.Errorf("array element line = %q", got)
+	}
+	if got := rawValueString(m.nodeAtCursor().Value); got != "1718037543219" {
+		t.Errorf("copied value = %q, want the raw number", got)
+	}
+
+	m = pressKey(m, "D")
+	if got := line(2); strings.Contains(got, "(") || !m.epochSuppressed["createdAt"] {
+		t.Errorf("suppressed createdAt still annotated: %q", got)
+	}
+	m = pressKey(m, "D")
+	if got := line(2); !strings.Contains(got, "(2024-06-10T16:39:03Z)") {
+		t.Errorf("createdAt not annotated after resuming: %q", got)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// documentKey identifies a document by its _id for state kept across
+// reloads. The type is part of the key since 1 and "1" are different _ids.
+func documentKey(id interface{}) string {
+	return fmt.Sprintf("%T:%v", id, id)
+}
+
+// nodePath returns the field path of node within its document, "" for roots
+func nodePath(node *JSONNode) string {
+	return formatFieldPath(nodePathSegments(node))
+}
+
+// collectCollapsed records whether each object and array of a document tree
+// is collapsed, by field path. Grafted referenced documents are skipped.
+func collectCollapsed(node *JSONNode, state map[string]bool) {
+	if node.Foreign || !(node.IsObject || node.IsArray) {
+		return
+	}
+	state[nodePath(node)] = node.Collapsed
+	for _, child := range node.Children {
+		collectCollapsed(child, state)
+	}
+}
+
+// applyCollapsed restores the recorded collapsed state of a rebuilt tree
+func applyCollapsed(node *JSONNode, state map[string]bool) {
+	if !(node.IsObject || node.IsArray) {
+		return
+	}
+	if collapsed, ok := state[nodePath(node)]; ok {
+		node.Collapsed = collapsed
+	}
+	for _, child := range node.Children {
+		applyCollapsed(child, state)
+	}
+}
+
+// findNodeByPath returns the node at a field path of a document tree, or nil
+func findNodeByPath(root *JSONNode, path string) *JSONNode {
+	if nodePath(root) == path {
+		return root
+	}
+	for _, child := range root.Children {
+		if node := findNodeByPath(child, path); node != nil {
+			return node
+		}
+	}
+	return nil
+}
+
+// saveExpandState remembers how the loaded documents are expanded, so the
+// next time they are shown they come back the same
+func (m *Model) saveExpandState() {
+	for i, root := range m.docTree {
+		if i >= len(m.documents) || m.documents[i]["_id"] == nil {
+			continue
+		}
+		state := map[string]bool{}
+		collectCollapsed(root, state)
+		m.expandState[documentKey(m.documents[i]["_id"])] = state
+	}
+}
+
+// buildDocumentTree builds the tree of a loaded document with its root
+// expanded, or expanded the way it was last shown
+func (m *Model) buildDocumentTree(doc bson.M) *JSONNode {
+	return m.restoreExpandState(newDocumentTree(doc, treeBuildBudget))
+}
+
+// restoreExpandState expands a freshly built document tree the way the
+// document was last shown
+func (m *Model) restoreExpandState(root *JSONNode) *JSONNode {
+	doc, _ := root.Value.(bson.M)
+	if state, ok := m.expandState[documentKey(doc["_id"])]; ok && !root.RawOnly {
+		applyCollapsed(root, state)
+	}
+	return root
+}
+
+// cursorPath returns the _id of the document under the cursor and the path
+// of the node within it. Inside a grafted referenced document it's the path
+// of the reference field.
+func (m Model) cursorPath() (interface{}, string) {
+	node := m.nodeAtCursor()
+	if node == nil || node.Depth < 0 || node.RawText != "" || node.More > 0 {
+		return m.documentIDAtCursor(), ""
+	}
+	for node.Foreign && node.Parent != nil {
+		node = node.Parent
+	}
+	return m.documentIDAtCursor(), nodePath(node)
+}
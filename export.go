@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// newExportPathInput creates the textinput for the export file path
+func newExportPathInput() textinput.Model {
+	ti := textinput.New()
+	ti.CharLimit = 300
+	ti.Width = 50
+	return ti
+}
+
+// exportScopes are the scopes the export prompt offers
+var exportScopes = []BulkScope{ScopePage, ScopePinned, ScopeFilter}
+
+// openExportPrompt opens the export prompt with a default file name
+func (m *Model) openExportPrompt() tea.Cmd {
+	m.exportPromptActive = true
+	m.exportScope = ScopeFilter
+	name := fmt.Sprintf("%s-%s.ndjson", m.selectedCollection, time.Now().Format("20060102-150405"))
+	m.exportPathInput.SetValue(name)
+	m.exportPathInput.CursorEnd()
+	m.exportPathInput.Focus()
+	return textinput.Blink
+}
+
+// handleExportPromptKey handles keyboard input in the export prompt
+func (m *Model) handleExportPromptKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "ctrl+g":
+		m.exportPromptActive = false
+		m.exportPathInput.Blur()
+		return nil
+	case "tab":
+		m.exportScope = cycleScope(exportScopes, m.exportScope, 1)
+		return nil
+	case "shift+tab":
+		m.exportScope = cycleScope(exportScopes, m.exportScope, -1)
+		return nil
+	case "enter":
+		path := strings.TrimSpace(m.exportPathInput.Value())
+		if path == "" {
+			return nil
+		}
+		target, err := m.bulkTarget(m.exportScope)
+		if err != nil {
+			return m.setStatus(err.Error())
+		}
+		m.exportPromptActive = false
+		m.exportPathInput.Blur()
+		path = expandTilde(path)
+
+		client, dbName, collName := m.client, m.selectedDatabase, m.selectedCollection
+		return m.startBulkJob("export "+namespaceOf(dbName, collName), target, func(ctx context.Context, report func(string)) (tea.Msg, error) {
+			return runExport(ctx, report, client, dbName, collName, target.filter, target.docs, path)
+		})
+	default:
+		var cmd tea.Cmd
+		m.exportPathInput, cmd = m.exportPathInput.Update(msg)
+		return cmd
+	}
+}
+
+// runExport is the job that writes documents to path as Extended JSON.
+// When docs is nil the full result set for filter is re-queried with a cursor.
+// Files ending in .json are written as a JSON array, anything else as NDJSON.
+func runExport(ctx context.Context, report func(string), client *mongo.Client, dbName, collName string, filter bson.M, docs []bson.M, path string) (tea.Msg, error) {
+	count, err := exportDocuments(ctx, report, client, dbName, collName, filter, docs, path)
+	if err != nil {
+		return nil, fmt.Errorf("writing %s stopped after %d documents: %w", path, count, err)
+	}
+	var size int64
+	if info, err := os.Stat(path); err == nil {
+		size = info.Size()
+	}
+	return exportDoneMsg{path: path, count: count, size: size}, nil
+}
+
+// exportDocuments does the actual writing for runExport and returns the number of documents written
+func exportDocuments(ctx context.Context, report func(string), client *mongo.Client, dbName, collName string, filter bson.M, docs []bson.M, path string) (int, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return 0, err
+		}
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	w := bufio.NewWriter(file)
+
+	asArray := strings.EqualFold(filepath.Ext(path), ".json")
+	if asArray {
+		w.WriteString("[\n")
+	}
+
+	count := 0
+	write := func(doc bson.M) error {
+		jsonBytes, err := bson.MarshalExtJSON(doc, false, false)
+		if err != nil {
+			return err
+		}
+		if asArray && count > 0 {
+			w.WriteString(",\n")
+		}
+		w.Write(jsonBytes)
+		if !asArray {
+			w.WriteByte('\n')
+		}
+		count++
+		report(fmt.Sprintf("%d documents", count))
+		return nil
+	}
+
+	if docs != nil {
+		for _, doc := range docs {
+			if err := ctx.Err(); err != nil {
+				return count, err
+			}
+			if err := write(doc); err != nil {
+				return count, err
+			}
+		}
+	} else {
+		if filter == nil {
+			filter = bson.M{}
+		}
+		cursor, err := client.Database(dbName).Collection(collName).Find(ctx, filter)
+		if err != nil {
+			return 0, err
+		}
+		defer cursor.Close(ctx)
+		for cursor.Next(ctx) {
+			var doc bson.M
+			if err := cursor.Decode(&doc); err != nil {
+				return count, err
+			}
+			if err := write(doc); err != nil {
+				return count, err
+			}
+		}
+		if err := cursor.Err(); err != nil {
+			return count, err
+		}
+	}
+
+	if asArray {
+		w.WriteString("\n]\n")
+	}
+	if err := w.Flush(); err != nil {
+		return count, err
+	}
+	return count, file.Close()
+}
+
+// renderExportPrompt renders the export prompt modal
+func (m Model) renderExportPrompt(background string) string {
+	width := m.modalWidth(70)
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render("Export "+m.selectedCollection),
+		"",
+		"File path (.json = array, otherwise NDJSON):",
+		fitInput(m.exportPathInput, width-4),
+		"",
+		m.renderScopeChoice(exportScopes, m.exportScope, width-4),
+		"",
+		hintStyle.Render("tab: change scope • enter: export • esc: cancel"),
+	)
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("205")).
+		Padding(1, 2).
+		Width(width)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(content),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
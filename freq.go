@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxFrequencyValues is the number of most frequent values listed
+const maxFrequencyValues = 50
+
+// frequencyMaxTime bounds the value frequency aggregation on the server
+const frequencyMaxTime = 10 * time.Second
+
+// valueCount is one row of the value frequency list
+type valueCount struct {
+	Value interface{}
+	Count int64
+}
+
+// frequencyPipeline builds the aggregation counting the values of field among
+// the documents matching filter. Every prefix of the path is unwound so
+// values inside arrays, including arrays of subdocuments, count individually.
+func frequencyPipeline(field string, filter bson.M) mongo.Pipeline {
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: filter}}}
+	segments := strings.Split(field, ".")
+	for i := range segments {
+		path := "$" + strings.Join(segments[:i+1], ".")
+		pipeline = append(pipeline, bson.D{{Key: "$unwind", Value: bson.M{"path": path, "preserveNullAndEmptyArrays": true}}})
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$facet", Value: bson.M{
+		"top": bson.A{
+			bson.M{"$sortByCount": "$" + field},
+			bson.M{"$limit": maxFrequencyValues},
+		},
+		"total": bson.A{bson.M{"$count": "n"}},
+	}}})
+	return pipeline
+}
+
+// countValues runs the value frequency aggregation for field
+func countValues(client *mongo.Client, dbName, collName, field string, filter bson.M) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), frequencyMaxTime+5*time.Second)
+		defer cancel()
+
+		opts := options.Aggregate().SetMaxTime(frequencyMaxTime)
+		cursor, err := client.Database(dbName).Collection(collName).Aggregate(ctx, frequencyPipeline(field, filter), opts)
+		if err != nil {
+			return valueCountsMsg{field: field, err: err}
+		}
+		defer cursor.Close(ctx)
+
+		var result []struct {
+			Top []struct {
+				ID    interface{} `bson:"_id"`
+				Count int64       `bson:"count"`
+			} `bson:"top"`
+			Total []struct {
+				N int64 `bson:"n"`
+			} `bson:"total"`
+		}
+		if err := cursor.All(ctx, &result); err != nil {
+			return valueCountsMsg{field: field, err: err}
+		}
+
+		msg := valueCountsMsg{field: field}
+		if len(result) > 0 {
+			for _, row := range result[0].Top {
+				msg.values = append(msg.values, valueCount{Value: row.ID, Count: row.Count})
+			}
+			if len(result[0].Total) > 0 {
+				msg.total = result[0].Total[0].N
+			}
+		}
+		return msg
+	}
+}
+
+// openValueFrequency counts the values of the field under the cursor among
+// the documents matching the current filter
+func (m *Model) openValueFrequency() tea.Cmd {
+	node := m.nodeAtCursor()
+	if node == nil || node.Parent == nil || node.IsObject || node.IsArray || node.Foreign || node.RawText != "" || node.More > 0 || m.schemaActive {
+		return nil
+	}
+	field := referencePattern(node)
+	if strings.Contains(field, "[") {
+		return m.setStatus("can't count values of a field whose name contains a dot")
+	}
+	if cmd := m.unsupported(FeatureFacet); cmd != nil {
+		return cmd
+	}
+
+	m.freqActive = true
+	m.freqLoading = true
+	m.freqField = field
+	m.freqValues = nil
+	m.freqTotal = 0
+	m.freqCursor = 0
+	m.freqScroll = 0
+	return countValues(m.client, m.selectedDatabase, m.selectedCollection, field, m.queryFilter)
+}
+
+// handleValueCounts shows the result of the value frequency aggregation
+func (m *Model) handleValueCounts(msg valueCountsMsg) {
+	if !m.freqActive || msg.field != m.freqField {
+		return // Closed or replaced while counting
+	}
+	m.freqLoading = false
+	if msg.err != nil {
+		m.freqActive = false
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Counting values of %s failed: %v", msg.field, msg.err)
+		return
+	}
+	m.freqValues = msg.values
+	m.freqTotal = msg.total
+}
+
+// getFrequencyListHeight returns the number of value rows visible in the overlay
+func (m Model) getFrequencyListHeight() int {
+	// Border (2) + padding (2) + title and blank (2) + blank and hint (2)
+	height := m.height - 4 - 8
+	if height < 3 {
+		height = 3
+	}
+	return height
+}
+
+// moveFrequencyCursor moves the overlay cursor, scrolling to keep it visible
+func (m *Model) moveFrequencyCursor(target int) {
+	visible := m.getFrequencyListHeight()
+	m.freqCursor = clampIndex(target, len(m.freqValues))
+	if m.freqCursor < m.freqScroll {
+		m.freqScroll = m.freqCursor
+	} else if m.freqCursor >= m.freqScroll+visible {
+		m.freqScroll = m.freqCursor - visible + 1
+	}
+}
+
+// handleFrequencyKey handles keyboard input while the value frequency overlay is open
+func (m *Model) handleFrequencyKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "ctrl+g", "q":
+		m.freqActive = false
+	case "up", "k", "ctrl+p":
+		m.moveFrequencyCursor(m.freqCursor - 1)
+	case "down", "j", "ctrl+n":
+		m.moveFrequencyCursor(m.freqCursor + 1)
+	case "pgup", "pgdown", "home", "end":
+		target, _ := pageKeyTarget(msg.String(), m.freqCursor, m.getFrequencyListHeight(), len(m.freqValues))
+		m.moveFrequencyCursor(target)
+	case "enter":
+		if m.freqCursor < len(m.freqValues) {
+			m.freqActive = false
+			return m.addFilterCondition(m.freqField, m.freqValues[m.freqCursor].Value)
+		}
+	}
+	return nil
+}
+
+// addFilterCondition adds {field: value} to the current filter and reruns it
+func (m *Model) addFilterCondition(field string, value interface{}) tea.Cmd {
+	filter := bson.M{}
+	for k, v := range m.queryFilter {
+		filter[k] = v
+	}
+	filter[field] = value
+	m.queryFilter = filter
+	if text, err := bson.MarshalExtJSON(filter, false, false); err == nil {
+		m.queryText = string(text)
+		m.queryCursor = len(m.queryText)
+	}
+
+	m.pinboardActive = false
+	m.queryLoading = true
+	m.currentPage = 0
+	m.docCursor = 0
+	m.docScrollOffset = 0
+	return loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, 0, m.docsPerPage, filter, m.sortSpec())
+}
+
+// renderFrequencyOverlay renders the value frequency list modal
+func (m Model) renderFrequencyOverlay(background string) string {
+	width := m.width - 10
+	if width > 90 {
+		width = 90
+	}
+	if width < 30 {
+		width = 30
+	}
+	contentWidth := width - 4
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+
+	title := "Values of " + m.freqField
+	var lines []string
+	switch {
+	case m.freqLoading:
+		lines = append(lines, normalStyle.Render("Counting..."))
+	case len(m.freqValues) == 0:
+		lines = append(lines, normalStyle.Render("(no values)"))
+	default:
+		title += fmt.Sprintf(" (top %d of %d values)", len(m.freqValues), m.freqTotal)
+		end := m.freqScroll + m.getFrequencyListHeight()
+		if end > len(m.freqValues) {
+			end = len(m.freqValues)
+		}
+		for i := m.freqScroll; i < end; i++ {
+			row := m.freqValues[i]
+			stats := fmt.Sprintf("%8d %7s", row.Count, formatPercent(int(row.Count), int(m.freqTotal)))
+			value := truncate(strings.ReplaceAll(rawValueString(row.Value), "\n", " "), contentWidth-len(stats)-2)
+			line := fmt.Sprintf("%-*s  %s", contentWidth-len(stats)-2, value, stats)
+			if i == m.freqCursor {
+				line = selectedStyle.Render(line)
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render(title),
+		"",
+		strings.Join(lines, "\n"),
+		"",
+		hintStyle.Render("enter: add to filter • ↑/↓: select • esc: close"),
+	)
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("205")).
+		Padding(1, 2).
+		Width(width)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(content),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
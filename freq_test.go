@@ -0,0 +1,20 @@
+package main
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestFrequencyPipelineUnwindsEveryPrefix(t *testing.T) {
+	pipeline := frequencyPipeline("items.sku", bson.M{"status": "paid"})
+	if len(pipeline) != 4 {
+		t.Fatalf("pipeline has %d stages, want $match, 2×$unwind, $facet", len(pipeline))
+	}
+	for i, path := range []string{"$items", "$items.sku"} {
+		unwind := pipeline[i+1][0]
+		if unwind.Key != "$unwind" || unwind.Value.(bson.M)["path"] != path {
+			t.Errorf("stage %d = %v, want $unwind of %s", i+1, unwind, path)
+		}
+	}
+}
@@ -0,0 +1,425 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// defaultTemplate seeds the generator for collections without a saved template
+const defaultTemplate = `{"name": "{{name}}", "score": "{{int 1 100}}", "createdAt": "{{date -30d now}}"}`
+
+// defaultGenerateCount is the count the generator prompt starts with
+const defaultGenerateCount = 50
+
+// maxGenerateCount guards against a mistyped count flooding the collection
+const maxGenerateCount = 100000
+
+// placeholderPattern matches a {{token args...}} placeholder
+var placeholderPattern = regexp.MustCompile(`\{\{\s*(.*?)\s*\}\}`)
+
+// placeholderHelp lists the placeholders in the generator prompt
+const placeholderHelp = `{{objectId}} {{int 1 100}} {{date -30d now}} {{oneOf "a" "b"}} {{name}} {{index}}`
+
+var (
+	templateFirstNames = []string{"Ada", "Alan", "Grace", "Linus", "Margaret", "Ken", "Barbara", "Dennis", "Frances", "Edsger", "Radia", "Donald"}
+	templateLastNames  = []string{"Lovelace", "Turing", "Hopper", "Torvalds", "Hamilton", "Thompson", "Liskov", "Ritchie", "Allen", "Dijkstra", "Perlman", "Knuth"}
+)
+
+// templateExpander fills in the placeholders of a template. Randomness and
+// the current time are fixed per expander so tests can reproduce them.
+type templateExpander struct {
+	rng *rand.Rand
+	now time.Time
+}
+
+// newTemplateExpander returns an expander seeded from the clock
+func newTemplateExpander() templateExpander {
+	now := time.Now()
+	return templateExpander{rng: rand.New(rand.NewSource(now.UnixNano())), now: now}
+}
+
+// parseTemplate parses a template document, keeping its field order. It
+// accepts the same relaxed syntax as the query panel.
+func parseTemplate(text string) (bson.D, error) {
+	var tmpl bson.D
+	err := bson.UnmarshalExtJSON([]byte(relaxedJSONToStrict(expandShellHelpers(text))), false, &tmpl)
+	return tmpl, err
+}
+
+// expandDocument returns the document for position index of the batch
+func (e templateExpander) expandDocument(tmpl bson.D, index int) (bson.D, error) {
+	value, err := e.expandValue(tmpl, index)
+	if err != nil {
+		return nil, err
+	}
+	return value.(bson.D), nil
+}
+
+// expandValue expands the placeholders in every string below value
+func (e templateExpander) expandValue(value interface{}, index int) (interface{}, error) {
+	switch v := value.(type) {
+	case bson.D:
+		doc := make(bson.D, len(v))
+		for i, field := range v {
+			expanded, err := e.expandValue(field.Value, index)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", field.Key, err)
+			}
+			doc[i] = bson.E{Key: field.Key, Value: expanded}
+		}
+		return doc, nil
+	case bson.A:
+		arr := make(bson.A, len(v))
+		for i, item := range v {
+			expanded, err := e.expandValue(item, index)
+			if err != nil {
+				return nil, err
+			}
+			arr[i] = expanded
+		}
+		return arr, nil
+	case string:
+		return e.expandString(v, index)
+	}
+	return value, nil
+}
+
+// expandString expands the placeholders of a string. A string that is one
+// placeholder becomes its typed value, so "{{int 1 9}}" is a number; inside
+// longer text each placeholder is replaced by its text.
+func (e templateExpander) expandString(s string, index int) (interface{}, error) {
+	matches := placeholderPattern.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s, nil
+	}
+	if len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(s) {
+		return e.placeholder(s[matches[0][2]:matches[0][3]], index)
+	}
+	var b strings.Builder
+	last := 0
+	for _, match := range matches {
+		value, err := e.placeholder(s[match[2]:match[3]], index)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(s[last:match[0]])
+		b.WriteString(placeholderText(value))
+		last = match[1]
+	}
+	b.WriteString(s[last:])
+	return b.String(), nil
+}
+
+// placeholderText renders a placeholder value inside a longer string
+func placeholderText(value interface{}) string {
+	switch v := value.(type) {
+	case primitive.ObjectID:
+		return v.Hex()
+	case primitive.DateTime:
+		return v.Time().UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprint(value)
+}
+
+// placeholder returns the value of one placeholder, given without braces
+func (e templateExpander) placeholder(expr string, index int) (interface{}, error) {
+	args, err := splitPlaceholderArgs(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty placeholder {{}}")
+	}
+	name, args := args[0], args[1:]
+	switch name {
+	case "objectId":
+		return primitive.NewObjectIDFromTimestamp(e.now), nil
+	case "index":
+		return int32(index), nil
+	case "name":
+		first := templateFirstNames[e.rng.Intn(len(templateFirstNames))]
+		last := templateLastNames[e.rng.Intn(len(templateLastNames))]
+		return first + " " + last, nil
+	case "int":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("{{int}} takes a minimum and a maximum, e.g. {{int 1 100}}")
+		}
+		lo, err1 := strconv.ParseInt(args[0], 10, 64)
+		hi, err2 := strconv.ParseInt(args[1], 10, 64)
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("{{int %s %s}}: bounds must be integers", args[0], args[1])
+		}
+		if hi < lo {
+			lo, hi = hi, lo
+		}
+		n := lo + e.rng.Int63n(hi-lo+1)
+		if n >= -1<<31 && n < 1<<31 {
+			return int32(n), nil
+		}
+		return n, nil
+	case "date":
+		if len(args) == 0 {
+			return primitive.NewDateTimeFromTime(e.now), nil
+		}
+		if len(args) != 2 {
+			return nil, fmt.Errorf("{{date}} takes a start and an end, e.g. {{date -30d now}}")
+		}
+		from, err := parseDateSpec(args[0], e.now)
+		if err != nil {
+			return nil, err
+		}
+		to, err := parseDateSpec(args[1], e.now)
+		if err != nil {
+			return nil, err
+		}
+		if to.Before(from) {
+			from, to = to, from
+		}
+		span := to.Sub(from).Milliseconds()
+		return primitive.NewDateTimeFromTime(from.Add(time.Duration(e.rng.Int63n(span+1)) * time.Millisecond)), nil
+	case "oneOf":
+		if len(args) == 0 {
+			return nil, fmt.Errorf(`{{oneOf}} takes the values to pick from, e.g. {{oneOf "a" "b"}}`)
+		}
+		return args[e.rng.Intn(len(args))], nil
+	}
+	return nil, fmt.Errorf("unknown placeholder {{%s}}", name)
+}
+
+// splitPlaceholderArgs splits a placeholder into its name and arguments at
+// spaces, keeping double-quoted arguments whole
+func splitPlaceholderArgs(expr string) ([]string, error) {
+	var args []string
+	for expr = strings.TrimSpace(expr); expr != ""; expr = strings.TrimSpace(expr) {
+		if expr[0] != '"' {
+			end := strings.IndexAny(expr, " \t")
+			if end < 0 {
+				end = len(expr)
+			}
+			args = append(args, expr[:end])
+			expr = expr[end:]
+			continue
+		}
+		quoted, err := strconv.QuotedPrefix(expr)
+		if err != nil {
+			return nil, fmt.Errorf("unterminated string in {{%s}}", expr)
+		}
+		arg, _ := strconv.Unquote(quoted)
+		args = append(args, arg)
+		expr = expr[len(quoted):]
+	}
+	return args, nil
+}
+
+// parseDateSpec parses a {{date}} bound: "now", an offset from now such as
+// -30d, +2h or -1w, or an absolute date such as 2024-01-31
+func parseDateSpec(spec string, now time.Time) (time.Time, error) {
+	if spec == "now" {
+		return now, nil
+	}
+	if t, err := time.Parse(time.RFC3339, spec); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", spec); err == nil {
+		return t, nil
+	}
+	units := map[byte]time.Duration{'s': time.Second, 'm': time.Minute, 'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	if len(spec) >= 3 && (spec[0] == '-' || spec[0] == '+') {
+		if unit, ok := units[spec[len(spec)-1]]; ok {
+			if n, err := strconv.Atoi(spec[:len(spec)-1]); err == nil {
+				return now.Add(time.Duration(n) * unit), nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("bad date %q: use now, an offset like -30d, or 2024-01-31", spec)
+}
+
+// newGenerateInputs creates the template and count inputs of the generator
+func newGenerateInputs() (textinput.Model, textinput.Model) {
+	template := textinput.New()
+	template.CharLimit = 4000
+	template.Width = 60
+	count := textinput.New()
+	count.CharLimit = 6
+	count.Width = 10
+	return template, count
+}
+
+// openGeneratePrompt opens the test data generator for the collection under
+// the cursor, starting from its saved template
+func (m *Model) openGeneratePrompt() tea.Cmd {
+	if len(m.collFiltered) == 0 || m.selectedDatabase == "" || m.client == nil {
+		return nil
+	}
+	if cmd := m.guardWritable(m.collFiltered[m.collCursor]); cmd != nil {
+		return cmd
+	}
+	m.generatePromptActive = true
+	m.generateCollection = m.collFiltered[m.collCursor]
+	m.generateErr = ""
+	tmpl, err := loadTemplate(m.connectionName, namespaceOf(m.selectedDatabase, m.generateCollection))
+	if err != nil || tmpl == "" {
+		tmpl = defaultTemplate
+	}
+	m.generateTemplateInput.SetValue(tmpl)
+	m.generateTemplateInput.CursorEnd()
+	m.generateTemplateInput.Focus()
+	m.generateCountInput.SetValue(strconv.Itoa(defaultGenerateCount))
+	m.generateCountInput.Blur()
+	m.generateCountFocused = false
+	return textinput.Blink
+}
+
+// handleGeneratePromptKey handles keyboard input in the generator prompt
+func (m *Model) handleGeneratePromptKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "ctrl+g":
+		m.generatePromptActive = false
+		m.generateTemplateInput.Blur()
+		m.generateCountInput.Blur()
+		return nil
+	case "tab", "shift+tab":
+		m.generateCountFocused = !m.generateCountFocused
+		if m.generateCountFocused {
+			m.generateTemplateInput.Blur()
+			m.generateCountInput.Focus()
+		} else {
+			m.generateCountInput.Blur()
+			m.generateTemplateInput.Focus()
+		}
+		return textinput.Blink
+	case "enter":
+		return m.startGenerate()
+	}
+	var cmd tea.Cmd
+	if m.generateCountFocused {
+		m.generateCountInput, cmd = m.generateCountInput.Update(msg)
+	} else {
+		m.generateTemplateInput, cmd = m.generateTemplateInput.Update(msg)
+	}
+	m.generateErr = ""
+	return cmd
+}
+
+// startGenerate checks the template by expanding it once, saves it for the
+// collection and starts inserting the documents as a background job
+func (m *Model) startGenerate() tea.Cmd {
+	text := strings.TrimSpace(m.generateTemplateInput.Value())
+	tmpl, err := parseTemplate(text)
+	if err != nil {
+		m.generateErr = "template: " + err.Error()
+		return nil
+	}
+	expander := newTemplateExpander()
+	if _, err := expander.expandDocument(tmpl, 0); err != nil {
+		m.generateErr = err.Error()
+		return nil
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(m.generateCountInput.Value()))
+	if err != nil || count < 1 || count > maxGenerateCount {
+		m.generateErr = fmt.Sprintf("count must be between 1 and %d", maxGenerateCount)
+		return nil
+	}
+
+	ns := namespaceOf(m.selectedDatabase, m.generateCollection)
+	if err := saveTemplate(m.connectionName, ns, text); err != nil {
+		m.errorModal = true
+		m.errorMessage = "Failed to save the template: " + err.Error()
+		return nil
+	}
+	m.generatePromptActive = false
+	m.generateTemplateInput.Blur()
+	m.generateCountInput.Blur()
+
+	coll := m.client.Database(m.selectedDatabase).Collection(m.generateCollection)
+	return m.startJob(fmt.Sprintf("generate %d into %s", count, ns), func(ctx context.Context, report func(string)) (tea.Msg, error) {
+		return runGenerate(ctx, report, coll, ns, tmpl, count, expander)
+	})
+}
+
+// runGenerate is the job that expands the template count times and inserts
+// the documents in batches, reporting like an import
+func runGenerate(ctx context.Context, report func(string), coll *mongo.Collection, ns string, tmpl bson.D, count int, expander templateExpander) (tea.Msg, error) {
+	var result importResult
+	batch := make([]interface{}, 0, importBatchSize)
+	positions := make([]int, 0, importBatchSize)
+	var err error
+	for i := 0; i < count && err == nil; i++ {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		var doc bson.D
+		if doc, err = expander.expandDocument(tmpl, i); err != nil {
+			break
+		}
+		batch = append(batch, doc)
+		positions = append(positions, i+1)
+		if len(batch) == importBatchSize || i == count-1 {
+			err = insertBatch(ctx, coll, batch, positions, &result)
+			batch, positions = batch[:0], positions[:0]
+			report(fmt.Sprintf("%d/%d documents", i+1, count))
+		}
+	}
+	return importDoneMsg{
+		namespace: ns,
+		path:      "template",
+		generated: true,
+		inserted:  result.inserted,
+		failed:    result.failed,
+		errors:    result.errors,
+		err:       err,
+	}, nil
+}
+
+// renderGeneratePrompt renders the generator prompt modal
+func (m Model) renderGeneratePrompt(background string) string {
+	width := m.modalWidth(80)
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+
+	lines := []string{
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render("Generate documents into " + namespaceOf(m.selectedDatabase, m.generateCollection)),
+		"",
+		"Template (Extended JSON with placeholders):",
+		fitInput(m.generateTemplateInput, width-4),
+		hintStyle.Render(truncate(placeholderHelp, width-4)),
+		"",
+		"Count:",
+		fitInput(m.generateCountInput, width-4),
+	}
+	if m.generateErr != "" {
+		lines = append(lines, "", lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Width(width-4).Render(m.generateErr))
+	}
+	lines = append(lines, "", hintStyle.Render("tab: switch field • enter: generate • esc: cancel"))
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("205")).
+		Padding(1, 2).
+		Width(width)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var generateNow = time.Date(2024, 3, 2, 11, 45, 0, 0, time.UTC)
+
+func testExpander(seed int64) templateExpander {
+	return templateExpander{rng: rand.New(rand.NewSource(seed)), now: generateNow}
+}
+
+func TestSplitPlaceholderArgs(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"name", []string{"name"}},
+		{"  int   1  100 ", []string{"int", "1", "100"}},
+		{`oneOf "a b" "c" d`, []string{"oneOf", "a b", "c", "d"}},
+		{`oneOf "say \"hi\"" ""`, []string{"oneOf", `say "hi"`, ""}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		got, err := splitPlaceholderArgs(tt.expr)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitPlaceholderArgs(%q) = %q, %v, want %q", tt.expr, got, err, tt.want)
+		}
+	}
+	if _, err := splitPlaceholderArgs(`oneOf "open`); err == nil {
+		t.Error("unterminated string accepted")
+	}
+}
+
+func TestParseDateSpec(t *testing.T) {
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"now", generateNow},
+		{"-30d", generateNow.Add(-30 * 24 * time.Hour)},
+		{"+2h", generateNow.Add(2 * time.Hour)},
+		{"-1w", generateNow.Add(-7 * 24 * time.Hour)},
+		{"-90s", generateNow.Add(-90 * time.Second)},
+		{"2024-01-31", time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{"2024-01-31T08:00:00Z", time.Date(2024, 1, 31, 8, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseDateSpec(tt.spec, generateNow)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parseDateSpec(%q) = %v, %v, want %v", tt.spec, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"yesterday", "30d", "-d", "-3x", "2024-13-01"} {
+		if _, err := parseDateSpec(bad, generateNow); err == nil {
+			t.Errorf("parseDateSpec(%q) accepted", bad)
+		}
+	}
+}
+
+func TestPlaceholderValues(t *testing.T) {
+	e := testExpander(1)
+	for i := 0; i < 200; i++ {
+		v, err := e.placeholder("int 1 3", i)
+		if n, ok := v.(int32); err != nil || !ok || n < 1 || n > 3 {
+			t.Fatalf("{{int 1 3}} = %#v, %v", v, err)
+		}
+		v, _ = e.placeholder("int 9 7", i)
+		if n := v.(int32); n < 7 || n > 9 {
+			t.Fatalf("{{int 9 7}} = %d, want reversed bounds to work", n)
+		}
+		v, err = e.placeholder("date -30d now", i)
+		dt, ok := v.(primitive.DateTime)
+		if err != nil || !ok || dt.Time().Before(generateNow.Add(-30*24*time.Hour)) || dt.Time().After(generateNow) {
+			t.Fatalf("{{date -30d now}} = %#v, %v", v, err)
+		}
+		v, _ = e.placeholder(`oneOf "a" "b" "c"`, i)
+		if s := v.(string); s != "a" && s != "b" && s != "c" {
+			t.Fatalf("{{oneOf}} = %q", s)
+		}
+		v, _ = e.placeholder("name", i)
+		if parts := strings.Fields(v.(string)); len(parts) != 2 {
+			t.Fatalf("{{name}} = %q, want first and last name", v)
+		}
+	}
+
+	if v, _ := e.placeholder("int 5000000000 5000000000", 0); v != int64(5000000000) {
+		t.Errorf("{{int}} beyond int32 = %#v, want int64", v)
+	}
+	if v, _ := e.placeholder("index", 7); v != int32(7) {
+		t.Errorf("{{index}} = %#v, want 7", v)
+	}
+	if v, _ := e.placeholder("date", 0); v != primitive.NewDateTimeFromTime(generateNow) {
+		t.Errorf("{{date}} = %v, want now", v)
+	}
+	a, _ := e.placeholder("objectId", 0)
+	b, _ := e.placeholder("objectId", 1)
+	if a == b || a.(primitive.ObjectID).Timestamp() != generateNow {
+		t.Errorf("{{objectId}} = %v, %v: want distinct ids stamped now", a, b)
+	}
+}
+
+func TestPlaceholderErrors(t *testing.T) {
+	e := testExpander(1)
+	for expr, want := range map[string]string{
+		"":             "empty placeholder",
+		"uuid":         "unknown placeholder {{uuid}}",
+		"int 1":        "takes a minimum and a maximum",
+		"int a 2":      "must be integers",
+		"date -30d":    "takes a start and an end",
+		"date -3q now": `bad date "-3q"`,
+		"oneOf":        "takes the values",
+	} {
+		if _, err := e.placeholder(expr, 0); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("{{%s}}: error %v, want %q", expr, err, want)
+		}
+	}
+}
+
+func TestExpandDocument(t *testing.T) {
+	tmpl, err := parseTemplate(`{sku: "SKU-{{index}}-{{oneOf \"x\"}}", qty: "{{int 4 4}}", tags: ["{{oneOf \"new\"}}", 3], meta: {at: "{{date now now}}", note: "{{ index }}"}, plain: "{not a placeholder}"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := testExpander(1).expandDocument(tmpl, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := bson.D{
+		{Key: "sku", Value: "SKU-12-x"},
+		{Key: "qty", Value: int32(4)},
+		{Key: "tags", Value: bson.A{"new", int32(3)}},
+		{Key: "meta", Value: bson.D{{Key: "at", Value: primitive.NewDateTimeFromTime(generateNow)}, {Key: "note", Value: int32(12)}}},
+		{Key: "plain", Value: "{not a placeholder}"},
+	}
+	if !reflect.DeepEqual(doc, want) {
+		t.Errorf("expandDocument =\n%#v\nwant\n%#v", doc, want)
+	}
+
+	// The template itself is left untouched for the next document
+	if tmpl[0].Value != `SKU-{{index}}-{{oneOf "x"}}` {
+		t.Errorf("template modified: %v", tmpl[0].Value)
+	}
+
+	// Errors name the field they come from
+	bad, _ := parseTemplate(`{meta: {at: "{{date soon now}}"}}`)
+	if _, err := testExpander(1).expandDocument(bad, 0); err == nil || !strings.HasPrefix(err.Error(), "meta: at: ") {
+		t.Errorf("error = %v, want it prefixed with the field path", err)
+	}
+}
+
+func TestEmbeddedPlaceholderText(t *testing.T) {
+	e := testExpander(1)
+	got, err := e.expandString("created {{date now now}} by {{objectId}}", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := got.(string); !strings.HasPrefix(s, "created 2024-03-02T11:45:00Z by ") || len(s) != len("created 2024-03-02T11:45:00Z by ")+24 {
+		t.Errorf("embedded placeholders = %q", s)
+	}
+}
+
+func TestGeneratePromptRejectsBadInput(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m.focus = FocusCollections
+	m = pressKey(m, "G")
+	if !m.generatePromptActive || m.generateTemplateInput.Value() != defaultTemplate || m.generateCountInput.Value() != "50" {
+		t.Fatalf("prompt = %v, template %q, count %q", m.generatePromptActive, m.generateTemplateInput.Value(), m.generateCountInput.Value())
+	}
+
+	m.generateTemplateInput.SetValue(`{n: "{{nope}}"}`)
+	m.startGenerate()
+	if !m.generatePromptActive || !strings.Contains(m.generateErr, "unknown placeholder {{nope}}") {
+		t.Errorf("bad template: active = %v, err = %q", m.generatePromptActive, m.generateErr)
+	}
+	m.generateTemplateInput.SetValue(defaultTemplate)
+	m.generateCountInput.SetValue("0")
+	m.startGenerate()
+	if !m.generatePromptActive || !strings.Contains(m.generateErr, "count must be") {
+		t.Errorf("bad count: active = %v, err = %q", m.generatePromptActive, m.generateErr)
+	}
+	if view := normalizeRender(m.View()); !strings.Contains(view, "Generate documents into shop.orders") || !strings.Contains(view, "count must be") {
+		t.Errorf("prompt view:\n%s", view)
+	}
+}
@@ -0,0 +1,403 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxGlobalFindCollections caps how many collections one find searches
+const maxGlobalFindCollections = 50
+
+// globalFindCollectionBudget bounds the time spent counting matches in one
+// collection, on the server and on the client
+const globalFindCollectionBudget = 5 * time.Second
+
+// globalFindHit is the outcome of searching one collection
+type globalFindHit struct {
+	Collection string
+	Count      int64
+	Filter     bson.M
+	Err        error // Set when the count failed or ran out of time
+}
+
+// newFindInputs creates the value and field list inputs of the find prompt
+func newFindInputs() (textinput.Model, textinput.Model) {
+	value := textinput.New()
+	value.Placeholder = "string, ObjectId or number"
+	value.CharLimit = 200
+	value.Width = 40
+
+	fields := textinput.New()
+	fields.Placeholder = "comma-separated, besides _id and indexed fields"
+	fields.CharLimit = 200
+	fields.Width = 40
+	return value, fields
+}
+
+// findCandidates returns the values text may stand for: the string itself,
+// plus the ObjectId or number it spells
+func findCandidates(text string) bson.A {
+	candidates := bson.A{text}
+	if id, err := primitive.ObjectIDFromHex(text); err == nil {
+		candidates = append(candidates, id)
+	}
+	if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+		candidates = append(candidates, n)
+	} else if f, err := strconv.ParseFloat(text, 64); err == nil {
+		candidates = append(candidates, f)
+	}
+	return candidates
+}
+
+// findFields parses the field list of the find prompt, always starting with _id
+func findFields(text string) []string {
+	fields := []string{"_id"}
+	seen := map[string]bool{"_id": true}
+	for _, field := range strings.Split(text, ",") {
+		field = strings.TrimSpace(field)
+		if field != "" && !seen[field] {
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// indexedFields returns the fields of the single-field indexes of a collection
+func indexedFields(ctx context.Context, coll *mongo.Collection) ([]string, error) {
+	cursor, err := coll.Indexes().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var indexes []struct {
+		Key bson.D `bson:"key"`
+	}
+	if err := cursor.All(ctx, &indexes); err != nil {
+		return nil, err
+	}
+	var fields []string
+	for _, index := range indexes {
+		if len(index.Key) != 1 {
+			continue
+		}
+		switch index.Key[0].Value.(type) {
+		case int32, int64, float64:
+			fields = append(fields, index.Key[0].Key)
+		}
+	}
+	return fields, nil
+}
+
+// globalFindFilter matches documents holding one of candidates in any of fields
+func globalFindFilter(fields []string, candidates bson.A) bson.M {
+	var or bson.A
+	seen := map[string]bool{}
+	for _, field := range fields {
+		if !seen[field] {
+			seen[field] = true
+			or = append(or, bson.M{field: bson.M{"$in": candidates}})
+		}
+	}
+	if len(or) == 1 {
+		return or[0].(bson.M)
+	}
+	return bson.M{"$or": or}
+}
+
+// searchCollection counts the documents of one collection holding the value
+func searchCollection(ctx context.Context, db *mongo.Database, collName string, fields []string, candidates bson.A) globalFindHit {
+	ctx, cancel := context.WithTimeout(ctx, globalFindCollectionBudget)
+	defer cancel()
+
+	coll := db.Collection(collName)
+	hit := globalFindHit{Collection: collName}
+	indexed, err := indexedFields(ctx, coll)
+	if err != nil {
+		hit.Err = err
+		return hit
+	}
+	hit.Filter = globalFindFilter(append(fields, indexed...), candidates)
+	hit.Count, hit.Err = coll.CountDocuments(ctx, hit.Filter, options.Count().SetMaxTime(globalFindCollectionBudget))
+	return hit
+}
+
+// findCollections returns the collections of the selected database a find
+// searches, skipping system and unreadable ones, and how many were left out
+// by the cap
+func (m Model) findCollections() ([]string, int) {
+	var colls []string
+	for _, coll := range m.collections {
+		if strings.HasPrefix(coll, "system.") || m.deniedNamespaces[namespaceOf(m.selectedDatabase, coll)] {
+			continue
+		}
+		colls = append(colls, coll)
+	}
+	if len(colls) > maxGlobalFindCollections {
+		return colls[:maxGlobalFindCollections], len(colls) - maxGlobalFindCollections
+	}
+	return colls, 0
+}
+
+// openGlobalFind opens the prompt to find a value across the collections of
+// the selected database, seeded with the value under the cursor
+func (m *Model) openGlobalFind() tea.Cmd {
+	if m.client == nil || m.selectedDatabase == "" || len(m.collections) == 0 {
+		return nil
+	}
+	if node := m.nodeAtCursor(); m.focus == FocusDocuments && node != nil && node.Depth >= 0 && !node.IsObject && !node.IsArray && node.RawText == "" && node.More == 0 && !m.schemaActive {
+		m.findValueInput.SetValue(rawValueString(node.Value))
+		m.findValueInput.CursorEnd()
+	}
+	m.findPromptActive = true
+	m.findFieldsFocused = false
+	m.findFieldsInput.Blur()
+	m.findValueInput.Focus()
+	return textinput.Blink
+}
+
+// handleFindPromptKey handles keyboard input in the find prompt
+func (m *Model) handleFindPromptKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "ctrl+g":
+		m.findPromptActive = false
+		m.findValueInput.Blur()
+		m.findFieldsInput.Blur()
+		return nil
+	case "tab", "shift+tab":
+		m.findFieldsFocused = !m.findFieldsFocused
+		if m.findFieldsFocused {
+			m.findValueInput.Blur()
+			m.findFieldsInput.Focus()
+		} else {
+			m.findFieldsInput.Blur()
+			m.findValueInput.Focus()
+		}
+		return textinput.Blink
+	case "enter":
+		value := strings.TrimSpace(m.findValueInput.Value())
+		if value == "" {
+			return nil
+		}
+		m.findPromptActive = false
+		m.findValueInput.Blur()
+		m.findFieldsInput.Blur()
+		return m.startGlobalFind(value, findFields(m.findFieldsInput.Value()))
+	}
+	var cmd tea.Cmd
+	if m.findFieldsFocused {
+		m.findFieldsInput, cmd = m.findFieldsInput.Update(msg)
+	} else {
+		m.findValueInput, cmd = m.findValueInput.Update(msg)
+	}
+	return cmd
+}
+
+// startGlobalFind searches the collections one at a time in a background
+// job, which the jobs overlay can cancel between or during collections
+func (m *Model) startGlobalFind(value string, fields []string) tea.Cmd {
+	db := m.client.Database(m.selectedDatabase)
+	colls, skipped := m.findCollections()
+	candidates := findCandidates(value)
+	return m.startJob(fmt.Sprintf("find %q in %s", value, m.selectedDatabase), func(ctx context.Context, report func(string)) (tea.Msg, error) {
+		msg := globalFindMsg{value: value, dbName: db.Name(), skipped: skipped}
+		for i, coll := range colls {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			report(fmt.Sprintf("%d/%d %s", i+1, len(colls), coll))
+			hit := searchCollection(ctx, db, coll, fields, candidates)
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			msg.searched++
+			if hit.Count > 0 || hit.Err != nil {
+				msg.hits = append(msg.hits, hit)
+			}
+		}
+		return msg, nil
+	})
+}
+
+// handleGlobalFind shows the collections a find matched in
+func (m *Model) handleGlobalFind(msg globalFindMsg) tea.Cmd {
+	if msg.dbName != m.selectedDatabase {
+		return m.setStatus(fmt.Sprintf("find %q finished in %s", msg.value, msg.dbName))
+	}
+	m.findActive = true
+	m.findValue = msg.value
+	m.findHits = msg.hits
+	m.findSearched = msg.searched
+	m.findSkipped = msg.skipped
+	m.findCursor = 0
+	return nil
+}
+
+// handleFindResultsKey handles keyboard input in the find results overlay
+func (m *Model) handleFindResultsKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "ctrl+g", "q":
+		m.findActive = false
+	case "up", "k", "ctrl+p":
+		m.findCursor = clampIndex(m.findCursor-1, len(m.findHits))
+	case "down", "j", "ctrl+n":
+		m.findCursor = clampIndex(m.findCursor+1, len(m.findHits))
+	case "enter":
+		if m.findCursor < len(m.findHits) && m.findHits[m.findCursor].Err == nil {
+			m.findActive = false
+			return m.openFindHit(m.findHits[m.findCursor])
+		}
+	}
+	return nil
+}
+
+// openFindHit shows the matching documents of a collection in the documents panel
+func (m *Model) openFindHit(hit globalFindHit) tea.Cmd {
+	m.selectedCollection = hit.Collection
+	for i, coll := range m.collFiltered {
+		if coll == hit.Collection {
+			m.collCursor = i
+		}
+	}
+	m.acknowledgeWatch(m.currentNamespace())
+	m.loadCollectionSettings()
+	m.pinboardActive = false
+	m.loadingDocs = true
+	m.docScrollOffset = 0
+	m.docCursor = 0
+	m.currentPage = 0
+	m.queryFilter = hit.Filter
+	if text, err := bson.MarshalExtJSON(hit.Filter, false, false); err == nil {
+		m.queryText = string(text)
+		m.queryCursor = len(m.queryText)
+	}
+	m.focus = FocusDocuments
+	return loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, 0, m.docsPerPage, m.queryFilter, m.sortSpec())
+}
+
+// renderFindPrompt renders the global find prompt modal
+func (m Model) renderFindPrompt(background string) string {
+	width := m.modalWidth(64)
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+
+	colls, skipped := m.findCollections()
+	scope := fmt.Sprintf("Searches %d collections of %s", len(colls), m.selectedDatabase)
+	if skipped > 0 {
+		scope += fmt.Sprintf(" (%d more not searched)", skipped)
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render("Find in "+m.selectedDatabase),
+		"",
+		"Value:",
+		fitInput(m.findValueInput, width-4),
+		"",
+		"Also look in fields:",
+		fitInput(m.findFieldsInput, width-4),
+		"",
+		hintStyle.Render(truncate(scope, width-4)),
+		hintStyle.Render("tab: switch field • enter: find • esc: cancel"),
+	)
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("205")).
+		Padding(1, 2).
+		Width(width)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(content),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
+
+// renderFindResults renders the collections a find matched in
+func (m Model) renderFindResults(background string) string {
+	width := m.modalWidth(70)
+	contentWidth := width - 4
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+
+	title := fmt.Sprintf("%q in %d of %d collections", m.findValue, len(m.findHits), m.findSearched)
+	var lines []string
+	if len(m.findHits) == 0 {
+		lines = append(lines, normalStyle.Render("(not found)"))
+	}
+
+	// Show the window of collections that keeps the cursor visible
+	visible := m.height - 4 - 8
+	if visible < 3 {
+		visible = 3
+	}
+	start := 0
+	if m.findCursor >= visible {
+		start = m.findCursor - visible + 1
+	}
+	for i := start; i < len(m.findHits) && i < start+visible; i++ {
+		hit := m.findHits[i]
+		count := fmt.Sprintf("%d", hit.Count)
+		if hit.Err != nil {
+			count = "failed: " + hit.Err.Error()
+			if strings.Contains(hit.Err.Error(), "deadline") || mongo.IsTimeout(hit.Err) {
+				count = "timed out"
+			}
+		}
+		count = truncate(count, contentWidth/2)
+		line := fmt.Sprintf("%-*s  %s", contentWidth-len(count)-4, truncate(hit.Collection, contentWidth-len(count)-4), count)
+		if i == m.findCursor {
+			line = selectedStyle.Render(line)
+		} else {
+			line = normalStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	if m.findSkipped > 0 {
+		lines = append(lines, "", hintStyle.Render(fmt.Sprintf("%d collections not searched (limit %d)", m.findSkipped, maxGlobalFindCollections)))
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render(truncate(title, contentWidth)),
+		"",
+		strings.Join(lines, "\n"),
+		"",
+		hintStyle.Render("enter: show documents • ↑/↓: select • esc: close"),
+	)
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("205")).
+		Padding(1, 2).
+		Width(width)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(content),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
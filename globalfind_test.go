@@ -0,0 +1,50 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFindCandidates(t *testing.T) {
+	id, _ := primitive.ObjectIDFromHex("64b7f0c2a1e4d3b2c1a09f8e")
+	cases := map[string]bson.A{
+		"ada":                      {"ada"},
+		"42":                       {"42", int64(42)},
+		"4.5":                      {"4.5", 4.5},
+		"64b7f0c2a1e4d3b2c1a09f8e": {"64b7f0c2a1e4d3b2c1a09f8e", id},
+	}
+	for text, want := range cases {
+		if got := findCandidates(text); !reflect.DeepEqual(got, want) {
+			t.Errorf("findCandidates(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
+
+func TestGlobalFindFilter(t *testing.T) {
+	fields := findFields(" email, _id,,user.name ")
+	if want := []string{"_id", "email", "user.name"}; !reflect.DeepEqual(fields, want) {
+		t.Fatalf("findFields = %v, want %v", fields, want)
+	}
+	candidates := bson.A{"ada"}
+	if got := globalFindFilter([]string{"_id"}, candidates); !reflect.DeepEqual(got, bson.M{"_id": bson.M{"$in": candidates}}) {
+		t.Errorf("single field filter = %v", got)
+	}
+	got := globalFindFilter(append(fields, "email"), candidates)
+	if or, ok := got["$or"].(bson.A); !ok || len(or) != 3 {
+		t.Errorf("filter = %v, want an $or over the 3 distinct fields", got)
+	}
+}
+
+func TestFindCollectionsSkipsSystemAndDenied(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.selectedDatabase = "shop"
+	m.collections = []string{"orders", "system.views", "secrets", "users"}
+	m.deniedNamespaces["shop.secrets"] = true
+	colls, skipped := m.findCollections()
+	if !reflect.DeepEqual(colls, []string{"orders", "users"}) || skipped != 0 {
+		t.Errorf("findCollections = %v (%d skipped)", colls, skipped)
+	}
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// maxClockSkew is how far the local clock may drift from the server's before
+// TLS certificate checks and time-based queries start to misbehave
+const maxClockSkew = 2 * time.Minute
+
+// healthWarning is a problem with the environment found by a self-check,
+// with a one-line remedy
+type healthWarning struct {
+	Check   string // What was checked, e.g. "editor"
+	Problem string
+	Remedy  string
+}
+
+// healthCheckedMsg carries the warnings of a self-check run. Startup runs
+// only open the panel when something is wrong.
+type healthCheckedMsg struct {
+	warnings []healthWarning
+	onDemand bool
+}
+
+// editorWaitFlags lists the flags GUI editors need to block until the file
+// is closed; without one they return at once and the edit is lost
+var editorWaitFlags = map[string][]string{
+	"code":          {"--wait", "-w"},
+	"code-insiders": {"--wait", "-w"},
+	"cursor":        {"--wait", "-w"},
+	"subl":          {"--wait", "-w"},
+	"atom":          {"--wait", "-w"},
+	"zed":           {"--wait", "-w"},
+	"mate":          {"--wait", "-w"},
+	"gvim":          {"-f", "--nofork"},
+	"mvim":          {"-f", "--nofork"},
+	"kate":          {"--block", "-b"},
+}
+
+// checkEditor checks that $EDITOR names an installed editor that blocks
+// until the file is closed
+func checkEditor(editorEnv string, lookPath func(string) (string, error)) *healthWarning {
+	parts := strings.Fields(editorEnv)
+	if len(parts) == 0 {
+		return &healthWarning{"editor", "EDITOR is not set, so documents open in vi", "export EDITOR to your editor, e.g. EDITOR=nano"}
+	}
+	if _, err := lookPath(parts[0]); err != nil {
+		return &healthWarning{"editor", fmt.Sprintf("EDITOR %q is not on the PATH", parts[0]), "install it or point EDITOR at a full path"}
+	}
+	flags, ok := editorWaitFlags[filepath.Base(parts[0])]
+	if !ok {
+		return nil
+	}
+	for _, arg := range parts[1:] {
+		for _, flag := range flags {
+			if arg == flag {
+				return nil
+			}
+		}
+	}
+	return &healthWarning{"editor", fmt.Sprintf("%s returns before the file is closed, so edits are lost", parts[0]),
+		fmt.Sprintf("EDITOR=%q", parts[0]+" "+flags[0])}
+}
+
+// checkSSHConfig checks that ~/.ssh/config exists when connections use SSH
+// aliases from it
+func checkSSHConfig(connections []Connection, configPath string) *healthWarning {
+	var names []string
+	for _, conn := range connections {
+		if conn.SSHAlias != "" {
+			names = append(names, conn.Name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	if _, err := os.Stat(configPath); err == nil {
+		return nil
+	}
+	return &healthWarning{"ssh", fmt.Sprintf("%s is missing but %s use SSH aliases", configPath, strings.Join(names, ", ")),
+		"define the aliases in " + configPath + " or clear them from the connections"}
+}
+
+// checkStoreDir checks that the directory of the SQLite store is writable
+func checkStoreDir(dir string) *healthWarning {
+	remedy := "fix the permissions of " + dir
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return &healthWarning{"storage", fmt.Sprintf("can't create %s: %v", dir, err), remedy}
+	}
+	file, err := os.CreateTemp(dir, ".mbongo-check-*")
+	if err != nil {
+		return &healthWarning{"storage", fmt.Sprintf("%s is not writable, so connections and settings aren't saved", dir), remedy}
+	}
+	file.Close()
+	os.Remove(file.Name())
+	return nil
+}
+
+// checkColors checks that the terminal can show the 256-color palette
+func checkColors(profile termenv.Profile, term string) *healthWarning {
+	if profile <= termenv.ANSI256 {
+		return nil
+	}
+	if term == "" {
+		term = "unset"
+	}
+	return &healthWarning{"terminal", fmt.Sprintf("the terminal doesn't report 256 colors (TERM=%s), so highlighting is lost", term),
+		"use a 256-color terminal or export TERM=xterm-256color"}
+}
+
+// checkClockSkew compares the local clock with the server's
+func checkClockSkew(local, server time.Time) *healthWarning {
+	skew := local.Sub(server)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew <= maxClockSkew {
+		return nil
+	}
+	direction := "ahead of"
+	if local.Before(server) {
+		direction = "behind"
+	}
+	return &healthWarning{"clock", fmt.Sprintf("the local clock is %s %s the server, which breaks TLS certificate checks", skew.Round(time.Second), direction),
+		"enable time sync (NTP) on this machine"}
+}
+
+// checkClockBehind catches a local clock set before the last time the store
+// was written, before any server can be asked for the time
+func checkClockBehind(now, lastWrite time.Time) *healthWarning {
+	if lastWrite.Sub(now) <= maxClockSkew {
+		return nil
+	}
+	return &healthWarning{"clock", fmt.Sprintf("the local clock (%s) is earlier than mbongo's last save", now.UTC().Format("2006-01-02 15:04 MST")),
+		"enable time sync (NTP) on this machine"}
+}
+
+// serverTime asks the server for its clock
+func serverTime(client *mongo.Client) (time.Time, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var reply struct {
+		LocalTime primitive.DateTime `bson:"localTime"`
+	}
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&reply); err != nil {
+		return time.Time{}, err
+	}
+	return reply.LocalTime.Time(), nil
+}
+
+// runHealthChecks runs every self-check off the UI thread. The clock is
+// compared with the server when connected, otherwise with the store file.
+func runHealthChecks(connections []Connection, client *mongo.Client, onDemand bool) tea.Cmd {
+	return func() tea.Msg {
+		home, _ := os.UserHomeDir()
+		storeDir := filepath.Join(home, ".config", "mbongo")
+		checks := []*healthWarning{
+			checkEditor(os.Getenv("EDITOR"), exec.LookPath),
+			checkSSHConfig(connections, filepath.Join(home, ".ssh", "config")),
+			checkStoreDir(storeDir),
+			checkColors(lipgloss.ColorProfile(), os.Getenv("TERM")),
+		}
+		if client != nil {
+			if server, err := serverTime(client); err == nil {
+				checks = append(checks, checkClockSkew(time.Now(), server))
+			}
+		} else if info, err := os.Stat(filepath.Join(storeDir, "mbongo.db")); err == nil {
+			checks = append(checks, checkClockBehind(time.Now(), info.ModTime()))
+		}
+
+		msg := healthCheckedMsg{onDemand: onDemand}
+		for _, warning := range checks {
+			if warning != nil {
+				msg.warnings = append(msg.warnings, *warning)
+			}
+		}
+		return msg
+	}
+}
+
+// checkHealth runs the self-checks on demand
+func (m Model) checkHealth() tea.Cmd {
+	return runHealthChecks(m.connections, m.client, true)
+}
+
+// handleHealthChecked opens the warnings panel with the results of a check
+func (m *Model) handleHealthChecked(msg healthCheckedMsg) {
+	if len(msg.warnings) == 0 && !msg.onDemand {
+		return
+	}
+	m.healthWarnings = msg.warnings
+	m.healthPanelActive = true
+}
+
+// handleHealthPanelKey dismisses the warnings panel
+func (m *Model) handleHealthPanelKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "enter", "esc", "ctrl+g", "q", " ":
+		m.healthPanelActive = false
+	}
+	return nil
+}
+
+// renderHealthPanel renders the self-check warnings over the screen
+func (m Model) renderHealthPanel(background string) string {
+	width := m.modalWidth(70)
+	color := SeverityWarning.color()
+	problemStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("252")).
+		Width(width - 4)
+	remedyStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("244")).
+		Width(width - 4)
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+
+	title := fmt.Sprintf("%d environment problems", len(m.healthWarnings))
+	if len(m.healthWarnings) == 1 {
+		title = "1 environment problem"
+	}
+	lines := []string{lipgloss.NewStyle().Bold(true).Foreground(color).Render(title)}
+	if len(m.healthWarnings) == 0 {
+		color = SeverityInfo.color()
+		lines = []string{
+			lipgloss.NewStyle().Bold(true).Foreground(color).Render("Environment check"),
+			"",
+			problemStyle.Render("All checks passed."),
+		}
+	}
+	for _, warning := range m.healthWarnings {
+		lines = append(lines,
+			"",
+			problemStyle.Render(lipgloss.NewStyle().Bold(true).Render(warning.Check+": ")+warning.Problem),
+			remedyStyle.Render("→ "+warning.Remedy),
+		)
+	}
+	lines = append(lines, "", hintStyle.Render("enter/esc: dismiss"))
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(color).
+		Padding(1, 2).
+		Width(width)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muesli/termenv"
+)
+
+func TestCheckEditor(t *testing.T) {
+	installed := func(name string) (string, error) {
+		if name == "missing" {
+			return "", errors.New("not found")
+		}
+		return "/usr/bin/" + name, nil
+	}
+	tests := []struct {
+		editor string
+		want   string // Part of the problem, empty for none
+	}{
+		{"", "not set"},
+		{"missing", "not on the PATH"},
+		{"code", "returns before the file is closed"},
+		{"/usr/local/bin/subl -n", "returns before the file is closed"},
+		{"code --wait", ""},
+		{"subl -w", ""},
+		{"vim", ""},
+		{"emacs -nw", ""},
+	}
+	for _, tt := range tests {
+		warning := checkEditor(tt.editor, installed)
+		switch {
+		case tt.want == "" && warning != nil:
+			t.Errorf("checkEditor(%q) = %q, want no warning", tt.editor, warning.Problem)
+		case tt.want != "" && (warning == nil || !strings.Contains(warning.Problem, tt.want)):
+			t.Errorf("checkEditor(%q) = %v, want a warning about %q", tt.editor, warning, tt.want)
+		}
+	}
+	if warning := checkEditor("code", installed); warning.Remedy != `EDITOR="code --wait"` {
+		t.Errorf("remedy = %q", warning.Remedy)
+	}
+}
+
+func TestCheckSSHConfig(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config")
+	direct := []Connection{{Name: "local"}}
+	tunneled := append(direct, Connection{Name: "prod", SSHAlias: "bastion"})
+
+	if warning := checkSSHConfig(direct, configPath); warning != nil {
+		t.Errorf("warned without SSH connections: %q", warning.Problem)
+	}
+	if warning := checkSSHConfig(tunneled, configPath); warning == nil || !strings.Contains(warning.Problem, "prod") {
+		t.Errorf("checkSSHConfig with a missing config = %v, want a warning naming prod", warning)
+	}
+	if err := os.WriteFile(configPath, []byte("Host bastion\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if warning := checkSSHConfig(tunneled, configPath); warning != nil {
+		t.Errorf("warned with a config present: %q", warning.Problem)
+	}
+}
+
+func TestCheckStoreDir(t *testing.T) {
+	dir := t.TempDir()
+	if warning := checkStoreDir(filepath.Join(dir, "mbongo")); warning != nil {
+		t.Errorf("warned about a writable directory: %q", warning.Problem)
+	}
+	entries, _ := os.ReadDir(filepath.Join(dir, "mbongo"))
+	if len(entries) != 0 {
+		t.Errorf("check left %d files behind", len(entries))
+	}
+
+	// A file where the directory should be can't be written into as root either
+	blocked := filepath.Join(dir, "blocked")
+	if err := os.WriteFile(blocked, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if warning := checkStoreDir(blocked); warning == nil {
+		t.Error("no warning for an unusable directory")
+	}
+}
+
+func TestCheckColors(t *testing.T) {
+	if warning := checkColors(termenv.ANSI256, "xterm-256color"); warning != nil {
+		t.Errorf("warned about a 256-color terminal: %q", warning.Problem)
+	}
+	if warning := checkColors(termenv.TrueColor, "xterm-kitty"); warning != nil {
+		t.Errorf("warned about a true color terminal: %q", warning.Problem)
+	}
+	if warning := checkColors(termenv.ANSI, "xterm"); warning == nil || !strings.Contains(warning.Problem, "TERM=xterm") {
+		t.Errorf("checkColors(ANSI) = %v, want a warning naming TERM", warning)
+	}
+}
+
+func TestCheckClock(t *testing.T) {
+	now := time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC)
+	if warning := checkClockSkew(now, now.Add(30*time.Second)); warning != nil {
+		t.Errorf("warned about 30s of skew: %q", warning.Problem)
+	}
+	if warning := checkClockSkew(now, now.Add(-10*time.Minute)); warning == nil || !strings.Contains(warning.Problem, "10m0s ahead of") {
+		t.Errorf("checkClockSkew = %v, want the clock 10m ahead", warning)
+	}
+	if warning := checkClockSkew(now, now.Add(time.Hour)); warning == nil || !strings.Contains(warning.Problem, "behind") {
+		t.Errorf("checkClockSkew = %v, want the clock behind", warning)
+	}
+
+	if warning := checkClockBehind(now, now.Add(-time.Hour)); warning != nil {
+		t.Errorf("warned about a store written an hour ago: %q", warning.Problem)
+	}
+	if warning := checkClockBehind(now, now.AddDate(1, 0, 0)); warning == nil {
+		t.Error("no warning for a store written a year from now")
+	}
+}
+
+func TestHealthPanel(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.handleHealthChecked(healthCheckedMsg{})
+	if m.healthPanelActive {
+		t.Fatal("startup check without problems opened the panel")
+	}
+	m.handleHealthChecked(healthCheckedMsg{warnings: []healthWarning{{"editor", "EDITOR is not set", "export EDITOR"}}})
+	if !m.healthPanelActive || !strings.Contains(normalizeRender(m.View()), "→ export EDITOR") {
+		t.Fatalf("panel not shown:\n%s", normalizeRender(m.View()))
+	}
+	m = pressKey(m, "q")
+	if m.healthPanelActive {
+		t.Error("q didn't dismiss the panel")
+	}
+}
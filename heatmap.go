@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// HeatLevel buckets how common a field is across the documents of the page
+type HeatLevel int
+
+const (
+	HeatNone   HeatLevel = iota // Heat map off, or the node isn't a field
+	HeatEvery                   // In every document
+	HeatCommon                  // In at least half of them
+	HeatRare                    // In fewer than half
+	HeatSingle                  // In one document of several
+)
+
+// heatStyles color field keys by heat level: fields every document has
+// recede, rare ones stand out
+var heatStyles = map[HeatLevel]lipgloss.Style{
+	HeatEvery:  lipgloss.NewStyle().Foreground(lipgloss.Color("243")),
+	HeatCommon: jsonKeyStyle,
+	HeatRare:   lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+	HeatSingle: lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true),
+}
+
+// heatLevelFor buckets a field found in count of total documents
+func heatLevelFor(count, total int) HeatLevel {
+	switch {
+	case count >= total:
+		return HeatEvery
+	case count == 1:
+		return HeatSingle
+	case count*2 >= total:
+		return HeatCommon
+	}
+	return HeatRare
+}
+
+// heatLevel returns the heat level of node's key, HeatNone when the heat
+// map is off or node isn't a field of a page document
+func (m Model) heatLevel(node *JSONNode) HeatLevel {
+	if !m.showHeatMap || m.fieldOccurrences == nil || node.Parent == nil || node.Foreign || strings.HasPrefix(node.Key, "[") {
+		return HeatNone
+	}
+	count, ok := m.fieldOccurrences[referencePattern(node)]
+	if !ok {
+		return HeatNone
+	}
+	return heatLevelFor(count, len(m.documents))
+}
+
+// keyStyle returns the style node's key is rendered in
+func (m Model) keyStyle(node *JSONNode) lipgloss.Style {
+	if style, ok := heatStyles[m.heatLevel(node)]; ok {
+		return style
+	}
+	return jsonKeyStyle
+}
+
+// toggleHeatMap turns the coloring of keys by how common they are on and off
+func (m *Model) toggleHeatMap() tea.Cmd {
+	m.showHeatMap = !m.showHeatMap
+	m.refreshOccurrences()
+	if m.showHeatMap {
+		return m.setStatus("heat map: rare fields " + heatStyles[HeatRare].Render("orange") + ", fields in one document " + heatStyles[HeatSingle].Render("red"))
+	}
+	return m.setStatus("heat map off")
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func heterogeneousPage() []bson.M {
+	return []bson.M{
+		{"_id": int32(1), "name": "a", "email": "a@x", "address": bson.M{"city": "London"}},
+		{"_id": int32(2), "name": "b", "email": "b@x", "address": bson.M{"city": "Paris", "zip": "75"}},
+		{"_id": int32(3), "name": "c", "email": "c@x", "tags": bson.A{bson.M{"k": 1}}},
+		{"_id": int32(4), "name": "d", "legacy_flag": true, "tags": bson.A{}},
+	}
+}
+
+func TestHeatLevels(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.documents = heterogeneousPage()
+	m.docTree = make([]*JSONNode, len(m.documents))
+	for i, doc := range m.documents {
+		m.docTree[i] = m.buildDocumentTree(doc)
+	}
+	m.rebuildFlattenedTree()
+	m.setTreeCollapsed(false, true)
+	m.toggleHeatMap()
+
+	want := map[string]HeatLevel{
+		"name":        HeatEvery,
+		"email":       HeatCommon,
+		"address":     HeatCommon,
+		"tags":        HeatCommon,
+		"city":        HeatCommon,
+		"zip":         HeatSingle,
+		"legacy_flag": HeatSingle,
+		"k":           HeatSingle,
+	}
+	for _, node := range flattenTree(m.docTree) {
+		if level, ok := want[node.Key]; ok && m.heatLevel(node) != level {
+			t.Errorf("%s: heat level %d, want %d", nodePath(node), m.heatLevel(node), level)
+		}
+	}
+	if level := m.heatLevel(m.docTree[0]); level != HeatNone {
+		t.Errorf("document root: heat level %d", level)
+	}
+	if got := heatLevelFor(1, 8); got != HeatSingle {
+		t.Errorf("heatLevelFor(1, 8) = %d", got)
+	}
+	if got := heatLevelFor(3, 8); got != HeatRare {
+		t.Errorf("heatLevelFor(3, 8) = %d", got)
+	}
+
+	// The heat map doesn't add the occurrence counts
+	if view := normalizeRender(m.View()); strings.Contains(view, "(4/4)") {
+		t.Errorf("occurrence counts shown with the heat map:\n%s", view)
+	}
+
+	m.toggleHeatMap()
+	if m.fieldOccurrences != nil || m.heatLevel(flattenTree(m.docTree)[1]) != HeatNone {
+		t.Error("heat map still computed after turning it off")
+	}
+}
+
+func TestHeatMapLeavesPagerTextAlone(t *testing.T) {
+	m := newTestModel(120, 30)
+	plain := m.pagerText(m.documents[0], true)
+	m.toggleHeatMap()
+	if got := m.pagerText(m.documents[0], true); got != plain {
+		t.Errorf("pager text changed by the heat map:\n%s", got)
+	}
+}
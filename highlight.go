@@ -0,0 +1,129 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// sgrReset ends all text attributes
+const sgrReset = "\x1b[0m"
+
+// sgrLength returns the length of the SGR escape sequence at the start of s,
+// or 0 if s doesn't start with one
+func sgrLength(s string) int {
+	if !strings.HasPrefix(s, "\x1b[") {
+		return 0
+	}
+	for i := 2; i < len(s); i++ {
+		c := s[i]
+		if c == 'm' {
+			return i + 1
+		}
+		if (c < '0' || c > '9') && c != ';' {
+			return 0
+		}
+	}
+	return 0
+}
+
+// stripANSI returns the visible text of a styled line
+func stripANSI(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if n := sgrLength(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		b.WriteByte(s[i])
+		i++
+	}
+	return b.String()
+}
+
+// findSubstrings returns the byte ranges of every case-insensitive,
+// non-overlapping occurrence of query in text
+func findSubstrings(text, query string) [][2]int {
+	lower, lowerQuery := strings.ToLower(text), strings.ToLower(query)
+	if lowerQuery == "" || len(lower) != len(text) {
+		// Lowercasing changed byte offsets; ranges wouldn't line up
+		return nil
+	}
+	var ranges [][2]int
+	for start := 0; start < len(lower); {
+		i := strings.Index(lower[start:], lowerQuery)
+		if i < 0 {
+			break
+		}
+		ranges = append(ranges, [2]int{start + i, start + i + len(lowerQuery)})
+		start += i + len(lowerQuery)
+	}
+	return ranges
+}
+
+// highlightRanges renders byte ranges of the visible text of a styled line
+// with style. The line's own key/value colors resume after each range, and
+// the highlight is re-applied when a color change falls inside a range.
+func highlightRanges(line string, ranges [][2]int, style lipgloss.Style) string {
+	rendered := style.Render("x")
+	on := rendered[:strings.Index(rendered, "x")]
+	if len(ranges) == 0 || on == "" {
+		return line
+	}
+
+	var b strings.Builder
+	active := "" // SGR sequences of the line in effect at the current position
+	pos := 0     // Offset in the visible text
+	r := 0
+	inRange := false
+	for i := 0; i < len(line); {
+		if n := sgrLength(line[i:]); n > 0 {
+			seq := line[i : i+n]
+			if seq == sgrReset || seq == "\x1b[m" {
+				active = ""
+			} else {
+				active += seq
+			}
+			b.WriteString(seq)
+			if inRange {
+				b.WriteString(on)
+			}
+			i += n
+			continue
+		}
+		if inRange && pos == ranges[r][1] {
+			b.WriteString(sgrReset + active)
+			inRange = false
+			r++
+		}
+		for !inRange && r < len(ranges) && ranges[r][1] <= pos {
+			r++ // Empty range
+		}
+		if !inRange && r < len(ranges) && pos == ranges[r][0] {
+			b.WriteString(on)
+			inRange = true
+		}
+		_, size := utf8.DecodeRuneInString(line[i:])
+		b.WriteString(line[i : i+size])
+		i += size
+		pos += size
+	}
+	if inRange {
+		b.WriteString(sgrReset + active)
+	}
+	return b.String()
+}
+
+// highlightSearchMatches highlights the occurrences of the search input in a
+// rendered line of a matching node. When every occurrence was truncated off
+// the end of the line, the trailing ellipsis is highlighted instead so the
+// match is still visible.
+func (m Model) highlightSearchMatches(node *JSONNode, line string) string {
+	text := stripANSI(line)
+	ranges := m.withoutOccurrenceLabel(node, text, m.docSearchRanges(text))
+	if len(ranges) == 0 && strings.HasSuffix(line, "...") {
+		return strings.TrimSuffix(line, "...") + docSearchSubstringStyle.Render("...")
+	}
+	return highlightRanges(line, ranges, docSearchSubstringStyle)
+}
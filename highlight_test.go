@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func TestFindSubstrings(t *testing.T) {
+	got := findSubstrings(`"name": "Ada NAME"`, "name")
+	want := [][2]int{{1, 5}, {13, 17}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("findSubstrings = %v, want %v", got, want)
+	}
+}
+
+func TestHighlightRanges(t *testing.T) {
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	defer lipgloss.SetColorProfile(termenv.Ascii)
+
+	key := lipgloss.NewStyle().Foreground(lipgloss.Color("81"))
+	mark := lipgloss.NewStyle().Background(lipgloss.Color("226"))
+	markRendered := mark.Render("x")
+	on := markRendered[:len(markRendered)-len("x"+sgrReset)]
+	keyRendered := key.Render("x")
+	keyOn := keyRendered[:len(keyRendered)-len("x"+sgrReset)]
+
+	// A match spanning the end of the styled key and the plain text after it
+	line := key.Render(`"city"`) + `: "Lisbon"`
+	ranges := findSubstrings(stripANSI(line), `y": "l`)
+	got := highlightRanges(line, ranges, mark)
+
+	want := keyOn + `"cit` + on + `y"` + sgrReset + on + `: "L` + sgrReset + `isbon"`
+	if got != want {
+		t.Errorf("highlightRanges =\n%q\nwant\n%q", got, want)
+	}
+	if stripANSI(got) != stripANSI(line) {
+		t.Errorf("highlighting changed the visible text: %q", stripANSI(got))
+	}
+}
+
+func TestCutColumnsKeepsStyles(t *testing.T) {
+	line := "\x1b[31mab\x1b[0m\x1b[32mcdé\x1b[0m"
+	if got := cutColumns(line, 3); got != "\x1b[31m\x1b[0m\x1b[32mdé\x1b[0m" {
+		t.Errorf("cutColumns = %q", got)
+	}
+}
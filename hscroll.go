@@ -0,0 +1,70 @@
+package main
+
+import (
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// hScrollStep is how many columns < and > scroll the documents panel
+const hScrollStep = 8
+
+// cutColumns drops the first n visible columns of a styled line. Escape
+// sequences are all kept, so the text after the cut keeps its colors.
+func cutColumns(line string, n int) string {
+	if n <= 0 {
+		return line
+	}
+	buf := make([]byte, 0, len(line))
+	skipped := 0
+	for i := 0; i < len(line); {
+		if seq := sgrLength(line[i:]); seq > 0 {
+			buf = append(buf, line[i:i+seq]...)
+			i += seq
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(line[i:])
+		if skipped < n {
+			skipped++
+		} else {
+			buf = append(buf, line[i:i+size]...)
+		}
+		i += size
+	}
+	return string(buf)
+}
+
+// maxDocHScroll returns how far the documents panel can scroll right to show
+// the end of the longest line in view
+func (m Model) maxDocHScroll() int {
+	width := m.getDocPanelWidth() - 2
+	end := m.docScrollOffset + m.getDocPanelHeight()
+	if end > len(m.flattenedTree) {
+		end = len(m.flattenedTree)
+	}
+	longest := 0
+	for i := m.docScrollOffset; i < end; i++ {
+		if node := m.flattenedTree[i]; node.Depth >= 0 {
+			if w := lipgloss.Width(m.renderNode(node, 1<<16)); w > longest {
+				longest = w
+			}
+		}
+	}
+	if longest <= width {
+		return 0
+	}
+	return longest - width
+}
+
+// scrollDocsHorizontally scrolls the lines of the documents panel by delta
+// columns, stopping at the start and once the longest line in view ends
+func (m *Model) scrollDocsHorizontally(delta int) {
+	offset := m.docHScroll + delta
+	if limit := m.maxDocHScroll(); offset > limit {
+		offset = limit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	m.docHScroll = offset
+}
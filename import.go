@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// importBatchSize is the number of documents sent per InsertMany
+const importBatchSize = 500
+
+// maxImportErrors caps the error lines kept for the import summary
+const maxImportErrors = 100
+
+// newImportPathInput creates the textinput for the import file path
+func newImportPathInput() textinput.Model {
+	ti := textinput.New()
+	ti.CharLimit = 300
+	ti.Width = 50
+	return ti
+}
+
+// openImportPrompt opens the import prompt for the collection under the cursor
+func (m *Model) openImportPrompt() tea.Cmd {
+	if len(m.collFiltered) == 0 || m.selectedDatabase == "" || m.client == nil {
+		return nil
+	}
+	if cmd := m.guardWritable(m.collFiltered[m.collCursor]); cmd != nil {
+		return cmd
+	}
+	m.importPromptActive = true
+	m.importCollection = m.collFiltered[m.collCursor]
+	m.importPathInput.SetValue("")
+	m.importPathInput.Focus()
+	return textinput.Blink
+}
+
+// handleImportPromptKey handles keyboard input in the import prompt
+func (m *Model) handleImportPromptKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "ctrl+g":
+		m.importPromptActive = false
+		m.importPathInput.Blur()
+		return nil
+	case "enter":
+		path := strings.TrimSpace(m.importPathInput.Value())
+		if path == "" {
+			return nil
+		}
+		m.importPromptActive = false
+		m.importPathInput.Blur()
+		path = expandTilde(path)
+
+		client, dbName, collName := m.client, m.selectedDatabase, m.importCollection
+		return m.startJob("import into "+namespaceOf(dbName, collName), func(ctx context.Context, report func(string)) (tea.Msg, error) {
+			return runImport(ctx, report, client, dbName, collName, path)
+		})
+	default:
+		var cmd tea.Cmd
+		m.importPathInput, cmd = m.importPathInput.Update(msg)
+		return cmd
+	}
+}
+
+// importResult accumulates counts and error lines for a running import
+type importResult struct {
+	inserted int
+	failed   int
+	errors   []string
+}
+
+// addError records a failed document for the summary
+func (r *importResult) addError(format string, args ...interface{}) {
+	r.failed++
+	if len(r.errors) < maxImportErrors {
+		r.errors = append(r.errors, fmt.Sprintf(format, args...))
+	}
+}
+
+// runImport is the job that inserts the documents in path into dbName.collName.
+// A file starting with '[' is read as a JSON array, anything else as
+// newline-delimited documents. Both are parsed as Extended JSON. The summary
+// is returned even when the import stops early.
+func runImport(ctx context.Context, report func(string), client *mongo.Client, dbName, collName, path string) (tea.Msg, error) {
+	var result importResult
+	err := importDocuments(ctx, report, client.Database(dbName).Collection(collName), path, &result)
+	return importDoneMsg{
+		namespace: namespaceOf(dbName, collName),
+		path:      path,
+		inserted:  result.inserted,
+		failed:    result.failed,
+		errors:    result.errors,
+		err:       err,
+	}, err
+}
+
+// importDocuments does the actual reading and inserting for runImport.
+// Files ending in .gz are decompressed on the fly.
+func importDocuments(ctx context.Context, report func(string), coll *mongo.Collection, path string, result *importResult) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var source io.Reader = file
+	if strings.EqualFold(filepath.Ext(path), ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		source = gz
+	}
+
+	reader := bufio.NewReader(source)
+	asArray, err := startsWithArray(reader)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(reader)
+	if asArray {
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+	}
+
+	batch := make([]interface{}, 0, importBatchSize)
+	// Position in the file of each document in batch, for error messages
+	positions := make([]int, 0, importBatchSize)
+	position := 0
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := insertBatch(ctx, coll, batch, positions, result)
+		batch = batch[:0]
+		positions = positions[:0]
+		report(fmt.Sprintf("%d inserted, %d failed", result.inserted, result.failed))
+		return err
+	}
+
+	for dec.More() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			flush()
+			return fmt.Errorf("document %d: %w", position+1, err)
+		}
+		position++
+
+		var doc bson.D
+		if err := bson.UnmarshalExtJSON(raw, false, &doc); err != nil {
+			result.addError("document %d: %v", position, err)
+			continue
+		}
+		batch = append(batch, doc)
+		positions = append(positions, position)
+		if len(batch) == importBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+// startsWithArray reports whether the first non-whitespace byte is '['
+func startsWithArray(reader *bufio.Reader) (bool, error) {
+	for {
+		b, err := reader.Peek(1)
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			reader.ReadByte()
+		case 0xEF:
+			// Skip a UTF-8 byte order mark
+			reader.Discard(3)
+		default:
+			return b[0] == '[', nil
+		}
+	}
+}
+
+// insertBatch inserts one batch unordered so individual write errors such as
+// duplicate keys don't stop the rest of the batch. Only errors that aren't
+// tied to a single document are returned.
+func insertBatch(ctx context.Context, coll *mongo.Collection, batch []interface{}, positions []int, result *importResult) error {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	_, err := coll.InsertMany(ctx, batch, options.InsertMany().SetOrdered(false))
+	if err == nil {
+		result.inserted += len(batch)
+		return nil
+	}
+
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || len(bulkErr.WriteErrors) == 0 {
+		return err
+	}
+	for _, we := range bulkErr.WriteErrors {
+		if mongo.IsDuplicateKeyError(we) {
+			result.addError("document %d: duplicate key: %s", positions[we.Index], we.Message)
+		} else {
+			result.addError("document %d: %s", positions[we.Index], we.Message)
+		}
+	}
+	result.inserted += len(batch) - len(bulkErr.WriteErrors)
+	return nil
+}
+
+// importSummary formats the failures of a finished import for the viewer
+func importSummary(msg importDoneMsg) string {
+	var b strings.Builder
+	if msg.generated {
+		b.WriteString("Generated from the collection's template\n")
+	} else {
+		fmt.Fprintf(&b, "File: %s\n", msg.path)
+	}
+	fmt.Fprintf(&b, "Inserted: %d\n", msg.inserted)
+	fmt.Fprintf(&b, "Failed: %d\n", msg.failed)
+	if msg.err != nil {
+		fmt.Fprintf(&b, "Stopped early: %v\n", msg.err)
+	}
+	if len(msg.errors) > 0 {
+		b.WriteString("\n")
+		for _, line := range msg.errors {
+			b.WriteString(line + "\n")
+		}
+		if msg.failed > len(msg.errors) {
+			fmt.Fprintf(&b, "... and %d more\n", msg.failed-len(msg.errors))
+		}
+	}
+	return b.String()
+}
+
+// handleImportDone reports a finished import and reloads the affected views
+func (m *Model) handleImportDone(msg importDoneMsg) tea.Cmd {
+	if msg.err != nil && msg.inserted == 0 && msg.failed == 0 {
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Import from %s failed: %v", msg.path, msg.err)
+		if msg.generated {
+			m.errorMessage = fmt.Sprintf("Generating documents failed: %v", msg.err)
+		}
+		return nil
+	}
+
+	var cmds []tea.Cmd
+	if msg.failed > 0 || msg.err != nil {
+		m.openViewer(ViewerImportSummary, "Import into "+msg.namespace, importSummary(msg))
+	} else {
+		verb := "imported"
+		if msg.generated {
+			verb = "generated"
+		}
+		cmds = append(cmds, m.setStatus(fmt.Sprintf("%s %d documents into %s", verb, msg.inserted, truncateMiddle(msg.namespace, maxToastNameWidth))))
+	}
+
+	if msg.inserted > 0 && msg.namespace == m.currentNamespace() {
+		m.loadingDocs = true
+		m.docCursor = 0
+		m.docScrollOffset = 0
+		cmds = append(cmds, loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, m.currentPage, m.docsPerPage, m.queryFilter, m.sortSpec()))
+	}
+	return tea.Batch(cmds...)
+}
+
+// renderImportPrompt renders the import prompt modal
+func (m Model) renderImportPrompt(background string) string {
+	width := m.modalWidth(70)
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render("Import into "+namespaceOf(m.selectedDatabase, m.importCollection)),
+		"",
+		"File path (JSON array or NDJSON, Extended JSON, optionally .gz):",
+		fitInput(m.importPathInput, width-4),
+		"",
+		hintStyle.Render("enter: import • esc: cancel"),
+	)
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("205")).
+		Padding(1, 2).
+		Width(width)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(content),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
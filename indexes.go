@@ -0,0 +1,354 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// indexInfo is one index of a collection as the indexes overlay lists it
+type indexInfo struct {
+	Name    string `bson:"name"`
+	Keys    bson.D `bson:"key"`
+	Unique  bool   `bson:"unique"`
+	Sparse  bool   `bson:"sparse"`
+	Hidden  bool   `bson:"hidden"`
+	TTL     *int64 `bson:"expireAfterSeconds"`      // Seconds after which documents expire, nil when not a TTL index
+	Partial bson.D `bson:"partialFilterExpression"` // Filter of a partial index, nil otherwise
+	Size    int64  `bson:"-"`                       // Bytes on disk, -1 when collStats isn't allowed
+}
+
+// indexesLoadedMsg carries the indexes of a collection
+type indexesLoadedMsg struct {
+	namespace string
+	indexes   []indexInfo
+	err       error
+}
+
+// keySpec formats the keys of an index, e.g. {"status":1,"createdAt":-1}
+func (ix indexInfo) keySpec() string {
+	return keySpecText(ix.Keys)
+}
+
+// keySpecText formats index keys as compact JSON
+func keySpecText(keys bson.D) string {
+	text, err := bson.MarshalExtJSON(keys, false, false)
+	if err != nil {
+		return fmt.Sprintf("%v", keys)
+	}
+	return string(text)
+}
+
+// attributes lists the options of an index that change how it behaves,
+// e.g. "unique, TTL 3600s"
+func (ix indexInfo) attributes() string {
+	var attrs []string
+	if ix.Unique {
+		attrs = append(attrs, "unique")
+	}
+	if ix.Sparse {
+		attrs = append(attrs, "sparse")
+	}
+	if ix.TTL != nil {
+		attrs = append(attrs, fmt.Sprintf("TTL %ds", *ix.TTL))
+	}
+	if ix.Partial != nil {
+		attrs = append(attrs, "partial")
+	}
+	if ix.Hidden {
+		attrs = append(attrs, "hidden")
+	}
+	return strings.Join(attrs, ", ")
+}
+
+// loadIndexes lists the indexes of a collection with their sizes. The sizes
+// come from collStats, which users allowed to list indexes may not run; the
+// indexes are then listed without them.
+func loadIndexes(client *mongo.Client, dbName, collName string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		ns := namespaceOf(dbName, collName)
+
+		coll := client.Database(dbName).Collection(collName)
+		cursor, err := coll.Indexes().List(ctx)
+		if err != nil {
+			return indexesLoadedMsg{namespace: ns, err: err}
+		}
+		var indexes []indexInfo
+		if err := cursor.All(ctx, &indexes); err != nil {
+			return indexesLoadedMsg{namespace: ns, err: err}
+		}
+
+		var stats struct {
+			IndexSizes map[string]int64 `bson:"indexSizes"`
+		}
+		statsErr := client.Database(dbName).RunCommand(ctx, bson.D{{Key: "collStats", Value: collName}}).Decode(&stats)
+		for i := range indexes {
+			indexes[i].Size = -1
+			if size, ok := stats.IndexSizes[indexes[i].Name]; ok && statsErr == nil {
+				indexes[i].Size = size
+			}
+		}
+		return indexesLoadedMsg{namespace: ns, indexes: indexes}
+	}
+}
+
+// openIndexes lists the indexes of the collection under the cursor
+func (m *Model) openIndexes() tea.Cmd {
+	if len(m.collFiltered) == 0 || m.selectedDatabase == "" || m.client == nil {
+		return nil
+	}
+	if spec := m.collSpecs[m.collFiltered[m.collCursor]]; spec.Kind == KindView {
+		return m.setStatus(fmt.Sprintf("views have no indexes of their own: queries use those of %v", spec.option("viewOn")))
+	}
+	m.indexesActive = true
+	m.indexesNamespace = namespaceOf(m.selectedDatabase, m.collFiltered[m.collCursor])
+	m.indexes = nil
+	m.indexesCursor = 0
+	m.indexesScroll = 0
+	return m.refreshIndexes()
+}
+
+// refreshIndexes lists the indexes of the open overlay's collection again
+func (m *Model) refreshIndexes() tea.Cmd {
+	m.indexesLoading = true
+	m.indexesErr = nil
+	dbName, collName := splitNamespace(m.indexesNamespace)
+	return loadIndexes(m.client, dbName, collName)
+}
+
+// handleIndexesLoaded shows the indexes of a collection, unless the overlay
+// was closed or moved on to another collection
+func (m *Model) handleIndexesLoaded(msg indexesLoadedMsg) {
+	if !m.indexesActive || msg.namespace != m.indexesNamespace {
+		return
+	}
+	m.indexesLoading = false
+	m.indexesErr = msg.err
+	m.indexes = msg.indexes
+	m.moveIndexesCursor(m.indexesCursor)
+}
+
+// getIndexesListHeight returns the number of index rows visible in the overlay
+func (m Model) getIndexesListHeight() int {
+	// Border (2) + padding (2) + title and blank (2) + header (1) + blank
+	// and up to three lines of details (4) + blank and hint (2)
+	height := m.height - 4 - 13
+	if height < 3 {
+		height = 3
+	}
+	return height
+}
+
+// moveIndexesCursor moves the overlay cursor, scrolling to keep it visible
+func (m *Model) moveIndexesCursor(target int) {
+	visible := m.getIndexesListHeight()
+	m.indexesCursor = clampIndex(target, len(m.indexes))
+	if m.indexesCursor < m.indexesScroll {
+		m.indexesScroll = m.indexesCursor
+	} else if m.indexesCursor >= m.indexesScroll+visible {
+		m.indexesScroll = m.indexesCursor - visible + 1
+	}
+}
+
+// handleIndexesKey handles keyboard input while the indexes overlay is open
+func (m *Model) handleIndexesKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "ctrl+g", "q":
+		m.indexesActive = false
+	case "up", "k", "ctrl+p":
+		m.moveIndexesCursor(m.indexesCursor - 1)
+	case "down", "j", "ctrl+n":
+		m.moveIndexesCursor(m.indexesCursor + 1)
+	case "pgup", "pgdown", "home", "end":
+		target, _ := pageKeyTarget(msg.String(), m.indexesCursor, m.getIndexesListHeight(), len(m.indexes))
+		m.moveIndexesCursor(target)
+	case "r":
+		if !m.indexesLoading {
+			return m.refreshIndexes()
+		}
+	case "c":
+		return m.openIndexEditor()
+	case "d":
+		return m.confirmDropIndex()
+	}
+	return nil
+}
+
+// renderIndexesOverlay renders the indexes of a collection
+func (m Model) renderIndexesOverlay(background string) string {
+	width := m.modalWidth(100)
+	contentWidth := width - 4
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+	title := "Indexes of " + m.indexesNamespace
+	var lines []string
+	switch {
+	case m.indexesLoading && m.indexes == nil:
+		lines = append(lines, normalStyle.Render("Loading..."))
+	case isUnauthorizedError(m.indexesErr):
+		lines = append(lines, normalStyle.Render("No permission to list the indexes of "+m.indexesNamespace))
+	case m.indexesErr != nil:
+		lines = append(lines, textWrapStyle(contentWidth).Render("Failed to list indexes: "+m.indexesErr.Error()))
+	case len(m.indexes) == 0:
+		lines = append(lines, normalStyle.Render("(no indexes)"))
+	default:
+		title += fmt.Sprintf(" (%d)", len(m.indexes))
+		// Rows leave a column each side for the padding of the selection
+		nameWidth, sizeWidth, attrWidth := 24, 9, 18
+		keyWidth := contentWidth - nameWidth - attrWidth - sizeWidth - 3 - 2
+		if keyWidth < 10 {
+			keyWidth = 10
+		}
+		row := func(name, keys, attrs, size string) string {
+			return fmt.Sprintf("%-*s %-*s %-*s %*s",
+				nameWidth, truncate(name, nameWidth),
+				keyWidth, truncate(keys, keyWidth),
+				attrWidth, truncate(attrs, attrWidth),
+				sizeWidth, size)
+		}
+		lines = append(lines, dimStyle.Render(" "+row("NAME", "KEYS", "OPTIONS", "SIZE")))
+		end := m.indexesScroll + m.getIndexesListHeight()
+		if end > len(m.indexes) {
+			end = len(m.indexes)
+		}
+		for i := m.indexesScroll; i < end; i++ {
+			ix := m.indexes[i]
+			size := "?"
+			if ix.Size >= 0 {
+				size = formatBytes(int(ix.Size))
+			}
+			line := " " + row(ix.Name, ix.keySpec(), ix.attributes(), size)
+			if i == m.indexesCursor {
+				line = selectedStyle.Render(strings.TrimPrefix(line, " "))
+			}
+			lines = append(lines, line)
+		}
+		if details := m.indexes[m.indexesCursor].details(); details != "" {
+			lines = append(lines, "", textWrapStyle(contentWidth).Render(details))
+		}
+	}
+
+	for _, build := range m.indexBuilds(m.indexesNamespace) {
+		lines = append(lines, "", statusMessageStyle.Render(truncate("building "+build+"... • J: jobs", contentWidth)))
+	}
+
+	hint := "c: create • d: drop • r: refresh • ↑/↓: select • esc: close"
+	if m.indexesLoading && m.indexes != nil {
+		hint = "refreshing... • " + hint
+	}
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render(truncate(title, contentWidth)),
+		"",
+		strings.Join(lines, "\n"),
+		"",
+		hintStyle.Render(hint),
+	)
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("205")).
+		Padding(1, 2).
+		Width(width)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(content),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
+
+// details describes what the row of an index leaves out: its full keys when
+// they're long and the filter of a partial index
+func (ix indexInfo) details() string {
+	var parts []string
+	parts = append(parts, ix.Name+": "+ix.keySpec())
+	if ix.Partial != nil {
+		if text, err := bson.MarshalExtJSON(ix.Partial, false, false); err == nil {
+			parts = append(parts, "partial filter: "+string(text))
+		}
+	}
+	if ix.Size < 0 {
+		parts = append(parts, "size unknown: collStats failed or isn't permitted")
+	}
+	return strings.Join(parts, "\n")
+}
+
+// textWrapStyle wraps plain text at width
+func textWrapStyle(width int) lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Width(width)
+}
+
+// indexDroppedMsg reports the end of dropping an index
+type indexDroppedMsg struct {
+	namespace string
+	name      string
+	err       error
+}
+
+// confirmDropIndex asks to confirm dropping the index under the cursor of
+// the indexes overlay. The _id index can't be dropped.
+func (m *Model) confirmDropIndex() tea.Cmd {
+	if m.indexesLoading || m.indexesCursor >= len(m.indexes) {
+		return nil
+	}
+	ix := m.indexes[m.indexesCursor]
+	if ix.Name == "_id_" {
+		return m.setStatus("the _id index can't be dropped: every collection needs it to identify documents")
+	}
+	ns := m.indexesNamespace
+	dbName, collName := splitNamespace(ns)
+	return m.openConfirm(&confirmation{
+		title:       "Drop Index",
+		message:     fmt.Sprintf("Drop index %s %s from %s? Queries using it will scan instead.", ix.Name, ix.keySpec(), m.targetLabel(dbName, collName)),
+		severity:    SeverityDanger,
+		destructive: true,
+		onConfirm: func(m *Model) tea.Cmd {
+			return dropIndex(m.client, ns, ix.Name)
+		},
+	})
+}
+
+// dropIndex drops the index called name from ns
+func dropIndex(client *mongo.Client, ns, name string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		dbName, collName := splitNamespace(ns)
+		_, err := client.Database(dbName).Collection(collName).Indexes().DropOne(ctx, name)
+		return indexDroppedMsg{namespace: ns, name: name, err: err}
+	}
+}
+
+// handleIndexDropped announces a dropped index and refreshes the indexes
+// overlay. A failure opens over the overlay, which stays open.
+func (m *Model) handleIndexDropped(msg indexDroppedMsg) tea.Cmd {
+	if msg.err != nil {
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Failed to drop index %s on %s: %v", msg.name, msg.namespace, msg.err)
+		return nil
+	}
+	status := m.setStatus(fmt.Sprintf("dropped index %s on %s", msg.name, truncateMiddle(msg.namespace, maxToastNameWidth)))
+	if m.indexesActive && m.indexesNamespace == msg.namespace {
+		return tea.Batch(status, m.refreshIndexes())
+	}
+	return status
+}
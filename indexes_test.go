@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func testIndexes() []indexInfo {
+	ttl := int64(3600)
+	return []indexInfo{
+		{Name: "_id_", Keys: bson.D{{Key: "_id", Value: int32(1)}}, Size: 36864},
+		{Name: "status_1_createdAt_-1", Keys: bson.D{{Key: "status", Value: int32(1)}, {Key: "createdAt", Value: int32(-1)}}, Unique: true, Size: 20480},
+		{Name: "expiresAt_1", Keys: bson.D{{Key: "expiresAt", Value: int32(1)}}, TTL: &ttl, Partial: bson.D{{Key: "archived", Value: true}}, Size: -1},
+	}
+}
+
+func TestIndexesOverlay(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m.focus = FocusCollections
+
+	m = pressKey(m, "x")
+	if !m.indexesActive || !m.indexesLoading || m.indexesNamespace != "shop.orders" {
+		t.Fatalf("active = %v, loading = %v, namespace %q", m.indexesActive, m.indexesLoading, m.indexesNamespace)
+	}
+
+	// Results of a collection the overlay left are ignored
+	m, _ = update(t, m, indexesLoadedMsg{namespace: "shop.customers", indexes: testIndexes()[:1]})
+	if m.indexes != nil {
+		t.Fatal("indexes of another collection shown")
+	}
+
+	m, _ = update(t, m, indexesLoadedMsg{namespace: "shop.orders", indexes: testIndexes()})
+	m = pressKey(m, "j")
+	m = pressKey(m, "j")
+	view := normalizeRender(m.View())
+	for _, want := range []string{"Indexes of shop.orders (3)", `{"status":1,"createdAt":-1}`, "unique", "36.0 KB", "TTL 3600s, partial", `partial filter: {"archived":true}`, "size unknown"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("overlay lacks %q:\n%s", want, view)
+		}
+	}
+
+	m = pressKey(m, "r")
+	if !m.indexesLoading || len(m.indexes) != 3 {
+		t.Errorf("refresh: loading = %v, %d indexes kept", m.indexesLoading, len(m.indexes))
+	}
+	m = pressKey(m, "q")
+	if m.indexesActive {
+		t.Error("q didn't close the overlay")
+	}
+}
+
+func TestIndexesOverlayExplainsDenial(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m.focus = FocusCollections
+	m = pressKey(m, "x")
+
+	denied := mongo.CommandError{Code: unauthorizedErrorCode, Message: "not authorized on shop to execute command { listIndexes: \"orders\" }"}
+	m, _ = update(t, m, indexesLoadedMsg{namespace: "shop.orders", err: denied})
+	if view := normalizeRender(m.View()); m.errorModal || !strings.Contains(view, "No permission to list the indexes of shop.orders") {
+		t.Errorf("modal = %v:\n%s", m.errorModal, view)
+	}
+
+	m, _ = update(t, m, indexesLoadedMsg{namespace: "shop.orders"})
+	if view := normalizeRender(m.View()); !strings.Contains(view, "(no indexes)") {
+		t.Errorf("empty list not explained:\n%s", view)
+	}
+}
+
+func TestDropIndex(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m.focus = FocusCollections
+	m = pressKey(m, "x")
+	m, _ = update(t, m, indexesLoadedMsg{namespace: "shop.orders", indexes: testIndexes()})
+
+	m = pressKey(m, "d")
+	if m.confirm != nil || !strings.Contains(m.statusMessage, "_id index can't be dropped") {
+		t.Fatalf("dropping _id_: confirm = %v, status %q", m.confirm != nil, m.statusMessage)
+	}
+
+	m = pressKey(m, "j")
+	m = pressKey(m, "d")
+	if m.confirm == nil || !strings.Contains(m.confirm.message, `status_1_createdAt_-1 {"status":1,"createdAt":-1}`) {
+		t.Fatalf("confirm = %+v", m.confirm)
+	}
+	m = pressKey(m, "esc")
+
+	m, _ = update(t, m, indexDroppedMsg{namespace: "shop.orders", name: "status_1_createdAt_-1", err: errors.New("index is in use by a view")})
+	if !m.errorModal || !m.indexesActive {
+		t.Fatalf("failed drop: modal = %v, overlay open = %v", m.errorModal, m.indexesActive)
+	}
+	m = pressKey(m, "esc")
+	if !m.indexesActive {
+		t.Fatal("dismissing the error closed the overlay")
+	}
+
+	m, cmd := update(t, m, indexDroppedMsg{namespace: "shop.orders", name: "status_1_createdAt_-1"})
+	if cmd == nil || !m.indexesLoading || !strings.Contains(m.statusMessage, "dropped index status_1_createdAt_-1") {
+		t.Errorf("loading = %v, status %q", m.indexesLoading, m.statusMessage)
+	}
+}
@@ -0,0 +1,415 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxConcurrentJobs bounds how many background jobs run at once so they
+// don't saturate the connection pool
+const maxConcurrentJobs = 2
+
+// jobProgressInterval throttles progress updates from a running job
+const jobProgressInterval = 250 * time.Millisecond
+
+// JobState is the lifecycle state of a background job
+type JobState int
+
+const (
+	JobQueued JobState = iota
+	JobRunning
+	JobDone
+	JobFailed
+	JobCancelled
+)
+
+func (s JobState) String() string {
+	switch s {
+	case JobQueued:
+		return "queued"
+	case JobRunning:
+		return "running"
+	case JobDone:
+		return "done"
+	case JobFailed:
+		return "failed"
+	case JobCancelled:
+		return "cancelled"
+	}
+	return "unknown"
+}
+
+// JobFunc does the work of a background job. It should return promptly once
+// ctx is cancelled, and may call report with a short progress line. A non-nil
+// message is delivered to Update when the job ends so the feature can report
+// its own outcome; otherwise a failure is shown in the error modal.
+type JobFunc func(ctx context.Context, report func(progress string)) (tea.Msg, error)
+
+// Job is a named unit of background work tracked by the job manager
+type Job struct {
+	ID       int
+	Name     string
+	Scope    string // Documents a bulk job acts on, e.g. "10 documents on this page"
+	State    JobState
+	Progress string
+	Err      error
+	Started  time.Time
+	Finished time.Time
+	run      JobFunc
+	cancel   context.CancelFunc
+}
+
+// startJob queues a job and starts it if a slot is free
+func (m *Model) startJob(name string, run JobFunc) tea.Cmd {
+	m.jobSeq++
+	m.jobs = append(m.jobs, &Job{ID: m.jobSeq, Name: name, State: JobQueued, run: run})
+	return m.runQueuedJobs()
+}
+
+// startBulkJob queues a job acting on the documents of target, which the
+// jobs overlay names next to it
+func (m *Model) startBulkJob(name string, target bulkTarget, run JobFunc) tea.Cmd {
+	cmd := m.startJob(name, run)
+	m.jobs[len(m.jobs)-1].Scope = target.describe()
+	return cmd
+}
+
+// findJob returns the job with the given id, or nil
+func (m Model) findJob(id int) *Job {
+	for _, job := range m.jobs {
+		if job.ID == id {
+			return job
+		}
+	}
+	return nil
+}
+
+// runningJobs returns the running jobs, oldest first
+func (m Model) runningJobs() []*Job {
+	var running []*Job
+	for _, job := range m.jobs {
+		if job.State == JobRunning {
+			running = append(running, job)
+		}
+	}
+	return running
+}
+
+// runQueuedJobs starts queued jobs until the concurrency limit is reached
+func (m *Model) runQueuedJobs() tea.Cmd {
+	var cmds []tea.Cmd
+	running := len(m.runningJobs())
+	for _, job := range m.jobs {
+		if running >= maxConcurrentJobs {
+			break
+		}
+		if job.State != JobQueued {
+			continue
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		job.State = JobRunning
+		job.Started = time.Now()
+		job.cancel = cancel
+		ch := make(chan tea.Msg)
+		go runJob(ctx, ch, serverCommands, job.ID, job.Name, job.run)
+		cmds = append(cmds, waitForJob(job.ID, ch))
+		running++
+	}
+	return tea.Batch(cmds...)
+}
+
+// waitForJob returns a command that delivers the next message of a job.
+// Update re-issues it after each progress message until the job finishes. A
+// cancelled job may close its channel without sending its result, which is
+// reported as the job's cancellation.
+func waitForJob(id int, ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return jobFinishedMsg{id: id, err: context.Canceled, cancelled: true}
+		}
+		return msg
+	}
+}
+
+// runJob runs a job function once limiter has a slot for it, streaming its
+// progress and result to ch. The slot is given back as soon as the function
+// returns, and nothing is sent once ctx is cancelled, so a job nobody
+// listens to any more can't hold a slot.
+func runJob(ctx context.Context, ch chan tea.Msg, limiter *serverLimiter, id int, name string, run JobFunc) {
+	defer close(ch)
+
+	release, ok := limiter.tryAcquire()
+	if !ok {
+		select {
+		case ch <- jobProgressMsg{id: id, progress: "waiting for a server slot", ch: ch}:
+		case <-ctx.Done():
+		}
+		var err error
+		if release, err = limiter.acquire(ctx, name); err != nil {
+			return
+		}
+		select {
+		case ch <- jobProgressMsg{id: id, ch: ch}:
+		case <-ctx.Done():
+		}
+	}
+
+	var lastProgress time.Time
+	report := func(progress string) {
+		if time.Since(lastProgress) < jobProgressInterval {
+			return
+		}
+		lastProgress = time.Now()
+		select {
+		case ch <- jobProgressMsg{id: id, progress: progress, ch: ch}:
+		case <-ctx.Done():
+		}
+	}
+
+	result, err := run(ctx, report)
+	release()
+	select {
+	case ch <- jobFinishedMsg{id: id, result: result, err: err, cancelled: ctx.Err() != nil}:
+	case <-ctx.Done():
+	}
+}
+
+// handleJobFinished records a job's outcome, starts the next queued job and
+// reports the result
+func (m *Model) handleJobFinished(msg jobFinishedMsg) tea.Cmd {
+	job := m.findJob(msg.id)
+	if job == nil {
+		return nil
+	}
+	job.Finished = time.Now()
+	job.cancel()
+
+	switch {
+	case msg.cancelled:
+		job.State = JobCancelled
+	case msg.err != nil:
+		job.State = JobFailed
+		job.Err = msg.err
+	default:
+		job.State = JobDone
+	}
+
+	cmds := []tea.Cmd{m.runQueuedJobs()}
+	if job.State == JobCancelled {
+		cmds = append(cmds, m.setStatus(job.Name+" cancelled"))
+		return tea.Batch(cmds...)
+	}
+
+	if msg.result != nil {
+		result := msg.result
+		cmds = append(cmds, func() tea.Msg { return result })
+	} else if msg.err != nil {
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("%s failed: %v", job.Name, msg.err)
+	} else {
+		cmds = append(cmds, m.setStatus(job.Name+" done"))
+	}
+	return tea.Batch(cmds...)
+}
+
+// cancelJob cancels a queued or running job. Running jobs finish
+// asynchronously and are marked cancelled when they stop.
+func (m *Model) cancelJob(job *Job) {
+	switch job.State {
+	case JobQueued:
+		job.State = JobCancelled
+		job.Finished = time.Now()
+	case JobRunning:
+		job.Progress = "cancelling..."
+		job.cancel()
+	}
+}
+
+// cancelAllJobs cancels every unfinished job, e.g. when disconnecting
+func (m *Model) cancelAllJobs() {
+	for _, job := range m.jobs {
+		m.cancelJob(job)
+	}
+}
+
+// clearFinishedJobs removes finished jobs from the list
+func (m *Model) clearFinishedJobs() {
+	var jobs []*Job
+	for _, job := range m.jobs {
+		if job.State == JobQueued || job.State == JobRunning {
+			jobs = append(jobs, job)
+		}
+	}
+	m.jobs = jobs
+	m.jobsCursor = clampIndex(m.jobsCursor, len(m.jobs))
+}
+
+// jobsSummary returns the help-line prefix describing running jobs
+func (m Model) jobsSummary() string {
+	running := m.runningJobs()
+	if serverCommands.isPaused() {
+		return "background work paused • J: jobs"
+	}
+	if len(running) == 0 {
+		return ""
+	}
+	summary := running[0].Name
+	if running[0].Progress != "" {
+		summary += ": " + running[0].Progress
+	}
+	if len(running) > 1 {
+		summary += fmt.Sprintf(" (+%d)", len(running)-1)
+	}
+	return summary + " • J: jobs"
+}
+
+// handleJobsKey handles keyboard input while the jobs overlay is open
+func (m *Model) handleJobsKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "ctrl+g", "q", "J":
+		m.jobsOverlayActive = false
+	case "up", "k", "ctrl+p":
+		m.jobsCursor = clampIndex(m.jobsCursor-1, len(m.jobs))
+	case "down", "j", "ctrl+n":
+		m.jobsCursor = clampIndex(m.jobsCursor+1, len(m.jobs))
+	case "pgup", "pgdown", "home", "end":
+		m.jobsCursor, _ = pageKeyTarget(msg.String(), m.jobsCursor, m.getJobsListHeight(), len(m.jobs))
+	case "x", "d":
+		if m.jobsCursor < len(m.jobs) {
+			m.cancelJob(m.jobs[m.jobsCursor])
+		}
+	case "c":
+		m.clearFinishedJobs()
+	case "p":
+		return m.toggleBackgroundPause()
+	case "+", "=":
+		changeServerCommandsLimit(1)
+	case "-":
+		changeServerCommandsLimit(-1)
+	}
+	return nil
+}
+
+// formatJobLine renders one row of the jobs overlay
+func formatJobLine(job *Job, width int) string {
+	var elapsed time.Duration
+	switch {
+	case job.State == JobRunning:
+		elapsed = time.Since(job.Started)
+	case !job.Started.IsZero():
+		elapsed = job.Finished.Sub(job.Started)
+	}
+
+	detail := job.Progress
+	if job.State == JobFailed && job.Err != nil {
+		detail = job.Err.Error()
+	}
+	line := fmt.Sprintf("%-9s %s", job.State, job.Name)
+	if job.Scope != "" {
+		line += " (" + job.Scope + ")"
+	}
+	if detail != "" {
+		line += " — " + detail
+	}
+	if elapsed > 0 {
+		line += fmt.Sprintf(" (%s)", elapsed.Round(time.Second))
+	}
+	return truncate(line, width)
+}
+
+// getJobsListHeight returns the number of job rows visible in the overlay
+func (m Model) getJobsListHeight() int {
+	// Border (2) + padding (2) + title, server line and blank (3) + blank
+	// and hint (2)
+	height := m.height - 4 - 9
+	if height < 3 {
+		height = 3
+	}
+	return height
+}
+
+// renderJobsOverlay renders the jobs list modal
+func (m Model) renderJobsOverlay(background string) string {
+	width := m.width - 10
+	if width > 90 {
+		width = 90
+	}
+	if width < 30 {
+		width = 30
+	}
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+
+	// Show the window of jobs that keeps the cursor visible
+	visible := m.getJobsListHeight()
+	start := 0
+	if m.jobsCursor >= visible {
+		start = m.jobsCursor - visible + 1
+	}
+	end := start + visible
+	if end > len(m.jobs) {
+		end = len(m.jobs)
+	}
+	title := "Background Jobs"
+	if len(m.jobs) > visible {
+		title = fmt.Sprintf("%s (%d-%d of %d)", title, start+1, end, len(m.jobs))
+	}
+
+	var lines []string
+	if len(m.jobs) == 0 {
+		lines = append(lines, normalStyle.Render("(no jobs)"))
+	}
+	for i := start; i < end; i++ {
+		job := m.jobs[i]
+		line := formatJobLine(job, width-6)
+		if i == m.jobsCursor {
+			line = selectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	server := serverCommands.stats()
+	serverStyle := hintStyle
+	if server.paused {
+		serverStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214"))
+	}
+	pause := "p: pause background work"
+	if server.paused {
+		pause = "p: resume background work"
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render(title),
+		serverStyle.Render(truncate(server.describe(), width-6)),
+		"",
+		strings.Join(lines, "\n"),
+		"",
+		hintStyle.Render(truncate("x: cancel • c: clear finished • "+pause+" • +/-: limit • esc: close", width-6)),
+	)
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("205")).
+		Padding(1, 2).
+		Width(width)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(content),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// blockingJob runs until its context is cancelled
+func blockingJob(ctx context.Context, report func(string)) (tea.Msg, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestJobsRespectConcurrencyLimit(t *testing.T) {
+	m := initialModel()
+	for i := 0; i < maxConcurrentJobs+1; i++ {
+		m.startJob("job", blockingJob)
+	}
+	if got := len(m.runningJobs()); got != maxConcurrentJobs {
+		t.Fatalf("running jobs = %d, want %d", got, maxConcurrentJobs)
+	}
+	if last := m.jobs[len(m.jobs)-1]; last.State != JobQueued {
+		t.Errorf("extra job state = %v, want queued", last.State)
+	}
+	m.cancelAllJobs()
+}
+
+func TestJobFinishStartsNextQueuedJob(t *testing.T) {
+	m := initialModel()
+	for i := 0; i < maxConcurrentJobs+1; i++ {
+		m.startJob("job", blockingJob)
+	}
+	first := m.jobs[0]
+	m.cancelJob(first)
+	m.handleJobFinished(jobFinishedMsg{id: first.ID, err: context.Canceled, cancelled: true})
+
+	if first.State != JobCancelled {
+		t.Errorf("cancelled job state = %v, want cancelled", first.State)
+	}
+	if last := m.jobs[len(m.jobs)-1]; last.State != JobRunning {
+		t.Errorf("queued job state = %v, want running after a slot freed up", last.State)
+	}
+	m.cancelAllJobs()
+}
+
+func TestJobFailureWithoutResultOpensErrorModal(t *testing.T) {
+	m := initialModel()
+	m.startJob("export shop.orders", blockingJob)
+	job := m.jobs[0]
+	job.cancel()
+	m.handleJobFinished(jobFinishedMsg{id: job.ID, err: errors.New("disk full")})
+
+	if job.State != JobFailed {
+		t.Errorf("job state = %v, want failed", job.State)
+	}
+	if !m.errorModal {
+		t.Error("want the error modal for a failed job without a result message")
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// startKeySequence records a prefix key and hints at the keys that can follow it
+func (m *Model) startKeySequence(prefix, hint string) tea.Cmd {
+	m.pendingKey = prefix
+	return m.setStatus(hint)
+}
+
+// handleKeySequence handles a completed multi-key sequence such as "yp".
+// Unknown sequences are silently dropped, like an unbound key.
+func (m *Model) handleKeySequence(seq string) tea.Cmd {
+	m.statusMessage = ""
+
+	switch seq {
+	case "ctrl+xs":
+		// Export query results to a file
+		if m.selectedCollection != "" && m.client != nil {
+			return m.openExportPrompt()
+		}
+	case "ctrl+xh":
+		// Export documents as a standalone HTML page
+		return m.openSnapshotPrompt()
+	case "ctrl+xb":
+		// Toggle between the filtered view and the pinboard
+		if m.selectedCollection != "" && m.client != nil {
+			return m.togglePinboard()
+		}
+	case "ctrl+xc":
+		// Clear all pins in this collection
+		return m.clearPins()
+	case "ctrl+xy":
+		// Copy the pinned _ids
+		return m.copyPinnedIDs()
+	case "ctrl+xo":
+		// Copy the pinned documents, or the one under the cursor, to
+		// another collection
+		return m.openCopyPicker()
+	case "ctrl+xm":
+		// Sample the collection and document its schema as Markdown
+		if m.selectedCollection != "" && m.client != nil {
+			return m.startSchemaMarkdownExport()
+		}
+	case "ctrl+xu":
+		// Update every document matching the query, after a dry run
+		return m.openUpdatePrompt()
+	case "ctrl+xU":
+		// Write the update for every matching document in $EDITOR
+		return m.openUpdateEditor(strings.TrimSpace(m.updateInput.Value()))
+	case "ctrl+xf":
+		// Find a value across the collections of the database
+		return m.openGlobalFind()
+	case "ctrl+xt":
+		// Time the current query over several runs
+		return m.openBenchmarkPrompt()
+	case "ctrl+xP":
+		// Stop handing out server slots to background work, e.g. while the
+		// server struggles
+		return m.toggleBackgroundPause()
+	case "ctrl+xi":
+		// Show the server's version, role and load
+		return m.openServerInfo()
+	case "ctrl+xr":
+		// Show the replica set members and how far behind they are
+		return m.openReplSetStatus()
+	case "ctrl+xa":
+		// Show the operations in progress, to kill a stuck one
+		return m.openCurrentOps()
+	case "ctrl+xq":
+		// Show the slow operations the profiler recorded
+		return m.openProfile()
+	case "ctrl+xQ":
+		// Show or change the profiling level of the database
+		return m.openProfileLevel()
+	case "ctrl+x!":
+		// Check the environment for problems
+		return m.checkHealth()
+	case "ctrl+xp":
+		// Read the document under the cursor in $PAGER
+		if docIndex := m.getDocumentIndexAtCursor(); docIndex >= 0 && docIndex < len(m.documents) {
+			return m.openInPager(m.documents[docIndex])
+		}
+	case "ctrl+xL":
+		// Expand every document on the page
+		m.setTreeCollapsed(false, true)
+	case "ctrl+xH":
+		// Collapse every document on the page
+		m.setTreeCollapsed(true, true)
+	case "gg":
+		// Jump to the first line of the documents panel
+		m.moveDocCursor(0)
+	case "ee":
+		// Edit the whole document under the cursor
+		if docIndex := m.getDocumentIndexAtCursor(); docIndex >= 0 && docIndex < len(m.documents) {
+			return m.startEdit(docIndex)
+		}
+	case "es":
+		// Edit just the field under the cursor
+		return m.startSubEdit()
+	case "yy":
+		// Copy the whole document under the cursor
+		if docIndex := m.getDocumentIndexAtCursor(); docIndex >= 0 && docIndex < len(m.documents) {
+			return m.yankDocument(m.documents[docIndex])
+		}
+	case "ys":
+		// Copy the document under the cursor as a mongosh insert statement
+		if docIndex := m.getDocumentIndexAtCursor(); docIndex >= 0 && docIndex < len(m.documents) {
+			return m.yankShellInsert(m.documents[docIndex])
+		}
+	case "yp":
+		// Copy the dotted field path of the node under the cursor
+		if node := m.nodeAtCursor(); node != nil && node.Parent != nil && node.RawText == "" && node.More == 0 && !node.Foreign {
+			return m.yank("path", formatFieldPath(nodePathSegments(node)))
+		}
+	case "yv":
+		// Copy the raw value of the node under the cursor
+		if node := m.nodeAtCursor(); node != nil && node.Depth >= 0 && node.RawText == "" && node.More == 0 && !m.schemaActive {
+			return m.yank("value of "+formatFieldPath(nodePathSegments(node)), rawValueString(node.Value))
+		}
+	}
+	return nil
+}
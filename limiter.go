@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultServerCommands is how many background commands run against the
+// server at once unless the user changed it
+const defaultServerCommands = 4
+
+// maxServerCommands bounds the limit the jobs overlay can raise it to
+const maxServerCommands = 16
+
+// serverCommandsSetting is the app setting holding the limit
+const serverCommandsSetting = "max_server_commands"
+
+// serverLimiter caps the background work running against the server at
+// once, across every feature, so fanning out over collections can't pile
+// dozens of operations onto a struggling server. Background work waits for
+// a slot in the order it asked; while paused, none is handed out and work
+// already running finishes. Interactive queries don't wait but are counted
+// so the jobs overlay shows everything hitting the server.
+type serverLimiter struct {
+	mu         sync.Mutex
+	limit      int
+	running    int
+	foreground int
+	paused     bool
+	waiting    []*limiterWaiter
+}
+
+// limiterWaiter is background work waiting for a slot
+type limiterWaiter struct {
+	label string
+	ready chan struct{} // Closed once the slot is handed over
+}
+
+// limiterStats is the state of a limiter at one moment
+type limiterStats struct {
+	limit      int
+	running    int
+	foreground int
+	paused     bool
+	waiting    []string // Labels of the waiting work, first in line first
+}
+
+// serverCommands is the limiter shared by all background work of the
+// active connection
+var serverCommands = newServerLimiter(defaultServerCommands)
+
+func newServerLimiter(limit int) *serverLimiter {
+	return &serverLimiter{limit: limit}
+}
+
+// acquire waits for a slot and returns the function giving it back, or the
+// context's error if it ends first. label names the work while it waits.
+func (l *serverLimiter) acquire(ctx context.Context, label string) (func(), error) {
+	if release, ok := l.tryAcquire(); ok {
+		return release, nil
+	}
+	l.mu.Lock()
+	w := &limiterWaiter{label: label, ready: make(chan struct{})}
+	l.waiting = append(l.waiting, w)
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return l.releaser(), nil
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	select {
+	case <-w.ready:
+		// Handed a slot just as the context ended
+		l.running--
+		l.grant()
+	default:
+		for i, other := range l.waiting {
+			if other == w {
+				l.waiting = append(l.waiting[:i], l.waiting[i+1:]...)
+				break
+			}
+		}
+	}
+	return nil, ctx.Err()
+}
+
+// tryAcquire takes a slot if one is free right now
+func (l *serverLimiter) tryAcquire() (func(), bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.paused || l.running >= l.limit || len(l.waiting) > 0 {
+		return nil, false
+	}
+	l.running++
+	return l.releaser(), true
+}
+
+// releaser returns the function giving back one slot, which does nothing
+// when called again
+func (l *serverLimiter) releaser() func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.running--
+			l.grant()
+		})
+	}
+}
+
+// grant hands free slots to the work that waited longest. l.mu must be held.
+func (l *serverLimiter) grant() {
+	for !l.paused && l.running < l.limit && len(l.waiting) > 0 {
+		w := l.waiting[0]
+		l.waiting = l.waiting[1:]
+		l.running++
+		close(w.ready)
+	}
+}
+
+// beginForeground counts an interactive query, which doesn't wait for a
+// slot, and returns the function ending it
+func (l *serverLimiter) beginForeground() func() {
+	l.mu.Lock()
+	l.foreground++
+	l.mu.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			l.foreground--
+			l.mu.Unlock()
+		})
+	}
+}
+
+// setPaused stops or resumes handing out slots
+func (l *serverLimiter) setPaused(paused bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.paused = paused
+	l.grant()
+}
+
+// isPaused reports whether background work is paused
+func (l *serverLimiter) isPaused() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.paused
+}
+
+// setLimit changes how many slots there are, between 1 and
+// maxServerCommands. Lowering it lets running work finish.
+func (l *serverLimiter) setLimit(limit int) int {
+	if limit < 1 {
+		limit = 1
+	}
+	if limit > maxServerCommands {
+		limit = maxServerCommands
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = limit
+	l.grant()
+	return limit
+}
+
+// stats returns the limiter's state for display
+func (l *serverLimiter) stats() limiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s := limiterStats{limit: l.limit, running: l.running, foreground: l.foreground, paused: l.paused}
+	for _, w := range l.waiting {
+		s.waiting = append(s.waiting, w.label)
+	}
+	return s
+}
+
+// describe summarizes the state, e.g. "server: 4/4 background commands •
+// 2 waiting, next: watch shop.orders • 1 foreground query"
+func (s limiterStats) describe() string {
+	line := fmt.Sprintf("server: %d/%d background commands", s.running, s.limit)
+	if len(s.waiting) > 0 {
+		line += fmt.Sprintf(" • %d waiting, next: %s", len(s.waiting), s.waiting[0])
+	}
+	if s.foreground > 0 {
+		line += fmt.Sprintf(" • %d foreground %s", s.foreground, pluralize(int64(s.foreground), "query", "queries"))
+	}
+	if s.paused {
+		line += " • PAUSED"
+	}
+	return line
+}
+
+// loadServerCommandsLimit applies the saved limit, if any
+func loadServerCommandsLimit() {
+	saved, err := loadSetting(serverCommandsSetting)
+	if err != nil || saved == "" {
+		return
+	}
+	if limit, err := strconv.Atoi(saved); err == nil {
+		serverCommands.setLimit(limit)
+	}
+}
+
+// changeServerCommandsLimit raises or lowers the limit by step and saves it
+func changeServerCommandsLimit(step int) {
+	limit := serverCommands.setLimit(serverCommands.stats().limit + step)
+	saveSetting(serverCommandsSetting, strconv.Itoa(limit))
+}
+
+// toggleBackgroundPause pauses background work, or resumes it
+func (m *Model) toggleBackgroundPause() tea.Cmd {
+	if serverCommands.isPaused() {
+		serverCommands.setPaused(false)
+		return m.setStatus("background work resumed")
+	}
+	serverCommands.setPaused(true)
+	return m.setStatus("background work paused: running commands finish, nothing new starts • ctrl+x P: resume")
+}
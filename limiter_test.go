@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestLimiterHandsOutSlotsInOrder(t *testing.T) {
+	l := newServerLimiter(1)
+	release, err := l.acquire(context.Background(), "first")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order := make(chan string, 2)
+	for _, label := range []string{"second", "third"} {
+		label := label
+		go func() {
+			release, err := l.acquire(context.Background(), label)
+			if err != nil {
+				return
+			}
+			order <- label
+			release()
+		}()
+		// Queue them one after the other
+		for len(l.stats().waiting) == 0 || l.stats().waiting[len(l.stats().waiting)-1] != label {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	if s := l.stats(); s.running != 1 || len(s.waiting) != 2 || !strings.Contains(s.describe(), "2 waiting, next: second") {
+		t.Fatalf("stats = %+v", s)
+	}
+
+	release()
+	release() // Giving a slot back twice frees it once
+	if first, second := <-order, <-order; first != "second" || second != "third" {
+		t.Errorf("order = %s, %s", first, second)
+	}
+	if s := l.stats(); s.running != 0 || len(s.waiting) != 0 {
+		t.Errorf("after releasing: %+v", s)
+	}
+}
+
+func TestLimiterPauseAndCancel(t *testing.T) {
+	l := newServerLimiter(2)
+	l.setPaused(true)
+	if _, ok := l.tryAcquire(); ok {
+		t.Fatal("slot handed out while paused")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.acquire(ctx, "watch shop.orders"); err == nil {
+		t.Fatal("acquire returned while paused")
+	}
+	if s := l.stats(); len(s.waiting) != 0 || s.running != 0 {
+		t.Errorf("cancelled waiter kept: %+v", s)
+	}
+
+	l.setPaused(false)
+	release, ok := l.tryAcquire()
+	if !ok {
+		t.Fatal("no slot after resuming")
+	}
+	release()
+
+	endQuery := l.beginForeground()
+	if s := l.stats(); s.foreground != 1 || !strings.Contains(s.describe(), "1 foreground query") {
+		t.Errorf("foreground not counted: %s", s.describe())
+	}
+	endQuery()
+	if l.setLimit(0) != 1 || l.setLimit(maxServerCommands+5) != maxServerCommands {
+		t.Error("limit not clamped")
+	}
+}
+
+func TestJobWaitsForAServerSlot(t *testing.T) {
+	l := newServerLimiter(1)
+	release, _ := l.tryAcquire()
+
+	ch := make(chan tea.Msg)
+	done := func(ctx context.Context, report func(string)) (tea.Msg, error) { return nil, nil }
+	go runJob(context.Background(), ch, l, 1, "export shop.orders", done)
+
+	if msg := (<-ch).(jobProgressMsg); msg.progress != "waiting for a server slot" {
+		t.Errorf("progress = %q", msg.progress)
+	}
+	if s := l.stats(); len(s.waiting) != 1 || s.waiting[0] != "export shop.orders" {
+		t.Errorf("stats = %+v", s)
+	}
+	release()
+	if msg := (<-ch).(jobProgressMsg); msg.progress != "" {
+		t.Errorf("progress once running = %q", msg.progress)
+	}
+	if msg := waitForJob(1, ch)().(jobFinishedMsg); msg.err != nil || msg.cancelled {
+		t.Errorf("finished = %+v", msg)
+	}
+}
+
+func TestCancelledJobGivesBackItsSlot(t *testing.T) {
+	l := newServerLimiter(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan tea.Msg)
+	started := make(chan struct{})
+	go runJob(ctx, ch, l, 1, "find in shop", func(ctx context.Context, report func(string)) (tea.Msg, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	<-started
+
+	// Nobody reads the result, yet the slot is free once the job stops
+	cancel()
+	acquired, stop := context.WithTimeout(context.Background(), time.Second)
+	defer stop()
+	release, err := l.acquire(acquired, "next")
+	if err != nil {
+		t.Fatalf("slot kept by the cancelled job: %v", err)
+	}
+	release()
+
+	// Its dropped result is reported as the cancellation
+	if msg := waitForJob(1, ch)().(jobFinishedMsg); !msg.cancelled || msg.id != 1 {
+		t.Errorf("finished = %+v", msg)
+	}
+}
+
+func TestPauseBackgroundWork(t *testing.T) {
+	t.Cleanup(func() {
+		serverCommands.setPaused(false)
+		serverCommands.setLimit(defaultServerCommands)
+	})
+	m := newTestModel(120, 30)
+
+	m = pressKey(m, "ctrl+x")
+	m = pressKey(m, "P")
+	if !serverCommands.isPaused() || !m.watchPollPaused() || !strings.Contains(m.statusMessage, "paused") {
+		t.Fatalf("paused = %v, status = %q", serverCommands.isPaused(), m.statusMessage)
+	}
+	if summary := m.jobsSummary(); !strings.Contains(summary, "background work paused") {
+		t.Errorf("summary = %q", summary)
+	}
+
+	m = pressKey(m, "J")
+	if view := normalizeRender(m.View()); !strings.Contains(view, "/4 background commands • PAUSED") || !strings.Contains(view, "p: resume") {
+		t.Errorf("jobs overlay:\n%s", view)
+	}
+	m = pressKey(m, "+")
+	if serverCommands.stats().limit != 5 {
+		t.Errorf("limit = %d", serverCommands.stats().limit)
+	}
+	m = pressKey(m, "p")
+	if serverCommands.isPaused() {
+		t.Error("p didn't resume")
+	}
+}
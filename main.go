@@ -2,9 +2,12 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -20,7 +23,7 @@ import (
 const (
 	defaultConnectionString = "mongodb://localhost:27017"
 	leftPanelWidth          = 30
-	docsPerPage             = 10
+	defaultDocsPerPage      = 10
 )
 
 // Screen represents which screen is currently displayed
@@ -44,15 +47,20 @@ const (
 // Model represents the application state
 type Model struct {
 	// Screen state
-	screen           Screen
-	connections      []Connection // Available connections
-	connCursor       int          // Cursor for connections list
-	connectionString string       // Originally selected connection string
-	activeConnString string       // Actual connection string in use (may be tunneled)
-	sshAlias         string       // SSH alias for tunneling (empty for direct)
-	sshTunnel        *SSHTunnel   // Active SSH tunnel (nil for direct)
+	screen             Screen
+	connections        []Connection // Available connections
+	connCursor         int          // Cursor for connections list
+	connectionName     string       // Name of the selected connection
+	connectionString   string       // Originally selected connection string
+	activeConnString   string       // Actual connection string in use (may be tunneled)
+	sshAlias           string       // SSH alias for tunneling (empty for direct)
+	connectionDatabase string       // Default database of the selected connection, opened once connected
+	connectionEnv      string       // Environment label of the selected connection, e.g. prod
+	connectionColor    string       // Color of its environment (empty for the default)
+	sshTunnel          *SSHTunnel   // Active SSH tunnel (nil for direct)
 	// MongoDB state
 	client             *mongo.Client
+	serverVersion      serverVersion // Detected at connect time, zero until known
 	databases          []string
 	collections        []string
 	documents          []bson.M
@@ -70,19 +78,36 @@ type Model struct {
 	selectedCollection string
 	explicitDBSelect   bool // True if user pressed Enter to select DB (show errors)
 	// Document tree view
-	docTree         []*JSONNode // Root nodes (one per document)
-	flattenedTree   []*JSONNode // Flattened visible nodes for display
-	docCursor       int         // Cursor position in flattened tree
+	docTree       []*JSONNode // Root nodes (one per document)
+	flattenedTree []*JSONNode // Flattened visible nodes for display
+	flattenedDocs []int       // Document index of each flattened node
+	// Collapsed state of each object and array by field path, per document
+	// _id, so documents come back expanded the same after reloads
+	expandState     map[string]map[string]bool
+	docCursor       int // Cursor position in flattened tree
 	docScrollOffset int
+	docHScroll      int           // Columns the lines are scrolled right
+	docProvenance   DocProvenance // How the documents on screen were produced
+	docsPerPage     int           // Page size for the selected collection
+	anchorDocIndex  int           // Document to scroll to after the next load (-1 for none)
+	// Full-screen single document view
+	docFullscreen         bool // Documents panel shows only one document, full screen
+	fullscreenDocIndex    int  // Document shown in full screen
+	fullscreenSavedCursor int  // Cursor to restore when leaving full screen
+	fullscreenSavedScroll int  // Scroll offset to restore when leaving full screen
 	// Query input
 	queryText    string        // The query text
 	queryCursor  int           // Cursor position within query text
 	querySpinner spinner.Model // Spinner for query loading
 	queryLoading bool          // Whether a query is in progress
-	queryFilter  bson.M        // Current active filter
+	// A spinner.TickMsg is pending; Update starts the tick loop when a load
+	// begins and it stops once nothing is loading
+	spinnerTicking bool
+	queryFilter    bson.M // Current active filter
 	// Error modal
 	errorModal   bool   // Whether to show error modal
 	errorMessage string // Error message to display
+	errorScroll  int    // First visible line of a long error message
 	// Collection search
 	collSearchActive    bool            // Whether search mode is active
 	collSearchInput     textinput.Model // Search input field
@@ -98,24 +123,268 @@ type Model struct {
 	newConnNameInput     textinput.Model // Name input field
 	newConnSSHAliasInput textinput.Model // SSH alias input field
 	newConnStringInput   textinput.Model // Connection string input field
-	newConnFocusField    int             // 0=name, 1=ssh alias, 2=connection string
+	newConnDatabaseInput textinput.Model // Default database input field
+	newConnEnvInput      textinput.Model // Environment label input field
+	newConnColorInput    textinput.Model // Environment color input field
+	newConnFocusField    int             // 0=name, 1=ssh alias, 2=connection string, 3=default database, 4=environment, 5=color
+	newConnErr           string          // Why the connection can't be saved as typed
+	duplicateConnIndex   int             // Saved connection to the same cluster being pointed out, -1 if none
 	editingConnIndex     int             // Index of connection being edited, -1 if creating new
 	editingConnOldName   string          // Original name of connection being edited (for DB update)
-	// Delete confirmation modal
-	deleteConnModal bool // Whether the delete confirmation modal is open
-	deleteConnIndex int  // Index of connection to delete
+	// Update of every document matching the query, with ctrl+x u
+	updatePromptActive bool
+	updateInput        textinput.Model // Update operators or pipeline
+	pendingUpdate      *bulkUpdate     // Update being previewed or confirmed
+	// Single value under the cursor being set, with e s
+	valuePromptActive bool
+	valueInput        textinput.Model
+	valueEdit         documentSave // Document, path and current value being edited
+	valueErr          string       // Why the typed value doesn't parse
+	// Most recently used connection, opened in the background on the connections screen
+	lastConnection string
+	warmup         *connectionWarmup // nil when none is running or ready
+	warmupSeq      int               // Incremented per warm-up so cancelled ones are told apart
+	warmupOff      bool              // Whether warm-up is turned off, toggled with w
+	// Passphrase of the saved connections, asked for to unlock them at
+	// start or chosen when turning encryption on with E
+	storeEncrypted         bool
+	passphraseActive       bool
+	passphraseChoosing     bool // A new passphrase is typed twice
+	passphraseBusy         bool // The key is being derived
+	passphraseInput        textinput.Model
+	passphraseConfirmInput textinput.Model
+	passphraseFocus        int // 0=passphrase, 1=confirmation
+	passphraseErr          string
+	// Whether system databases and system.* collections are left out of the
+	// lists, saved in settings and toggled with alt+., and whether . shows
+	// them anyway for this session
+	hideSystem   bool
+	revealSystem bool
+	// Confirmation modal of the action waiting to be confirmed, nil when closed
+	confirm      *confirmation
+	confirmInput textinput.Model // Typed confirmation text
+	// Edited document that changed on the server before it was saved, nil when none
+	saveConflict *saveConflict
 	// Connection search
 	connSearchActive    bool            // Whether search mode is active
 	connSearchInput     textinput.Model // Search input field
 	connFiltered        []Connection    // Filtered connections
 	connFilteredIndices []int           // Indices into original connections slice
+	connSortByName      bool            // Saved connections listed by name rather than most recently used first, toggled with s
 	// Document search
 	docSearchActive  bool            // Whether document search is active
 	docSearchInput   textinput.Model // Search input field
 	docSearchMatches []int           // Indices of matching lines in flattenedTree
 	docSearchCurrent int             // Current match index (-1 if none)
+	docSearchMode    SearchMode      // How the input is matched, cycled with ctrl+t
+	docSearchRegex   *regexp.Regexp  // Compiled input in regex mode, nil if invalid
+	docSearchErr     string          // Regex compile error shown in the search bar
+	// _ids pasted into an empty query, awaiting confirmation to convert them
+	pasteIDs   []string // nil when no prompt is showing
+	pasteUUIDs bool
+	pasteText  string // Pasted text, inserted as is when declined
+	// Search across all pages, replacing the query until esc
+	serverSearchQuery string
+	serverSearchPrev  *savedQuery // Query to restore, nil when not searching
 	// Auto-select database from env var
 	autoSelectDB string // Database name to auto-select (from $DATABASE_NAME)
+	// Connection given on the command line, cleared once connecting
+	launch launchOptions
+	// Transient status message shown in the help line
+	statusMessage string   // Message text (empty when none)
+	statusSeq     int      // Incremented per message so stale clears are ignored
+	suspended     bool     // Whether ctrl+z handed the terminal to the shell
+	queuedToasts  []string // Toasts to show on resume, oldest first
+	keptEdits     []string // Files of edits whose editor failed, removed on quit
+	// Multi-key sequences (e.g. "yp")
+	pendingKey  string // Prefix key waiting for its second key
+	countPrefix int    // Count typed before a documents panel motion, 0 if none
+	// Scrollable text viewer overlay
+	viewerKind     ViewerKind // What the viewer is showing (ViewerNone when closed)
+	viewerTitle    string     // Title shown at the top of the viewer
+	viewerText     string     // Raw text being shown (used for copy actions)
+	viewerLines    []string   // viewerText split into lines
+	viewerScroll   int        // First visible line
+	viewerSavePath string     // File w writes the text to, for viewers that can be saved
+	viewerBinary   []byte     // Payload w writes instead of the text, for ViewerBinary
+	// Registers holding the session's copies, newest first, shown with "
+	registers       []register
+	registersActive bool // Whether the registers panel is open
+	registerCursor  int  // Highlighted register
+	// Environment self-check, at startup and with ! / ctrl+x !
+	healthPanelActive bool            // Whether the warnings panel is open
+	healthWarnings    []healthWarning // Problems found by the last check
+	// Credential prompt shown when authentication fails mid-session
+	authPromptActive bool            // Whether the credential prompt is open
+	authUserInput    textinput.Model // Username input field
+	authPassInput    textinput.Model // Password input field (masked)
+	authFocusField   int             // 0=username, 1=password
+	authSaveToConn   bool            // Write the new credentials back to the saved connection
+	authError        string          // Last authentication error
+	authFailures     int             // Consecutive failed reconnect attempts (drives backoff)
+	authRetryOp      AuthRetryOp     // Operation to re-run once reconnected
+	authReconnecting bool            // True while reconnecting with new credentials
+	authConnecting   bool            // The prompt asks for the password before connecting
+	passwordAsked    bool            // The saved connection string holds no password, which is never written back
+	// Collection watches (count-change notifications)
+	watches              []Watch          // Watched namespaces for the active connection
+	watchBaselines       map[string]int64 // Acknowledged count per namespace
+	watchBadges          map[string]int64 // Unacknowledged count change per namespace
+	watchPollSeq         int              // Poll loop generation
+	watchPromptActive    bool             // Whether the watch delta prompt is open
+	watchPromptNamespace string           // Namespace being added by the prompt
+	watchInput           textinput.Model  // Delta input field
+	editorActive         bool             // True while $EDITOR owns the terminal
+	// Estimated document count per namespace, shown in the Collections panel
+	// and fetched once per session
+	collCounts map[string]collectionCount
+	// Kind and options of each collection of the selected database
+	collSpecs map[string]collectionSpec
+	// When the lists were last refreshed with R, shown in their panel titles
+	dbRefreshedAt   time.Time
+	collRefreshedAt time.Time
+	collRefreshedDB string // Database whose collections were refreshed
+	// Export prompt
+	exportPromptActive bool            // Whether the export prompt is open
+	exportPathInput    textinput.Model // Output file path
+	exportScope        BulkScope       // Which documents are exported
+	// HTML snapshot prompt, with ctrl+x h
+	snapshotPromptActive bool            // Whether the snapshot prompt is open
+	snapshotPathInput    textinput.Model // Output file path
+	snapshotScope        BulkScope       // Which documents the snapshot contains
+	// Import prompt
+	importPromptActive bool            // Whether the import prompt is open
+	importPathInput    textinput.Model // Input file path
+	importCollection   string          // Collection being imported into
+	// Test data generator prompt, with G on a collection
+	generatePromptActive  bool            // Whether the prompt is open
+	generateCollection    string          // Collection the documents go into
+	generateTemplateInput textinput.Model // Template document with {{placeholders}}
+	generateCountInput    textinput.Model // Number of documents
+	generateCountFocused  bool            // Tab moved the cursor to the count
+	generateErr           string          // Why the template or count was rejected
+	// Query benchmark prompt, with ctrl+x t
+	benchmarkPromptActive bool            // Whether the prompt is open
+	benchmarkRunsInput    textinput.Model // Number of runs
+	benchmarkErr          string          // Why the runs were rejected
+	// Database export/import prompt, with E and I on a database
+	dumpPromptActive  bool            // Whether the prompt is open
+	dumpImport        bool            // Importing a directory rather than exporting
+	dumpDatabase      string          // Database under the cursor when the prompt opened
+	dumpDirInput      textinput.Model // Export directory
+	dumpTargetInput   textinput.Model // Database to import into
+	dumpTargetFocused bool            // Tab moved the cursor to the target input
+	// Deferred flattening while expand/collapse keys repeat
+	treeDirty            bool        // Node collapse state changed since the last flatten
+	treeRebuildScheduled bool        // A treeRebuildMsg tick is pending
+	showTypes            bool        // Annotate leaf values with their BSON type
+	dateDisplay          DateDisplay // How dates are rendered, cycled with T and saved in settings
+	// Decoded dates after numbers that look like epoch timestamps, toggled with d
+	showEpochs      bool
+	epochDetector   epochDetector   // Field names and value ranges taken for timestamps
+	epochSuppressed map[string]bool // Field paths of this collection never decoded
+	// Per-field counts of how many page documents contain the field, shown
+	// after keys when showOccurrences is on (nil otherwise)
+	showOccurrences  bool
+	fieldOccurrences map[string]int
+	// Whether keys are colored by how many page documents have them, toggled with O
+	showHeatMap bool
+	// Collections Find/Count was refused on, by namespace, for this session
+	deniedNamespaces map[string]bool
+	// Value frequency overlay for a field, opened with #
+	freqActive  bool
+	freqLoading bool
+	freqField   string
+	freqValues  []valueCount
+	freqTotal   int64 // Number of values counted, for percentages
+	freqCursor  int
+	freqScroll  int
+	// Indexes overlay of a collection, opened with x
+	indexesActive    bool
+	indexesLoading   bool
+	indexesNamespace string
+	indexes          []indexInfo
+	indexesErr       error
+	indexesCursor    int
+	indexesScroll    int
+	indexDraft       string // Spec of the index last written in $EDITOR, until it is built
+	// Picker of the collection to copy documents to, with ctrl+x o
+	copyActive       bool
+	copyInput        textinput.Model // Fuzzy filter, or the name of a new collection
+	copySourceTarget bulkTarget      // Documents being copied
+	copyTargets      []string        // Namespaces the documents can go to
+	copyFiltered     []string        // copyTargets matching the input
+	copyCursor       int
+	copyAllDBs       bool // Whether the collections of other databases are listed
+	copyNewIDs       bool // Give documents whose _id is taken a new one instead of skipping them
+	// Prompt to clone a collection, with C on a collection
+	cloneActive    bool
+	cloneSource    string          // Collection being cloned
+	cloneNameInput textinput.Model // Name of the clone
+	cloneIndexes   bool            // Whether the indexes are copied too
+	cloneErr       string          // Why the name was rejected
+	// Prompt for a new database and its first collection, with n on the databases panel
+	createDBActive      bool
+	createDBInput       textinput.Model
+	createDBCollInput   textinput.Model
+	createDBCollFocused bool   // Tab moved the cursor to the collection name
+	createDBErr         string // Why the names were rejected
+	// Replica set members, states and lag, with ctrl+x r
+	replSetActive  bool
+	replSetLoading bool
+	replSet        *replSetStatus // Last status read, nil before the first
+	replSetErr     error          // Why replSetGetStatus failed
+	// Operations in progress on the server, with ctrl+x a
+	opsActive  bool
+	opsLoading bool
+	ops        []currentOp
+	opsOwnOnly bool // Listing every user's operations wasn't permitted
+	opsErr     error
+	opsCursor  int
+	opsScroll  int
+	// Prompt showing and changing the profiling level, with ctrl+x Q
+	profileActive    bool
+	profileLoading   bool
+	profileDatabase  string
+	profileWas       int             // Level the database had when the prompt opened, -1 until read
+	profileLevel     int             // Level selected in the prompt
+	profileSlowInput textinput.Model // slowms threshold
+	profileErr       string
+	// Schema summary shown in the documents panel instead of documents
+	schemaActive     bool
+	schemaSampled    int // Number of documents the summary was built from
+	schemaSampleSize int // Documents to sample, from SCHEMA_SAMPLE_SIZE
+	docSizeWarning   int // Document size in bytes above which it's warning-colored, from DOC_SIZE_WARNING_KB
+	// Active sort of the documents, set with s on a field
+	sortField string // Dotted field path, empty for natural order
+	sortDesc  bool
+	// Reference targets of foreign key fields in the selected collection
+	refTargets      map[string]string // Target collection per field path
+	refPromptActive bool              // Whether the reference target prompt is open
+	refPromptPath   string            // Field path being configured
+	refInput        textinput.Model   // Target collection input field
+	// Find of a value across the collections of the database, with ctrl+x f
+	findPromptActive  bool
+	findFieldsFocused bool            // Whether tab moved to the field list input
+	findValueInput    textinput.Model // Value to find
+	findFieldsInput   textinput.Model // Fields searched besides _id and indexed ones
+	findActive        bool            // Whether the results overlay is open
+	findValue         string
+	findHits          []globalFindHit // Collections that matched or failed
+	findSearched      int             // Number of collections searched
+	findSkipped       int             // Collections left out by the cap
+	findCursor        int
+	// Background jobs
+	jobs              []*Job // Queued, running and finished jobs
+	jobSeq            int    // Last assigned job id
+	jobsOverlayActive bool   // Whether the jobs overlay is open
+	jobsCursor        int    // Selected job in the overlay
+	// Pinboard of documents collected during the session
+	pins           map[string][]interface{}  // Pinned _ids per namespace
+	pinboardActive bool                      // Documents panel shows the pinned documents
+	pinSavedFilter bson.M                    // Filter to restore when leaving the pinboard
+	pinSavedPage   int                       // Page to restore when leaving the pinboard
+	saveHistory    map[string][]savedVersion // Undoable saves per document, newest last, for U
 }
 
 func initialModel() Model {
@@ -139,6 +408,21 @@ func initialModel() Model {
 	connStringInput.CharLimit = 200
 	connStringInput.Width = 40
 
+	databaseInput := textinput.New()
+	databaseInput.Placeholder = "(blank for the connection string's)"
+	databaseInput.CharLimit = 64
+	databaseInput.Width = 40
+
+	envInput := textinput.New()
+	envInput.Placeholder = "dev, staging, prod..."
+	envInput.CharLimit = 30
+	envInput.Width = 40
+
+	colorInput := textinput.New()
+	colorInput.Placeholder = "196 or #ff5f87"
+	colorInput.CharLimit = 7
+	colorInput.Width = 40
+
 	// Document search input
 	docSearchInput := textinput.New()
 	docSearchInput.Placeholder = ""
@@ -152,40 +436,85 @@ func initialModel() Model {
 	connSearchInput.CharLimit = 100
 	connSearchInput.Width = 30
 
+	// Credential prompt inputs
+	authUserInput, authPassInput := newAuthInputs()
+	dumpDirInput, dumpTargetInput := newDumpInputs()
+	generateTemplateInput, generateCountInput := newGenerateInputs()
+
 	// Check for DATABASE_NAME env var for auto-selection
 	autoSelectDB := os.Getenv("DATABASE_NAME")
 
-	return Model{
-		screen:               ScreenConnections,
-		connections:          defaultConnections,
-		connCursor:           0,
-		databases:            []string{},
-		collections:          []string{},
-		documents:            []bson.M{},
-		dbCursor:             0,
-		collCursor:           0,
-		queryText:            "{}",
-		queryCursor:          1, // Start between the braces
-		querySpinner:         s,
-		queryFilter:          bson.M{},
-		focus:                FocusDatabases,
-		loading:              false, // Don't start loading until connection is selected
-		collSearchInput:      newCollectionSearchInput(),
-		collFiltered:         []string{},
-		dbSearchInput:        newDatabaseSearchInput(),
-		dbFiltered:           []string{},
-		newConnNameInput:     nameInput,
-		newConnSSHAliasInput: sshAliasInput,
-		newConnStringInput:   connStringInput,
-		newConnFocusField:    0,
-		connSearchInput:      connSearchInput,
-		connFiltered:         []Connection{},
-		connFilteredIndices:  []int{},
-		docSearchInput:       docSearchInput,
-		docSearchMatches:     []int{},
-		docSearchCurrent:     -1,
-		autoSelectDB:         autoSelectDB,
+	m := Model{
+		screen:                ScreenConnections,
+		connections:           defaultConnections,
+		connCursor:            0,
+		databases:             []string{},
+		collections:           []string{},
+		documents:             []bson.M{},
+		dbCursor:              0,
+		collCursor:            0,
+		queryText:             "{}",
+		queryCursor:           1, // Start between the braces
+		querySpinner:          s,
+		queryFilter:           bson.M{},
+		focus:                 FocusDatabases,
+		loading:               false, // Don't start loading until connection is selected
+		collSearchInput:       newCollectionSearchInput(),
+		collFiltered:          []string{},
+		dbSearchInput:         newDatabaseSearchInput(),
+		dbFiltered:            []string{},
+		newConnNameInput:      nameInput,
+		newConnSSHAliasInput:  sshAliasInput,
+		newConnStringInput:    connStringInput,
+		newConnDatabaseInput:  databaseInput,
+		newConnEnvInput:       envInput,
+		newConnColorInput:     colorInput,
+		newConnFocusField:     0,
+		connSearchInput:       connSearchInput,
+		connFiltered:          []Connection{},
+		connFilteredIndices:   []int{},
+		docSearchInput:        docSearchInput,
+		docSearchMatches:      []int{},
+		docSearchCurrent:      -1,
+		autoSelectDB:          autoSelectDB,
+		authUserInput:         authUserInput,
+		authPassInput:         authPassInput,
+		watchInput:            newWatchInput(),
+		watchBaselines:        map[string]int64{},
+		watchBadges:           map[string]int64{},
+		collCounts:            map[string]collectionCount{},
+		exportPathInput:       newExportPathInput(),
+		snapshotPathInput:     newExportPathInput(),
+		docsPerPage:           defaultDocsPerPage,
+		anchorDocIndex:        -1,
+		duplicateConnIndex:    -1,
+		importPathInput:       newImportPathInput(),
+		dumpDirInput:          dumpDirInput,
+		generateTemplateInput: generateTemplateInput,
+		generateCountInput:    generateCountInput,
+		dumpTargetInput:       dumpTargetInput,
+		refTargets:            map[string]string{},
+		deniedNamespaces:      map[string]bool{},
+		schemaSampleSize:      schemaSampleSizeFromEnv(),
+		docSizeWarning:        docSizeWarningFromEnv(),
+		refInput:              newReferenceInput(),
+		copyInput:             newCopyInput(),
+		cloneNameInput:        newCloneNameInput(),
+		pins:                  map[string][]interface{}{},
+		saveHistory:           map[string][]savedVersion{},
+		expandState:           map[string]map[string]bool{},
+		epochDetector:         epochDetectorFromEnv(),
+		epochSuppressed:       map[string]bool{},
 	}
+	m.findValueInput, m.findFieldsInput = newFindInputs()
+	m.createDBInput, m.createDBCollInput = newCreateDatabaseInputs()
+	m.confirmInput = newConfirmInput()
+	m.updateInput = newUpdateInput()
+	m.valueInput = newValueInput()
+	m.benchmarkRunsInput = newBenchmarkRunsInput()
+	m.profileSlowInput = newProfileSlowInput()
+	m.passphraseInput, m.passphraseConfirmInput = newPassphraseInputs()
+	return m
 }
 
 // connectionsLoadedMsg is sent when connections are loaded from the database
@@ -200,17 +529,86 @@ func (m Model) Init() tea.Cmd {
 		if err := initDB(); err != nil {
 			return connectionsLoadedMsg{err: err}
 		}
-		connections, err := loadConnections()
-		if err != nil {
-			return connectionsLoadedMsg{err: err}
+		return loadStoredConnections()
+	}
+}
+
+// loadStoredConnections loads the saved connections from the open
+// database. Encrypted ones are unlocked with MBONGO_PASSPHRASE when it's
+// set, otherwise the passphrase is asked for.
+func loadStoredConnections() tea.Msg {
+	if locked, err := storeLocked(); err != nil {
+		return connectionsLoadedMsg{err: err}
+	} else if locked {
+		if err := unlockFromEnv(); err != nil {
+			return storeLockedMsg{}
 		}
-		return connectionsLoadedMsg{connections: connections}
 	}
+	connections, err := loadConnections()
+	if err != nil {
+		return connectionsLoadedMsg{err: err}
+	}
+	return connectionsLoadedMsg{connections: connections}
 }
 
+// Update handles a message, then keeps the spinner ticking while a query or
+// page load is in flight, whichever panel has focus
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	updated, cmd := m.update(msg)
+	next, ok := updated.(Model)
+	if !ok || !next.busy() || next.spinnerTicking {
+		return updated, cmd
+	}
+	next.spinnerTicking = true
+	return next, tea.Batch(cmd, next.querySpinner.Tick)
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Apply deferred expand/collapse before anything that reads the flattened tree
+	if m.treeDirty && !m.isTreeToggleKey(msg) {
+		m.flushTree()
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		// Hand the terminal back to the shell until fg
+		if msg.String() == "ctrl+z" {
+			return m, m.suspend()
+		}
+
+		// Handle passphrase prompt, over the connections screen
+		if m.passphraseActive {
+			return m, m.handlePassphraseKey(msg)
+		}
+
+		// Handle confirmation modal, on either screen
+		if m.confirm != nil {
+			return m, m.handleConfirmKey(msg)
+		}
+
+		// Handle environment warnings panel, on either screen
+		if m.healthPanelActive {
+			return m, m.handleHealthPanelKey(msg)
+		}
+
+		// Handle error modal dismissal, on either screen
+		if m.errorModal {
+			switch msg.String() {
+			case "enter", "esc", "escape", " ":
+				m.errorModal = false
+				m.errorMessage = ""
+				m.errorScroll = 0
+			case "up", "k", "ctrl+p":
+				m.scrollErrorModal(-1)
+			case "down", "j", "ctrl+n":
+				m.scrollErrorModal(1)
+			case "pgup", "pgdown", "home", "end":
+				lines, visible := m.errorModalLines()
+				m.errorScroll, _ = pageKeyTarget(msg.String(), m.errorScroll, visible, len(lines)-visible+1)
+			}
+			return m, nil
+		}
+
 		// Handle connections screen
 		if m.screen == ScreenConnections {
 			cmd, shouldContinue := m.handleConnectionsKeyMsg(msg)
@@ -219,25 +617,142 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			// If we switched to main screen, start connecting
 			if m.screen == ScreenMain && m.loading {
-				// If SSH alias is set, establish tunnel first
-				if m.sshAlias != "" {
-					return m, establishSSHTunnel(m.sshAlias, m.connectionString)
+				m.lastConnection = m.connectionName
+				saveSetting(lastConnectionSetting, m.connectionName)
+				m.markConnectionUsed(m.connectionName)
+				if cmd, ok := m.takeWarmup(); ok {
+					return m, cmd
 				}
-				// Direct connection - activeConnString is same as connectionString
-				m.activeConnString = m.connectionString
-				return m, connectToMongo(m.connectionString)
+				return m, m.connect()
 			}
 			return m, cmd
 		}
 
-		// Handle error modal dismissal FIRST - it takes priority over everything
-		if m.errorModal {
-			switch msg.String() {
-			case "enter", "esc", "escape", " ":
-				m.errorModal = false
-				m.errorMessage = ""
-			}
-			return m, nil
+		// Handle credential prompt
+		if m.authPromptActive {
+			cmd, _ := m.handleAuthPromptKey(msg)
+			return m, cmd
+		}
+
+		// Handle watch delta prompt
+		if m.watchPromptActive {
+			return m, m.handleWatchPromptKey(msg)
+		}
+
+		// Handle export prompt
+		if m.exportPromptActive {
+			return m, m.handleExportPromptKey(msg)
+		}
+
+		// Handle HTML snapshot prompt
+		if m.snapshotPromptActive {
+			return m, m.handleSnapshotPromptKey(msg)
+		}
+
+		// Handle import prompt
+		if m.importPromptActive {
+			return m, m.handleImportPromptKey(msg)
+		}
+
+		// Handle clone prompt
+		if m.cloneActive {
+			return m, m.handleClonePromptKey(msg)
+		}
+
+		// Handle new database prompt
+		if m.createDBActive {
+			return m, m.handleCreateDatabasePromptKey(msg)
+		}
+
+		// Handle replica set overlay
+		if m.replSetActive {
+			return m, m.handleReplSetKey(msg)
+		}
+
+		// Handle operations overlay
+		if m.opsActive {
+			return m, m.handleOpsKey(msg)
+		}
+
+		// Handle profiling level prompt
+		if m.profileActive {
+			return m, m.handleProfileLevelKey(msg)
+		}
+
+		// Handle database export/import prompt
+		if m.dumpPromptActive {
+			return m, m.handleDumpPromptKey(msg)
+		}
+
+		// Handle test data generator prompt
+		if m.generatePromptActive {
+			return m, m.handleGeneratePromptKey(msg)
+		}
+
+		// Handle query benchmark prompt
+		if m.benchmarkPromptActive {
+			return m, m.handleBenchmarkPromptKey(msg)
+		}
+
+		// Handle reference target prompt
+		if m.refPromptActive {
+			return m, m.handleReferencePromptKey(msg)
+		}
+
+		// Handle bulk update prompt
+		if m.updatePromptActive {
+			return m, m.handleUpdatePromptKey(msg)
+		}
+
+		// Handle value prompt
+		if m.valuePromptActive {
+			return m, m.handleValuePromptKey(msg)
+		}
+
+		// Handle global find prompt
+		if m.findPromptActive {
+			return m, m.handleFindPromptKey(msg)
+		}
+
+		// Handle registers panel
+		if m.registersActive {
+			return m, m.handleRegistersKey(msg)
+		}
+
+		// Handle jobs overlay
+		if m.jobsOverlayActive {
+			return m, m.handleJobsKey(msg)
+		}
+
+		// Handle value frequency overlay
+		if m.freqActive {
+			return m, m.handleFrequencyKey(msg)
+		}
+
+		// Handle indexes overlay
+		if m.indexesActive {
+			return m, m.handleIndexesKey(msg)
+		}
+
+		// Handle copy picker
+		if m.copyActive {
+			return m, m.handleCopyPickerKey(msg)
+		}
+
+		// Handle global find results overlay
+		if m.findActive {
+			return m, m.handleFindResultsKey(msg)
+		}
+
+		// Handle text viewer overlay
+		if m.viewerKind != ViewerNone {
+			cmd, _ := m.handleViewerKey(msg)
+			return m, cmd
+		}
+
+		// Handle save conflict modal
+		if m.saveConflict != nil {
+			return m, m.handleSaveConflictKey(msg)
 		}
 
 		// Handle query input when focused
@@ -272,6 +787,20 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		// Complete a multi-key sequence started by a prefix key
+		if m.pendingKey != "" {
+			seq := m.pendingKey + msg.String()
+			m.pendingKey = ""
+			return m, m.handleKeySequence(seq)
+		}
+
+		// Accumulate a count for the next motion, as in 15j
+		if cmd, handled := m.handleCountDigit(msg.String()); handled {
+			return m, cmd
+		}
+		counted := m.countPrefix > 0
+		count := m.takeCount()
+
 		switch msg.String() {
 		case "ctrl+c", "q":
 			// Clean up SSH tunnel if active
@@ -291,19 +820,34 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.client.Disconnect(nil)
 				m.client = nil
 			}
+			m.serverVersion = serverVersion{}
 			m.screen = ScreenConnections
 			m.databases = []string{}
 			m.collections = []string{}
+			m.collSpecs = nil
 			m.documents = []bson.M{}
 			m.docTree = nil
 			m.flattenedTree = nil
+			m.docFullscreen = false
 			m.selectedDatabase = ""
 			m.selectedCollection = ""
+			m.connectionName = ""
 			m.connectionString = ""
+			m.watches = nil
+			m.watchPollSeq++ // Stop the poll loop
+			m.collCounts = map[string]collectionCount{}
+			m.pins = map[string][]interface{}{}
+			m.saveHistory = map[string][]savedVersion{}
+			m.pinboardActive = false
+			m.cancelAllJobs()
 			m.activeConnString = ""
 			m.sshAlias = ""
+			m.connectionDatabase = ""
+			m.connectionEnv = ""
+			m.connectionColor = ""
+			m.passwordAsked = false
 			m.updateFilteredConnections()
-			return m, nil
+			return m, m.startWarmup()
 
 		case "/", "ctrl+s":
 			// Activate search when on Databases, Collections, or Documents panel
@@ -325,6 +869,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.docSearchInput.Focus()
 				m.docSearchMatches = []int{}
 				m.docSearchCurrent = -1
+				m.docSearchErr = ""
 				return m, textinput.Blink
 			}
 
@@ -344,10 +889,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.collCursor--
 				}
 			case FocusDocuments:
-				if m.docCursor > 0 {
-					m.docCursor--
-					m.adjustScrollForCursor()
-				}
+				m.moveDocCursor(m.docCursor - count)
 			}
 
 		case "down", "j", "ctrl+n":
@@ -366,10 +908,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.collCursor++
 				}
 			case FocusDocuments:
-				if m.docCursor < len(m.flattenedTree)-1 {
-					m.docCursor++
-					m.adjustScrollForCursor()
-				}
+				m.moveDocCursor(m.docCursor + count)
 			}
 
 		case "right", "l":
@@ -378,7 +917,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				node := m.flattenedTree[m.docCursor]
 				if (node.IsObject || node.IsArray) && node.Collapsed {
 					node.Collapsed = false
-					m.rebuildFlattenedTree()
+					return m, m.markTreeDirty()
+				} else if cmd, ok := m.expandReference(node); ok {
+					return m, cmd
 				}
 			}
 
@@ -388,8 +929,154 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				node := m.flattenedTree[m.docCursor]
 				if (node.IsObject || node.IsArray) && !node.Collapsed {
 					node.Collapsed = true
-					m.rebuildFlattenedTree()
+					return m, m.markTreeDirty()
+				} else {
+					m.collapseReference(node)
+				}
+			}
+
+		case "+", "=":
+			// Show more documents per page
+			if m.focus == FocusDocuments && m.selectedCollection != "" && m.client != nil {
+				return m, m.changePageSize(1)
+			}
+
+		case "-":
+			// Show fewer documents per page
+			if m.focus == FocusDocuments && m.selectedCollection != "" && m.client != nil {
+				return m, m.changePageSize(-1)
+			}
+
+		case "d":
+			// Toggle decoded dates after epoch numbers
+			if m.focus == FocusDocuments {
+				return m, m.toggleEpochs()
+			}
+
+		case "D":
+			// Drop the collection under the cursor, once its name is typed
+			if m.focus == FocusCollections {
+				return m, m.confirmDropCollection()
+			}
+			// Drop the database under the cursor, once its name is typed
+			if m.focus == FocusDatabases {
+				return m, m.confirmDropDatabase()
+			}
+			// Stop or resume decoding the field under the cursor as a date
+			if m.focus == FocusDocuments && m.showEpochs {
+				return m, m.toggleEpochSuppression()
+			}
+
+		case "T":
+			// Cycle dates between stored, local and UTC time with relative time
+			if m.focus == FocusDocuments {
+				return m, m.cycleDateDisplay()
+			}
+
+		case "t":
+			// Toggle BSON type annotations on leaf values
+			if m.focus == FocusDocuments {
+				m.showTypes = !m.showTypes
+				m.rewrap()
+				if m.showTypes {
+					return m, m.setStatus("showing BSON types")
+				}
+				return m, m.setStatus("BSON types hidden")
+			}
+
+		case ">", "shift+right":
+			// Scroll the documents panel right to read the end of long lines
+			if m.focus == FocusDocuments {
+				m.scrollDocsHorizontally(count * hScrollStep)
+			}
+
+		case "<", "shift+left":
+			// Scroll the documents panel back left
+			if m.focus == FocusDocuments {
+				m.scrollDocsHorizontally(-count * hScrollStep)
+			}
+
+		case "o":
+			// Toggle how many documents of the page contain each field
+			if m.focus == FocusDocuments {
+				m.showOccurrences = !m.showOccurrences
+				m.refreshOccurrences()
+				m.rewrap()
+				if m.showOccurrences {
+					return m, m.setStatus("showing field occurrences on this page")
 				}
+				return m, m.setStatus("field occurrences hidden")
+			}
+
+		case "O":
+			// Toggle coloring keys by how common they are on this page
+			if m.focus == FocusDocuments {
+				return m, m.toggleHeatMap()
+			}
+
+		case "s":
+			// Sort the results by the field under the cursor
+			if m.focus == FocusDocuments && len(m.flattenedTree) > 0 && !m.schemaActive {
+				return m, m.sortByCursorField()
+			}
+
+		case "R":
+			// List the databases or collections again
+			if m.focus == FocusDatabases || m.focus == FocusCollections {
+				return m, m.refreshFocusedList()
+			}
+			// Configure the collection the field under the cursor refers to
+			if m.focus == FocusDocuments && len(m.flattenedTree) > 0 {
+				return m, m.openReferencePrompt()
+			}
+
+		case "#":
+			// Fetch the document counts of the collections again
+			if m.focus == FocusCollections {
+				return m, m.recountCollections()
+			}
+			// Count the values of the field under the cursor
+			if m.focus == FocusDocuments && m.client != nil {
+				return m, m.openValueFrequency()
+			}
+
+		case "J":
+			// Show background jobs
+			m.jobsOverlayActive = true
+			m.jobsCursor = clampIndex(m.jobsCursor, len(m.jobs))
+
+		case "r":
+			// Toggle raw Extended JSON for the document under the cursor
+			if m.focus == FocusDocuments && len(m.flattenedTree) > 0 {
+				return m, m.toggleRawView()
+			}
+
+		case "z":
+			// Toggle the full-screen view of the document under the cursor
+			if m.focus == FocusDocuments && (m.docFullscreen || len(m.flattenedTree) > 0) {
+				m.toggleFullscreen()
+			}
+
+		case "esc":
+			// Leave the full-screen document view or the schema summary
+			if m.docFullscreen {
+				m.toggleFullscreen()
+			} else if m.schemaActive && m.focus == FocusDocuments {
+				return m, m.closeSchemaSummary()
+			} else if m.serverSearchPrev != nil && m.focus == FocusDocuments && m.client != nil {
+				return m, m.restoreSearchedQuery()
+			}
+
+		case "L":
+			// Expand every node of the document under the cursor
+			if m.focus == FocusDocuments && len(m.flattenedTree) > 0 {
+				m.setTreeCollapsed(false, false)
+			}
+
+		case "H":
+			// Collapse the document under the cursor back to its root
+			if m.focus == FocusDocuments && len(m.flattenedTree) > 0 {
+				m.setTreeCollapsed(true, false)
 			}
 
 		case "tab":
@@ -430,6 +1117,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case FocusCollections:
 				if len(m.collFiltered) > 0 && m.client != nil && m.selectedDatabase != "" {
 					m.selectedCollection = m.collFiltered[m.collCursor]
+					m.acknowledgeWatch(namespaceOf(m.selectedDatabase, m.selectedCollection))
+					m.loadCollectionSettings()
+					m.pinboardActive = false
 					m.loadingDocs = true
 					m.docScrollOffset = 0
 					m.docCursor = 0
@@ -438,15 +1128,23 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.queryText = "{}"
 					m.queryCursor = 1
 					m.focus = FocusDocuments
-					return m, loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, 0, m.queryFilter)
+					return m, loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, 0, m.docsPerPage, m.queryFilter, m.sortSpec())
 				}
 			case FocusDocuments:
+				// Filter by the schema field under the cursor
+				if m.schemaActive {
+					m.seedExistsQuery()
+					return m, nil
+				}
 				// Toggle expand/collapse on enter
 				if len(m.flattenedTree) > 0 {
 					node := m.flattenedTree[m.docCursor]
+					if node.More > 0 {
+						return m, m.showMore(node)
+					}
 					if node.IsObject || node.IsArray {
 						node.Collapsed = !node.Collapsed
-						m.rebuildFlattenedTree()
+						return m, m.markTreeDirty()
 					}
 				}
 			}
@@ -455,35 +1153,42 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Spacebar also toggles expand/collapse in documents panel
 			if m.focus == FocusDocuments && len(m.flattenedTree) > 0 {
 				node := m.flattenedTree[m.docCursor]
+				if node.More > 0 {
+					return m, m.showMore(node)
+				}
 				if node.IsObject || node.IsArray {
 					node.Collapsed = !node.Collapsed
-					m.rebuildFlattenedTree()
+					return m, m.markTreeDirty()
 				}
 			}
 
 		case "n":
+			// Create a database with its first collection
+			if m.focus == FocusDatabases {
+				return m, m.openCreateDatabasePrompt()
+			}
 			// Next page of documents
 			if m.focus == FocusDocuments && len(m.documents) > 0 {
-				maxPage := (int(m.totalDocs) - 1) / docsPerPage
+				maxPage := (int(m.totalDocs) - 1) / m.docsPerPage
 				if m.currentPage < maxPage {
 					m.currentPage++
 					m.loadingDocs = true
 					m.docCursor = 0
 					m.docScrollOffset = 0
-					return m, loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, m.currentPage, m.queryFilter)
+					return m, loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, m.currentPage, m.docsPerPage, m.queryFilter, m.sortSpec())
 				}
 			}
 
 		case "N":
 			// Jump to last page of documents
 			if m.focus == FocusDocuments && len(m.documents) > 0 {
-				maxPage := (int(m.totalDocs) - 1) / docsPerPage
+				maxPage := (int(m.totalDocs) - 1) / m.docsPerPage
 				if m.currentPage < maxPage {
 					m.currentPage = maxPage
 					m.loadingDocs = true
 					m.docCursor = 0
 					m.docScrollOffset = 0
-					return m, loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, m.currentPage, m.queryFilter)
+					return m, loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, m.currentPage, m.docsPerPage, m.queryFilter, m.sortSpec())
 				}
 			}
 
@@ -494,7 +1199,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.loadingDocs = true
 				m.docCursor = 0
 				m.docScrollOffset = 0
-				return m, loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, m.currentPage, m.queryFilter)
+				return m, loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, m.currentPage, m.docsPerPage, m.queryFilter, m.sortSpec())
 			}
 
 		case "P":
@@ -504,37 +1209,77 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.loadingDocs = true
 				m.docCursor = 0
 				m.docScrollOffset = 0
-				return m, loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, 0, m.queryFilter)
+				return m, loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, 0, m.docsPerPage, m.queryFilter, m.sortSpec())
 			}
 
 		case "ctrl+v":
 			// Page down (half page) in documents panel
 			if m.focus == FocusDocuments {
-				halfPage := m.getDocPanelHeight() / 2
-				if halfPage < 1 {
-					halfPage = 1
-				}
-				m.docCursor += halfPage
-				if m.docCursor >= len(m.flattenedTree) {
-					m.docCursor = len(m.flattenedTree) - 1
-				}
-				if m.docCursor < 0 {
-					m.docCursor = 0
-				}
+				m.docCursor, _ = pageKeyTarget("pgdown", m.docCursor, count*(m.getDocPanelHeight()/2), len(m.flattenedTree))
 				m.adjustScrollForCursor()
 			}
 
 		case "alt+v":
 			// Page up (half page) in documents panel
 			if m.focus == FocusDocuments {
-				halfPage := m.getDocPanelHeight() / 2
-				if halfPage < 1 {
-					halfPage = 1
+				m.docCursor, _ = pageKeyTarget("pgup", m.docCursor, count*(m.getDocPanelHeight()/2), len(m.flattenedTree))
+				m.adjustScrollForCursor()
+			}
+
+		case "}", "{":
+			// Jump to the next or previous node at the same level
+			if m.focus == FocusDocuments {
+				if msg.String() == "{" {
+					count = -count
 				}
-				m.docCursor -= halfPage
-				if m.docCursor < 0 {
-					m.docCursor = 0
+				m.jumpToSibling(count)
+			}
+
+		case "u", "[":
+			// Jump to the enclosing object or array, count levels up
+			if m.focus == FocusDocuments {
+				for i := 0; i < count; i++ {
+					m.jumpToParent()
+				}
+			}
+
+		case "g":
+			// gg jumps to the first line
+			if m.focus == FocusDocuments {
+				return m, m.startKeySequence("g", "g: first line")
+			}
+
+		case "G":
+			// Generate test documents into the collection under the cursor
+			if m.focus == FocusCollections {
+				return m, m.openGeneratePrompt()
+			}
+			// Jump to the last line, or to line N with a count
+			if m.focus == FocusDocuments {
+				if counted {
+					m.moveDocCursor(count - 1)
+				} else {
+					m.moveDocCursor(len(m.flattenedTree) - 1)
+				}
+			}
+
+		case "pgup", "pgdown", "home", "end":
+			// Full page and top/bottom movement in the focused panel
+			switch m.focus {
+			case FocusDatabases:
+				target, _ := pageKeyTarget(msg.String(), m.dbCursor, m.getListHeight(), len(m.dbFiltered))
+				if target != m.dbCursor {
+					m.dbCursor = target
+					if m.client != nil {
+						m.selectedDatabase = m.dbFiltered[m.dbCursor]
+						m.explicitDBSelect = false // Paging = silent errors, like arrow keys
+						return m, loadCollections(m.client, m.selectedDatabase)
+					}
 				}
+			case FocusCollections:
+				m.collCursor, _ = pageKeyTarget(msg.String(), m.collCursor, m.getListHeight(), len(m.collFiltered))
+			case FocusDocuments:
+				m.docCursor, _ = pageKeyTarget(msg.String(), m.docCursor, m.getDocPanelHeight(), len(m.flattenedTree))
 				m.adjustScrollForCursor()
 			}
 
@@ -558,20 +1303,335 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "e":
 			// Edit document in external editor
 			if m.focus == FocusDocuments && len(m.documents) > 0 {
+				if m.busy() {
+					return m, m.setStatus("wait for the query to finish before editing")
+				}
+				if cmd := m.guardWritable(m.selectedCollection); cmd != nil {
+					return m, cmd
+				}
+				// Fields can also be edited on their own
+				if node := m.nodeAtCursor(); node != nil && node.Parent != nil && node.RawText == "" && node.More == 0 && !node.Foreign {
+					return m, m.startKeySequence("e", "edit: e=whole document • s="+formatFieldPath(nodePathSegments(node))+" only")
+				}
 				docIndex := m.getDocumentIndexAtCursor()
 				if docIndex >= 0 && docIndex < len(m.documents) {
-					return m, m.openInEditor(docIndex)
+					return m, m.startEdit(docIndex)
+				}
+			}
+
+		case "U":
+			// Revert the latest save of the document under the cursor
+			if m.focus == FocusDocuments && len(m.documents) > 0 {
+				if m.busy() {
+					return m, m.setStatus("wait for the query to finish before undoing")
 				}
+				if cmd := m.guardWritable(m.selectedCollection); cmd != nil {
+					return m, cmd
+				}
+				return m, m.undoSave()
+			}
+
+		case "y":
+			// Copy prefix: yy = document, yp = field path, yv = value, ys = mongosh insert
+			if m.focus == FocusDocuments && len(m.documents) > 0 {
+				return m, m.startKeySequence("y", "copy: yy=document • yp=field path • yv=value • ys=mongosh insert")
+			}
+
+		case "w":
+			// Watch or unwatch the collection under the cursor
+			if m.focus == FocusCollections {
+				return m, m.toggleWatch()
+			}
+			// Wrap or truncate the string value under the cursor
+			if m.focus == FocusDocuments {
+				return m, m.toggleWrap()
+			}
+
+		case "\"":
+			// Show the registers of copied text
+			if m.focus != FocusQuery {
+				return m, m.openRegisters()
+			}
+
+		case "i":
+			// Show the kind and options of the collection under the cursor
+			if m.focus == FocusCollections {
+				return m, m.openCollectionInfo()
+			}
+			// Inspect the binary value under the cursor
+			if m.focus == FocusDocuments {
+				return m, m.inspectBinary()
+			}
+
+		case "x":
+			// List the indexes of the collection under the cursor
+			if m.focus == FocusCollections {
+				return m, m.openIndexes()
+			}
+
+		case "S":
+			// Sample the collection under the cursor and summarize its fields
+			if m.focus == FocusCollections {
+				return m, m.openSchemaSummary()
+			}
+
+		case "I":
+			// Import a JSON/NDJSON file into the collection under the cursor,
+			// or a database export directory into the database under it
+			if m.focus == FocusCollections {
+				return m, m.openImportPrompt()
+			}
+			if m.focus == FocusDatabases {
+				return m, m.openDumpPrompt(true)
+			}
+
+		case ".":
+			// Show the hidden system databases and collections, or hide them again
+			if m.focus == FocusDatabases || m.focus == FocusCollections {
+				return m, m.toggleSystemReveal()
+			}
+
+		case "alt+.":
+			// Hide system databases and collections by default, or stop
+			if m.focus == FocusDatabases || m.focus == FocusCollections {
+				return m, m.toggleHideSystem()
+			}
+
+		case "C":
+			// Clone the collection under the cursor under a new name
+			if m.focus == FocusCollections {
+				return m, m.openClonePrompt()
+			}
+
+		case "E":
+			// Export every collection of the database under the cursor
+			if m.focus == FocusDatabases {
+				return m, m.openDumpPrompt(false)
+			}
+
+		case "ctrl+x":
+			// Command prefix for documents panel actions
+			if m.focus == FocusDocuments && m.selectedCollection != "" {
+				return m, m.startKeySequence("ctrl+x", "ctrl+x: s=export results • b=pinboard • c=clear pins • y=copy pinned _ids • o=copy pinned to collection • L/H=expand/collapse page • p=read in $PAGER • m=schema as Markdown • h=HTML snapshot • f=find in database • u=update matches • U=update in $EDITOR • t=benchmark query • i=server info • r=replica set • a=operations • q/Q=profiler, level • P=pause background work • !=check environment")
+			}
+			// The server-wide commands work from every panel
+			if m.client != nil {
+				return m, m.startKeySequence("ctrl+x", "ctrl+x: i=server info • r=replica set • a=operations • q/Q=profiler, level • P=pause background work • !=check environment")
+			}
+
+		case "*":
+			// Pin or unpin the document under the cursor
+			if m.focus == FocusDocuments && len(m.documents) > 0 {
+				return m, m.togglePin()
+			}
+
+		case "Y":
+			// Build a redacted reproduction bundle for the current query and preview it
+			if m.focus == FocusDocuments && m.selectedCollection != "" && m.client != nil {
+				return m, tea.Batch(
+					m.setStatus("building reproduction bundle..."),
+					buildReproBundle(m.client, m.selectedDatabase, m.selectedCollection, m.queryFilter, m.sortSpec()),
+				)
 			}
 		}
 
+	case treeRebuildMsg:
+		// The deferred rebuild itself happened before the switch
+		m.treeRebuildScheduled = false
+
+	case referenceLoadedMsg:
+		if msg.err != nil {
+			m.errorModal = true
+			m.errorMessage = fmt.Sprintf("Failed to fetch referenced document: %v", msg.err)
+			return m, nil
+		}
+		// The page may have changed while the document was loading
+		if m.treeContains(msg.node) {
+			m.statusMessage = ""
+			m.graftReference(msg)
+		}
+
+	case reproBundleMsg:
+		if msg.err != nil {
+			m.errorModal = true
+			m.errorMessage = fmt.Sprintf("Failed to build reproduction bundle: %v", msg.err)
+			return m, nil
+		}
+		m.statusMessage = ""
+		m.openViewer(ViewerReproBundle, "Reproduction Bundle", msg.text)
+
+	case clipboardCopiedMsg:
+		if msg.err != nil {
+			return m, m.setStatus(fmt.Sprintf("no clipboard (%v): copied to register 0, \" shows it", msg.err))
+		}
+		return m, m.setStatus(fmt.Sprintf("copied %s", formatBytes(msg.size)))
+
+	case jobProgressMsg:
+		if job := m.findJob(msg.id); job != nil && job.State == JobRunning {
+			job.Progress = msg.progress
+		}
+		return m, waitForJob(msg.id, msg.ch)
+
+	case jobFinishedMsg:
+		return m, m.handleJobFinished(msg)
+
+	case exportDoneMsg:
+		return m, m.setStatus(fmt.Sprintf("exported %d documents (%s) to %s", msg.count, formatBytes(int(msg.size)), msg.path))
+
+	case importDoneMsg:
+		return m, m.handleImportDone(msg)
+
+	case benchmarkDoneMsg:
+		return m, m.handleBenchmarkDone(msg)
+
+	case databaseExportDoneMsg:
+		return m, m.handleDatabaseExportDone(msg)
+
+	case databaseImportDoneMsg:
+		m.handleDatabaseImportDone(msg)
+
+	case serverSearchMsg:
+		return m, m.handleServerSearch(msg)
+
+	case globalFindMsg:
+		return m, m.handleGlobalFind(msg)
+
+	case confirmCountMsg:
+		m.handleConfirmCount(msg)
+
+	case dropCountMsg:
+		return m, m.handleDropCount(msg)
+
+	case collectionDroppedMsg:
+		return m, m.handleCollectionDropped(msg)
+
+	case databaseDropCountMsg:
+		return m, m.handleDatabaseDropCount(msg)
+
+	case databaseDroppedMsg:
+		return m, m.handleDatabaseDropped(msg)
+
+	case databaseCreatedMsg:
+		return m, m.handleDatabaseCreated(msg)
+
+	case updatePreviewMsg:
+		m.handleUpdatePreview(msg)
+
+	case updateEditedMsg:
+		return m, m.handleUpdateEdited(msg)
+
+	case bulkUpdateDoneMsg:
+		return m, m.handleBulkUpdateDone(msg)
+
+	case valueCountsMsg:
+		m.handleValueCounts(msg)
+
+	case indexesLoadedMsg:
+		m.handleIndexesLoaded(msg)
+
+	case indexEditedMsg:
+		return m, m.handleIndexEdited(msg)
+
+	case indexCreatedMsg:
+		return m, m.handleIndexCreated(msg)
+
+	case indexDroppedMsg:
+		return m, m.handleIndexDropped(msg)
+
+	case copyTargetsMsg:
+		return m, m.handleCopyTargets(msg)
+
+	case copyDoneMsg:
+		return m, m.handleCopyDone(msg)
+
+	case serverInfoMsg:
+		m.handleServerInfo(msg)
+
+	case replSetStatusMsg:
+		m.handleReplSetStatus(msg)
+
+	case currentOpsMsg:
+		m.handleCurrentOps(msg)
+
+	case opKilledMsg:
+		return m, m.handleOpKilled(msg)
+
+	case profileLevelMsg:
+		m.handleProfileLevel(msg)
+
+	case profileSetMsg:
+		return m, m.handleProfileSet(msg)
+
+	case cloneDoneMsg:
+		return m, m.handleCloneDone(msg)
+
+	case schemaAnalyzedMsg:
+		if !msg.summary {
+			m.handleSchemaAnalyzed(msg)
+			return m, nil
+		}
+		m.loadingDocs = false
+		if isUnauthorizedError(msg.err) {
+			m.deniedNamespaces[m.currentNamespace()] = true
+			m.documents = nil
+			m.docTree = nil
+			m.flattenedTree = nil
+			return m, nil
+		}
+		if msg.err != nil {
+			m.errorModal = true
+			m.errorMessage = fmt.Sprintf("Schema sampling failed: %v", msg.err)
+			return m, nil
+		}
+		m.showSchemaSummary(msg.analysis)
+
+	case watchTickMsg:
+		if msg.seq != m.watchPollSeq {
+			return m, nil // Stale loop from a previous connection
+		}
+		if m.watchPollPaused() {
+			return m, scheduleWatchPoll(m.watchPollSeq)
+		}
+		return m, tea.Batch(pollWatches(m.client, m.pollableWatches()), scheduleWatchPoll(m.watchPollSeq))
+
+	case watchCountsMsg:
+		return m, m.applyWatchCounts(msg.counts)
+
+	case tea.ResumeMsg:
+		return m, m.resume()
+
+	case statusClearMsg:
+		// Toasts expiring while suspended are shown again on resume
+		if msg.seq == m.statusSeq && !m.suspended {
+			m.statusMessage = ""
+		}
+
+	case fullDocumentMsg:
+		if msg.err != nil {
+			m.errorModal = true
+			m.errorMessage = fmt.Sprintf("Failed to fetch the full document for editing: %v", msg.err)
+			return m, nil
+		}
+		docIndex := m.documentIndexByID(msg.doc["_id"])
+		if docIndex < 0 {
+			return m, m.setStatus("document is no longer on this page")
+		}
+		m.replaceDocument(docIndex, msg.doc)
+		m.editorActive = true
+		return m, m.openInEditor(docIndex)
+
 	case editorFinishedMsg:
-		// Clean up temp file
-		defer os.Remove(msg.tempFile)
+		m.editorActive = false
 
 		if msg.err != nil {
+			// Quitting the editor with an error status cancels the edit
+			if exit, ok := editorExit(msg.err); ok {
+				m.keepEdit(msg.tempFile)
+				return m, m.setStatus(fmt.Sprintf("editor %s — edit discarded, kept in %s until you quit • e: retry", exit, msg.tempFile))
+			}
+			os.Remove(msg.tempFile)
 			m.errorModal = true
-			m.errorMessage = fmt.Sprintf("Editor error: %v", msg.err)
+			m.errorMessage = editorErrorMessage(msg.err)
 			return m, nil
 		}
 
@@ -582,55 +1642,119 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.errorMessage = fmt.Sprintf("Failed to read edited file: %v", err)
 			return m, nil
 		}
+		newJSON = stripEditError(newJSON)
 
 		// Check if content changed
 		if string(newJSON) == string(msg.originalJSON) {
 			// No changes, nothing to do
+			os.Remove(msg.tempFile)
 			return m, nil
 		}
 
-		// Parse the new JSON
-		var newDoc bson.M
-		if err := json.Unmarshal(newJSON, &newDoc); err != nil {
-			m.errorModal = true
-			m.errorMessage = fmt.Sprintf("Invalid JSON: %v\n\nDocument was NOT saved.", err)
-			return m, nil
+		// Parse the new JSON, keeping the edit to fix when it doesn't parse
+		if msg.path != nil {
+			value, err := parseEditedValue(newJSON)
+			if err != nil {
+				return m, m.offerReopenEditor(msg, newJSON, err)
+			}
+			return m, m.confirmEditedValue(msg, value)
+		}
+		newDoc, err := parseEditedDocument(newJSON)
+		if err != nil {
+			return m, m.offerReopenEditor(msg, newJSON, err)
 		}
 
-		// Save to MongoDB
-		return m, m.saveDocument(msg.docIndex, newDoc)
+		// Save to MongoDB once the changes are confirmed
+		return m, m.confirmEditedDocument(msg, newDoc)
 
 	case documentSavedMsg:
+		if msg.conflict != nil {
+			m.saveConflict = msg.conflict
+			return m, nil
+		}
 		if msg.err != nil {
 			m.errorModal = true
 			m.errorMessage = fmt.Sprintf("Failed to save document: %v\n\nDocument was NOT saved.", msg.err)
+			if msg.tempFile != "" {
+				m.errorMessage += " Your edit is kept in " + msg.tempFile
+			}
 			return m, nil
 		}
+		os.Remove(msg.tempFile)
 
-		// Update local state with the new document
-		m.documents[msg.docIndex] = msg.newDoc
-		m.docTree[msg.docIndex] = buildJSONTree(msg.newDoc, 0)
-		m.docTree[msg.docIndex].Collapsed = false
-		m.rebuildFlattenedTree()
+		// Update local state if the document is still on the page
+		if docIndex := m.documentIndexByID(msg.docID); docIndex >= 0 {
+			m.replaceDocument(docIndex, msg.newDoc)
+		}
+		if msg.undo {
+			m.dropLastSave(msg.namespace, msg.docID)
+			return m, m.setStatus(fmt.Sprintf("reverted _id=%s to before the save", shortID(msg.docID)))
+		}
+		if msg.before != nil {
+			m.recordSave(msg.namespace, msg.docID, msg.before, msg.newDoc)
+		}
+
+	case documentInsertedMsg:
+		if msg.err != nil {
+			m.errorModal = true
+			m.errorMessage = fmt.Sprintf("Failed to insert document: %v\n\nDocument was NOT inserted. Your edit is kept in %s", msg.err, msg.tempFile)
+			return m, nil
+		}
+		os.Remove(msg.tempFile)
+
+		// Show the new document below the page it was edited from
+		if msg.namespace == m.currentNamespace() {
+			m.appendDocument(msg.doc)
+			m.totalDocs++
+		}
+		return m, m.setStatus(fmt.Sprintf("inserted as new document _id=%s", shortID(msg.doc["_id"])))
 
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		m.reflow()
+
+	case reauthClientMsg:
+		return m, m.handleReauthClient(msg)
 
 	case databasesLoadedMsg:
 		m.loading = false
+		if m.authReconnecting {
+			return m, m.handleReauthResult(msg)
+		}
+		if isAuthError(msg.err) {
+			// Let a mistyped or outdated password be typed again
+			m.authFailures++
+			return m, m.openPasswordPrompt(msg.err)
+		}
 		if msg.err != nil {
 			m.err = msg.err
 			return m, nil
 		}
+		m.authFailures = 0
+		// Only the load right after connecting picks a database; later ones
+		// keep the user's selection
+		firstLoad := m.selectedDatabase == ""
 		m.databases = msg.databases
 		m.updateFilteredDatabases()
 
 		// Store client for later use - reconnect to get it
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-		client, _ := mongo.Connect(ctx, options.Client().ApplyURI(m.activeConnString))
+		client := msg.client
+		if client == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			client, _ = mongo.Connect(ctx, options.Client().ApplyURI(m.activeConnString))
+		}
 		m.client = client
+		m.serverVersion = serverVersion{}
+		watchCmd := tea.Batch(m.startWatchPolling(), loadServerVersion(client))
+
+		// The database given on the command line comes first
+		if m.launch.db != "" && firstLoad {
+			if cmd := m.openLaunchDatabase(msg.listDenied); cmd != nil {
+				return m, tea.Batch(watchCmd, cmd)
+			}
+		}
 
 		// Check if we should auto-select a database from DATABASE_NAME env var
 		if m.autoSelectDB != "" {
@@ -640,30 +1764,88 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.selectedDatabase = db
 					m.focus = FocusCollections // Shift focus to Collections panel
 					m.autoSelectDB = ""        // Clear so we don't re-trigger
-					return m, loadCollections(m.client, m.selectedDatabase)
+					return m, tea.Batch(watchCmd, loadCollections(m.client, m.selectedDatabase))
 				}
 			}
 			// Database not found, clear autoSelectDB and fall through to default behavior
 			m.autoSelectDB = ""
 		}
 
+		// The connection's default database comes before the connection string's
+		if m.connectionDatabase != "" && firstLoad {
+			cmd, opened := m.openDefaultDatabase(msg.listDenied)
+			if opened {
+				return m, tea.Batch(watchCmd, cmd)
+			}
+			watchCmd = tea.Batch(watchCmd, cmd)
+		}
+
+		// The connection string's default database is listed even when
+		// listDatabases doesn't return it (no data yet, or no permission to
+		// list), and preselected once connected
+		if uriDB, _ := uriDefaults(m.connectionString); uriDB != "" {
+			found := false
+			for _, db := range m.databases {
+				if db == uriDB {
+					found = true
+					break
+				}
+			}
+			if !found {
+				m.databases = append(m.databases, uriDB)
+				sort.Strings(m.databases)
+				m.updateFilteredDatabases()
+			}
+			if firstLoad {
+				for i, db := range m.dbFiltered {
+					if db == uriDB {
+						m.dbCursor = i
+					}
+				}
+				m.selectedDatabase = uriDB
+				m.focus = FocusCollections
+				cmds := []tea.Cmd{watchCmd, loadCollections(m.client, m.selectedDatabase)}
+				if msg.listDenied {
+					cmds = append(cmds, m.setStatus(fmt.Sprintf("no permission to list databases: opened %s from the connection string", uriDB)))
+				}
+				return m, tea.Batch(cmds...)
+			}
+		}
+
+		if !firstLoad {
+			// Keep the cursor on the selection as the list changes around it
+			for i, db := range m.dbFiltered {
+				if db == m.selectedDatabase {
+					m.dbCursor = i
+				}
+			}
+			return m, watchCmd
+		}
+
 		// Don't auto-load collections - user may not have access to all databases
 		// Just select the first database visually, user will press Enter to load collections
 		if len(m.dbFiltered) > 0 {
 			m.selectedDatabase = m.dbFiltered[0]
 		}
+		return m, watchCmd
 
 	case collectionsLoadedMsg:
 		if msg.err != nil {
+			// A previously working session failing auth usually means the password rotated
+			if isAuthError(msg.err) {
+				return m, m.openAuthPrompt(AuthRetryCollections, msg.err)
+			}
 			// Only show error if user explicitly selected the database (pressed Enter)
 			// Silently swallow errors when just arrowing through the list
 			if m.explicitDBSelect {
 				m.errorModal = true
 				m.errorMessage = fmt.Sprintf("Failed to load collections: %v", msg.err)
 			}
+			m.launch = launchOptions{}
 			return m, nil
 		}
 		m.collections = msg.collections
+		m.collSpecs = msg.specs
 		m.collCursor = 0
 		// Reset search state
 		m.collSearchActive = false
@@ -675,72 +1857,159 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.totalDocs = 0
 		m.docTree = nil
 		m.flattenedTree = nil
+		if m.launch.collection != "" {
+			return m, tea.Batch(m.countCollections(), m.openLaunchCollection())
+		}
+		return m, m.countCollections()
+
+	case collectionCountMsg:
+		m.handleCollectionCount(msg)
+
+	case databasesRefreshedMsg:
+		return m, m.handleDatabasesRefreshed(msg)
+
+	case collectionsRefreshedMsg:
+		return m, m.handleCollectionsRefreshed(msg)
 
 	case documentsLoadedMsg:
+		if msg.namespace != m.currentNamespace() {
+			// Results for a collection left while they loaded; the load of
+			// the current one is still on its way
+			return m, nil
+		}
 		m.loadingDocs = false
 		m.queryLoading = false
 		if msg.err != nil {
+			if isAuthError(msg.err) {
+				return m, m.openAuthPrompt(AuthRetryDocuments, msg.err)
+			}
+			if isUnauthorizedError(msg.err) {
+				// Shown in the documents panel rather than the error modal
+				m.deniedNamespaces[m.currentNamespace()] = true
+				m.documents = nil
+				m.docTree = nil
+				m.flattenedTree = nil
+				m.totalDocs = 0
+				return m, nil
+			}
 			m.errorModal = true
 			m.errorMessage = fmt.Sprintf("Query error: %v", msg.err)
 			return m, nil
 		}
+		delete(m.deniedNamespaces, m.currentNamespace())
+		// Navigation stays live while a query runs, so keep the cursor on
+		// the same document when it's still in the results
+		cursorID, cursorPath := m.cursorPath()
+		cursorRow := m.docCursor - m.docScrollOffset
+		if !m.schemaActive {
+			m.saveExpandState()
+		}
+		m.schemaActive = false
 		m.documents = msg.documents
 		m.totalDocs = msg.totalCount
-		// Build tree structure
+		m.docProvenance = msg.provenance
+		m.docFullscreen = false
+		var cmd tea.Cmd
+		if msg.countRefreshed {
+			m.currentPage = msg.page
+			cmd = m.setStatus("collection changed, counts refreshed")
+		}
+		// Trees are built by the load itself; expand them as the documents
+		// were last shown
 		m.docTree = make([]*JSONNode, len(m.documents))
 		for i, doc := range m.documents {
-			m.docTree[i] = buildJSONTree(doc, 0)
-			m.docTree[i].Collapsed = false // Expand root level
+			if len(msg.trees) == len(m.documents) {
+				m.docTree[i] = m.restoreExpandState(msg.trees[i])
+			} else {
+				m.docTree[i] = m.buildDocumentTree(doc)
+			}
 		}
 		m.rebuildFlattenedTree()
+		m.refreshOccurrences()
+		m.restoreCursorToDocument(cursorID, cursorPath, cursorRow)
+		m.applyDocumentAnchor()
+		return m, cmd
 
 	case spinner.TickMsg:
-		if m.queryLoading {
-			var cmd tea.Cmd
-			m.querySpinner, cmd = m.querySpinner.Update(msg)
-			return m, cmd
+		// The tick loop ends with the last load; Update starts the next one
+		if !m.busy() {
+			m.spinnerTicking = false
+			return m, nil
 		}
-		m.docCursor = 0
-		m.docScrollOffset = 0
+		var cmd tea.Cmd
+		m.querySpinner, cmd = m.querySpinner.Update(msg)
+		return m, cmd
 
 	case connectionsLoadedMsg:
-		if msg.err != nil {
-			m.err = msg.err
-			return m, nil
+		var tooNew *schemaTooNewError
+		if errors.As(msg.err, &tooNew) {
+			return m, m.offerReadOnlyStore(tooNew)
 		}
-		// Merge saved connections with default localhost
-		m.connections = defaultConnections
-		for _, conn := range msg.connections {
-			// Don't duplicate if name matches a default
-			isDuplicate := false
-			for _, def := range defaultConnections {
-				if conn.Name == def.Name {
-					isDuplicate = true
-					break
-				}
-			}
-			if !isDuplicate {
-				m.connections = append(m.connections, conn)
+		if msg.err != nil {
+			// The default connections still work without the saved ones
+			m.connections = mergeConnections(nil)
+			m.updateFilteredConnections()
+			m.errorModal = true
+			m.errorMessage = fmt.Sprintf("Failed to load saved connections: %v", msg.err)
+			if m.launch.uri != "" {
+				return m, tea.Batch(runHealthChecks(m.connections, nil, false), m.connectFromCommandLine())
 			}
+			return m, runHealthChecks(m.connections, nil, false)
 		}
+		// Merge saved connections with default localhost
+		m.connections = mergeConnections(msg.connections)
+		m.storeEncrypted, _ = encryptionEnabled()
+		order, _ := loadSetting(connectionOrderSetting)
+		m.connSortByName = order == "name"
 		// Initialize filtered connections
-		m.updateFilteredConnections()
+		m.sortConnections()
+		if display, err := loadSetting(dateDisplaySetting); err == nil {
+			m.dateDisplay = parseDateDisplay(display)
+		}
+		m.lastConnection, _ = loadSetting(lastConnectionSetting)
+		if warmup, err := loadSetting(warmupSetting); err == nil {
+			m.warmupOff = warmup == "off"
+		}
+		hideSystem, _ := loadSetting(hideSystemSetting)
+		m.hideSystem = hideSystem != "off"
+		loadServerCommandsLimit()
+
+		healthCheck := runHealthChecks(m.connections, nil, false)
+
+		if m.launch.uri != "" {
+			return m, tea.Batch(healthCheck, m.connectFromCommandLine())
+		}
 
 		// If DATABASE_NAME env var is set, auto-connect using localhost
 		if m.autoSelectDB != "" && len(m.connections) > 0 {
 			// Use the first connection (localhost)
-			conn := m.connections[0]
-			m.connectionString = conn.ConnectionString
-			m.sshAlias = conn.SSHAlias
+			m.useConnection(m.connections[0])
 			m.screen = ScreenMain
 			m.loading = true
-			if m.sshAlias != "" {
-				return m, establishSSHTunnel(m.sshAlias, m.connectionString)
-			}
-			// Direct connection
-			m.activeConnString = m.connectionString
-			return m, connectToMongo(m.connectionString)
+			return m, tea.Batch(healthCheck, m.connect())
 		}
+		return m, tea.Batch(healthCheck, m.startWarmup())
+
+	case storeLockedMsg:
+		return m, m.openPassphrasePrompt(false)
+
+	case storeUnlockedMsg:
+		return m, m.handleStoreUnlocked(msg)
+
+	case storeEncryptedMsg:
+		return m, m.handleStoreEncrypted(msg)
+
+	case healthCheckedMsg:
+		m.handleHealthChecked(msg)
+
+	case pagerClosedMsg:
+		return m, m.handlePagerClosed(msg)
+
+	case serverVersionMsg:
+		return m, m.handleServerVersion(msg)
+
+	case warmupDoneMsg:
+		return m, m.handleWarmupDone(msg)
 
 	case sshTunnelEstablishedMsg:
 		if msg.err != nil {
@@ -763,6 +2032,8 @@ func (m Model) View() string {
 		return m.renderConnectionsScreen()
 	}
 
+	// Only failing to connect leaves nothing to show; recoverable errors
+	// go to the error modal
 	if m.err != nil {
 		// Wrap long error messages for readability
 		errStr := fmt.Sprintf("%v", m.err)
@@ -841,35 +2112,222 @@ func (m Model) View() string {
 
 	// Join left and right panels
 	mainContent := lipgloss.JoinHorizontal(lipgloss.Top, leftPanel, " ", rightPanel)
+	if m.docFullscreen {
+		mainContent = m.renderDocumentsPanel(m.width-2, availableHeight-2)
+	}
 
-	// Help text
+	// Help text (replaced by a transient status message when one is showing)
 	help := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("241")).
-		Render("↑/↓: navigate • /: search • ←/→/space: collapse/expand • n/p: next/prev page • e: edit • tab: switch • q: quit")
+		Render("↑/↓: navigate • /: search • ←/→/space: collapse/expand • n/p: next/prev page • e: edit • U: undo save • yy/yp/yv: copy doc/path/value • Y: repro • tab: switch • q: quit")
+	if m.statusMessage != "" {
+		help = statusMessageStyle.Render(m.statusMessage)
+	} else if name := m.truncatedNameAtCursor(); name != "" {
+		// Full name of the shortened list entry under the cursor
+		help = statusMessageStyle.Render(name)
+	} else if jobs := m.jobsSummary(); jobs != "" {
+		help = statusMessageStyle.Render(jobs+" •") + " " + help
+	} else if pinned := len(m.pinnedIDs()); pinned > 0 && m.selectedCollection != "" {
+		help = statusMessageStyle.Render(fmt.Sprintf("%d pinned •", pinned)) + " " + help
+	}
+	// The environment stays in sight whatever the help line shows
+	if badge := m.environmentBadge(); badge != "" {
+		help = badge + " " + help
+	}
+	// Cut the help line rather than let the terminal wrap it
+	help = lipgloss.NewStyle().MaxWidth(m.width).Render(help)
 
 	result := lipgloss.JoinVertical(lipgloss.Left, mainContent, help)
 
+	// Overlay credential prompt if open
+	if m.authPromptActive {
+		result = m.renderAuthPrompt(result)
+	}
+
+	// Overlay watch prompt if open
+	if m.watchPromptActive {
+		result = m.renderWatchPrompt(result)
+	}
+
+	// Overlay export prompt if open
+	if m.exportPromptActive {
+		result = m.renderExportPrompt(result)
+	}
+
+	// Overlay HTML snapshot prompt if open
+	if m.snapshotPromptActive {
+		result = m.renderSnapshotPrompt(result)
+	}
+
+	// Overlay import prompt if open
+	if m.importPromptActive {
+		result = m.renderImportPrompt(result)
+	}
+
+	// Overlay clone prompt if open
+	if m.cloneActive {
+		result = m.renderClonePrompt(result)
+	}
+
+	// Overlay new database prompt if open
+	if m.createDBActive {
+		result = m.renderCreateDatabasePrompt(result)
+	}
+
+	// Overlay replica set status if open
+	if m.replSetActive {
+		result = m.renderReplSetOverlay(result)
+	}
+
+	// Overlay operations in progress if open
+	if m.opsActive {
+		result = m.renderOpsOverlay(result)
+	}
+
+	// Overlay profiling level prompt if open
+	if m.profileActive {
+		result = m.renderProfileLevelPrompt(result)
+	}
+
+	// Overlay database export/import prompt if open
+	if m.dumpPromptActive {
+		result = m.renderDumpPrompt(result)
+	}
+
+	// Overlay test data generator prompt if open
+	if m.generatePromptActive {
+		result = m.renderGeneratePrompt(result)
+	}
+
+	// Overlay query benchmark prompt if open
+	if m.benchmarkPromptActive {
+		result = m.renderBenchmarkPrompt(result)
+	}
+
+	// Overlay reference target prompt if open
+	if m.refPromptActive {
+		result = m.renderReferencePrompt(result)
+	}
+
+	// Overlay bulk update prompt if open
+	if m.updatePromptActive {
+		result = m.renderUpdatePrompt(result)
+	}
+
+	// Overlay value prompt if open
+	if m.valuePromptActive {
+		result = m.renderValuePrompt(result)
+	}
+
+	// Overlay global find prompt if open
+	if m.findPromptActive {
+		result = m.renderFindPrompt(result)
+	}
+
+	// Overlay registers if open
+	if m.registersActive {
+		result = m.renderRegisters(result)
+	}
+
+	// Overlay jobs list if open
+	if m.jobsOverlayActive {
+		result = m.renderJobsOverlay(result)
+	}
+
+	// Overlay value frequency list if open
+	if m.freqActive {
+		result = m.renderFrequencyOverlay(result)
+	}
+
+	// Overlay indexes if open
+	if m.indexesActive {
+		result = m.renderIndexesOverlay(result)
+	}
+
+	// Overlay copy picker if open
+	if m.copyActive {
+		result = m.renderCopyPicker(result)
+	}
+
+	// Overlay global find results if open
+	if m.findActive {
+		result = m.renderFindResults(result)
+	}
+
+	// Overlay save conflict modal if open
+	if m.saveConflict != nil {
+		result = m.renderSaveConflict(result)
+	}
+
+	// Overlay text viewer if open
+	if m.viewerKind != ViewerNone {
+		result = m.renderViewer(result)
+	}
+
+	// Overlay confirmation modal if open
+	if m.confirm != nil {
+		result = m.renderConfirmModal(result)
+	}
+
 	// Overlay error modal if active
 	if m.errorModal {
 		result = m.renderErrorModal(result)
 	}
 
+	// Overlay environment warnings if open
+	if m.healthPanelActive {
+		result = m.renderHealthPanel(result)
+	}
+
 	return result
 }
 
-func (m Model) renderErrorModal(background string) string {
-	// Create modal box
-	modalWidth := 50
-	if m.width-10 < modalWidth {
-		modalWidth = m.width - 10
+// reflow re-clamps the scroll positions that depend on the terminal size
+// after a resize
+func (m *Model) reflow() {
+	m.scrollErrorModal(0)
+	m.scrollViewer(0)
+	m.moveFrequencyCursor(m.freqCursor)
+	m.moveIndexesCursor(m.indexesCursor)
+	m.moveOpsCursor(m.opsCursor)
+	if len(m.flattenedTree) > 0 {
+		m.rewrap()
+		m.adjustScrollForCursor()
 	}
-	if modalWidth < 20 {
-		modalWidth = 20
+}
+
+// getErrorModalWidth returns the inner width of the error modal
+func (m Model) getErrorModalWidth() int {
+	return m.modalWidth(50)
+}
+
+// modalWidth returns the width of a modal that is preferred columns wide on
+// a large enough terminal, narrowed to fit the current one. Renderers call
+// it on every frame so modals follow terminal resizes.
+func (m Model) modalWidth(preferred int) int {
+	width := preferred
+	if m.width-10 < width {
+		width = m.width - 10
+	}
+	if width < 20 {
+		width = 20
+	}
+	return width
+}
+
+// fitInput renders a text input no wider than width columns, prompt included
+func fitInput(input textinput.Model, width int) string {
+	if fit := width - lipgloss.Width(input.Prompt) - 1; input.Width > fit {
+		input.Width = fit
 	}
+	return input.View()
+}
 
-	// Wrap error message
+// errorModalLines returns the wrapped error message and how many of its
+// lines fit in the modal
+func (m Model) errorModalLines() ([]string, int) {
 	msg := m.errorMessage
-	maxMsgWidth := modalWidth - 6 // Account for padding and border
+	maxMsgWidth := m.getErrorModalWidth() - 6 // Account for padding and border
 	if len(msg) > maxMsgWidth {
 		// Simple word wrap
 		var lines []string
@@ -894,6 +2352,37 @@ func (m Model) renderErrorModal(background string) string {
 		msg = strings.Join(lines, "\n")
 	}
 
+	// Border (2) + padding (2) + title and blank (2) + blank and hint (2)
+	visible := m.height - 4 - 8
+	if visible < 3 {
+		visible = 3
+	}
+	return strings.Split(msg, "\n"), visible
+}
+
+// scrollErrorModal moves the error modal scroll offset by delta, clamped to the message
+func (m *Model) scrollErrorModal(delta int) {
+	lines, visible := m.errorModalLines()
+	m.errorScroll = clampScroll(m.errorScroll+delta, len(lines), visible)
+}
+
+func (m Model) renderErrorModal(background string) string {
+	// Create modal box
+	modalWidth := m.getErrorModalWidth()
+
+	// Show the window of the wrapped message that fits on screen
+	lines, visible := m.errorModalLines()
+	start := clampScroll(m.errorScroll, len(lines), visible)
+	end := start + visible
+	if end > len(lines) {
+		end = len(lines)
+	}
+	msg := strings.Join(lines[start:end], "\n")
+	hint := "Press Enter, Esc, or Space to dismiss"
+	if len(lines) > visible {
+		hint = fmt.Sprintf("Lines %d-%d of %d • ↑/↓/PgUp/PgDn to scroll • Enter/Esc to dismiss", start+1, end, len(lines))
+	}
+
 	errorTitleStyle := lipgloss.NewStyle().
 		Bold(true).
 		Foreground(lipgloss.Color("196"))
@@ -907,7 +2396,7 @@ func (m Model) renderErrorModal(background string) string {
 		"",
 		msg,
 		"",
-		hintStyle.Render("Press Enter, Esc, or Space to dismiss"),
+		hintStyle.Render(hint),
 	)
 
 	modalStyle := lipgloss.NewStyle().
@@ -955,8 +2444,8 @@ func (m Model) renderPanel(title, rightInfo, content string, focused bool, width
 
 		// Truncate title if needed (before styling)
 		displayTitle := title
-		if len(displayTitle) > maxTitleWidth-1 { // -1 for the padding in titleStyle
-			displayTitle = displayTitle[:maxTitleWidth-4] + "..."
+		if runes := []rune(displayTitle); lipgloss.Width(displayTitle) > maxTitleWidth-1 && len(runes) > maxTitleWidth-4 { // -1 for the padding in titleStyle
+			displayTitle = string(runes[:maxTitleWidth-4]) + "..."
 		}
 		titleRendered := titleStyle.Render(displayTitle)
 		titleWidth := lipgloss.Width(titleRendered)
@@ -970,8 +2459,8 @@ func (m Model) renderPanel(title, rightInfo, content string, focused bool, width
 	} else {
 		// No right info, just truncate title if needed
 		displayTitle := title
-		if len(displayTitle) > contentWidth-1 {
-			displayTitle = displayTitle[:contentWidth-4] + "..."
+		if runes := []rune(displayTitle); lipgloss.Width(displayTitle) > contentWidth-1 && len(runes) > contentWidth-4 {
+			displayTitle = string(runes[:contentWidth-4]) + "..."
 		}
 		header = titleStyle.Render(displayTitle)
 	}
@@ -1017,17 +2506,92 @@ func truncate(s string, maxLen int) string {
 	return s[:maxLen-3] + "..."
 }
 
+// truncateMiddle shortens s to at most width terminal columns by replacing
+// its middle with an ellipsis, keeping the prefix and suffix that usually
+// tell similar names apart (events_v2_…_customer_activity)
+func truncateMiddle(s string, width int) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	if width <= 1 {
+		return strings.Repeat("…", width)
+	}
+	runes := []rune(s)
+	budget := width - 1 // Columns left beside the ellipsis
+	headWidth := (budget + 1) / 2
+	head, used := 0, 0
+	for head < len(runes) {
+		w := lipgloss.Width(string(runes[head]))
+		if used+w > headWidth {
+			break
+		}
+		used += w
+		head++
+	}
+	tail := len(runes)
+	for tail > head {
+		w := lipgloss.Width(string(runes[tail-1]))
+		if used+w > budget {
+			break
+		}
+		used += w
+		tail--
+	}
+	return string(runes[:head]) + "…" + string(runes[tail:])
+}
+
+// maxToastNameWidth caps the names quoted in status messages so the rest of
+// the message stays visible
+const maxToastNameWidth = 40
+
+// listNameWidth returns the columns left for a name in the database and
+// collection lists beside its suffix badge
+func listNameWidth(suffix string) int {
+	// Panel width - borders (2) - panel padding (2) - item padding (2)
+	width := leftPanelWidth - 6
+	if suffix != "" {
+		width -= lipgloss.Width(suffix) + 1
+	}
+	return width
+}
+
+// truncatedNameAtCursor returns the full name of the database or collection
+// under the cursor when the focused list shows it shortened, or ""
+func (m Model) truncatedNameAtCursor() string {
+	switch m.focus {
+	case FocusDatabases:
+		if m.dbCursor < len(m.dbFiltered) {
+			if name := m.dbFiltered[m.dbCursor]; lipgloss.Width(name) > listNameWidth("") {
+				return "database: " + name
+			}
+		}
+	case FocusCollections:
+		if m.collCursor < len(m.collFiltered) {
+			suffixes := m.collectionSuffixes()
+			if name := m.collFiltered[m.collCursor]; lipgloss.Width(name) > listNameWidth(suffixes[m.collCursor]) {
+				return "collection: " + name
+			}
+		}
+	}
+	return ""
+}
+
 func (m Model) renderList(items []string, cursor int, focused bool, maxHeight int) string {
 	return m.renderListWithSelection(items, cursor, focused, maxHeight, true)
 }
 
 func (m Model) renderListWithSelection(items []string, cursor int, focused bool, maxHeight int, showUnfocusedSelection bool) string {
+	return m.renderListWithSuffixes(items, nil, cursor, focused, maxHeight, showUnfocusedSelection)
+}
+
+// renderListWithSuffixes renders a list where each item may carry a short
+// right-aligned suffix (badges, counts); names are truncated to make room
+func (m Model) renderListWithSuffixes(items, suffixes []string, cursor int, focused bool, maxHeight int, showUnfocusedSelection bool) string {
 	if len(items) == 0 {
 		return normalStyle.Render("(empty)")
 	}
 
-	// Calculate max item width: panel width - borders (2) - panel padding (2) - item padding (2)
-	maxItemWidth := leftPanelWidth - 6
+	maxItemWidth := listNameWidth("")
 
 	// Calculate visible window around cursor
 	visibleItems := maxHeight
@@ -1053,7 +2617,13 @@ func (m Model) renderListWithSelection(items []string, cursor int, focused bool,
 
 	var rendered string
 	for i := start; i < end; i++ {
-		item := truncate(items[i], maxItemWidth)
+		item := truncateMiddle(items[i], maxItemWidth)
+		if i < len(suffixes) && suffixes[i] != "" {
+			suffix := suffixes[i]
+			nameWidth := listNameWidth(suffix)
+			name := truncateMiddle(items[i], nameWidth)
+			item = name + strings.Repeat(" ", nameWidth-lipgloss.Width(name)+1) + suffix
+		}
 		if i == cursor && focused {
 			rendered += selectedStyle.Render(item) + "\n"
 		} else if i == cursor && showUnfocusedSelection {
@@ -1067,10 +2637,32 @@ func (m Model) renderListWithSelection(items []string, cursor int, focused bool,
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "get" {
+		err := runGet(os.Args[2:], os.Stdout, os.Stderr, isTerminal(os.Stdout))
+		closeDB()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "mbongo get: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	opts, err := parseLaunchOptions(os.Args[1:], os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		os.Exit(2)
+	}
+
 	defer closeDB()
 
-	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	m := initialModel()
+	m.launch = opts
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	final, err := p.Run()
+	removeKeptEdits(final)
+	if err != nil {
 		fmt.Printf("Error running program: %v\n", err)
 		os.Exit(1)
 	}
@@ -1,23 +1,43 @@
 package main
 
-import "go.mongodb.org/mongo-driver/bson"
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
 
 // Messages for async operations
 
 type databasesLoadedMsg struct {
-	databases []string
-	err       error
+	databases  []string
+	err        error
+	client     *mongo.Client // Client already connected by a warm-up, nil to connect one
+	listDenied bool          // The user may not list databases; databases is the URI's default one
 }
 
 type collectionsLoadedMsg struct {
 	collections []string
+	specs       map[string]collectionSpec // Kind and options per collection
 	err         error
 }
 
 type documentsLoadedMsg struct {
+	namespace  string // Collection the documents were loaded from
 	documents  []bson.M
+	trees      []*JSONNode // Trees of the documents, built off the event loop
 	totalCount int64
+	page       int           // Page actually loaded, which may differ after a recount
+	provenance DocProvenance // How the documents were produced
 	err        error
+	// Whether the collection changed during the load and the count was refreshed
+	countRefreshed bool
+}
+
+// fullDocumentMsg carries the complete source document refetched by _id
+// before editing a projected result
+type fullDocumentMsg struct {
+	doc bson.M
+	err error
 }
 
 // editorFinishedMsg is sent when the editor closes
@@ -25,14 +45,30 @@ type editorFinishedMsg struct {
 	err          error
 	tempFile     string
 	originalJSON []byte
-	docIndex     int
+	docID        interface{} // _id of the edited document, nil if it has none
+	path         []string    // Sub-document edited on its own, nil for the whole document
+	document     bson.M      // Whole document a sub-document edit comes from
 }
 
 // documentSavedMsg is sent when a document is saved to MongoDB
 type documentSavedMsg struct {
 	err      error
-	docIndex int
+	docID    interface{}
 	newDoc   bson.M
+	tempFile string        // The edit it was saved from, removed once saved
+	conflict *saveConflict // Set instead of saving when the document changed meanwhile
+	// Saved documents can be undone later
+	namespace string
+	before    bson.M // Document as it was loaded for editing, nil if unknown
+	undo      bool   // The save reverted the latest save
+}
+
+// documentInsertedMsg is sent when an edited document is inserted as a new one
+type documentInsertedMsg struct {
+	err       error
+	doc       bson.M // The new document with the _id it was given
+	namespace string // Collection it was inserted into
+	tempFile  string // The edit it was inserted from, removed once inserted
 }
 
 // sshTunnelEstablishedMsg is sent when an SSH tunnel is established
@@ -41,3 +77,119 @@ type sshTunnelEstablishedMsg struct {
 	connectionString string // Modified connection string pointing to local tunnel
 	err              error
 }
+
+// clipboardCopiedMsg is sent when a copy to the clipboard completes
+type clipboardCopiedMsg struct {
+	size int // Number of bytes copied
+	err  error
+}
+
+// statusClearMsg is sent when a transient status message expires
+type statusClearMsg struct {
+	seq int // Only clear if no newer status message was shown since
+}
+
+// reproBundleMsg is sent when a reproduction bundle has been assembled
+type reproBundleMsg struct {
+	text string
+	err  error
+}
+
+// watchTickMsg triggers a poll of the watched namespaces
+type watchTickMsg struct {
+	seq int // Poll loop generation; stale loops stop when this doesn't match
+}
+
+// watchCountsMsg carries the polled counts of watched namespaces
+type watchCountsMsg struct {
+	counts map[string]int64
+}
+
+// exportDoneMsg is sent when an export finishes
+type exportDoneMsg struct {
+	path  string
+	count int
+	size  int64
+}
+
+// importDoneMsg is sent when an import finishes
+type importDoneMsg struct {
+	namespace string
+	path      string
+	generated bool // Documents came from the generator, not the file at path
+	inserted  int
+	failed    int
+	errors    []string // Per-document failures, capped at maxImportErrors
+	err       error    // Set when the import stopped before the end of the file
+}
+
+// databaseExportDoneMsg is sent when a database export finishes
+type databaseExportDoneMsg struct {
+	dir      string
+	manifest dumpManifest
+}
+
+// databaseImportDoneMsg is sent when a database export has been restored
+type databaseImportDoneMsg struct {
+	dir         string
+	target      string
+	collections []restoredCollection
+	skipped     []dumpSkipped // Collections the export itself left out
+}
+
+// jobProgressMsg reports the progress line of a running background job
+type jobProgressMsg struct {
+	id       int
+	progress string
+	ch       chan tea.Msg // Channel to keep listening on
+}
+
+// jobFinishedMsg is sent when a background job returns
+type jobFinishedMsg struct {
+	id        int
+	result    tea.Msg // Feature-specific message to deliver, if any
+	err       error
+	cancelled bool
+}
+
+// referenceLoadedMsg carries the document a reference leaf points to
+type referenceLoadedMsg struct {
+	node     *JSONNode // Reference leaf the document is grafted under
+	collName string
+	doc      bson.M // nil when the referenced document doesn't exist
+	err      error
+}
+
+// treeRebuildMsg flushes expand/collapse changes deferred by markTreeDirty
+type treeRebuildMsg struct{}
+
+// schemaAnalyzedMsg carries the field analysis of a sampled collection
+type schemaAnalyzedMsg struct {
+	analysis *schemaAnalysis
+	summary  bool // For the schema summary view rather than Markdown export
+	err      error
+}
+
+// serverSearchMsg carries the filter built for a search across all pages
+type serverSearchMsg struct {
+	query  string
+	filter bson.M
+	err    error
+}
+
+// globalFindMsg carries the collections a find across the database matched in
+type globalFindMsg struct {
+	value    string
+	dbName   string
+	hits     []globalFindHit
+	searched int
+	skipped  int // Collections left out by the cap
+}
+
+// valueCountsMsg carries the most frequent values of a field
+type valueCountsMsg struct {
+	field  string
+	values []valueCount
+	total  int64
+	err    error
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// migration brings the settings database from one schema version to the
+// next. Migrations must be idempotent: databases from before schema_version
+// existed start at version 0 and replay all of them over tables that may
+// already be there.
+type migration struct {
+	description string
+	apply       func(tx *sql.Tx) error
+}
+
+// migrations in the order they were introduced. The schema version is the
+// number applied, so entries are only ever appended.
+var migrations = []migration{
+	{"create connections", createTable(`
+		CREATE TABLE IF NOT EXISTS connections (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			connection_string TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)},
+	{"add connections.ssh_alias", addColumn("connections", "ssh_alias", "TEXT DEFAULT ''")},
+	{"create watches", createTable(`
+		CREATE TABLE IF NOT EXISTS watches (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			connection_name TEXT NOT NULL,
+			namespace TEXT NOT NULL,
+			delta INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(connection_name, namespace)
+		)
+	`)},
+	{"create collection_settings", createTable(`
+		CREATE TABLE IF NOT EXISTS collection_settings (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			connection_name TEXT NOT NULL,
+			namespace TEXT NOT NULL,
+			page_size INTEGER NOT NULL DEFAULT 0,
+			UNIQUE(connection_name, namespace)
+		)
+	`)},
+	{"create reference_targets", createTable(`
+		CREATE TABLE IF NOT EXISTS reference_targets (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			connection_name TEXT NOT NULL,
+			namespace TEXT NOT NULL,
+			field_path TEXT NOT NULL,
+			target_collection TEXT NOT NULL,
+			UNIQUE(connection_name, namespace, field_path)
+		)
+	`)},
+	{"create epoch_suppressions", createTable(`
+		CREATE TABLE IF NOT EXISTS epoch_suppressions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			connection_name TEXT NOT NULL,
+			namespace TEXT NOT NULL,
+			field_path TEXT NOT NULL,
+			UNIQUE(connection_name, namespace, field_path)
+		)
+	`)},
+	{"create settings", createTable(`
+		CREATE TABLE IF NOT EXISTS settings (
+			key TEXT PRIMARY KEY,
+			value TEXT NOT NULL
+		)
+	`)},
+	{"create templates", createTable(`
+		CREATE TABLE IF NOT EXISTS templates (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			connection_name TEXT NOT NULL,
+			namespace TEXT NOT NULL,
+			template TEXT NOT NULL,
+			UNIQUE(connection_name, namespace)
+		)
+	`)},
+	{"create benchmarks", createTable(`
+		CREATE TABLE IF NOT EXISTS benchmarks (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			connection_name TEXT NOT NULL,
+			namespace TEXT NOT NULL,
+			query TEXT NOT NULL,
+			runs INTEGER NOT NULL,
+			min_us INTEGER NOT NULL,
+			median_us INTEGER NOT NULL,
+			max_us INTEGER NOT NULL,
+			docs_examined INTEGER NOT NULL,
+			keys_examined INTEGER NOT NULL,
+			plan TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)},
+	// Holds the key derivation of encrypted connection strings, one row
+	// while encryption is on. Plaintext rows are encrypted at unlock.
+	{"create store_encryption", createTable(`
+		CREATE TABLE IF NOT EXISTS store_encryption (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			salt BLOB NOT NULL,
+			verifier TEXT NOT NULL,
+			time_cost INTEGER NOT NULL,
+			memory_kib INTEGER NOT NULL,
+			threads INTEGER NOT NULL
+		)
+	`)},
+	{"add connections.environment", addColumn("connections", "environment", "TEXT DEFAULT ''")},
+	{"add connections.color", addColumn("connections", "color", "TEXT DEFAULT ''")},
+	{"add connections.default_database", addColumn("connections", "default_database", "TEXT DEFAULT ''")},
+	{"add connections.last_used_at", addColumn("connections", "last_used_at", "DATETIME")},
+}
+
+// createTable returns a migration running a CREATE TABLE IF NOT EXISTS
+func createTable(statement string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		_, err := tx.Exec(statement)
+		return err
+	}
+}
+
+// addColumn returns a migration adding a column unless the table has it
+func addColumn(table, column, definition string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		exists, err := hasColumn(tx, table, column)
+		if err != nil || exists {
+			return err
+		}
+		_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+		return err
+	}
+}
+
+// hasColumn reports whether a table has a column
+func hasColumn(tx *sql.Tx, table, column string) (bool, error) {
+	var count int
+	err := tx.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column).Scan(&count)
+	return count > 0, err
+}
+
+// schemaTooNewError is returned when the settings database was migrated by
+// a newer mbongo than this one. Its tables still hold the columns this
+// version reads, so it can be used read-only.
+type schemaTooNewError struct {
+	found, known int
+}
+
+func (e *schemaTooNewError) Error() string {
+	return fmt.Sprintf("the settings database is newer than this binary (schema version %d, this mbongo knows up to %d)", e.found, e.known)
+}
+
+// errReadOnlyStore is returned by writes to a settings database opened read-only
+var errReadOnlyStore = errors.New("settings are read-only: the settings database is from a newer mbongo")
+
+// dbReadOnly is set when the user chose to keep using a settings database
+// that is newer than this binary
+var dbReadOnly bool
+
+// schemaVersion returns the number of migrations applied to the database
+func schemaVersion(conn *sql.DB) (int, error) {
+	if _, err := conn.Exec(`CREATE TABLE IF NOT EXISTS schema_version (version INTEGER NOT NULL)`); err != nil {
+		return 0, err
+	}
+	var version int
+	err := conn.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version)
+	return version, err
+}
+
+// migrateDB applies the migrations the database hasn't had yet, each in its
+// own transaction along with the version it brings the database to
+func migrateDB(conn *sql.DB) error {
+	version, err := schemaVersion(conn)
+	if err != nil {
+		return err
+	}
+	if version > len(migrations) {
+		return &schemaTooNewError{found: version, known: len(migrations)}
+	}
+	for i := version; i < len(migrations); i++ {
+		if err := applyMigration(conn, i); err != nil {
+			return fmt.Errorf("migrating the settings database (%s): %w", migrations[i].description, err)
+		}
+	}
+	return nil
+}
+
+// applyMigration applies migrations[i] and records the new version
+func applyMigration(conn *sql.DB, i int) error {
+	tx, err := conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := migrations[i].apply(tx); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM schema_version`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_version (version) VALUES (?)`, i+1); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// execWrite runs a statement changing the settings database, refusing it
+// when the database is read-only
+func execWrite(query string, args ...interface{}) (sql.Result, error) {
+	if dbReadOnly {
+		return nil, errReadOnlyStore
+	}
+	return db.Exec(query, args...)
+}
+
+// offerReadOnlyStore explains that the settings database is newer than this
+// binary and offers to go on without saving anything to it, or quit
+func (m *Model) offerReadOnlyStore(tooNew *schemaTooNewError) tea.Cmd {
+	path, _ := getDBPath()
+	return m.openConfirm(&confirmation{
+		title: "Settings From a Newer mbongo",
+		message: fmt.Sprintf("%s was upgraded by a newer version of mbongo: %v.\n\n"+
+			"Continue read-only? Saved connections and settings can be used, but nothing you change is saved. Cancelling quits; upgrade mbongo to use them fully.",
+			path, tooNew),
+		severity: SeverityWarning,
+		onConfirm: func(m *Model) tea.Cmd {
+			dbReadOnly = true
+			return loadStoredConnections
+		},
+		onCancel: func(m *Model) tea.Cmd {
+			return tea.Quit
+		},
+	})
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func openTestStore(t *testing.T) *sql.DB {
+	conn, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "mbongo.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// historicalSnapshot builds the database an older mbongo left behind, with
+// the first n migrations applied. Binaries from before schema_version
+// existed left no version behind.
+func historicalSnapshot(t *testing.T, n int, versioned bool) *sql.DB {
+	conn := openTestStore(t)
+	for i := 0; i < n; i++ {
+		tx, err := conn.Begin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := migrations[i].apply(tx); err != nil {
+			t.Fatalf("snapshot %d: %s: %v", n, migrations[i].description, err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n > 0 {
+		if _, err := conn.Exec(`INSERT INTO connections (name, connection_string) VALUES ('prod', 'mongodb://prod')`); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if versioned {
+		if _, err := schemaVersion(conn); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := conn.Exec(`INSERT INTO schema_version (version) VALUES (?)`, n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return conn
+}
+
+func TestMigrateFromEverySnapshot(t *testing.T) {
+	for n := 0; n <= len(migrations); n++ {
+		for _, versioned := range []bool{false, true} {
+			conn := historicalSnapshot(t, n, versioned)
+			// Migrating twice is a no-op
+			for run := 0; run < 2; run++ {
+				if err := migrateDB(conn); err != nil {
+					t.Fatalf("snapshot %d (versioned %v), run %d: %v", n, versioned, run, err)
+				}
+			}
+			if version, _ := schemaVersion(conn); version != len(migrations) {
+				t.Errorf("snapshot %d (versioned %v): version %d, want %d", n, versioned, version, len(migrations))
+			}
+
+			db = conn
+			connections, err := loadConnections()
+			if err != nil || (n > 0 && len(connections) != 1) {
+				t.Errorf("snapshot %d (versioned %v): connections %v, %v", n, versioned, connections, err)
+			}
+			for _, table := range []string{"watches", "collection_settings", "reference_targets", "epoch_suppressions", "settings", "templates"} {
+				if _, err := conn.Exec("SELECT * FROM " + table); err != nil {
+					t.Errorf("snapshot %d (versioned %v): %v", n, versioned, err)
+				}
+			}
+			db = nil
+		}
+	}
+}
+
+func TestNewerSchemaIsReadOnly(t *testing.T) {
+	conn := historicalSnapshot(t, len(migrations), false)
+	if err := migrateDB(conn); err != nil {
+		t.Fatal(err)
+	}
+	conn.Exec(`UPDATE schema_version SET version = ?`, len(migrations)+1)
+	conn.Exec(`ALTER TABLE connections ADD COLUMN color TEXT`)
+
+	var tooNew *schemaTooNewError
+	if err := migrateDB(conn); !errors.As(err, &tooNew) || tooNew.found != len(migrations)+1 {
+		t.Fatalf("migrateDB() = %v, want a schemaTooNewError", err)
+	}
+
+	db, dbReadOnly = conn, true
+	defer func() { db, dbReadOnly = nil, false }()
+	if connections, err := loadConnections(); err != nil || len(connections) != 1 {
+		t.Errorf("loadConnections() = %v, %v", connections, err)
+	}
+	if err := saveSetting("k", "v"); !errors.Is(err, errReadOnlyStore) {
+		t.Errorf("saveSetting() = %v, want errReadOnlyStore", err)
+	}
+}
+
+func TestNewerSchemaOffersReadOnly(t *testing.T) {
+	m := newTestModel(120, 30)
+	m, _ = update(t, m, connectionsLoadedMsg{err: &schemaTooNewError{found: 12, known: 8}})
+	if m.confirm == nil || m.confirm.title != "Settings From a Newer mbongo" {
+		t.Fatalf("confirm = %+v", m.confirm)
+	}
+	defer func() { dbReadOnly = false }()
+	m = pressKey(m, "y")
+	if !dbReadOnly {
+		t.Error("continuing didn't make the settings read-only")
+	}
+}
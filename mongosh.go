@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// shellIdentifierPattern matches keys mongosh accepts without quotes
+var shellIdentifierPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// shellInsertStatement formats a document as a mongosh insertOne statement
+// that recreates it with the same BSON types
+func shellInsertStatement(collName string, doc bson.M) string {
+	return fmt.Sprintf("db.getCollection(%s).insertOne(%s)", shellString(collName), shellValue(sortedDocument(doc), ""))
+}
+
+// shellValue formats a value in mongosh syntax, using the shell's type
+// wrappers where plain JavaScript would lose the BSON type. Nested documents
+// and arrays are indented two spaces per level below indent.
+func shellValue(value interface{}, indent string) string {
+	switch v := value.(type) {
+	case nil, primitive.Null:
+		return "null"
+	case bson.D:
+		if len(v) == 0 {
+			return "{}"
+		}
+		inner := indent + "  "
+		fields := make([]string, len(v))
+		for i, e := range v {
+			fields[i] = inner + shellKey(e.Key) + ": " + shellValue(e.Value, inner)
+		}
+		return "{\n" + strings.Join(fields, ",\n") + "\n" + indent + "}"
+	case bson.M:
+		return shellValue(sortedDocument(v), indent)
+	case bson.A:
+		if len(v) == 0 {
+			return "[]"
+		}
+		inner := indent + "  "
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = inner + shellValue(item, inner)
+		}
+		return "[\n" + strings.Join(items, ",\n") + "\n" + indent + "]"
+	case string:
+		return shellString(v)
+	case primitive.Symbol:
+		return shellString(string(v))
+	case bool:
+		return strconv.FormatBool(v)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int:
+		// Built in Go, e.g. sort directions; the driver sends it as int32
+		// when it fits
+		if v == int(int32(v)) {
+			return strconv.Itoa(v)
+		}
+		return fmt.Sprintf("NumberLong(%q)", strconv.Itoa(v))
+	case int64:
+		return fmt.Sprintf("NumberLong(%q)", strconv.FormatInt(v, 10))
+	case float64:
+		return shellDouble(v)
+	case primitive.Decimal128:
+		return fmt.Sprintf("NumberDecimal(%q)", v.String())
+	case primitive.ObjectID:
+		return fmt.Sprintf("ObjectId(%q)", v.Hex())
+	case primitive.DateTime:
+		return fmt.Sprintf("ISODate(%q)", v.Time().UTC().Format("2006-01-02T15:04:05.000Z"))
+	case primitive.Timestamp:
+		return fmt.Sprintf("Timestamp({t: %d, i: %d})", v.T, v.I)
+	case primitive.Binary:
+		if uuid, ok := binaryUUID(v); ok && v.Subtype == 4 {
+			return fmt.Sprintf("UUID(%q)", uuid)
+		}
+		return fmt.Sprintf("BinData(%d, %q)", v.Subtype, base64.StdEncoding.EncodeToString(v.Data))
+	case primitive.Regex:
+		return "/" + escapeRegexSlashes(v.Pattern) + "/" + v.Options
+	case primitive.JavaScript:
+		return fmt.Sprintf("Code(%s)", shellString(string(v)))
+	case primitive.CodeWithScope:
+		return fmt.Sprintf("Code(%s, %s)", shellString(string(v.Code)), shellValue(v.Scope, indent))
+	case primitive.DBPointer:
+		return fmt.Sprintf("DBRef(%s, ObjectId(%q))", shellString(v.DB), v.Pointer.Hex())
+	case primitive.MinKey:
+		return "MinKey()"
+	case primitive.MaxKey:
+		return "MaxKey()"
+	case primitive.Undefined:
+		return "undefined"
+	default:
+		return shellString(fmt.Sprintf("%v", v))
+	}
+}
+
+// shellDouble formats a double. Whole numbers are wrapped in Double() since
+// mongosh would otherwise store them as int32.
+func shellDouble(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	case f == math.Trunc(f) && math.Abs(f) < 1e21:
+		return fmt.Sprintf("Double(%s)", strconv.FormatFloat(f, 'f', -1, 64))
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// shellKey quotes a field name unless it is a plain identifier
+func shellKey(key string) string {
+	if shellIdentifierPattern.MatchString(key) {
+		return key
+	}
+	return shellString(key)
+}
+
+// shellString quotes a string as a JavaScript string literal
+func shellString(s string) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// escapeRegexSlashes escapes the forward slashes that would end a regex
+// literal early, leaving already escaped ones alone
+func escapeRegexSlashes(pattern string) string {
+	var b strings.Builder
+	escaped := false
+	for _, r := range pattern {
+		if r == '/' && !escaped {
+			b.WriteByte('\\')
+		}
+		escaped = r == '\\' && !escaped
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// yankShellInsert copies a document as a mongosh insert statement
+func (m *Model) yankShellInsert(doc bson.M) tea.Cmd {
+	return m.yank("insert of "+shortID(doc["_id"]), shellInsertStatement(m.selectedCollection, doc))
+}
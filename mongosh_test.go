@@ -0,0 +1,85 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestShellInsertStatement(t *testing.T) {
+	oid, _ := primitive.ObjectIDFromHex("65f1a2b3c4d5e6f708192a3b")
+	price, _ := primitive.ParseDecimal128("19.99")
+	doc := bson.M{
+		"_id":     oid,
+		"created": primitive.NewDateTimeFromTime(time.Date(2024, 3, 2, 11, 45, 0, 0, time.UTC)),
+		"qty":     int32(3),
+		"views":   int64(9007199254740993),
+		"price":   price,
+		"ratio":   0.25,
+		"total":   float64(12),
+		"items": bson.A{
+			bson.M{"sku": "A-1", "tags": bson.A{"new", bson.A{}}},
+			bson.A{int32(1), nil},
+		},
+		"meta":      bson.M{},
+		"shipped":   false,
+		"order-ref": `say "hi" </b>`,
+	}
+	want := `db.getCollection("orders").insertOne({
+  _id: ObjectId("65f1a2b3c4d5e6f708192a3b"),
+  created: ISODate("2024-03-02T11:45:00.000Z"),
+  items: [
+    {
+      sku: "A-1",
+      tags: [
+        "new",
+        []
+      ]
+    },
+    [
+      1,
+      null
+    ]
+  ],
+  meta: {},
+  "order-ref": "say \"hi\" </b>",
+  price: NumberDecimal("19.99"),
+  qty: 3,
+  ratio: 0.25,
+  shipped: false,
+  total: Double(12),
+  views: NumberLong("9007199254740993")
+})`
+	if got := shellInsertStatement("orders", doc); got != want {
+		t.Errorf("shellInsertStatement =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestShellValueTypes(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{primitive.Binary{Subtype: 4, Data: []byte{0x3b, 0x24, 0x11, 0x01, 0xe2, 0xbb, 0x42, 0x55, 0x8c, 0xaf, 0x41, 0x36, 0xc5, 0x66, 0xa9, 0x62}}, `UUID("3b241101-e2bb-4255-8caf-4136c566a962")`},
+		{primitive.Binary{Subtype: 0, Data: []byte("hello")}, `BinData(0, "aGVsbG8=")`},
+		{primitive.Binary{Subtype: 3, Data: make([]byte, 16)}, `BinData(3, "AAAAAAAAAAAAAAAAAAAAAA==")`},
+		{primitive.Regex{Pattern: "^a/b", Options: "i"}, `/^a\/b/i`},
+		{primitive.Regex{Pattern: `^a\/b\\`, Options: ""}, `/^a\/b\\/`},
+		{primitive.Timestamp{T: 1700000000, I: 2}, `Timestamp({t: 1700000000, i: 2})`},
+		{primitive.MinKey{}, "MinKey()"},
+		{primitive.MaxKey{}, "MaxKey()"},
+		{primitive.JavaScript("function() { return 1 }"), `Code("function() { return 1 }")`},
+		{math.NaN(), "NaN"},
+		{math.Inf(-1), "-Infinity"},
+		{1e300, "1e+300"},
+		{int64(-5), `NumberLong("-5")`},
+	}
+	for _, tt := range tests {
+		if got := shellValue(tt.value, ""); got != tt.want {
+			t.Errorf("shellValue(%#v) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
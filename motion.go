@@ -0,0 +1,97 @@
+package main
+
+import (
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxCountPrefix caps a typed count so it can't overflow
+const maxCountPrefix = 99999
+
+// handleCountDigit accumulates a vim-style count typed before a motion in
+// the documents panel, like the 15 of 15j. A leading 0 isn't a count.
+func (m *Model) handleCountDigit(key string) (tea.Cmd, bool) {
+	if m.focus != FocusDocuments || len(key) != 1 || key[0] < '0' || key[0] > '9' {
+		return nil, false
+	}
+	if key == "0" && m.countPrefix == 0 {
+		return nil, false
+	}
+	m.countPrefix = m.countPrefix*10 + int(key[0]-'0')
+	if m.countPrefix > maxCountPrefix {
+		m.countPrefix = maxCountPrefix
+	}
+	return m.setStatus(strconv.Itoa(m.countPrefix)), true
+}
+
+// takeCount returns the typed count, 1 when there is none, and clears it
+func (m *Model) takeCount() int {
+	count := m.countPrefix
+	if count == 0 {
+		return 1
+	}
+	m.countPrefix = 0
+	m.statusMessage = "" // The count echo
+	return count
+}
+
+// moveDocCursor moves the document cursor to line, clamped to the tree, and
+// scrolls to keep it visible
+func (m *Model) moveDocCursor(line int) {
+	m.docCursor = clampIndex(line, len(m.flattenedTree))
+	m.adjustScrollForCursor()
+}
+
+// flattenedIndex returns the line node is shown on, or -1 if it's hidden
+func (m Model) flattenedIndex(node *JSONNode) int {
+	for i, visible := range m.flattenedTree {
+		if visible == node {
+			return i
+		}
+	}
+	return -1
+}
+
+// siblingNodes returns node and the nodes beside it under the same parent;
+// document roots are siblings of each other outside full screen
+func (m Model) siblingNodes(node *JSONNode) []*JSONNode {
+	if node.Parent == nil {
+		if m.docFullscreen {
+			return nil
+		}
+		return m.docTree
+	}
+	return node.Parent.Children
+}
+
+// jumpToSibling moves the cursor count siblings forward (or back when count
+// is negative) from the node under it, stopping at the first or last one
+func (m *Model) jumpToSibling(count int) {
+	node := m.nodeAtCursor()
+	if node == nil || node.Depth < 0 || node.RawText != "" {
+		return
+	}
+	siblings := m.siblingNodes(node)
+	for i, sibling := range siblings {
+		if sibling == node {
+			target := siblings[clampIndex(i+count, len(siblings))]
+			if line := m.flattenedIndex(target); line >= 0 {
+				m.moveDocCursor(line)
+			}
+			return
+		}
+	}
+}
+
+// jumpToParent moves the cursor to the object or array enclosing the node
+// under it
+func (m *Model) jumpToParent() {
+	node := m.nodeAtCursor()
+	if node == nil || node.Parent == nil {
+		return
+	}
+	if line := m.flattenedIndex(node.Parent); line >= 0 {
+		m.moveDocCursor(line)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// maxDatabaseNameBytes is the longest database name MongoDB accepts
+const maxDatabaseNameBytes = 63
+
+// databaseNameForbidden are the characters a database name can't contain.
+// Unix servers only reject /\. "$ but Windows ones reject the rest too, and
+// a name should work wherever the database is restored.
+const databaseNameForbidden = `/\. "$*<>:|?`
+
+// systemDatabases are the databases the server keeps for itself
+var systemDatabases = map[string]bool{"admin": true, "local": true, "config": true}
+
+// isSystemDatabase reports whether name is one of the server's own databases
+func isSystemDatabase(name string) bool {
+	return systemDatabases[name]
+}
+
+// maxNamespaceBytes is the longest "database.collection" name the server
+// accepts: 255 bytes since MongoDB 4.4, 120 before
+func maxNamespaceBytes(version serverVersion) int {
+	if version.known() && !version.atLeast(serverVersion{4, 4}) {
+		return 120
+	}
+	return 255
+}
+
+// validateDatabaseName checks a name for a new database against MongoDB's
+// rules. Database names are case-insensitive on the server, so a name that
+// differs from an existing database only in case is rejected.
+func validateDatabaseName(name string, existing []string) error {
+	switch {
+	case name == "":
+		return errors.New("the database name is empty")
+	case len(name) > maxDatabaseNameBytes:
+		return fmt.Errorf("database names are limited to %d bytes; this one has %d", maxDatabaseNameBytes, len(name))
+	case strings.ContainsRune(name, 0):
+		return errors.New("database names can't contain null bytes")
+	}
+	if i := strings.IndexAny(name, databaseNameForbidden); i >= 0 {
+		return fmt.Errorf("database names can't contain %q", name[i])
+	}
+	for _, other := range existing {
+		if other != name && strings.EqualFold(other, name) {
+			return fmt.Errorf("database %q already exists with different case", other)
+		}
+	}
+	return nil
+}
+
+// validateCollectionName checks a name for a new collection in dbName
+// against MongoDB's rules, including the namespace length limit of the
+// server version
+func validateCollectionName(dbName, name string, existing []string, version serverVersion) error {
+	switch {
+	case name == "":
+		return errors.New("the collection name is empty")
+	case strings.ContainsRune(name, 0):
+		return errors.New("collection names can't contain null bytes")
+	case strings.Contains(name, "$"):
+		return errors.New(`collection names can't contain "$"`)
+	case strings.HasPrefix(name, "system."):
+		return errors.New(`the "system." prefix is reserved for the server`)
+	}
+	if ns, limit := namespaceOf(dbName, name), maxNamespaceBytes(version); len(ns) > limit {
+		return fmt.Errorf("%s is %d bytes; namespaces are limited to %d", ns, len(ns), limit)
+	}
+	for _, other := range existing {
+		if other == name {
+			return fmt.Errorf("collection %q already exists", name)
+		}
+	}
+	return nil
+}
+
+// friendlyNamespaceError rewrites the server errors about bad or taken
+// names that slipped past validation into plain messages
+func friendlyNamespaceError(err error) error {
+	var serverErr mongo.ServerError
+	if !errors.As(err, &serverErr) {
+		return err
+	}
+	switch {
+	case serverErr.HasErrorCode(73): // InvalidNamespace
+		return fmt.Errorf("the server rejected the name: %w", err)
+	case serverErr.HasErrorCode(48): // NamespaceExists
+		return fmt.Errorf("a collection with this name already exists: %w", err)
+	case serverErr.HasErrorCode(13297): // DatabaseDifferCase
+		return fmt.Errorf("a database with this name already exists with different case: %w", err)
+	}
+	return err
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestValidateDatabaseName(t *testing.T) {
+	existing := []string{"admin", "Shop"}
+	for _, ok := range []string{"shop_restore", "Shop", "a-b", strings.Repeat("d", 63)} {
+		if err := validateDatabaseName(ok, existing); err != nil {
+			t.Errorf("validateDatabaseName(%q) = %v", ok, err)
+		}
+	}
+	for name, want := range map[string]string{
+		"":                      "empty",
+		strings.Repeat("d", 64): "limited to 63 bytes",
+		"shop.v2":               `can't contain '.'`,
+		"my db":                 `can't contain ' '`,
+		"cost$":                 `can't contain '$'`,
+		"a*b":                   `can't contain '*'`,
+		"nul\x00":               "null bytes",
+		"shop":                  `"Shop" already exists with different case`,
+	} {
+		if err := validateDatabaseName(name, existing); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("validateDatabaseName(%q) = %v, want %q", name, err, want)
+		}
+	}
+}
+
+func TestValidateCollectionName(t *testing.T) {
+	existing := []string{"orders"}
+	if err := validateCollectionName("shop", "orders.archive", existing, serverVersion{}); err != nil {
+		t.Errorf("dotted name rejected: %v", err)
+	}
+	for name, want := range map[string]string{
+		"":               "empty",
+		"orders":         "already exists",
+		"price$":         `can't contain "$"`,
+		"system.indexes": "reserved",
+		"a\x00b":         "null bytes",
+	} {
+		if err := validateCollectionName("shop", name, existing, serverVersion{}); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("validateCollectionName(%q) = %v, want %q", name, err, want)
+		}
+	}
+
+	// Namespaces are limited to 120 bytes before 4.4 and 255 since
+	long := strings.Repeat("c", 200)
+	if err := validateCollectionName("shop", long, nil, serverVersion{4, 2}); err == nil || !strings.Contains(err.Error(), "limited to 120") {
+		t.Errorf("4.2: %v, want the 120 byte limit", err)
+	}
+	if err := validateCollectionName("shop", long, nil, serverVersion{4, 4}); err != nil {
+		t.Errorf("4.4: %v", err)
+	}
+	if err := validateCollectionName("shop", long+long, nil, serverVersion{}); err == nil || !strings.Contains(err.Error(), "limited to 255") {
+		t.Errorf("unknown version: %v, want the 255 byte limit", err)
+	}
+}
+
+func TestFriendlyNamespaceError(t *testing.T) {
+	err := friendlyNamespaceError(mongo.CommandError{Code: 48, Message: "Collection already exists. NS: shop.orders"})
+	if !strings.HasPrefix(err.Error(), "a collection with this name already exists") {
+		t.Errorf("NamespaceExists = %v", err)
+	}
+	plain := errors.New("connection refused")
+	if friendlyNamespaceError(plain) != plain || friendlyNamespaceError(nil) != nil {
+		t.Error("other errors must pass through unchanged")
+	}
+}
+
+func TestDumpImportPromptValidatesTarget(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m.databases = []string{"admin", "Shop"}
+	m.updateFilteredDatabases()
+	m.focus = FocusDatabases
+	m = pressKey(m, "I")
+	if !m.dumpPromptActive || !m.dumpImport {
+		t.Fatal("import prompt not open")
+	}
+	m.dumpDirInput.SetValue("backup")
+	m.dumpTargetInput.SetValue("shop")
+	if view := normalizeRender(m.View()); !strings.Contains(view, "already exists with different case") {
+		t.Errorf("prompt doesn't show the violation:\n%s", view)
+	}
+	m = pressKey(m, "enter")
+	if !m.dumpPromptActive || len(m.jobs) != 0 {
+		t.Error("an invalid target started the import")
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// countFieldOccurrences counts, for every field path, how many of docs
+// contain it. Paths drop array indexes like referencePattern, so a field
+// counts once per document however many array elements have it.
+func countFieldOccurrences(docs []bson.M) map[string]int {
+	counts := make(map[string]int)
+	for _, doc := range docs {
+		seen := make(map[string]bool)
+		collectFieldPaths(doc, nil, seen)
+		for path := range seen {
+			counts[path]++
+		}
+	}
+	return counts
+}
+
+// collectFieldPaths records the path of every field below value in seen
+func collectFieldPaths(value interface{}, segments []string, seen map[string]bool) {
+	switch v := value.(type) {
+	case bson.M:
+		for key, child := range v {
+			path := append(segments[:len(segments):len(segments)], key)
+			seen[formatFieldPath(path)] = true
+			collectFieldPaths(child, path, seen)
+		}
+	case bson.A:
+		for _, item := range v {
+			collectFieldPaths(item, segments, seen)
+		}
+	}
+}
+
+// refreshOccurrences recomputes the field occurrence counts of the current
+// page when they are shown or colored, and drops them otherwise
+func (m *Model) refreshOccurrences() {
+	if !m.showOccurrences && !m.showHeatMap {
+		m.fieldOccurrences = nil
+		return
+	}
+	m.fieldOccurrences = countFieldOccurrences(m.documents)
+}
+
+// occurrenceLabel returns the " (n/total)" annotation shown after the key of
+// a field, or "" when counts are hidden or don't apply to the node
+func (m Model) occurrenceLabel(node *JSONNode) string {
+	if !m.showOccurrences || m.fieldOccurrences == nil || node.Parent == nil || node.Foreign || strings.HasPrefix(node.Key, "[") {
+		return ""
+	}
+	count, ok := m.fieldOccurrences[referencePattern(node)]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf(" (%d/%d)", count, len(m.documents))
+}
+
+// renderOccurrenceLabel returns the styled occurrence annotation of node
+func (m Model) renderOccurrenceLabel(node *JSONNode) string {
+	if label := m.occurrenceLabel(node); label != "" {
+		return paginationStyle.Render(label)
+	}
+	return ""
+}
+
+// withoutOccurrenceLabel drops the search match ranges that fall within the
+// occurrence annotation of node, which isn't part of the document
+func (m Model) withoutOccurrenceLabel(node *JSONNode, text string, ranges [][2]int) [][2]int {
+	label := m.occurrenceLabel(node)
+	if label == "" {
+		return ranges
+	}
+	key := fmt.Sprintf("%q", node.Key)
+	i := strings.Index(text, key+label)
+	if i < 0 {
+		return ranges
+	}
+	start, end := i+len(key), i+len(key)+len(label)
+	kept := ranges[:0:0]
+	for _, r := range ranges {
+		if r[1] <= start || r[0] >= end {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
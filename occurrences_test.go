@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestCountFieldOccurrences(t *testing.T) {
+	docs := []bson.M{
+		{"_id": 1, "metadata": bson.M{"region": "eu"}, "items": bson.A{bson.M{"sku": "a"}, bson.M{"sku": "b"}}},
+		{"_id": 2, "metadata": bson.M{"region": "us", "tier": "gold"}},
+		{"_id": 3, "metadata": bson.M{}, "items": bson.A{}},
+	}
+	counts := countFieldOccurrences(docs)
+	want := map[string]int{"_id": 3, "metadata": 3, "metadata.region": 2, "metadata.tier": 1, "items": 2, "items.sku": 1}
+	for path, n := range want {
+		if counts[path] != n {
+			t.Errorf("%s occurs in %d documents, want %d", path, counts[path], n)
+		}
+	}
+}
+
+func TestOccurrenceLabelsAreNotSearched(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.docTree[0].Collapsed = false
+	m.rebuildFlattenedTree()
+	m = pressKey(m, "o")
+
+	var address *JSONNode
+	for _, node := range m.flattenedTree {
+		if node.Key == "address" {
+			address = node
+		}
+	}
+	line := stripANSI(m.renderNode(address, 100))
+	if !strings.Contains(line, `"address" (1/2)`) {
+		t.Fatalf("address line %q lacks the occurrence count", line)
+	}
+	if text := m.getNodeSearchText(address); strings.Contains(text, "1/2") {
+		t.Errorf("search text %q includes the occurrence count", text)
+	}
+
+	if ranges := m.withoutOccurrenceLabel(address, line, findSubstrings(line, "1")); len(ranges) != 0 {
+		t.Errorf("occurrence count in %q kept as search matches %v", line, ranges)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// defaultPager is used when $PAGER isn't set
+const defaultPager = "less -R"
+
+// pagerClosedMsg is sent when the pager exits
+type pagerClosedMsg struct {
+	tempFile string
+	err      error
+}
+
+// pagerCommand returns the $PAGER command that shows path, and whether the
+// pager passes ANSI colors through rather than showing them as escapes
+func pagerCommand(path string) (*exec.Cmd, bool) {
+	pager := os.Getenv("PAGER")
+	if strings.TrimSpace(pager) == "" {
+		pager = defaultPager
+	}
+	parts := strings.Fields(pager)
+	var args []string
+	for _, arg := range parts[1:] {
+		args = append(args, expandTilde(arg))
+	}
+	return exec.Command(parts[0], append(args, path)...), pagerShowsColor(parts, os.Getenv("LESS"))
+}
+
+// pagerShowsColor reports whether a pager command line shows colors: less
+// does with -R or -r, given on the command line or in $LESS
+func pagerShowsColor(command []string, lessEnv string) bool {
+	if len(command) == 0 || !strings.HasSuffix(command[0], "less") {
+		return false
+	}
+	for _, arg := range append(command[1:], strings.Fields(lessEnv)...) {
+		switch {
+		case arg == "--RAW-CONTROL-CHARS" || arg == "--raw-control-chars":
+			return true
+		case strings.HasPrefix(arg, "--"):
+			// Another long option
+		case strings.ContainsAny(strings.TrimPrefix(arg, "-"), "Rr"):
+			return true
+		}
+	}
+	return false
+}
+
+// pagerText renders a document the way the documents panel does, fully
+// expanded whatever its collapse state, one line per node
+func (m Model) pagerText(doc bson.M, color bool) string {
+	// The heat map describes the page, not the document
+	m.showHeatMap = false
+	root := (&treeBuilder{all: true}).valueNode("", doc, 0)
+	root.Size = documentSize(doc)
+	setCollapsedRecursive(root, false)
+	nodes := flattenNode(root, false)
+	lines := make([]string, len(nodes))
+	for i, node := range nodes {
+		// Lines are never truncated: the pager scrolls sideways
+		lines[i] = m.renderNode(node, math.MaxInt32)
+		if !color {
+			lines[i] = stripANSI(lines[i])
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// openInPager writes a document to a temp file and reads it in $PAGER.
+// mbongo comes back as it was left when the pager exits.
+func (m *Model) openInPager(doc bson.M) tea.Cmd {
+	tmpFile, err := os.CreateTemp("", "mbongo-*.txt")
+	if err != nil {
+		return m.setStatus(fmt.Sprintf("can't open pager: %v", err))
+	}
+	c, color := pagerCommand(tmpFile.Name())
+	_, err = tmpFile.WriteString(m.pagerText(doc, color))
+	tmpFile.Close()
+	if err != nil {
+		os.Remove(tmpFile.Name())
+		return m.setStatus(fmt.Sprintf("can't open pager: %v", err))
+	}
+
+	path := tmpFile.Name()
+	return tea.ExecProcess(c, func(err error) tea.Msg {
+		return pagerClosedMsg{tempFile: path, err: err}
+	})
+}
+
+// handlePagerClosed removes the pager's temp file
+func (m *Model) handlePagerClosed(msg pagerClosedMsg) tea.Cmd {
+	os.Remove(msg.tempFile)
+	if msg.err != nil {
+		return m.setStatus(fmt.Sprintf("pager failed: %v", msg.err))
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestPagerShowsColor(t *testing.T) {
+	tests := []struct {
+		pager string
+		less  string
+		want  bool
+	}{
+		{"less -R", "", true},
+		{"/usr/bin/less -FRX", "", true},
+		{"less --RAW-CONTROL-CHARS", "", true},
+		{"less", "-FRX", true},
+		{"less", "FRX", true},
+		{"less", "--quit-if-one-screen", false},
+		{"less -S", "", false},
+		{"more", "-R", false},
+		{"cat", "", false},
+	}
+	for _, tt := range tests {
+		if got := pagerShowsColor(strings.Fields(tt.pager), tt.less); got != tt.want {
+			t.Errorf("pagerShowsColor(%q, LESS=%q) = %v, want %v", tt.pager, tt.less, got, tt.want)
+		}
+	}
+}
+
+func TestPagerTextIsFullyExpanded(t *testing.T) {
+	items := make(bson.A, maxTreeChildren+5)
+	for i := range items {
+		items[i] = int32(i)
+	}
+	m := newTestModel(120, 30)
+	doc := bson.M{"_id": 1, "address": bson.M{"city": "London"}, "items": items}
+	text := m.pagerText(doc, false)
+	for _, want := range []string{`"city": "London"`, fmt.Sprintf("[%d]: %d", maxTreeChildren+4, maxTreeChildren+4)} {
+		if !strings.Contains(text, want) {
+			t.Errorf("pager text missing %q", want)
+		}
+	}
+	if strings.Contains(text, "\x1b[") || strings.Contains(text, "show more") {
+		t.Error("plain pager text has colors or show more lines")
+	}
+}
+
+func TestPagerTempFileIsRemoved(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+	t.Setenv("PAGER", "cat")
+	m := newTestModel(120, 30)
+	m.focus = FocusDocuments
+	if cmd := m.handleKeySequence("ctrl+xp"); cmd == nil {
+		t.Fatal("no pager command")
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "mbongo-*.txt"))
+	if len(files) != 1 {
+		t.Fatalf("temp files = %v", files)
+	}
+	text, _ := os.ReadFile(files[0])
+	if !strings.Contains(string(text), `"name": "Ada Lovelace"`) {
+		t.Errorf("pager file:\n%s", text)
+	}
+	m.handlePagerClosed(pagerClosedMsg{tempFile: files[0]})
+	if _, err := os.Stat(files[0]); !os.IsNotExist(err) {
+		t.Errorf("temp file not removed: %v", err)
+	}
+}
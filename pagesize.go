@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pageSizeSteps are the page sizes + and - step through
+var pageSizeSteps = []int{1, 2, 3, 5, 10, 20, 30, 50, 100}
+
+// nextPageSize returns the next larger (dir > 0) or smaller page size step
+func nextPageSize(current, dir int) int {
+	if dir > 0 {
+		for _, size := range pageSizeSteps {
+			if size > current {
+				return size
+			}
+		}
+		return pageSizeSteps[len(pageSizeSteps)-1]
+	}
+	for i := len(pageSizeSteps) - 1; i >= 0; i-- {
+		if pageSizeSteps[i] < current {
+			return pageSizeSteps[i]
+		}
+	}
+	return pageSizeSteps[0]
+}
+
+// pageSizeFor returns the saved page size for a namespace, or the default
+func (m Model) pageSizeFor(ns string) int {
+	size, err := loadPageSize(m.connectionName, ns)
+	if err != nil || size < 1 {
+		return defaultDocsPerPage
+	}
+	return size
+}
+
+// firstVisibleDocument returns the index of the document shown at the top of
+// the documents panel, or -1 when there are none
+func (m Model) firstVisibleDocument() int {
+	if m.docScrollOffset < 0 || m.docScrollOffset >= len(m.flattenedTree) {
+		return -1
+	}
+	node := m.flattenedTree[m.docScrollOffset]
+	if node.Depth < 0 && m.docScrollOffset+1 < len(m.flattenedTree) {
+		node = m.flattenedTree[m.docScrollOffset+1] // Separator: the next document starts below it
+	}
+	for node.Parent != nil {
+		node = node.Parent
+	}
+	for i, root := range m.docTree {
+		if root == node {
+			return i
+		}
+	}
+	return -1
+}
+
+// changePageSize steps the page size up or down, saves it for the collection
+// and reloads the page containing the first visible document
+func (m *Model) changePageSize(dir int) tea.Cmd {
+	size := nextPageSize(m.docsPerPage, dir)
+	if size == m.docsPerPage {
+		return m.setStatus(fmt.Sprintf("page size: %d", size))
+	}
+
+	first := m.currentPage * m.docsPerPage
+	if docIndex := m.firstVisibleDocument(); docIndex >= 0 {
+		first += docIndex
+	}
+	m.docsPerPage = size
+	m.currentPage = first / size
+	m.anchorDocIndex = first % size
+
+	statusCmd := m.setStatus(fmt.Sprintf("page size: %d", size))
+	if err := savePageSize(m.connectionName, m.currentNamespace(), size); err != nil {
+		statusCmd = m.setStatus(fmt.Sprintf("page size: %d (not saved: %v)", size, err))
+	}
+
+	m.loadingDocs = true
+	m.docCursor = 0
+	m.docScrollOffset = 0
+	return tea.Batch(statusCmd, loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, m.currentPage, m.docsPerPage, m.queryFilter, m.sortSpec()))
+}
+
+// applyDocumentAnchor scrolls a freshly loaded page so the anchored document
+// is at the top, then clears the anchor
+func (m *Model) applyDocumentAnchor() {
+	if m.anchorDocIndex < 0 {
+		return
+	}
+	if m.anchorDocIndex < len(m.docTree) {
+		root := m.docTree[m.anchorDocIndex]
+		for i, node := range m.flattenedTree {
+			if node == root {
+				m.docCursor = i
+				m.docScrollOffset = i
+				break
+			}
+		}
+		m.docScrollOffset = clampScroll(m.docScrollOffset, len(m.flattenedTree), m.getDocPanelHeight())
+	}
+	m.anchorDocIndex = -1
+}
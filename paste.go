@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var (
+	objectIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{24}$`)
+	uuidPattern     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+	// Shell helpers accepted in the query panel, rewritten to Extended JSON
+	objectIDCallPattern = regexp.MustCompile(`ObjectId\(\s*["']([0-9a-fA-F]{24})["']\s*\)`)
+	uuidCallPattern     = regexp.MustCompile(`UUID\(\s*["']([0-9a-fA-F-]{36})["']\s*\)`)
+)
+
+// expandShellHelpers rewrites ObjectId("...") and UUID("...") in query text
+// to their Extended JSON form
+func expandShellHelpers(text string) string {
+	text = objectIDCallPattern.ReplaceAllString(text, `{"$$oid": "$1"}`)
+	return uuidCallPattern.ReplaceAllString(text, `{"$$uuid": "$1"}`)
+}
+
+// pastedIDs splits pasted text into _ids when every newline, comma or space
+// separated token is a bare ObjectId, or every token a UUID. It returns nil
+// for anything else.
+func pastedIDs(text string) (ids []string, uuids bool) {
+	tokens := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+	seen := make(map[string]bool)
+	for i, token := range tokens {
+		token = strings.Trim(token, `"'`)
+		isUUID := uuidPattern.MatchString(token)
+		if !isUUID && !objectIDPattern.MatchString(token) {
+			return nil, false
+		}
+		if i == 0 {
+			uuids = isUUID
+		} else if isUUID != uuids {
+			return nil, false
+		}
+		if !seen[token] {
+			seen[token] = true
+			ids = append(ids, token)
+		}
+	}
+	return ids, uuids
+}
+
+// stringIDs reports whether the loaded page has string _ids, in which case
+// pasted ids are matched as plain strings
+func (m Model) stringIDs() bool {
+	if len(m.documents) == 0 {
+		return false
+	}
+	_, ok := m.documents[0]["_id"].(string)
+	return ok
+}
+
+// pastedIDQuery builds the {_id: {$in: [...]}} query text for pasted ids,
+// using the representation the collection's _ids have
+func (m Model) pastedIDQuery(ids []string, uuids bool) string {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		switch {
+		case m.stringIDs():
+			values[i] = fmt.Sprintf("%q", id)
+		case uuids:
+			values[i] = fmt.Sprintf("UUID(%q)", strings.ToLower(id))
+		default:
+			values[i] = fmt.Sprintf("ObjectId(%q)", strings.ToLower(id))
+		}
+	}
+	return "{_id: {$in: [" + strings.Join(values, ", ") + "]}}"
+}
+
+// pastedIDLabel describes pasted ids in the conversion prompt
+func (m Model) pastedIDLabel() string {
+	kind := "ObjectIds"
+	if m.pasteUUIDs {
+		kind = "UUIDs"
+	}
+	if m.stringIDs() {
+		kind = "string _ids"
+	}
+	if len(m.pasteIDs) == 1 {
+		kind = strings.TrimSuffix(kind, "s")
+	}
+	return fmt.Sprintf("%d %s", len(m.pasteIDs), kind)
+}
+
+// pasteIntoQuery inserts pasted text at the query cursor. Pasting a list of
+// _ids into an empty query offers to turn it into an $in filter instead.
+func (m *Model) pasteIntoQuery(text string) {
+	if query := strings.TrimSpace(m.queryText); query == "" || query == "{}" {
+		if ids, uuids := pastedIDs(text); len(ids) > 0 {
+			m.pasteText = text
+			m.pasteIDs = ids
+			m.pasteUUIDs = uuids
+			return
+		}
+	}
+	m.insertQueryText(text)
+}
+
+// insertQueryText inserts text at the query cursor, flattening line breaks
+// and dropping characters the query line can't hold
+func (m *Model) insertQueryText(text string) {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\n' || r == '\r' || r == '\t':
+			b.WriteByte(' ')
+		case r >= 32 && r < 127:
+			b.WriteRune(r)
+		}
+	}
+	m.queryText = m.queryText[:m.queryCursor] + b.String() + m.queryText[m.queryCursor:]
+	m.queryCursor += b.Len()
+}
+
+// handlePastePromptKey answers the prompt to convert pasted _ids: y or enter
+// converts them, any other key pastes the text as it was
+func (m *Model) handlePastePromptKey(msg tea.KeyMsg) tea.Cmd {
+	ids, text := m.pasteIDs, m.pasteText
+	m.pasteIDs = nil
+	m.pasteText = ""
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "y", "enter":
+		m.queryText = m.pastedIDQuery(ids, m.pasteUUIDs)
+		m.queryCursor = len(m.queryText)
+		return m.setStatus("enter: run the query")
+	default:
+		m.insertQueryText(text)
+		return nil
+	}
+}
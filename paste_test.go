@@ -0,0 +1,74 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPastedIDs(t *testing.T) {
+	for _, tt := range []struct {
+		text  string
+		ids   int
+		uuids bool
+	}{
+		{"65ab12cd34ef56ab78cd90ef\n65ab12cd34ef56ab78cd90f0\n", 2, false},
+		{`"65ab12cd34ef56ab78cd90ef", "65ab12cd34ef56ab78cd90ef"`, 1, false},
+		{"0e3a4f1c-2b7d-4c55-9a10-6f2f3c1d4e5a,6F2F3C1D-4E5A-4C55-9A10-0E3A4F1C2B7D", 2, true},
+		{"65ab12cd34ef56ab78cd90ef 0e3a4f1c-2b7d-4c55-9a10-6f2f3c1d4e5a", 0, false},
+		{`{status: "paid"}`, 0, false},
+		{"   ", 0, false},
+	} {
+		ids, uuids := pastedIDs(tt.text)
+		if len(ids) != tt.ids || uuids != tt.uuids {
+			t.Errorf("pastedIDs(%q) = %v, %v; want %d ids, uuids %v", tt.text, ids, uuids, tt.ids, tt.uuids)
+		}
+	}
+}
+
+func TestPasteIDsIntoEmptyQuery(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.focus = FocusQuery
+	m.queryText = "{}"
+	m.queryCursor = 1
+
+	paste := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("65ab12cd34ef56ab78cd90ef\n65AB12CD34EF56AB78CD90F0"), Paste: true}
+	updated, _ := m.Update(paste)
+	m = updated.(Model)
+	if m.pasteIDs == nil || m.queryText != "{}" {
+		t.Fatalf("pasting ids didn't prompt (query %q)", m.queryText)
+	}
+	m = pressKey(m, "y")
+	want := `{_id: {$in: [ObjectId("65ab12cd34ef56ab78cd90ef"), ObjectId("65ab12cd34ef56ab78cd90f0")]}}`
+	if m.queryText != want {
+		t.Fatalf("query = %q, want %q", m.queryText, want)
+	}
+	filter, err := parseQueryFilter(m.queryText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := filter["_id"].(bson.M)["$in"].(bson.A)
+	if id, ok := in[1].(primitive.ObjectID); !ok || id.Hex() != "65ab12cd34ef56ab78cd90f0" {
+		t.Errorf("parsed $in = %#v, want ObjectIds", in)
+	}
+
+	// Declining pastes the text as typed
+	m.queryText, m.queryCursor = "", 0
+	updated, _ = m.Update(paste)
+	m = pressKey(updated.(Model), "n")
+	if m.queryText != "65ab12cd34ef56ab78cd90ef 65AB12CD34EF56AB78CD90F0" {
+		t.Errorf("declined paste inserted %q", m.queryText)
+	}
+}
+
+func TestParseQueryFilterUUID(t *testing.T) {
+	filter, err := parseQueryFilter(`{_id: UUID('0e3a4f1c-2b7d-4c55-9a10-6f2f3c1d4e5a'), n: 3}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, ok := filter["_id"].(primitive.Binary); !ok || id.Subtype != 4 {
+		t.Errorf("_id = %#v, want a subtype 4 binary", filter["_id"])
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// idKey returns a comparable key for a document _id of any BSON type
+func idKey(id interface{}) string {
+	return fmt.Sprintf("%T:%v", id, id)
+}
+
+// currentNamespace returns the namespace of the selected collection
+func (m Model) currentNamespace() string {
+	return namespaceOf(m.selectedDatabase, m.selectedCollection)
+}
+
+// pinnedIDs returns the pinned _ids for the selected collection
+func (m Model) pinnedIDs() []interface{} {
+	return m.pins[m.currentNamespace()]
+}
+
+// isPinned reports whether an _id is pinned in the selected collection
+func (m Model) isPinned(id interface{}) bool {
+	key := idKey(id)
+	for _, pinned := range m.pinnedIDs() {
+		if idKey(pinned) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// togglePin pins or unpins the document under the cursor
+func (m *Model) togglePin() tea.Cmd {
+	docIndex := m.getDocumentIndexAtCursor()
+	if docIndex < 0 || docIndex >= len(m.documents) {
+		return nil
+	}
+	id, ok := m.documents[docIndex]["_id"]
+	if !ok {
+		return m.setStatus("document has no _id to pin")
+	}
+
+	ns := m.currentNamespace()
+	if m.isPinned(id) {
+		key := idKey(id)
+		pins := m.pins[ns]
+		for i, pinned := range pins {
+			if idKey(pinned) == key {
+				m.pins[ns] = append(pins[:i], pins[i+1:]...)
+				break
+			}
+		}
+		// Drop the document from the pinboard right away
+		if m.pinboardActive {
+			return tea.Batch(m.setStatus("unpinned"), m.loadPinboard())
+		}
+		return m.setStatus(fmt.Sprintf("unpinned (%d pinned)", len(m.pins[ns])))
+	}
+
+	m.pins[ns] = append(m.pins[ns], id)
+	return m.setStatus(fmt.Sprintf("pinned (%d pinned)", len(m.pins[ns])))
+}
+
+// pinboardFilter returns the filter that selects exactly the pinned documents
+func (m Model) pinboardFilter() bson.M {
+	ids := bson.A{}
+	for _, id := range m.pinnedIDs() {
+		ids = append(ids, id)
+	}
+	return bson.M{"_id": bson.M{"$in": ids}}
+}
+
+// loadPinboard (re)loads the pinned documents into the documents panel
+func (m *Model) loadPinboard() tea.Cmd {
+	m.queryFilter = m.pinboardFilter()
+	m.currentPage = 0
+	m.docCursor = 0
+	m.docScrollOffset = 0
+	m.loadingDocs = true
+	return loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, 0, m.docsPerPage, m.queryFilter, m.sortSpec())
+}
+
+// togglePinboard switches between the filtered view and the pinboard
+func (m *Model) togglePinboard() tea.Cmd {
+	if m.pinboardActive {
+		m.pinboardActive = false
+		m.queryFilter = m.pinSavedFilter
+		m.currentPage = m.pinSavedPage
+		m.docCursor = 0
+		m.docScrollOffset = 0
+		m.loadingDocs = true
+		return loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, m.currentPage, m.docsPerPage, m.queryFilter, m.sortSpec())
+	}
+
+	if len(m.pinnedIDs()) == 0 {
+		return m.setStatus("no pinned documents in this collection")
+	}
+	m.pinboardActive = true
+	m.pinSavedFilter = m.queryFilter
+	m.pinSavedPage = m.currentPage
+	return m.loadPinboard()
+}
+
+// clearPins removes every pin in the selected collection
+func (m *Model) clearPins() tea.Cmd {
+	delete(m.pins, m.currentNamespace())
+	if m.pinboardActive {
+		return m.togglePinboard()
+	}
+	return m.setStatus("pins cleared")
+}
+
+// copyPinnedIDs copies the pinned _ids to the clipboard, one per line
+func (m *Model) copyPinnedIDs() tea.Cmd {
+	ids := m.pinnedIDs()
+	if len(ids) == 0 {
+		return nil
+	}
+	lines := make([]string, len(ids))
+	for i, id := range ids {
+		lines[i] = rawValueString(id)
+	}
+	return m.yank("pinned _ids", strings.Join(lines, "\n"))
+}
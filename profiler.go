@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// profileCollection is where the profiler of a database writes the
+// operations it records
+const profileCollection = "system.profile"
+
+// profileSlowMillis is the duration from which the profiled operations are
+// shown when jumping to system.profile
+const profileSlowMillis = 100
+
+// profileLevels describes the levels of the profile command
+var profileLevels = []string{
+	"0: off",
+	"1: operations slower than slowms",
+	"2: every operation",
+}
+
+// openProfile shows the slow operations the profiler recorded in the
+// current database, newest first, with the filter in the query panel to
+// refine it
+func (m *Model) openProfile() tea.Cmd {
+	if m.client == nil || m.selectedDatabase == "" {
+		return nil
+	}
+	if indexOfString(m.collections, profileCollection) < 0 {
+		return m.setStatus(fmt.Sprintf("%s has no %s: profiling is off, ctrl+x Q turns it on", truncateMiddle(m.selectedDatabase, maxToastNameWidth), profileCollection))
+	}
+	m.selectedCollection = profileCollection
+	// The open collection stays listed when system collections are hidden
+	m.updateFilteredCollections()
+	if i := indexOfString(m.collFiltered, profileCollection); i >= 0 {
+		m.collCursor = i
+	}
+	m.acknowledgeWatch(m.currentNamespace())
+	m.loadCollectionSettings()
+	m.sortField = "ts"
+	m.sortDesc = true
+	m.pinboardActive = false
+	m.loadingDocs = true
+	m.docScrollOffset = 0
+	m.docCursor = 0
+	m.currentPage = 0
+	m.queryFilter = bson.M{"millis": bson.M{"$gte": profileSlowMillis}}
+	if text, err := bson.MarshalExtJSON(m.queryFilter, false, false); err == nil {
+		m.queryText = string(text)
+		m.queryCursor = len(m.queryText)
+	}
+	m.focus = FocusDocuments
+	return loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, 0, m.docsPerPage, m.queryFilter, m.sortSpec())
+}
+
+// profileLevelMsg carries the profiling level of a database
+type profileLevelMsg struct {
+	dbName string
+	level  int
+	slowms int
+	err    error
+}
+
+// profileSetMsg reports the end of changing the profiling level
+type profileSetMsg struct {
+	dbName string
+	level  int
+	slowms int
+	err    error
+}
+
+// newProfileSlowInput creates the input for the slowms threshold
+func newProfileSlowInput() textinput.Model {
+	ti := textinput.New()
+	ti.CharLimit = 7
+	ti.Width = 10
+	return ti
+}
+
+// loadProfileLevel reads the profiling level of dbName: the profile command
+// with level -1 changes nothing
+func loadProfileLevel(client *mongo.Client, dbName string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var reply struct {
+			Was    int `bson:"was"`
+			Slowms int `bson:"slowms"`
+		}
+		err := client.Database(dbName).RunCommand(ctx, bson.D{{Key: "profile", Value: -1}}).Decode(&reply)
+		return profileLevelMsg{dbName: dbName, level: reply.Was, slowms: reply.Slowms, err: err}
+	}
+}
+
+// openProfileLevel opens the prompt showing and changing the profiling
+// level of the current database
+func (m *Model) openProfileLevel() tea.Cmd {
+	if m.client == nil || m.selectedDatabase == "" {
+		return nil
+	}
+	m.profileActive = true
+	m.profileDatabase = m.selectedDatabase
+	m.profileLoading = true
+	m.profileWas = -1
+	m.profileLevel = 0
+	m.profileErr = ""
+	m.profileSlowInput.SetValue("")
+	m.profileSlowInput.Focus()
+	return tea.Batch(textinput.Blink, loadProfileLevel(m.client, m.selectedDatabase))
+}
+
+// handleProfileLevel fills in the prompt with the current profiling level,
+// unless it was closed meanwhile
+func (m *Model) handleProfileLevel(msg profileLevelMsg) {
+	if !m.profileActive || msg.dbName != m.profileDatabase {
+		return
+	}
+	m.profileLoading = false
+	if isUnauthorizedError(msg.err) {
+		m.profileErr = "no permission to read the profiling level: it needs the dbAdmin role on " + msg.dbName
+		return
+	}
+	if msg.err != nil {
+		m.profileErr = "failed to read the profiling level: " + msg.err.Error()
+		return
+	}
+	m.profileWas = msg.level
+	m.profileLevel = msg.level
+	m.profileSlowInput.SetValue(strconv.Itoa(msg.slowms))
+	m.profileSlowInput.CursorEnd()
+}
+
+// handleProfileLevelKey handles keyboard input in the profiling level prompt
+func (m *Model) handleProfileLevelKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "ctrl+g":
+		m.profileActive = false
+		m.profileSlowInput.Blur()
+		return nil
+	case "up", "ctrl+p":
+		m.profileLevel = clampIndex(m.profileLevel-1, len(profileLevels))
+		return nil
+	case "down", "ctrl+n":
+		m.profileLevel = clampIndex(m.profileLevel+1, len(profileLevels))
+		return nil
+	case "enter":
+		if m.profileLoading {
+			return nil
+		}
+		slowms, err := strconv.Atoi(strings.TrimSpace(m.profileSlowInput.Value()))
+		if err != nil || slowms < 0 {
+			m.profileErr = "slowms must be a number of milliseconds"
+			return nil
+		}
+		m.profileActive = false
+		m.profileSlowInput.Blur()
+		return setProfileLevel(m.client, m.profileDatabase, m.profileLevel, slowms)
+	}
+	var cmd tea.Cmd
+	m.profileSlowInput, cmd = m.profileSlowInput.Update(msg)
+	m.profileErr = ""
+	return cmd
+}
+
+// setProfileLevel changes the profiling level of dbName
+func setProfileLevel(client *mongo.Client, dbName string, level, slowms int) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		cmd := bson.D{{Key: "profile", Value: level}, {Key: "slowms", Value: slowms}}
+		err := client.Database(dbName).RunCommand(ctx, cmd).Err()
+		return profileSetMsg{dbName: dbName, level: level, slowms: slowms, err: err}
+	}
+}
+
+// handleProfileSet announces the new profiling level. Turning profiling on
+// creates system.profile, so the collections are reloaded.
+func (m *Model) handleProfileSet(msg profileSetMsg) tea.Cmd {
+	if isUnauthorizedError(msg.err) {
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("No permission to change the profiling level of %s: it needs the dbAdmin role", msg.dbName)
+		return nil
+	}
+	if msg.err != nil {
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Failed to change the profiling level of %s: %v", msg.dbName, msg.err)
+		return nil
+	}
+	dbName := truncateMiddle(msg.dbName, maxToastNameWidth)
+	var status tea.Cmd
+	switch msg.level {
+	case 0:
+		status = m.setStatus(fmt.Sprintf("profiling of %s off", dbName))
+	case 1:
+		status = m.setStatus(fmt.Sprintf("profiling operations of %s slower than %dms • ctrl+x q shows them", dbName, msg.slowms))
+	default:
+		status = m.setStatus(fmt.Sprintf("profiling every operation of %s • ctrl+x q shows the slow ones", dbName))
+	}
+	if msg.level > 0 && msg.dbName == m.selectedDatabase {
+		return tea.Batch(status, refreshCollections(m.client, msg.dbName))
+	}
+	return status
+}
+
+// renderProfileLevelPrompt renders the profiling level prompt modal
+func (m Model) renderProfileLevelPrompt(background string) string {
+	width := m.modalWidth(64)
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+
+	lines := []string{
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render(truncate("Profiling of "+m.profileDatabase, width-4)),
+		"",
+	}
+	if m.profileLoading {
+		lines = append(lines, normalStyle.Render("Loading..."))
+	} else {
+		for i, level := range profileLevels {
+			line := "  " + level
+			if i == m.profileWas {
+				line += " (current)"
+			}
+			if i == m.profileLevel {
+				line = selectedStyle.Render("> " + strings.TrimPrefix(line, "  "))
+			}
+			lines = append(lines, line)
+		}
+	}
+	lines = append(lines,
+		"",
+		"Slow operation threshold (slowms):",
+		fitInput(m.profileSlowInput, width-4),
+	)
+	if m.profileErr != "" {
+		lines = append(lines, "", lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Width(width-4).Render(m.profileErr))
+	}
+	lines = append(lines, "", hintStyle.Render("↑/↓: level • enter: apply • esc: cancel"))
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("205")).
+		Padding(1, 2).
+		Width(width)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
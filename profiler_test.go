@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestOpenProfile(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+
+	m = pressKey(m, "ctrl+x")
+	m = pressKey(m, "q")
+	if m.selectedCollection != "orders" || !strings.Contains(m.statusMessage, "shop has no system.profile: profiling is off") {
+		t.Fatalf("collection %q, status %q", m.selectedCollection, m.statusMessage)
+	}
+
+	m.collections = append(m.collections, profileCollection)
+	m.hideSystem = true
+	m.updateFilteredCollections()
+	m = pressKey(m, "ctrl+x")
+	m = pressKey(m, "q")
+	if m.selectedCollection != profileCollection || entryAt(m.collFiltered, m.collCursor) != profileCollection {
+		t.Fatalf("collection %q, cursor on %q", m.selectedCollection, entryAt(m.collFiltered, m.collCursor))
+	}
+	if m.queryText != `{"millis":{"$gte":100}}` || m.sortLabel() != "ts ↓" || m.focus != FocusDocuments || !m.loadingDocs {
+		t.Errorf("query %q, sort %q, focus %v, loading %v", m.queryText, m.sortLabel(), m.focus, m.loadingDocs)
+	}
+}
+
+func TestProfileLevelPrompt(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+
+	m = pressKey(m, "ctrl+x")
+	m = pressKey(m, "Q")
+	if !m.profileActive || !m.profileLoading || m.profileDatabase != "shop" {
+		t.Fatalf("active = %v, loading = %v, database %q", m.profileActive, m.profileLoading, m.profileDatabase)
+	}
+
+	m, _ = update(t, m, profileLevelMsg{dbName: "shop", level: 0, slowms: 100})
+	if view := normalizeRender(m.View()); !strings.Contains(view, "0: off (current)") || m.profileSlowInput.Value() != "100" {
+		t.Errorf("slowms %q:\n%s", m.profileSlowInput.Value(), view)
+	}
+
+	m = pressKey(m, "down")
+	m.profileSlowInput.SetValue("fast")
+	m = pressKey(m, "enter")
+	if !m.profileActive || m.profileErr != "slowms must be a number of milliseconds" {
+		t.Fatalf("active = %v, error %q", m.profileActive, m.profileErr)
+	}
+	m.profileSlowInput.SetValue("50")
+	m, cmd := update(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.profileActive || cmd == nil || m.profileLevel != 1 {
+		t.Errorf("active = %v, level %d", m.profileActive, m.profileLevel)
+	}
+}
+
+func TestProfileLevelDenied(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m = pressKey(m, "ctrl+x")
+	m = pressKey(m, "Q")
+
+	denied := mongo.CommandError{Code: 13, Name: "Unauthorized", Message: "not authorized on shop to execute command"}
+	m, _ = update(t, m, profileLevelMsg{dbName: "shop", err: denied})
+	if !strings.Contains(m.profileErr, "needs the dbAdmin role") {
+		t.Errorf("error %q", m.profileErr)
+	}
+}
+
+func TestProfileSet(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+
+	m, cmd := update(t, m, profileSetMsg{dbName: "shop", level: 1, slowms: 50})
+	if cmd == nil || m.statusMessage != "profiling operations of shop slower than 50ms • ctrl+x q shows them" {
+		t.Errorf("status %q", m.statusMessage)
+	}
+
+	m, _ = update(t, m, profileSetMsg{dbName: "shop", level: 2, err: errors.New("profile not allowed on mongos")})
+	if !m.errorModal || !strings.Contains(m.errorMessage, "Failed to change the profiling level of shop") {
+		t.Errorf("modal = %v %q", m.errorModal, m.errorMessage)
+	}
+}
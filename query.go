@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -110,6 +109,15 @@ func relaxedJSONToStrict(input string) string {
 	return result.String()
 }
 
+// parseQueryFilter parses the query panel text: relaxed JavaScript-style
+// JSON that may use Extended JSON ($oid, $date...) and the ObjectId() and
+// UUID() shell helpers
+func parseQueryFilter(text string) (bson.M, error) {
+	var filter bson.M
+	err := bson.UnmarshalExtJSON([]byte(relaxedJSONToStrict(expandShellHelpers(text))), false, &filter)
+	return filter, err
+}
+
 // isIdentifierStart returns true if ch can start a JavaScript identifier
 func isIdentifierStart(ch byte) bool {
 	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || ch == '_' || ch == '$'
@@ -123,6 +131,13 @@ func isIdentifierChar(ch byte) bool {
 // handleQueryKey handles keyboard input when query panel is focused
 // Returns the command to run and whether the key was handled
 func (m *Model) handleQueryKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	if m.pasteIDs != nil {
+		return m.handlePastePromptKey(msg), true
+	}
+	if msg.Paste {
+		m.pasteIntoQuery(string(msg.Runes))
+		return nil, true
+	}
 	switch msg.String() {
 	case "ctrl+c":
 		return tea.Quit, true
@@ -159,6 +174,9 @@ func (m *Model) handleQueryKey(msg tea.KeyMsg) (tea.Cmd, bool) {
 			m.queryText = m.queryText[:m.queryCursor] + m.queryText[m.queryCursor+1:]
 		}
 		return nil, true
+	case "ctrl+y":
+		// Yank the last copy from register 0
+		return m.yankRegisterIntoQuery(), true
 	case "ctrl+k":
 		// Kill to end of line
 		m.queryText = m.queryText[:m.queryCursor]
@@ -171,24 +189,19 @@ func (m *Model) handleQueryKey(msg tea.KeyMsg) (tea.Cmd, bool) {
 	case "enter":
 		// Execute query
 		if m.selectedCollection != "" && m.client != nil {
-			// Convert relaxed JS-style JSON to strict JSON, then parse
-			strictJSON := relaxedJSONToStrict(m.queryText)
-			var filter bson.M
-			err := json.Unmarshal([]byte(strictJSON), &filter)
+			filter, err := parseQueryFilter(m.queryText)
 			if err != nil {
 				m.errorModal = true
 				m.errorMessage = fmt.Sprintf("Invalid query JSON: %v", err)
 				return nil, true
 			}
 			m.queryFilter = filter
+			m.pinboardActive = false
+			m.serverSearchQuery = ""
+			m.serverSearchPrev = nil
 			m.queryLoading = true
 			m.currentPage = 0
-			m.docCursor = 0
-			m.docScrollOffset = 0
-			return tea.Batch(
-				m.querySpinner.Tick,
-				loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, 0, filter),
-			), true
+			return loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, 0, m.docsPerPage, filter, m.sortSpec()), true
 		}
 		return nil, true
 	default:
@@ -204,6 +217,11 @@ func (m *Model) handleQueryKey(msg tea.KeyMsg) (tea.Cmd, bool) {
 	}
 }
 
+// busy reports whether a query or a page load is in flight
+func (m Model) busy() bool {
+	return m.queryLoading || m.loadingDocs
+}
+
 func (m Model) renderQueryPanel(width, height int) string {
 	// Render query text with cursor
 	contentWidth := width - 2 // Account for padding
@@ -219,6 +237,10 @@ func (m Model) renderQueryPanel(width, height int) string {
 	availableWidth := contentWidth - spinnerWidth
 
 	var content string
+	if m.pasteIDs != nil {
+		prompt := fmt.Sprintf("Paste %s as an _id $in filter? y/enter: convert • other: paste as text", m.pastedIDLabel())
+		return m.renderQueryPanelFrame("Query", truncate(prompt, contentWidth), true, width, height)
+	}
 	if m.focus == FocusQuery {
 		// Show cursor
 		before := m.queryText[:m.queryCursor]
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// toggleRawView swaps the tree of the document under the cursor for its
+// canonical Extended JSON text, or back. The tree nodes themselves are left
+// untouched, so switching back restores the previous expand/collapse state.
+func (m *Model) toggleRawView() tea.Cmd {
+	node := m.nodeAtCursor()
+	if node == nil || node.Depth < 0 {
+		return nil
+	}
+	root := node
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	row := m.docCursor - m.docScrollOffset
+
+	if root.RawOnly {
+		return m.setStatus("this document is too large for the tree view")
+	}
+	if root.Raw {
+		root.Raw = false
+		root.RawLines = nil
+	} else {
+		if _, ok := root.Value.(bson.M); !ok {
+			return nil
+		}
+		if err := setRawLines(root); err != nil {
+			return m.setStatus(fmt.Sprintf("can't render raw JSON: %v", err))
+		}
+		root.Raw = true
+		root.Collapsed = false
+	}
+	m.rebuildFlattenedTree()
+	m.anchorCursor(root, row)
+	return nil
+}
+
+// setRawLines renders a document root as canonical Extended JSON lines
+func setRawLines(root *JSONNode) error {
+	jsonBytes, err := bson.MarshalExtJSONIndent(sortedDocument(root.Value), true, false, "", "  ")
+	if err != nil {
+		return err
+	}
+	root.RawLines = buildRawLines(root, string(jsonBytes))
+	return nil
+}
+
+// sortedDocument converts a bson.M, including nested documents and arrays,
+// to a bson.D with keys in the same sorted order as the tree view
+func sortedDocument(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bson.M:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		d := make(bson.D, 0, len(v))
+		for _, k := range keys {
+			d = append(d, bson.E{Key: k, Value: sortedDocument(v[k])})
+		}
+		return d
+	case bson.A:
+		a := make(bson.A, len(v))
+		for i, item := range v {
+			a[i] = sortedDocument(item)
+		}
+		return a
+	}
+	return value
+}
+
+// buildRawLines creates one node per line of raw JSON after the opening
+// brace, which the root node itself displays. The milliseconds line of a
+// date keeps the date as its Value, for the date display to annotate.
+func buildRawLines(root *JSONNode, text string) []*JSONNode {
+	lines := strings.Split(text, "\n")
+	nodes := make([]*JSONNode, 0, len(lines))
+	for i, line := range lines[1:] {
+		node := &JSONNode{
+			RawText: line,
+			Parent:  root,
+			Depth:   1,
+		}
+		if strings.TrimSpace(lines[i]) == `"$date": {` {
+			var ms int64
+			if _, err := fmt.Sscanf(strings.TrimSpace(line), `"$numberLong": "%d"`, &ms); err == nil {
+				node.Value = primitive.DateTime(ms)
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// renderRawLine renders a line of raw JSON with syntax highlighting
+func renderRawLine(text string, maxWidth int) string {
+	return "  " + highlightJSONLine(truncate(text, maxWidth-2))
+}
+
+// highlightJSONLine colors the keys, strings, numbers and literals of a line
+// of indented JSON using the same styles as the tree view
+func highlightJSONLine(line string) string {
+	var b strings.Builder
+	i := 0
+	for i < len(line) {
+		c := line[i]
+		switch {
+		case c == '"':
+			end := i + 1
+			for end < len(line) && line[end] != '"' {
+				if line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end < len(line) {
+				end++
+			}
+			token := line[i:end]
+			if strings.HasPrefix(strings.TrimLeft(line[end:], " "), ":") {
+				b.WriteString(jsonKeyStyle.Render(token))
+			} else {
+				b.WriteString(jsonStringStyle.Render(token))
+			}
+			i = end
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(line) && strings.IndexByte("0123456789.eE+-", line[end]) >= 0 {
+				end++
+			}
+			b.WriteString(jsonNumberStyle.Render(line[i:end]))
+			i = end
+		case strings.HasPrefix(line[i:], "true"), strings.HasPrefix(line[i:], "false"):
+			word := "true"
+			if c == 'f' {
+				word = "false"
+			}
+			b.WriteString(jsonBoolStyle.Render(word))
+			i += len(word)
+		case strings.HasPrefix(line[i:], "null"):
+			b.WriteString(jsonNullStyle.Render("null"))
+			i += 4
+		case strings.IndexByte("{}[]", c) >= 0:
+			b.WriteString(jsonBracketStyle.Render(string(c)))
+			i++
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// redactedValue replaces the value of sensitive fields
+const redactedValue = "***"
+
+// sensitiveFieldPattern matches field names whose values should never leave
+// the terminal unmasked (clipboard bundles, exports, shared snapshots). It
+// runs on the words of the name joined by underscores, see fieldWords, and
+// only matches whole words, so "authToken" and "db_pass" are masked but
+// "author" and "sessionCount" are not.
+var sensitiveFieldPattern = regexp.MustCompile(`(^|_)(pass(word|wd)?|pwd|secrets?|tokens?|api_?key|credentials?|auth|authorization|ssn|social_security|credit_card|card_number|cvv|private_key)(_|$)|(^|_)session(_(id|token|key))?$`)
+
+// wordBoundary finds where a camelCase name starts a new word, as in
+// "sessionCount" or "APIKey"
+var wordBoundary = regexp.MustCompile(`([a-z0-9])([A-Z])|([A-Z])([A-Z][a-z])`)
+
+// fieldWords returns the words of a field name in lower case joined by
+// underscores, e.g. "api_key" for "APIKey", "api-key" or "api.key"
+func fieldWords(key string) string {
+	key = wordBoundary.ReplaceAllString(key, "${1}${3}_${2}${4}")
+	key = strings.NewReplacer("-", "_", ".", "_", " ", "_").Replace(key)
+	return strings.ToLower(key)
+}
+
+// isSensitiveField returns true if the field name matches a sensitive pattern
+func isSensitiveField(key string) bool {
+	return sensitiveFieldPattern.MatchString(fieldWords(key))
+}
+
+// maskSensitiveFields returns a deep copy of doc with the values of
+// sensitive fields replaced by redactedValue
+func maskSensitiveFields(doc bson.M) bson.M {
+	masked, _ := maskValue(doc).(bson.M)
+	return masked
+}
+
+// maskValue recursively masks sensitive fields inside a value
+func maskValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bson.M:
+		out := make(bson.M, len(v))
+		for k, child := range v {
+			if isSensitiveField(k) {
+				out[k] = redactedValue
+			} else {
+				out[k] = maskValue(child)
+			}
+		}
+		return out
+	case bson.D:
+		out := make(bson.D, len(v))
+		for i, e := range v {
+			out[i] = bson.E{Key: e.Key, Value: redactedValue}
+			if !isSensitiveField(e.Key) {
+				out[i].Value = maskValue(e.Value)
+			}
+		}
+		return out
+	case bson.A:
+		out := make(bson.A, len(v))
+		for i, item := range v {
+			out[i] = maskValue(item)
+		}
+		return out
+	default:
+		return value
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestIsSensitiveFieldMatchesWholeWords(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"password", true},
+		{"db_pass", true},
+		{"authToken", true},
+		{"auth", true},
+		{"APIKey", true},
+		{"api-key", true},
+		{"sessionId", true},
+		{"session", true},
+		{"user.session_token", true},
+		{"creditCard", true},
+		{"author", false},
+		{"sessionCount", false},
+		{"passport", false},
+		{"tokenizer", false},
+		{"bypass", false},
+	}
+	for _, tt := range tests {
+		if got := isSensitiveField(tt.key); got != tt.want {
+			t.Errorf("isSensitiveField(%q) = %v, want %v (words %q)", tt.key, got, tt.want, fieldWords(tt.key))
+		}
+	}
+}
+
+func TestReproQueryMasksTheFilter(t *testing.T) {
+	filter := bson.M{
+		"author": "ada",
+		"$or":    bson.A{bson.M{"apiKey": "sk-live-123"}, bson.M{"password": bson.M{"$in": bson.A{"hunter2"}}}},
+	}
+	query := reproQuery("shop", "orders", filter, bson.D{{Key: "createdAt", Value: -1}})
+	for _, leaked := range []string{"sk-live-123", "hunter2"} {
+		if strings.Contains(query, leaked) {
+			t.Errorf("query leaks %q:\n%s", leaked, query)
+		}
+	}
+	for _, want := range []string{`db.getSiblingDB("shop").getCollection("orders").find(`, `author: "ada"`, `apiKey: "***"`, ".sort({\n  createdAt: -1\n})"} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query lacks %q:\n%s", want, query)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// databasesRefreshedMsg carries the databases listed again with R
+type databasesRefreshedMsg struct {
+	databases []string
+	err       error
+}
+
+// collectionsRefreshedMsg carries the collections of dbName listed again
+// with R
+type collectionsRefreshedMsg struct {
+	dbName      string
+	collections []string
+	specs       map[string]collectionSpec
+	err         error
+}
+
+// refreshDatabases lists the databases of the connected server again
+func refreshDatabases(client *mongo.Client) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		databases, err := client.ListDatabaseNames(ctx, map[string]interface{}{})
+		sort.Strings(databases)
+		return databasesRefreshedMsg{databases: databases, err: err}
+	}
+}
+
+// refreshCollections lists the collections of dbName again
+func refreshCollections(client *mongo.Client, dbName string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		collections, specs, err := listCollections(ctx, client, dbName)
+		return collectionsRefreshedMsg{dbName: dbName, collections: collections, specs: specs, err: err}
+	}
+}
+
+// refreshFocusedList lists the databases or collections again, whichever
+// panel has the focus, to pick up what other clients created or dropped
+func (m *Model) refreshFocusedList() tea.Cmd {
+	if m.client == nil {
+		return nil
+	}
+	switch m.focus {
+	case FocusDatabases:
+		return refreshDatabases(m.client)
+	case FocusCollections:
+		if m.selectedDatabase != "" {
+			return refreshCollections(m.client, m.selectedDatabase)
+		}
+	}
+	return nil
+}
+
+// handleDatabasesRefreshed replaces the databases list, keeping the cursor
+// on the same database, or at the same position when it's gone, and the
+// selection as it is. The default database of the connection string stays
+// listed, as after connecting.
+func (m *Model) handleDatabasesRefreshed(msg databasesRefreshedMsg) tea.Cmd {
+	if msg.err != nil {
+		if isUnauthorizedError(msg.err) {
+			return m.setStatus("no permission to list databases: the list was kept")
+		}
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Failed to refresh databases: %v", msg.err)
+		return nil
+	}
+	databases := msg.databases
+	if uriDB, _ := uriDefaults(m.connectionString); uriDB != "" && indexOfString(databases, uriDB) < 0 {
+		databases = append(databases, uriDB)
+		sort.Strings(databases)
+	}
+	name := entryAt(m.dbFiltered, m.dbCursor)
+	m.databases = databases
+	m.updateFilteredDatabases()
+	if i := indexOfString(m.dbFiltered, name); i >= 0 {
+		m.dbCursor = i
+	}
+	m.dbRefreshedAt = time.Now()
+	return nil
+}
+
+// handleCollectionsRefreshed replaces the collections list, keeping the
+// cursor on the same collection and the open one open, and counts the
+// collections that are new
+func (m *Model) handleCollectionsRefreshed(msg collectionsRefreshedMsg) tea.Cmd {
+	if msg.dbName != m.selectedDatabase {
+		return nil // Moved on to another database meanwhile
+	}
+	if msg.err != nil {
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Failed to refresh collections: %v", msg.err)
+		return nil
+	}
+	name := entryAt(m.collFiltered, m.collCursor)
+	m.collections = msg.collections
+	m.collSpecs = msg.specs
+	m.updateFilteredCollections()
+	if i := indexOfString(m.collFiltered, name); i >= 0 {
+		m.collCursor = i
+	}
+	m.collRefreshedAt = time.Now()
+	m.collRefreshedDB = msg.dbName
+	return m.countCollections()
+}
+
+// refreshedSuffix returns the panel title info showing when its list was
+// last refreshed with R, or "" if it wasn't
+func refreshedSuffix(at time.Time) string {
+	if at.IsZero() {
+		return ""
+	}
+	return "↻ " + at.Format("15:04")
+}
+
+// entryAt returns the entry of list at index, or "" when out of range
+func entryAt(list []string, index int) string {
+	if index >= 0 && index < len(list) {
+		return list[index]
+	}
+	return ""
+}
+
+// indexOfString returns the index of s in list, or -1
+func indexOfString(list []string, s string) int {
+	for i, entry := range list {
+		if entry == s {
+			return i
+		}
+	}
+	return -1
+}
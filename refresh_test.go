@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRefreshKeepsTheCursorOnTheSameEntry(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m.focus = FocusCollections
+	m.collCursor = 2 // events_v2_partitioned_2024_06_eu_west_1
+
+	m = pressKey(m, "R")
+	m, _ = update(t, m, collectionsRefreshedMsg{dbName: "shop", collections: []string{"audit", "customers", "events_v2_partitioned_2024_06_eu_west_1", "orders"}})
+	if m.collFiltered[m.collCursor] != "events_v2_partitioned_2024_06_eu_west_1" || m.selectedCollection != "orders" || len(m.documents) == 0 {
+		t.Errorf("cursor on %q, selected %q", m.collFiltered[m.collCursor], m.selectedCollection)
+	}
+	if !m.collCounts["shop.audit"].pending {
+		t.Error("new collection not counted")
+	}
+	if panel := normalizeRender(m.renderCollectionPanel(10)); !strings.Contains(panel, "↻ "+time.Now().Format("15:04")) {
+		t.Errorf("refresh not shown:\n%s", panel)
+	}
+
+	// When the entry under the cursor is gone the cursor keeps its place
+	m, _ = update(t, m, collectionsRefreshedMsg{dbName: "shop", collections: []string{"audit", "customers", "orders"}})
+	if m.collFiltered[m.collCursor] != "orders" {
+		t.Errorf("cursor on %q", m.collFiltered[m.collCursor])
+	}
+
+	// Collections of a database left meanwhile are dropped
+	m.selectedDatabase = "analytics"
+	m, _ = update(t, m, collectionsRefreshedMsg{dbName: "shop", collections: []string{"customers"}})
+	if len(m.collections) != 3 {
+		t.Errorf("stale refresh applied: %v", m.collections)
+	}
+}
+
+func TestRefreshDatabases(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m.connectionString = "mongodb://127.0.0.1:1/reports"
+	m.focus = FocusDatabases
+
+	m, _ = update(t, m, databasesRefreshedMsg{databases: []string{"admin", "analytics", "archive", "shop"}})
+	if strings.Join(m.databases, ",") != "admin,analytics,archive,reports,shop" {
+		t.Errorf("databases = %v", m.databases)
+	}
+	if m.dbFiltered[m.dbCursor] != "shop" || m.selectedDatabase != "shop" {
+		t.Errorf("cursor on %q, selected %q", m.dbFiltered[m.dbCursor], m.selectedDatabase)
+	}
+	if panel := normalizeRender(m.renderDatabasePanel(10)); !strings.Contains(panel, "↻") {
+		t.Errorf("refresh not shown:\n%s", panel)
+	}
+}
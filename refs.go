@@ -0,0 +1,262 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// loadCollectionSettings loads the saved view settings for the selected
+// collection and resets the ones that only apply to the previous one
+func (m *Model) loadCollectionSettings() {
+	ns := m.currentNamespace()
+	m.sortField = ""
+	m.sortDesc = false
+	m.serverSearchQuery = ""
+	m.serverSearchPrev = nil
+	m.docHScroll = 0
+	m.expandState = map[string]map[string]bool{}
+	m.docsPerPage = m.pageSizeFor(ns)
+	m.refTargets = map[string]string{}
+	if targets, err := loadReferenceTargets(m.connectionName, ns); err == nil {
+		m.refTargets = targets
+	}
+	m.epochSuppressed = map[string]bool{}
+	if paths, err := loadEpochSuppressions(m.connectionName, ns); err == nil {
+		m.epochSuppressed = paths
+	}
+}
+
+// referencePattern returns the field path of node with array indexes removed,
+// so one configured target covers every element (items.productId)
+func referencePattern(node *JSONNode) string {
+	var segments []string
+	for n := node; n != nil && n.Parent != nil; n = n.Parent {
+		if !n.Parent.IsArray {
+			segments = append([]string{n.Key}, segments...)
+		}
+	}
+	return formatFieldPath(segments)
+}
+
+// referenceTarget returns the database and collection a leaf refers to: the
+// $ref of a DBRef, or the configured target of a foreign key field
+func (m Model) referenceTarget(node *JSONNode) (dbName, collName string, ok bool) {
+	if node.IsObject || node.IsArray || node.Foreign || node.Parent == nil {
+		return "", "", false
+	}
+	if node.Key == "$id" {
+		if ref, isDoc := node.Parent.Value.(bson.M); isDoc {
+			if coll, isString := ref["$ref"].(string); isString {
+				dbName, _ = ref["$db"].(string)
+				if dbName == "" {
+					dbName = m.selectedDatabase
+				}
+				return dbName, coll, true
+			}
+		}
+	}
+	if coll, found := m.refTargets[referencePattern(node)]; found {
+		return m.selectedDatabase, coll, true
+	}
+	return "", "", false
+}
+
+// expandReference expands a reference leaf, fetching the referenced document
+// the first time. It returns false when node isn't a reference.
+func (m *Model) expandReference(node *JSONNode) (tea.Cmd, bool) {
+	dbName, collName, ok := m.referenceTarget(node)
+	if !ok {
+		return nil, false
+	}
+	if node.Ref != nil {
+		node.Collapsed = false
+		m.rebuildFlattenedTree()
+		return nil, true
+	}
+	return tea.Batch(
+		m.setStatus(fmt.Sprintf("fetching %s...", truncateMiddle(namespaceOf(dbName, collName), maxToastNameWidth))),
+		fetchReference(m.client, dbName, collName, node),
+	), true
+}
+
+// collapseReference hides the grafted document under a reference leaf
+func (m *Model) collapseReference(node *JSONNode) bool {
+	if node.Ref == nil || node.Collapsed {
+		return false
+	}
+	node.Collapsed = true
+	m.rebuildFlattenedTree()
+	return true
+}
+
+// fetchReference loads the document a reference leaf points to
+func fetchReference(client *mongo.Client, dbName, collName string, node *JSONNode) tea.Cmd {
+	id := node.Value
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var doc bson.M
+		err := client.Database(dbName).Collection(collName).FindOne(ctx, bson.M{"_id": id}).Decode(&doc)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return referenceLoadedMsg{node: node, collName: collName}
+		}
+		return referenceLoadedMsg{node: node, collName: collName, doc: doc, err: err}
+	}
+}
+
+// graftReference attaches a fetched document under its reference leaf as
+// read-only foreign nodes
+func (m *Model) graftReference(msg referenceLoadedMsg) {
+	node := msg.node
+	label := "→ " + msg.collName + "/" + shortID(node.Value)
+
+	ref := &JSONNode{Parent: node, Depth: node.Depth + 1, Foreign: true}
+	if msg.doc == nil {
+		ref.Key = "→ (not found)"
+	} else {
+		tree := buildJSONTree(msg.doc, node.Depth+1)
+		ref.Key = label
+		ref.Value = msg.doc
+		ref.IsObject = true
+		ref.Children = tree.Children
+		for _, child := range ref.Children {
+			child.Parent = ref
+		}
+		markForeign(ref)
+	}
+	node.Ref = ref
+	node.Collapsed = false
+	m.rebuildFlattenedTree()
+}
+
+// markForeign flags a grafted subtree so it's excluded from edits and copies
+func markForeign(node *JSONNode) {
+	node.Foreign = true
+	for _, child := range node.Children {
+		markForeign(child)
+	}
+}
+
+// shortID abbreviates an _id for the reference label
+func shortID(id interface{}) string {
+	s := rawValueString(id)
+	if oid, ok := id.(primitive.ObjectID); ok {
+		s = oid.Hex()
+	}
+	if len(s) > 8 {
+		return s[:4] + "…"
+	}
+	return s
+}
+
+// treeContains reports whether node is still part of the displayed documents
+func (m Model) treeContains(node *JSONNode) bool {
+	root := node
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	for _, r := range m.docTree {
+		if r == root {
+			return true
+		}
+	}
+	return false
+}
+
+// newReferenceInput creates the textinput for the reference target prompt
+func newReferenceInput() textinput.Model {
+	ti := textinput.New()
+	ti.CharLimit = 120
+	ti.Width = 40
+	return ti
+}
+
+// openReferencePrompt opens the prompt to configure the target collection of
+// the field under the cursor
+func (m *Model) openReferencePrompt() tea.Cmd {
+	node := m.nodeAtCursor()
+	if node == nil || node.Parent == nil || node.Foreign || node.RawText != "" || node.IsObject || node.IsArray {
+		return nil
+	}
+	m.refPromptActive = true
+	m.refPromptPath = referencePattern(node)
+	m.refInput.SetValue(m.refTargets[m.refPromptPath])
+	m.refInput.CursorEnd()
+	m.refInput.Focus()
+	return textinput.Blink
+}
+
+// handleReferencePromptKey handles keyboard input in the reference target prompt
+func (m *Model) handleReferencePromptKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "ctrl+g":
+		m.refPromptActive = false
+		m.refInput.Blur()
+		return nil
+	case "enter":
+		target := strings.TrimSpace(m.refInput.Value())
+		m.refPromptActive = false
+		m.refInput.Blur()
+		if err := saveReferenceTarget(m.connectionName, m.currentNamespace(), m.refPromptPath, target); err != nil {
+			m.errorModal = true
+			m.errorMessage = fmt.Sprintf("Failed to save reference: %v", err)
+			return nil
+		}
+		if target == "" {
+			delete(m.refTargets, m.refPromptPath)
+			return m.setStatus(m.refPromptPath + " is no longer a reference")
+		}
+		m.refTargets[m.refPromptPath] = target
+		return m.setStatus(fmt.Sprintf("%s → %s (l to expand)", m.refPromptPath, target))
+	default:
+		var cmd tea.Cmd
+		m.refInput, cmd = m.refInput.Update(msg)
+		return cmd
+	}
+}
+
+// renderReferencePrompt renders the reference target prompt modal
+func (m Model) renderReferencePrompt(background string) string {
+	width := m.modalWidth(60)
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render("Reference "+m.refPromptPath),
+		"",
+		"Collection the values of this field refer to (empty to clear):",
+		fitInput(m.refInput, width-4),
+		"",
+		hintStyle.Render("enter: save • esc: cancel"),
+	)
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("205")).
+		Padding(1, 2).
+		Width(width)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(content),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
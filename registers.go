@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// maxRegisters is how many copies the registers keep, like vim's "0 to "9
+const maxRegisters = 10
+
+// register is one copied text, kept for the session so copies work on
+// terminals without a clipboard
+type register struct {
+	Label string // What was copied, e.g. "path" or "document 65ab…"
+	Text  string
+}
+
+// yank copies text into register 0, shifting the older ones down, and to the
+// system clipboard when there is one
+func (m *Model) yank(label, text string) tea.Cmd {
+	m.registers = append([]register{{Label: label, Text: text}}, m.registers...)
+	if len(m.registers) > maxRegisters {
+		m.registers = m.registers[:maxRegisters]
+	}
+	return copyTextCmd(text)
+}
+
+// yankDocument copies a document as pretty-printed Extended JSON
+func (m *Model) yankDocument(doc bson.M) tea.Cmd {
+	// Relaxed Extended JSON keeps ObjectIds and dates intact while staying readable
+	jsonBytes, err := bson.MarshalExtJSONIndent(doc, false, false, "", "  ")
+	if err != nil {
+		return m.setStatus(fmt.Sprintf("copy failed: %v", err))
+	}
+	return m.yank("document "+shortID(doc["_id"]), string(jsonBytes))
+}
+
+// registerPreview flattens a register's text onto one line
+func registerPreview(text string, width int) string {
+	return truncate(strings.Join(strings.Fields(text), " "), width)
+}
+
+// openRegisters shows the registers panel
+func (m *Model) openRegisters() tea.Cmd {
+	if len(m.registers) == 0 {
+		return m.setStatus("registers are empty: copy something with yy, yp or yv")
+	}
+	m.registersActive = true
+	m.registerCursor = 0
+	return nil
+}
+
+// yankRegisterIntoQuery pastes register 0 at the query cursor, like a
+// clipboard paste
+func (m *Model) yankRegisterIntoQuery() tea.Cmd {
+	if len(m.registers) == 0 {
+		return m.setStatus("registers are empty")
+	}
+	m.pasteIntoQuery(m.registers[0].Text)
+	return nil
+}
+
+// handleRegistersKey handles keyboard input in the registers panel: enter
+// pastes into the query, e edits the document under the cursor starting
+// from the register, y copies it to the clipboard again
+func (m *Model) handleRegistersKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "ctrl+g", "q", "\"":
+		m.registersActive = false
+	case "up", "k", "ctrl+p":
+		if m.registerCursor > 0 {
+			m.registerCursor--
+		}
+	case "down", "j", "ctrl+n":
+		if m.registerCursor < len(m.registers)-1 {
+			m.registerCursor++
+		}
+	case "enter":
+		m.registersActive = false
+		m.focus = FocusQuery
+		m.pasteIntoQuery(m.registers[m.registerCursor].Text)
+	case "e":
+		docIndex := m.getDocumentIndexAtCursor()
+		if docIndex < 0 || docIndex >= len(m.documents) {
+			return m.setStatus("no document under the cursor to edit")
+		}
+		if _, err := editGuard(m.docProvenance, m.documents[docIndex]); err != nil {
+			return m.setStatus(err.Error())
+		}
+		m.registersActive = false
+		m.editorActive = true
+		return m.openInEditorWith(docIndex, []byte(m.registers[m.registerCursor].Text))
+	case "y":
+		m.registersActive = false
+		return copyTextCmd(m.registers[m.registerCursor].Text)
+	}
+	return nil
+}
+
+// renderRegisters renders the registers panel overlay
+func (m Model) renderRegisters(background string) string {
+	width := m.modalWidth(80)
+	contentWidth := width - 4
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+
+	lines := []string{
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render("Registers"),
+		"",
+	}
+	for i, reg := range m.registers {
+		head := fmt.Sprintf("  %d  %s (%s)  ", i, truncateMiddle(reg.Label, maxToastNameWidth), formatBytes(len(reg.Text)))
+		preview := paginationStyle.Render(registerPreview(reg.Text, contentWidth-lipgloss.Width(head)))
+		if i == m.registerCursor {
+			head = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render("▶" + head[1:])
+		}
+		lines = append(lines, head+preview)
+	}
+	lines = append(lines, "", hintStyle.Render("enter: paste into query • e: edit document from it • y: copy to clipboard • esc: close"))
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("205")).
+		Padding(1, 2).
+		Width(width)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
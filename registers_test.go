@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestYankKeepsNewestRegisters(t *testing.T) {
+	m := newTestModel(120, 30)
+	for i := 0; i < maxRegisters+3; i++ {
+		m.yank("path", fmt.Sprintf("field%d", i))
+	}
+	if len(m.registers) != maxRegisters {
+		t.Fatalf("%d registers, want %d", len(m.registers), maxRegisters)
+	}
+	if m.registers[0].Text != "field12" || m.registers[maxRegisters-1].Text != "field3" {
+		t.Errorf("registers run from %q to %q, want field12 to field3", m.registers[0].Text, m.registers[maxRegisters-1].Text)
+	}
+}
+
+func TestCopyKeysFillRegisters(t *testing.T) {
+	m := newTestModel(120, 30)
+	m = pressKey(m, "y")
+	m = pressKey(m, "y")
+	if len(m.registers) != 1 || !strings.HasPrefix(m.registers[0].Label, "document 65ab") ||
+		!strings.Contains(m.registers[0].Text, `"Ada Lovelace"`) {
+		t.Fatalf("registers after yy = %+v", m.registers)
+	}
+
+	m = pressKey(m, "\"")
+	if !m.registersActive || !strings.Contains(normalizeRender(m.View()), "▶ 0  document 65ab…") {
+		t.Fatalf("registers panel not shown:\n%s", normalizeRender(m.View()))
+	}
+}
+
+func TestPasteRegisterIntoQuery(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.yank("path", "address.city")
+	m.yank("value of name", "Ada")
+
+	// ctrl+y yanks the newest register at the query cursor
+	m.focus = FocusQuery
+	m.queryText, m.queryCursor = "{: 1}", 1
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlY})
+	m = updated.(Model)
+	if m.queryText != "{Ada: 1}" {
+		t.Errorf("query after ctrl+y = %q, want {Ada: 1}", m.queryText)
+	}
+
+	// enter in the panel pastes the highlighted register
+	m.focus = FocusDocuments
+	m = pressKey(m, "\"")
+	m = pressKey(m, "j")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if m.registersActive || m.focus != FocusQuery || m.queryText != "{Adaaddress.city: 1}" {
+		t.Errorf("after enter: panel open %v, focus %v, query %q", m.registersActive, m.focus, m.queryText)
+	}
+}
+
+func TestYIsTheCopyPrefix(t *testing.T) {
+	m := newTestModel(120, 30)
+	if help := normalizeRender(m.View()); !strings.Contains(help, "yy/yp/yv: copy") {
+		t.Errorf("help line lacks the copy keys:\n%s", help)
+	}
+	m = pressKey(m, "y")
+	if m.pendingKey != "y" || !strings.Contains(m.statusMessage, "yy=document") {
+		t.Errorf("pending = %q, hint = %q", m.pendingKey, m.statusMessage)
+	}
+}
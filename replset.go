@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// replicationLagWarning is the lag behind the primary above which a
+// member's lag is highlighted
+const replicationLagWarning = 10 * time.Second
+
+// Error codes replSetGetStatus fails with on servers that aren't replica
+// set members
+const (
+	commandNotFoundCode      = 59 // mongos doesn't have the command
+	noReplicationEnabledCode = 76 // Standalone server
+)
+
+// replSetMember is one member of a replica set as replSetGetStatus reports it
+type replSetMember struct {
+	Name       string    `bson:"name"`
+	State      string    `bson:"stateStr"`
+	Health     float64   `bson:"health"`
+	OptimeDate time.Time `bson:"optimeDate"`
+	Self       bool      `bson:"self"`
+}
+
+// replSetStatus is the reply of replSetGetStatus
+type replSetStatus struct {
+	Set     string          `bson:"set"`
+	Members []replSetMember `bson:"members"`
+}
+
+// replSetStatusMsg carries the replica set status
+type replSetStatusMsg struct {
+	status replSetStatus
+	err    error
+}
+
+// loadReplSetStatus runs replSetGetStatus
+func loadReplSetStatus(client *mongo.Client) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var status replSetStatus
+		err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "replSetGetStatus", Value: 1}}).Decode(&status)
+		return replSetStatusMsg{status: status, err: err}
+	}
+}
+
+// openReplSetStatus shows the members of the replica set
+func (m *Model) openReplSetStatus() tea.Cmd {
+	if m.client == nil {
+		return nil
+	}
+	m.replSetActive = true
+	m.replSet = nil
+	return m.refreshReplSetStatus()
+}
+
+// refreshReplSetStatus asks for the replica set status again
+func (m *Model) refreshReplSetStatus() tea.Cmd {
+	m.replSetLoading = true
+	m.replSetErr = nil
+	return loadReplSetStatus(m.client)
+}
+
+// handleReplSetStatus shows the replica set status, unless the overlay was
+// closed meanwhile
+func (m *Model) handleReplSetStatus(msg replSetStatusMsg) {
+	if !m.replSetActive {
+		return
+	}
+	m.replSetLoading = false
+	m.replSetErr = msg.err
+	if msg.err == nil {
+		status := msg.status
+		m.replSet = &status
+	}
+}
+
+// handleReplSetKey handles keyboard input while the replica set overlay is open
+func (m *Model) handleReplSetKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "ctrl+g", "q":
+		m.replSetActive = false
+	case "r":
+		if !m.replSetLoading {
+			return m.refreshReplSetStatus()
+		}
+	}
+	return nil
+}
+
+// primary returns the member that is primary, or nil during an election
+func (s replSetStatus) primary() *replSetMember {
+	for i := range s.Members {
+		if s.Members[i].State == "PRIMARY" {
+			return &s.Members[i]
+		}
+	}
+	return nil
+}
+
+// lag returns how far member's last applied write is behind the primary's,
+// or behind the newest member's when there is no primary, and whether the
+// member replicates at all
+func (s replSetStatus) lag(member replSetMember) (time.Duration, bool) {
+	if member.State == "PRIMARY" || member.State == "ARBITER" || member.OptimeDate.IsZero() {
+		return 0, false
+	}
+	var newest time.Time
+	if primary := s.primary(); primary != nil {
+		newest = primary.OptimeDate
+	} else {
+		for _, other := range s.Members {
+			if other.OptimeDate.After(newest) {
+				newest = other.OptimeDate
+			}
+		}
+	}
+	return newest.Sub(member.OptimeDate), true
+}
+
+// replSetErrorText explains why there is no replica set status to show
+func replSetErrorText(err error) string {
+	var serverErr mongo.ServerError
+	switch {
+	case isUnauthorizedError(err):
+		return "No permission to run replSetGetStatus: it needs the clusterMonitor role"
+	case errors.As(err, &serverErr) && serverErr.HasErrorCode(noReplicationEnabledCode):
+		return "This server is standalone: it isn't a member of a replica set"
+	case errors.As(err, &serverErr) && serverErr.HasErrorCode(commandNotFoundCode):
+		return "Connected through mongos: each shard is its own replica set, connect to one to see its members"
+	}
+	return "Failed to get the replica set status: " + err.Error()
+}
+
+// renderReplSetOverlay renders the members of the replica set
+func (m Model) renderReplSetOverlay(background string) string {
+	width := m.modalWidth(90)
+	contentWidth := width - 4
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	lagStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+	downStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+	title := "Replica set"
+	var lines []string
+	switch {
+	case m.replSetLoading && m.replSet == nil:
+		lines = append(lines, normalStyle.Render("Loading..."))
+	case m.replSetErr != nil:
+		lines = append(lines, textWrapStyle(contentWidth).Render(replSetErrorText(m.replSetErr)))
+	case m.replSet != nil:
+		status := *m.replSet
+		title += " " + status.Set
+		if primary := status.primary(); primary != nil {
+			lines = append(lines, truncate("Primary: "+primary.Name, contentWidth), "")
+		} else {
+			lines = append(lines, downStyle.Render("No primary: an election may be in progress"), "")
+		}
+
+		stateWidth, healthWidth, lagWidth := 11, 6, 10
+		nameWidth := contentWidth - stateWidth - healthWidth - lagWidth - 3
+		row := func(name, state, health, lag string) string {
+			return fmt.Sprintf("%-*s %-*s %-*s %*s",
+				nameWidth, truncate(name, nameWidth),
+				stateWidth, truncate(state, stateWidth),
+				healthWidth, health,
+				lagWidth, lag)
+		}
+		lines = append(lines, dimStyle.Render(row("MEMBER", "STATE", "HEALTH", "LAG")))
+		for _, member := range status.Members {
+			name := member.Name
+			if member.Self {
+				name += " (connected)"
+			}
+			health := "up"
+			if member.Health < 1 {
+				health = "down"
+			}
+			lagText := "-"
+			lag, replicates := status.lag(member)
+			if replicates {
+				lagText = lag.Round(time.Second).String()
+			}
+			line := row(name, member.State, health, lagText)
+			switch {
+			case member.Health < 1:
+				line = downStyle.Render(line)
+			case replicates && lag > replicationLagWarning:
+				line = lagStyle.Render(line)
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	hint := "r: refresh • esc: close"
+	if m.replSetLoading && m.replSet != nil {
+		hint = "refreshing... • " + hint
+	}
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render(truncate(title, contentWidth)),
+		"",
+		strings.Join(lines, "\n"),
+		"",
+		hintStyle.Render(hint),
+	)
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("205")).
+		Padding(1, 2).
+		Width(width)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(content),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func testReplSetStatus() replSetStatus {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	return replSetStatus{
+		Set: "rs0",
+		Members: []replSetMember{
+			{Name: "db1:27017", State: "PRIMARY", Health: 1, OptimeDate: now, Self: true},
+			{Name: "db2:27017", State: "SECONDARY", Health: 1, OptimeDate: now.Add(-2 * time.Second)},
+			{Name: "db3:27017", State: "SECONDARY", Health: 1, OptimeDate: now.Add(-45 * time.Second)},
+			{Name: "arb:27017", State: "ARBITER", Health: 1},
+		},
+	}
+}
+
+func TestReplSetLag(t *testing.T) {
+	status := testReplSetStatus()
+	for i, want := range []struct {
+		lag        time.Duration
+		replicates bool
+	}{{0, false}, {2 * time.Second, true}, {45 * time.Second, true}, {0, false}} {
+		lag, replicates := status.lag(status.Members[i])
+		if lag != want.lag || replicates != want.replicates {
+			t.Errorf("%s: lag = %v, %v, want %v, %v", status.Members[i].Name, lag, replicates, want.lag, want.replicates)
+		}
+	}
+
+	// Without a primary the lag is measured from the newest member
+	status.Members[0].State = "SECONDARY"
+	if lag, _ := status.lag(status.Members[2]); lag != 45*time.Second {
+		t.Errorf("lag without primary = %v", lag)
+	}
+	if status.primary() != nil {
+		t.Error("found a primary among secondaries")
+	}
+}
+
+func TestReplSetOverlay(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m.focus = FocusDatabases
+
+	m = pressKey(m, "ctrl+x")
+	m = pressKey(m, "r")
+	if !m.replSetActive || !m.replSetLoading || !strings.Contains(normalizeRender(m.View()), "Loading...") {
+		t.Fatalf("active = %v, loading = %v", m.replSetActive, m.replSetLoading)
+	}
+
+	m, _ = update(t, m, replSetStatusMsg{status: testReplSetStatus()})
+	view := normalizeRender(m.View())
+	for _, want := range []string{"Replica set rs0", "Primary: db1:27017", "db1:27017 (connected)", "45s", "2s"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q:\n%s", want, view)
+		}
+	}
+
+	m = pressKey(m, "r")
+	if !m.replSetLoading || m.replSet == nil {
+		t.Errorf("refresh: loading = %v, status kept = %v", m.replSetLoading, m.replSet != nil)
+	}
+	m = pressKey(m, "esc")
+	if m.replSetActive {
+		t.Fatal("esc didn't close the overlay")
+	}
+
+	// A status arriving after the overlay closed is dropped
+	m, _ = update(t, m, replSetStatusMsg{status: testReplSetStatus()})
+	if m.replSetActive {
+		t.Error("late status reopened the overlay")
+	}
+}
+
+func TestReplSetErrorText(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{mongo.CommandError{Code: 76, Name: "NoReplicationEnabled", Message: "not running with --replSet"}, "standalone"},
+		{mongo.CommandError{Code: 13, Name: "Unauthorized", Message: "not authorized on admin"}, "clusterMonitor"},
+		{mongo.CommandError{Code: 59, Name: "CommandNotFound", Message: "no such cmd: replSetGetStatus"}, "mongos"},
+		{errors.New("connection reset"), "Failed to get the replica set status: connection reset"},
+	}
+	for _, tt := range tests {
+		if got := replSetErrorText(tt.err); !strings.Contains(got, tt.want) {
+			t.Errorf("replSetErrorText(%v) = %q, want it to mention %q", tt.err, got, tt.want)
+		}
+	}
+}
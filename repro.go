@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// buildReproBundle assembles a markdown reproduction bundle for the current
+// query: namespace, shell query, explain summary, server version and the first
+// matching document, with sensitive fields masked in the query and document
+func buildReproBundle(client *mongo.Client, dbName, collName string, filter bson.M, sort bson.D) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if filter == nil {
+			filter = bson.M{}
+		}
+		database := client.Database(dbName)
+
+		// Server version
+		version := "(unknown)"
+		var buildInfo bson.M
+		if err := database.RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&buildInfo); err == nil {
+			if v, ok := buildInfo["version"].(string); ok {
+				version = v
+			}
+		}
+
+		// Explain summary
+		explainSummary := "(explain unavailable)"
+		var explain bson.M
+		find := bson.D{
+			{Key: "find", Value: collName},
+			{Key: "filter", Value: filter},
+		}
+		if len(sort) > 0 {
+			find = append(find, bson.E{Key: "sort", Value: sort})
+		}
+		explainCmd := bson.D{
+			{Key: "explain", Value: find},
+			{Key: "verbosity", Value: "executionStats"},
+		}
+		if err := database.RunCommand(ctx, explainCmd).Decode(&explain); err == nil {
+			explainSummary = summarizeExplain(explain)
+		} else {
+			explainSummary = fmt.Sprintf("(explain failed: %v)", err)
+		}
+
+		// First matching document, masked
+		docJSON := "(no matching documents)"
+		var first bson.M
+		findOptions := options.FindOne()
+		if len(sort) > 0 {
+			findOptions.SetSort(sort)
+		}
+		err := database.Collection(collName).FindOne(ctx, filter, findOptions).Decode(&first)
+		if err == nil {
+			jsonBytes, err := bson.MarshalExtJSONIndent(maskSensitiveFields(first), false, false, "", "  ")
+			if err == nil {
+				docJSON = string(jsonBytes)
+			}
+		} else if err != mongo.ErrNoDocuments {
+			return reproBundleMsg{err: err}
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "### mbongo reproduction: %s.%s\n\n", dbName, collName)
+		fmt.Fprintf(&b, "- **Namespace:** `%s.%s`\n", dbName, collName)
+		fmt.Fprintf(&b, "- **Server version:** %s\n", version)
+		fmt.Fprintf(&b, "- **Explain:** %s\n\n", explainSummary)
+		b.WriteString("**Query (mongosh):**\n\n```js\n")
+		b.WriteString(reproQuery(dbName, collName, filter, sort) + "\n")
+		b.WriteString("```\n\n")
+		b.WriteString("**First matching document (sensitive fields masked):**\n\n```json\n")
+		b.WriteString(docJSON)
+		b.WriteString("\n```\n")
+
+		return reproBundleMsg{text: b.String()}
+	}
+}
+
+// reproQuery formats the query as a mongosh statement with the values of
+// sensitive fields in the filter masked. The documents view never projects,
+// so the statement has no projection.
+func reproQuery(dbName, collName string, filter bson.M, sort bson.D) string {
+	query := fmt.Sprintf("db.getSiblingDB(%s).getCollection(%s).find(%s)",
+		shellString(dbName), shellString(collName), shellValue(maskSensitiveFields(filter), ""))
+	if len(sort) > 0 {
+		query += fmt.Sprintf(".sort(%s)", shellValue(sort, ""))
+	}
+	return query
+}
+
+// summarizeExplain condenses explain output into a single line
+func summarizeExplain(explain bson.M) string {
+	var parts []string
+
+	if plan := winningPlan(explain); plan != nil {
+		parts = append(parts, "plan "+summarizePlanStages(plan))
+	}
+
+	if stats, ok := explain["executionStats"].(bson.M); ok {
+		parts = append(parts, fmt.Sprintf("returned %v, keys examined %v, docs examined %v, %vms",
+			stats["nReturned"], stats["totalKeysExamined"], stats["totalDocsExamined"], stats["executionTimeMillis"]))
+	}
+
+	if len(parts) == 0 {
+		return "(no plan information)"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// winningPlan returns the plan explain chose, or nil
+func winningPlan(explain bson.M) bson.M {
+	planner, _ := explain["queryPlanner"].(bson.M)
+	plan, _ := planner["winningPlan"].(bson.M)
+	// Newer servers nest the classic plan under queryPlan
+	if inner, ok := plan["queryPlan"].(bson.M); ok {
+		plan = inner
+	}
+	return plan
+}
+
+// summarizePlanStages renders a plan tree as "FETCH <- IXSCAN status_1"
+func summarizePlanStages(plan bson.M) string {
+	var stages []string
+	for plan != nil {
+		stage, _ := plan["stage"].(string)
+		if name, ok := plan["indexName"].(string); ok {
+			stage += " " + name
+		}
+		stages = append(stages, stage)
+
+		next, _ := plan["inputStage"].(bson.M)
+		if next == nil {
+			if inputs, ok := plan["inputStages"].(bson.A); ok && len(inputs) > 0 {
+				next, _ = inputs[0].(bson.M)
+			}
+		}
+		plan = next
+	}
+	return strings.Join(stages, " <- ")
+}
@@ -0,0 +1,468 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// defaultSchemaSampleSize is the number of documents the schema analyzer samples
+const defaultSchemaSampleSize = 100
+
+// maxSchemaExampleLength caps example values in the schema documentation
+const maxSchemaExampleLength = 40
+
+// schemaField accumulates what was observed for one field across the sample.
+// The analysis root is a schemaField without a name whose fields are the
+// top-level keys.
+type schemaField struct {
+	Name    string
+	Count   int            // Number of parent objects containing the field
+	Types   map[string]int // Observed BSON type names and how often
+	Example interface{}    // First non-null scalar value seen
+	Objects int            // Number of object values seen, the base for Fields' presence
+	Fields  []*schemaField // Subfields of object values, sorted by name
+	Items   *schemaField   // Element schema of array values
+	byName  map[string]*schemaField
+}
+
+// schemaAnalysis is the result of sampling a collection
+type schemaAnalysis struct {
+	Namespace string
+	Root      *schemaField
+	Generated time.Time
+}
+
+// newSchemaField creates an empty field accumulator
+func newSchemaField(name string) *schemaField {
+	return &schemaField{Name: name, Types: map[string]int{}, byName: map[string]*schemaField{}}
+}
+
+// analyzeSchema walks the keys of docs and returns the root of the field tree
+func analyzeSchema(docs []bson.M) *schemaField {
+	root := newSchemaField("")
+	for _, doc := range docs {
+		root.observe(doc)
+	}
+	return root
+}
+
+// observe records one value of the field
+func (f *schemaField) observe(value interface{}) {
+	f.Types[bsonTypeName(value)]++
+	switch v := value.(type) {
+	case bson.M:
+		f.Objects++
+		for key, child := range v {
+			field := f.field(key)
+			field.Count++
+			field.observe(child)
+		}
+	case bson.A:
+		if f.Items == nil {
+			f.Items = newSchemaField("[]")
+		}
+		for _, item := range v {
+			f.Items.Count++
+			f.Items.observe(item)
+		}
+	default:
+		if f.Example == nil && value != nil {
+			f.Example = value
+		}
+	}
+}
+
+// field returns the subfield named key, creating it in sorted position
+func (f *schemaField) field(key string) *schemaField {
+	if field, ok := f.byName[key]; ok {
+		return field
+	}
+	field := newSchemaField(key)
+	f.byName[key] = field
+	i := sort.Search(len(f.Fields), func(i int) bool { return f.Fields[i].Name >= key })
+	f.Fields = append(f.Fields, nil)
+	copy(f.Fields[i+1:], f.Fields[i:])
+	f.Fields[i] = field
+	return field
+}
+
+// sortedTypes returns the observed type names, most frequent first
+func (f *schemaField) sortedTypes() []string {
+	types := make([]string, 0, len(f.Types))
+	for t := range f.Types {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if f.Types[types[i]] != f.Types[types[j]] {
+			return f.Types[types[i]] > f.Types[types[j]]
+		}
+		return types[i] < types[j]
+	})
+	return types
+}
+
+// sampleDocuments returns up to size random documents of a collection. On
+// servers without $sample they are picked client-side from the first
+// documents instead.
+func sampleDocuments(ctx context.Context, client *mongo.Client, dbName, collName string, size int, version serverVersion) ([]bson.M, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if !version.supports(FeatureSample) {
+		return sampleClientSide(ctx, client.Database(dbName).Collection(collName), size)
+	}
+	pipeline := mongo.Pipeline{{{Key: "$sample", Value: bson.M{"size": size}}}}
+	cursor, err := client.Database(dbName).Collection(collName).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []bson.M
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// clientSampleFactor is how many documents per requested sample are read
+// when sampling client-side
+const clientSampleFactor = 10
+
+// sampleClientSide reads the first size*clientSampleFactor documents and
+// keeps a random size of them
+func sampleClientSide(ctx context.Context, coll *mongo.Collection, size int) ([]bson.M, error) {
+	cursor, err := coll.Find(ctx, bson.M{}, options.Find().SetLimit(int64(size*clientSampleFactor)))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	// Reservoir sampling keeps each document read with equal probability
+	var docs []bson.M
+	seen := 0
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		seen++
+		if len(docs) < size {
+			docs = append(docs, doc)
+		} else if i := rand.Intn(seen); i < size {
+			docs[i] = doc
+		}
+	}
+	return docs, cursor.Err()
+}
+
+// runSchemaAnalysis is the job that samples a collection and analyzes its fields
+func runSchemaAnalysis(ctx context.Context, client *mongo.Client, dbName, collName string, size int, version serverVersion) (tea.Msg, error) {
+	docs, err := sampleDocuments(ctx, client, dbName, collName, size, version)
+	if err != nil {
+		return nil, err
+	}
+	return schemaAnalyzedMsg{analysis: &schemaAnalysis{
+		Namespace: namespaceOf(dbName, collName),
+		Root:      analyzeSchema(docs),
+		Generated: time.Now(),
+	}}, nil
+}
+
+// startSchemaMarkdownExport samples the selected collection and shows its
+// schema as Markdown documentation
+func (m *Model) startSchemaMarkdownExport() tea.Cmd {
+	client, dbName, collName, version := m.client, m.selectedDatabase, m.selectedCollection, m.serverVersion
+	return m.startJob("schema of "+namespaceOf(dbName, collName), func(ctx context.Context, report func(string)) (tea.Msg, error) {
+		report(fmt.Sprintf("sampling %d documents", defaultSchemaSampleSize))
+		return runSchemaAnalysis(ctx, client, dbName, collName, defaultSchemaSampleSize, version)
+	})
+}
+
+// handleSchemaAnalyzed opens the Markdown documentation of an analysis in
+// the viewer, from where it can be copied or saved
+func (m *Model) handleSchemaAnalyzed(msg schemaAnalyzedMsg) {
+	_, collName := splitNamespace(msg.analysis.Namespace)
+	m.openViewer(ViewerSchemaMarkdown, "Schema of "+msg.analysis.Namespace, schemaMarkdown(msg.analysis))
+	m.viewerSavePath = collName + "-schema.md"
+}
+
+// saveViewerText writes the viewer text to its save path
+func (m *Model) saveViewerText() tea.Cmd {
+	path := m.viewerSavePath
+	if err := os.WriteFile(path, []byte(m.viewerText), 0644); err != nil {
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Failed to write %s: %v", path, err)
+		return nil
+	}
+	m.closeViewer()
+	return m.setStatus("schema written to " + path)
+}
+
+// schemaMarkdown renders an analysis as Markdown tables: one for the top-level
+// fields, then one section per nested object and per array of objects.
+// Presence is relative to the parent objects, so a nested field present in
+// every address reads 100% even when only half the documents have an address.
+func schemaMarkdown(a *schemaAnalysis) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Schema of %s\n\n", a.Namespace)
+	fmt.Fprintf(&b, "Sampled %d documents • generated %s\n", a.Root.Objects, a.Generated.UTC().Format("2006-01-02 15:04 MST"))
+	writeSchemaSection(&b, "", a.Root, false)
+	return b.String()
+}
+
+// writeSchemaSection writes the table of an object's fields, followed by the
+// sections of its nested objects and arrays of objects
+func writeSchemaSection(b *strings.Builder, path string, obj *schemaField, masked bool) {
+	if path != "" {
+		fmt.Fprintf(b, "\n## %s\n\n", path)
+		fmt.Fprintf(b, "Present in %d sampled values.\n", obj.Objects)
+	}
+	b.WriteString("\n| Field | Types | Presence | Example |\n|---|---|---|---|\n")
+
+	type section struct {
+		path   string
+		obj    *schemaField
+		masked bool
+	}
+	var sections []section
+	for _, field := range obj.Fields {
+		fieldMasked := masked || isSensitiveField(field.Name)
+		fmt.Fprintf(b, "| `%s` | %s | %s | %s |\n",
+			markdownCell(field.Name),
+			markdownCell(schemaTypesLabel(field)),
+			formatPercent(field.Count, obj.Objects),
+			schemaExample(field, fieldMasked),
+		)
+
+		fieldPath := joinSchemaPath(path, field.Name)
+		if field.Objects > 0 {
+			sections = append(sections, section{fieldPath, field, fieldMasked})
+		}
+		if field.Items != nil && field.Items.Objects > 0 {
+			sections = append(sections, section{fieldPath + "[]", field.Items, fieldMasked})
+		}
+	}
+	for _, s := range sections {
+		writeSchemaSection(b, s.path, s.obj, s.masked)
+	}
+}
+
+// joinSchemaPath appends a field name to a dotted section path
+func joinSchemaPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// schemaTypesLabel lists the types of a field with their share when mixed,
+// and the element types of arrays ("array of string")
+func schemaTypesLabel(field *schemaField) string {
+	total := 0
+	for _, count := range field.Types {
+		total += count
+	}
+	var parts []string
+	for _, t := range field.sortedTypes() {
+		label := t
+		if t == "array" && field.Items != nil && len(field.Items.Types) > 0 {
+			label = "array of " + strings.Join(field.Items.sortedTypes(), "/")
+		}
+		if len(field.Types) > 1 {
+			label += " (" + formatPercent(field.Types[t], total) + ")"
+		}
+		parts = append(parts, label)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// schemaExample formats a field's example value as inline code, masking
+// sensitive fields
+func schemaExample(field *schemaField, masked bool) string {
+	example := field.Example
+	if example == nil && field.Items != nil {
+		example = field.Items.Example
+	}
+	if example == nil {
+		return ""
+	}
+	if masked {
+		return "`" + redactedValue + "`"
+	}
+	text := strings.ReplaceAll(rawValueString(example), "\n", " ")
+	text = strings.ReplaceAll(truncate(text, maxSchemaExampleLength), "`", "'")
+	return "`" + markdownCell(text) + "`"
+}
+
+// markdownCell escapes the characters that would break a table cell
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// formatPercent formats count/total as a percentage with at most one decimal
+func formatPercent(count, total int) string {
+	if total == 0 {
+		return "0%"
+	}
+	percent := float64(count) * 100 / float64(total)
+	if percent == float64(int(percent)) {
+		return fmt.Sprintf("%d%%", int(percent))
+	}
+	return fmt.Sprintf("%.1f%%", percent)
+}
+
+// schemaSampleSizeFromEnv returns the sample size for the schema summary from
+// SCHEMA_SAMPLE_SIZE, or the default
+func schemaSampleSizeFromEnv() int {
+	if size, err := strconv.Atoi(os.Getenv("SCHEMA_SAMPLE_SIZE")); err == nil && size > 0 {
+		return size
+	}
+	return defaultSchemaSampleSize
+}
+
+// loadSchemaSummary samples a collection for the schema summary view
+func loadSchemaSummary(client *mongo.Client, dbName, collName string, size int, version serverVersion) tea.Cmd {
+	return func() tea.Msg {
+		msg, err := runSchemaAnalysis(context.Background(), client, dbName, collName, size, version)
+		if err != nil {
+			return schemaAnalyzedMsg{summary: true, err: err}
+		}
+		analyzed := msg.(schemaAnalyzedMsg)
+		analyzed.summary = true
+		return analyzed
+	}
+}
+
+// openSchemaSummary selects the collection under the cursor and samples it
+// for the schema summary view
+func (m *Model) openSchemaSummary() tea.Cmd {
+	if len(m.collFiltered) == 0 || m.client == nil || m.selectedDatabase == "" {
+		return nil
+	}
+	m.selectedCollection = m.collFiltered[m.collCursor]
+	m.loadCollectionSettings()
+	m.pinboardActive = false
+	m.loadingDocs = true
+	m.docScrollOffset = 0
+	m.docCursor = 0
+	m.currentPage = 0
+	m.queryFilter = bson.M{}
+	m.queryText = "{}"
+	m.queryCursor = 1
+	m.focus = FocusDocuments
+	return loadSchemaSummary(m.client, m.selectedDatabase, m.selectedCollection, m.schemaSampleSize, m.serverVersion)
+}
+
+// showSchemaSummary replaces the documents panel contents with the field
+// tree of an analysis
+func (m *Model) showSchemaSummary(analysis *schemaAnalysis) {
+	m.schemaActive = true
+	m.schemaSampled = analysis.Root.Objects
+	m.documents = nil
+	m.fieldOccurrences = nil
+	m.totalDocs = 0
+	m.docFullscreen = false
+	root := buildSchemaTree(analysis.Root, 0)
+	root.Key = analysis.Namespace
+	root.Collapsed = false
+	m.docTree = []*JSONNode{root}
+	m.rebuildFlattenedTree()
+	m.docCursor = 0
+	m.docScrollOffset = 0
+}
+
+// closeSchemaSummary leaves the schema summary and reloads the documents
+func (m *Model) closeSchemaSummary() tea.Cmd {
+	m.schemaActive = false
+	m.loadingDocs = true
+	m.docCursor = 0
+	m.docScrollOffset = 0
+	return loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, m.currentPage, m.docsPerPage, m.queryFilter, m.sortSpec())
+}
+
+// buildSchemaTree converts analyzed fields to tree nodes whose Value is the
+// *schemaField they describe. Object fields get their subfields as children,
+// and arrays of objects a "[]" child holding the element schema.
+func buildSchemaTree(field *schemaField, depth int) *JSONNode {
+	node := &JSONNode{
+		Key:       field.Name,
+		Value:     field,
+		Collapsed: depth > 0,
+		Depth:     depth,
+	}
+	for _, child := range field.Fields {
+		node.Children = append(node.Children, buildSchemaTree(child, depth+1))
+	}
+	if field.Items != nil && field.Items.Objects > 0 {
+		node.Children = append(node.Children, buildSchemaTree(field.Items, depth+1))
+	}
+	for _, child := range node.Children {
+		child.Parent = node
+	}
+	node.IsObject = len(node.Children) > 0
+	return node
+}
+
+// schemaNodeLine renders a schema tree node: the field name, its presence
+// among the parent values and the observed types
+func schemaNodeLine(node *JSONNode, field *schemaField) string {
+	caret := "  "
+	if node.IsObject {
+		caret = caretStyle.Render("▼") + " "
+		if node.Collapsed {
+			caret = caretStyle.Render("▶") + " "
+		}
+	}
+	if node.Parent == nil {
+		return caret + jsonKeyStyle.Render(node.Key) + " " + paginationStyle.Render(fmt.Sprintf("%d documents sampled", field.Objects))
+	}
+
+	parent, _ := node.Parent.Value.(*schemaField)
+	var presence string
+	if field.Name == "[]" {
+		presence = fmt.Sprintf("%d elements", field.Count)
+	} else if parent != nil {
+		presence = formatPercent(field.Count, parent.Objects)
+	}
+	return caret + jsonKeyStyle.Render(field.Name) + " " + paginationStyle.Render(presence) + " " + typeAnnotationStyle.Render(schemaTypesLabel(field))
+}
+
+// schemaFieldPath returns the dotted path of a schema node. Array element
+// levels are skipped since dot-notation reaches into arrays implicitly.
+func schemaFieldPath(node *JSONNode) string {
+	var segments []string
+	for n := node; n != nil && n.Parent != nil; n = n.Parent {
+		if n.Key != "[]" {
+			segments = append([]string{n.Key}, segments...)
+		}
+	}
+	return strings.Join(segments, ".")
+}
+
+// seedExistsQuery puts a {field: {$exists: true}} filter for the schema field
+// under the cursor into the query panel
+func (m *Model) seedExistsQuery() {
+	node := m.nodeAtCursor()
+	if node == nil {
+		return
+	}
+	path := schemaFieldPath(node)
+	if path == "" {
+		return
+	}
+	m.queryText = fmt.Sprintf(`{%q: {"$exists": true}}`, path)
+	m.queryCursor = len(m.queryText)
+	m.focus = FocusQuery
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func testSchemaDocuments() []bson.M {
+	return []bson.M{
+		{
+			"_id":      int32(1),
+			"name":     "Ada",
+			"password": "hunter2",
+			"address":  bson.M{"city": "London", "zip": "N1"},
+			"items":    bson.A{bson.M{"sku": "A-1", "qty": int32(2)}, bson.M{"sku": "B|2"}},
+			"tags":     bson.A{"vip"},
+		},
+		{
+			"_id":     int32(2),
+			"name":    nil,
+			"address": bson.M{"city": "Paris"},
+			"items":   bson.A{},
+			"score":   int64(10),
+		},
+		{
+			"_id":   int32(3),
+			"name":  "Grace",
+			"score": 9.5,
+		},
+	}
+}
+
+func TestAnalyzeSchema(t *testing.T) {
+	root := analyzeSchema(testSchemaDocuments())
+	if root.Objects != 3 {
+		t.Fatalf("root.Objects = %d, want 3", root.Objects)
+	}
+
+	fields := map[string]*schemaField{}
+	for _, f := range root.Fields {
+		fields[f.Name] = f
+	}
+	if got := fields["address"]; got.Count != 2 || got.Objects != 2 || got.Fields[0].Name != "city" || got.Fields[0].Count != 2 {
+		t.Errorf("address = %+v, want present twice with city in both", got)
+	}
+	if got := fields["score"].Types; got["int64"] != 1 || got["double"] != 1 {
+		t.Errorf("score types = %v, want one int64 and one double", got)
+	}
+	if items := fields["items"].Items; items == nil || items.Count != 2 || items.Objects != 2 {
+		t.Errorf("items element schema = %+v, want two object elements", items)
+	}
+}
+
+func TestGoldenSchemaMarkdown(t *testing.T) {
+	analysis := &schemaAnalysis{
+		Namespace: "shop.customers",
+		Root:      analyzeSchema(testSchemaDocuments()),
+		Generated: time.Date(2024, 6, 1, 12, 30, 0, 0, time.UTC),
+	}
+	assertGolden(t, "schema_markdown", schemaMarkdown(analysis))
+}
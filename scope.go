@@ -0,0 +1,179 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// BulkScope is which documents a bulk command acts on. The page is only
+// what is loaded, at most docsPerPage documents, so every bulk command says
+// which scope it runs on rather than leaving it to the page size.
+type BulkScope int
+
+const (
+	ScopeDocument BulkScope = iota // The document under the cursor
+	ScopePage                      // The loaded page
+	ScopePinned                    // The pinned documents of the collection
+	ScopeFilter                    // Every document matching the query
+)
+
+// label names the scope in choices and the snapshot header
+func (s BulkScope) label() string {
+	switch s {
+	case ScopePage:
+		return "current page"
+	case ScopePinned:
+		return "pinned documents"
+	case ScopeFilter:
+		return "all matching documents"
+	default:
+		return "current document"
+	}
+}
+
+// describe states the scope with the number of documents it holds, e.g.
+// "10 documents on this page" or "all 4,812 documents matching the filter"
+func (s BulkScope) describe(count int64) string {
+	switch s {
+	case ScopePage:
+		return fmt.Sprintf("%s on this page", pluralDocuments(count))
+	case ScopePinned:
+		return fmt.Sprintf("%s pinned %s", formatCount(count), pluralize(count, "document", "documents"))
+	case ScopeFilter:
+		if count == 1 {
+			return "the 1 document matching the filter"
+		}
+		return fmt.Sprintf("all %s matching the filter", pluralDocuments(count))
+	default:
+		return "the document under the cursor"
+	}
+}
+
+// formatCount formats n with thousands separators
+func formatCount(n int64) string {
+	digits := strconv.FormatInt(n, 10)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	var b strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	return sign + b.String()
+}
+
+// pluralize returns singular for a count of one, plural otherwise
+func pluralize(n int64, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
+
+// pluralDocuments formats a number of documents, e.g. "4,812 documents"
+func pluralDocuments(n int64) string {
+	return formatCount(n) + " " + pluralize(n, "document", "documents")
+}
+
+// bulkTarget is the documents a bulk command runs on, resolved from its
+// scope when it is confirmed. Commands read from docs when it is set and
+// query filter otherwise, so count is what they act on.
+type bulkTarget struct {
+	scope  BulkScope
+	count  int64    // Documents in the scope when it was resolved
+	docs   []bson.M // Loaded documents of the document and page scopes
+	filter bson.M   // Query selecting the scope's documents, for every scope
+}
+
+// describe states the target's scope and size
+func (t bulkTarget) describe() string {
+	return t.scope.describe(t.count)
+}
+
+// idsFilter returns the filter selecting documents by _id
+func idsFilter(docs []bson.M) bson.M {
+	ids := bson.A{}
+	for _, doc := range docs {
+		ids = append(ids, doc["_id"])
+	}
+	return bson.M{"_id": bson.M{"$in": ids}}
+}
+
+// bulkTarget resolves a scope to the documents it holds right now
+func (m Model) bulkTarget(scope BulkScope) (bulkTarget, error) {
+	target := bulkTarget{scope: scope}
+	switch scope {
+	case ScopeDocument:
+		docIndex := m.getDocumentIndexAtCursor()
+		if docIndex < 0 || docIndex >= len(m.documents) {
+			return target, errors.New("no document under the cursor")
+		}
+		target.docs = []bson.M{m.documents[docIndex]}
+		target.filter = bson.M{"_id": m.documents[docIndex]["_id"]}
+	case ScopePage:
+		if len(m.documents) == 0 {
+			return target, errors.New("no documents on this page")
+		}
+		target.docs = m.documents
+		target.filter = idsFilter(m.documents)
+	case ScopePinned:
+		if len(m.pinnedIDs()) == 0 {
+			return target, errors.New("no pinned documents in this collection")
+		}
+		target.filter = m.pinboardFilter()
+		target.count = int64(len(m.pinnedIDs()))
+	case ScopeFilter:
+		target.filter = m.queryFilter
+		if target.filter == nil {
+			target.filter = bson.M{}
+		}
+		target.count = m.totalDocs
+	}
+	if target.docs != nil {
+		target.count = int64(len(target.docs))
+	}
+	return target, nil
+}
+
+// scopeCount returns how many documents a scope holds, 0 when it can't be
+// used right now
+func (m Model) scopeCount(scope BulkScope) int64 {
+	target, err := m.bulkTarget(scope)
+	if err != nil {
+		return 0
+	}
+	return target.count
+}
+
+// cycleScope returns the scope after (step 1) or before (step -1) current
+// among scopes
+func cycleScope(scopes []BulkScope, current BulkScope, step int) BulkScope {
+	for i, scope := range scopes {
+		if scope == current {
+			return scopes[(i+step+len(scopes))%len(scopes)]
+		}
+	}
+	return scopes[0]
+}
+
+// renderScopeChoice renders the scopes a prompt offers with their counts,
+// one per line, marking the chosen one
+func (m Model) renderScopeChoice(scopes []BulkScope, current BulkScope, width int) string {
+	choices := make([]string, len(scopes))
+	for i, scope := range scopes {
+		mark := "( )"
+		if scope == current {
+			mark = "(•)"
+		}
+		choices[i] = truncate(fmt.Sprintf("%s %s (%s)", mark, scope.label(), formatCount(m.scopeCount(scope))), width)
+	}
+	return strings.Join(choices, "\n")
+}
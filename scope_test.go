@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestFormatCount(t *testing.T) {
+	for n, want := range map[int64]string{0: "0", 7: "7", 999: "999", 1000: "1,000", 4812: "4,812", 1234567: "1,234,567", -4812: "-4,812"} {
+		if got := formatCount(n); got != want {
+			t.Errorf("formatCount(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestScopeDescriptions(t *testing.T) {
+	tests := []struct {
+		scope BulkScope
+		count int64
+		want  string
+	}{
+		{ScopeDocument, 1, "the document under the cursor"},
+		{ScopePage, 10, "10 documents on this page"},
+		{ScopePage, 1, "1 document on this page"},
+		{ScopePinned, 3, "3 pinned documents"},
+		{ScopeFilter, 4812, "all 4,812 documents matching the filter"},
+		{ScopeFilter, 1, "the 1 document matching the filter"},
+	}
+	for _, tt := range tests {
+		if got := tt.scope.describe(tt.count); got != tt.want {
+			t.Errorf("%v.describe(%d) = %q, want %q", tt.scope, tt.count, got, tt.want)
+		}
+	}
+}
+
+// inIDs returns the _ids of an {_id: {$in: [...]}} filter
+func inIDs(t *testing.T, filter bson.M) bson.A {
+	t.Helper()
+	in, ok := filter["_id"].(bson.M)["$in"].(bson.A)
+	if !ok {
+		t.Fatalf("filter %v doesn't select by _id", filter)
+	}
+	return in
+}
+
+func TestBulkTargetCountsMatchWhatRuns(t *testing.T) {
+	m := newTestModel(120, 30)
+
+	page, err := m.bulkTarget(ScopePage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.count != int64(len(m.documents)) || len(page.docs) != len(m.documents) || len(inIDs(t, page.filter)) != len(m.documents) {
+		t.Errorf("page: count %d, %d docs, %d ids, want %d", page.count, len(page.docs), len(inIDs(t, page.filter)), len(m.documents))
+	}
+
+	if _, err := m.bulkTarget(ScopePinned); err == nil {
+		t.Error("pinned scope resolved without pins")
+	}
+	m.pins[m.currentNamespace()] = []interface{}{m.documents[0]["_id"], m.documents[1]["_id"]}
+	pinned, err := m.bulkTarget(ScopePinned)
+	if err != nil || pinned.count != 2 || pinned.docs != nil || len(inIDs(t, pinned.filter)) != 2 {
+		t.Errorf("pinned: %+v, %v", pinned, err)
+	}
+
+	m.queryFilter = bson.M{"active": true}
+	all, _ := m.bulkTarget(ScopeFilter)
+	if all.count != m.totalDocs || all.docs != nil || all.filter["active"] != true {
+		t.Errorf("filter: %+v", all)
+	}
+	if got := all.describe(); got != "all 42 documents matching the filter" {
+		t.Errorf("describe = %q", got)
+	}
+
+	one, _ := m.bulkTarget(ScopeDocument)
+	if one.count != 1 || one.filter["_id"] != m.documents[0]["_id"] {
+		t.Errorf("document: %+v", one)
+	}
+}
+
+func TestExportWritesTheCountItShows(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m.openExportPrompt()
+	if m.exportScope != ScopeFilter {
+		t.Fatalf("default scope = %v, want all matching documents", m.exportScope)
+	}
+	m, _ = update(t, m, tea.KeyMsg{Type: tea.KeyShiftTab})
+	m, _ = update(t, m, tea.KeyMsg{Type: tea.KeyShiftTab})
+	if m.exportScope != ScopePage {
+		t.Fatalf("scope = %v after shift+tab twice, want the page", m.exportScope)
+	}
+	view := normalizeRender(m.View())
+	if !strings.Contains(view, "(•) current page (2)") || !strings.Contains(view, "( ) all matching documents (42)") {
+		t.Errorf("prompt view:\n%s", view)
+	}
+
+	path := filepath.Join(t.TempDir(), "orders.ndjson")
+	m.exportPathInput.SetValue(path)
+	m, _ = update(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	job := m.jobs[len(m.jobs)-1]
+	if job.Scope != "2 documents on this page" {
+		t.Errorf("job scope = %q", job.Scope)
+	}
+
+	target, _ := m.bulkTarget(ScopePage)
+	msg, err := runExport(context.Background(), func(string) {}, nil, "shop", "orders", target.filter, target.docs, path)
+	if done, ok := msg.(exportDoneMsg); err != nil || !ok || int64(done.count) != target.count {
+		t.Errorf("export wrote %+v, %v, want %d documents", msg, err, target.count)
+	}
+}
+
+func TestBulkUpdateConfirmSwitchesScope(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m.queryFilter = bson.M{}
+	m.pendingUpdate = &bulkUpdate{filter: bson.M{}, update: bson.M{"$set": bson.M{"seen": true}}, updateText: "{$set: {seen: true}}"}
+	m.confirmBulkUpdate()
+	c := m.confirm
+	if c.target.scope != ScopeFilter || !strings.Contains(normalizeRender(m.View()), "Scope: all 42 documents matching the filter") {
+		t.Fatalf("initial scope %v:\n%s", c.target.scope, normalizeRender(m.View()))
+	}
+
+	// The pinned scope is skipped while nothing is pinned
+	m, _ = update(t, m, tea.KeyMsg{Type: tea.KeyTab})
+	if c.target.scope != ScopePage || c.target.count != 2 || len(inIDs(t, c.target.filter)) != 2 {
+		t.Fatalf("after tab: %+v", c.target)
+	}
+	if !strings.Contains(normalizeRender(m.View()), "Scope: 2 documents on this page") {
+		t.Errorf("view:\n%s", normalizeRender(m.View()))
+	}
+
+	// A count fetched for the previous scope is dropped
+	m.handleConfirmCount(confirmCountMsg{confirm: c, scope: ScopeFilter, count: 42})
+	if !c.counting {
+		t.Error("stale count applied")
+	}
+	m.handleConfirmCount(confirmCountMsg{confirm: c, scope: ScopePage, count: 2})
+	if c.counting || c.matchCount != 2 {
+		t.Errorf("count = %d, counting %v", c.matchCount, c.counting)
+	}
+
+	m, _ = update(t, m, tea.KeyMsg{Type: tea.KeyShiftTab})
+	if c.target.scope != ScopeFilter {
+		t.Errorf("after shift+tab: %v", c.target.scope)
+	}
+
+	// The update runs as a job on the confirmed scope, which can be cancelled
+	m, _ = update(t, m, tea.KeyMsg{Type: tea.KeyTab})
+	m, _ = update(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.confirm != nil || m.pendingUpdate != nil || len(m.jobs) != 1 {
+		t.Fatalf("confirm = %+v, %d jobs", m.confirm, len(m.jobs))
+	}
+	job := m.jobs[0]
+	if job.Name != "update shop.orders" || job.Scope != "2 documents on this page" {
+		t.Errorf("job %q on %q", job.Name, job.Scope)
+	}
+	m.cancelJob(job)
+	m.handleJobFinished(jobFinishedMsg{id: job.ID, err: context.Canceled, cancelled: true})
+	if job.State != JobCancelled || m.errorModal {
+		t.Errorf("job state = %v after cancelling, error %q", job.State, m.errorMessage)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+// clampIndex clamps i to a valid index into a list of n items (0 when empty)
+func clampIndex(i, n int) int {
+	if i >= n {
+		i = n - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	return i
+}
+
+// clampScroll clamps a scroll offset so a window of visible lines stays
+// within total lines
+func clampScroll(offset, total, visible int) int {
+	return clampIndex(offset, total-visible+1)
+}
+
+// pageKeyTarget returns where PgUp/PgDn/Home/End move a cursor or scroll
+// offset in a list of n positions, with page being the visible height.
+// ok is false for any other key.
+func pageKeyTarget(key string, current, page, n int) (target int, ok bool) {
+	if page < 1 {
+		page = 1
+	}
+	switch key {
+	case "pgup":
+		return clampIndex(current-page, n), true
+	case "pgdown":
+		return clampIndex(current+page, n), true
+	case "home":
+		return 0, true
+	case "end":
+		return clampIndex(n-1, n), true
+	}
+	return current, false
+}
+
+// getListHeight returns the number of visible rows in the Databases and
+// Collections lists
+func (m Model) getListHeight() int {
+	availableHeight := m.height - 1
+	if availableHeight < 10 {
+		availableHeight = 10
+	}
+	innerHeight := availableHeight/2 - 2
+	if innerHeight < 3 {
+		innerHeight = 3
+	}
+	// Header and blank line (2) + list padding (1)
+	return innerHeight - 3
+}
+
+// getConnectionsListHeight returns the number of connections that fit on the
+// connections screen around the title and help text
+func (m Model) getConnectionsListHeight() int {
+	height := m.height - 8
+	if height < 1 {
+		height = 1
+	}
+	return height
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SearchMode is how the document search input is matched against nodes
+type SearchMode int
+
+const (
+	SearchPlain     SearchMode = iota // Case-insensitive substring
+	SearchRegex                       // Go regular expression
+	SearchWholeWord                   // Case-insensitive substring between word boundaries
+)
+
+func (s SearchMode) String() string {
+	switch s {
+	case SearchRegex:
+		return "regex"
+	case SearchWholeWord:
+		return "word"
+	default:
+		return "plain"
+	}
+}
+
+// next returns the mode ctrl+t cycles to
+func (s SearchMode) next() SearchMode {
+	return (s + 1) % 3
+}
+
+// isWordRune reports whether r is part of a word for whole-word matching
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// findWholeWords returns the ranges of findSubstrings that aren't preceded
+// or followed by a word character
+func findWholeWords(text, query string) [][2]int {
+	var words [][2]int
+	for _, r := range findSubstrings(text, query) {
+		before, _ := utf8.DecodeLastRuneInString(text[:r[0]])
+		after, _ := utf8.DecodeRuneInString(text[r[1]:])
+		if (r[0] == 0 || !isWordRune(before)) && (r[1] == len(text) || !isWordRune(after)) {
+			words = append(words, r)
+		}
+	}
+	return words
+}
+
+// compileDocSearch prepares the search input for the active mode, keeping
+// the regex compile error to show in the search bar
+func (m *Model) compileDocSearch() {
+	m.docSearchRegex = nil
+	m.docSearchErr = ""
+	if m.docSearchMode != SearchRegex || m.docSearchInput.Value() == "" {
+		return
+	}
+	re, err := regexp.Compile(m.docSearchInput.Value())
+	if err != nil {
+		m.docSearchErr = err.Error()
+		return
+	}
+	m.docSearchRegex = re
+}
+
+// docSearchRanges returns the byte ranges of text matched by the search
+// input in the active mode
+func (m Model) docSearchRanges(text string) [][2]int {
+	query := m.docSearchInput.Value()
+	switch m.docSearchMode {
+	case SearchRegex:
+		if m.docSearchRegex == nil {
+			return nil
+		}
+		var ranges [][2]int
+		for _, r := range m.docSearchRegex.FindAllStringIndex(text, -1) {
+			ranges = append(ranges, [2]int{r[0], r[1]})
+		}
+		return ranges
+	case SearchWholeWord:
+		return findWholeWords(text, query)
+	default:
+		return findSubstrings(text, query)
+	}
+}
+
+// docSearchMatchesText reports whether text matches the search input in the
+// active mode
+func (m Model) docSearchMatchesText(text string) bool {
+	if m.docSearchMode == SearchPlain {
+		// Unlike findSubstrings, this also matches text whose case folding
+		// changes its length
+		return strings.Contains(strings.ToLower(text), strings.ToLower(m.docSearchInput.Value()))
+	}
+	if m.docSearchMode == SearchRegex && m.docSearchRegex != nil {
+		return m.docSearchRegex.MatchString(text)
+	}
+	return len(m.docSearchRanges(text)) > 0
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFindWholeWords(t *testing.T) {
+	got := findWholeWords("cat catalog cat_x (Cat)", "cat")
+	want := [][2]int{{0, 3}, {19, 22}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("findWholeWords = %v, want %v", got, want)
+	}
+}
+
+func TestDocSearchModes(t *testing.T) {
+	m := newTestModel(120, 30)
+	for _, root := range m.docTree {
+		root.Collapsed = false
+	}
+	m.rebuildFlattenedTree()
+	m.docSearchActive = true
+
+	search := func(mode SearchMode, query string) int {
+		m.docSearchMode = mode
+		m.docSearchInput.SetValue(query)
+		m.updateDocSearchMatches()
+		return len(m.docSearchMatches)
+	}
+	if n := search(SearchPlain, "love"); n != 1 {
+		t.Errorf("plain \"love\" matched %d lines, want 1", n)
+	}
+	if n := search(SearchWholeWord, "love"); n != 0 {
+		t.Errorf("whole-word \"love\" matched %d lines, want 0", n)
+	}
+	if n := search(SearchWholeWord, "lovelace"); n != 1 {
+		t.Errorf("whole-word \"lovelace\" matched %d lines, want 1", n)
+	}
+	if n := search(SearchRegex, `^age \d+$`); n != 2 {
+		t.Errorf("regex matched %d lines, want 2", n)
+	}
+
+	search(SearchRegex, "(unclosed")
+	bar := m.renderDocSearchBar(100)
+	if len(m.docSearchMatches) != 0 || !strings.Contains(bar, "missing closing )") {
+		t.Errorf("invalid regex not reported in the search bar: %q", bar)
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	m = updated.(Model)
+	if m.docSearchMode != SearchWholeWord || m.docSearchErr != "" {
+		t.Errorf("ctrl+t left mode %v with error %q", m.docSearchMode, m.docSearchErr)
+	}
+}
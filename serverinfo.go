@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// serverInfo is what the server info panel shows, from buildInfo,
+// serverStatus and hello. Each command may fail on its own: serverStatus
+// needs clusterMonitor, which many users don't have.
+type serverInfo struct {
+	build     bson.M
+	buildErr  error
+	status    bson.M
+	statusErr error
+	hello     bson.M
+	helloErr  error
+}
+
+// serverInfoMsg carries the server info panel's contents
+type serverInfoMsg struct {
+	info serverInfo
+}
+
+// loadServerInfo runs the commands the server info panel reads
+func loadServerInfo(client *mongo.Client) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		admin := client.Database("admin")
+		run := func(name string) (bson.M, error) {
+			var doc bson.M
+			err := admin.RunCommand(ctx, bson.D{{Key: name, Value: 1}}).Decode(&doc)
+			return doc, err
+		}
+		var info serverInfo
+		info.build, info.buildErr = run("buildInfo")
+		info.status, info.statusErr = run("serverStatus")
+		info.hello, info.helloErr = run("hello")
+		if info.helloErr != nil {
+			// Servers before 4.4.2 only know the legacy name
+			info.hello, info.helloErr = run("isMaster")
+		}
+		return serverInfoMsg{info: info}
+	}
+}
+
+// openServerInfo shows the server info panel, filled in once the commands return
+func (m *Model) openServerInfo() tea.Cmd {
+	if m.client == nil {
+		return nil
+	}
+	m.openViewer(ViewerServerInfo, "Server "+m.targetLabel(), "Loading...")
+	return loadServerInfo(m.client)
+}
+
+// handleServerInfo fills in the server info panel, unless it was closed
+// meanwhile. A refresh keeps the scroll position.
+func (m *Model) handleServerInfo(msg serverInfoMsg) {
+	if m.viewerKind != ViewerServerInfo {
+		return
+	}
+	scroll := m.viewerScroll
+	m.openViewer(ViewerServerInfo, m.viewerTitle, serverInfoText(msg.info))
+	m.scrollViewer(scroll)
+}
+
+// serverInfoText renders the interesting fields of a serverInfo
+func serverInfoText(info serverInfo) string {
+	var b strings.Builder
+	row := func(label, value string) {
+		fmt.Fprintf(&b, "%-13s %s\n", label+":", value)
+	}
+
+	if info.buildErr != nil {
+		row("Version", unavailable(info.buildErr))
+	} else {
+		version := fmt.Sprint(info.build["version"])
+		if modules, ok := info.build["modules"].(bson.A); ok && len(modules) > 0 {
+			version += fmt.Sprintf(" %v", modules)
+		}
+		row("Version", version)
+	}
+	if info.helloErr != nil {
+		row("Role", unavailable(info.helloErr))
+	} else {
+		row("Role", serverRole(info.hello))
+	}
+
+	status := func(path string) interface{} {
+		value, _ := lookupPath(info.status, path)
+		return value
+	}
+	if info.statusErr != nil {
+		for _, label := range []string{"Uptime", "Connections", "Opcounters", "Memory"} {
+			row(label, unavailable(info.statusErr))
+		}
+		return b.String()
+	}
+	if seconds, ok := wholeNumber(status("uptime")); ok {
+		row("Uptime", formatUptime(time.Duration(seconds)*time.Second))
+	}
+	current, _ := wholeNumber(status("connections.current"))
+	available, _ := wholeNumber(status("connections.available"))
+	row("Connections", fmt.Sprintf("%s current, %s available", formatCount(current), formatCount(available)))
+	var counters []string
+	for _, op := range []string{"insert", "query", "update", "delete", "getmore", "command"} {
+		n, _ := wholeNumber(status("opcounters." + op))
+		counters = append(counters, fmt.Sprintf("%s %s", op, formatCount(n)))
+	}
+	row("Opcounters", strings.Join(counters, " • "))
+	resident, _ := wholeNumber(status("mem.resident"))
+	virtual, _ := wholeNumber(status("mem.virtual"))
+	row("Memory", fmt.Sprintf("%s resident, %s virtual", formatBytes(int(resident)<<20), formatBytes(int(virtual)<<20)))
+	if engine, ok := status("storageEngine.name").(string); ok {
+		row("Storage", engine)
+	}
+	return b.String()
+}
+
+// serverRole tells a mongos, the members of a replica set and a standalone
+// server apart from a hello reply
+func serverRole(hello bson.M) string {
+	if hello["msg"] == "isdbgrid" {
+		return "mongos (sharded cluster router)"
+	}
+	setName, ok := hello["setName"].(string)
+	if !ok {
+		return "standalone"
+	}
+	switch {
+	case hello["isWritablePrimary"] == true || hello["ismaster"] == true:
+		return "primary of replica set " + setName
+	case hello["secondary"] == true:
+		return "secondary of replica set " + setName
+	case hello["arbiterOnly"] == true:
+		return "arbiter of replica set " + setName
+	}
+	return "member of replica set " + setName
+}
+
+// unavailable says why a field couldn't be read, "(no permission)" when the
+// user lacks the role for the command
+func unavailable(err error) string {
+	if isUnauthorizedError(err) {
+		return "(no permission)"
+	}
+	return fmt.Sprintf("(unavailable: %v)", err)
+}
+
+// formatUptime formats a duration in days, hours and minutes, e.g. "3d 4h 12m"
+func formatUptime(d time.Duration) string {
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	}
+	if hours > 0 {
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
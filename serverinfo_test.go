@@ -0,0 +1,76 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestServerInfoText(t *testing.T) {
+	info := serverInfo{
+		build: bson.M{"version": "7.0.2"},
+		hello: bson.M{"setName": "rs0", "isWritablePrimary": true},
+		status: bson.M{
+			"uptime":      float64(273600),
+			"connections": bson.M{"current": int32(12), "available": int32(838848)},
+			"opcounters":  bson.M{"insert": int64(1234), "query": int64(56), "update": int64(7), "delete": int64(0), "getmore": int64(3), "command": int64(98765)},
+			"mem":         bson.M{"resident": int32(1536), "virtual": int32(3072)},
+		},
+	}
+	text := serverInfoText(info)
+	for _, want := range []string{"7.0.2", "primary of replica set rs0", "3d 4h 0m", "12 current, 838,848 available", "insert 1,234 • query 56", "command 98,765", "1536.0 MB resident"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("info lacks %q:\n%s", want, text)
+		}
+	}
+
+	// Without clusterMonitor only serverStatus is missing
+	info.status = nil
+	info.statusErr = mongo.CommandError{Code: unauthorizedErrorCode, Message: "not authorized on admin to execute command { serverStatus: 1 }"}
+	text = serverInfoText(info)
+	if !strings.Contains(text, "Uptime:       (no permission)") || !strings.Contains(text, "7.0.2") {
+		t.Errorf("denied serverStatus:\n%s", text)
+	}
+}
+
+func TestServerRole(t *testing.T) {
+	for _, tt := range []struct {
+		hello bson.M
+		want  string
+	}{
+		{bson.M{"msg": "isdbgrid"}, "mongos (sharded cluster router)"},
+		{bson.M{"ismaster": true}, "standalone"},
+		{bson.M{"setName": "rs0", "secondary": true}, "secondary of replica set rs0"},
+		{bson.M{"setName": "rs0", "arbiterOnly": true}, "arbiter of replica set rs0"},
+	} {
+		if got := serverRole(tt.hello); got != tt.want {
+			t.Errorf("serverRole(%v) = %q, want %q", tt.hello, got, tt.want)
+		}
+	}
+	if got := formatUptime(90 * time.Minute); got != "1h 30m" {
+		t.Errorf("formatUptime = %q", got)
+	}
+}
+
+func TestServerInfoFromAnyPanel(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	m.focus = FocusDatabases
+
+	m = pressKey(m, "ctrl+x")
+	m = pressKey(m, "i")
+	if m.viewerKind != ViewerServerInfo || m.viewerText != "Loading..." {
+		t.Fatalf("viewer %v %q", m.viewerKind, m.viewerText)
+	}
+	m, _ = update(t, m, serverInfoMsg{info: serverInfo{build: bson.M{"version": "6.0.4"}, hello: bson.M{}}})
+	if !strings.Contains(m.viewerText, "6.0.4") {
+		t.Errorf("viewer %q", m.viewerText)
+	}
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")}); cmd == nil {
+		t.Error("r didn't refresh")
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// savedQuery is the filter and page a search across all pages replaced,
+// restored with esc
+type savedQuery struct {
+	filter bson.M
+	text   string
+	page   int
+}
+
+// stringFieldPaths returns the dotted paths of the fields seen holding
+// strings, including strings inside arrays. Fields whose names can't be
+// used in a query path are skipped.
+func stringFieldPaths(field *schemaField, prefix string) []string {
+	var paths []string
+	for _, child := range field.Fields {
+		if strings.Contains(child.Name, ".") || strings.HasPrefix(child.Name, "$") {
+			continue
+		}
+		path := child.Name
+		if prefix != "" {
+			path = prefix + "." + child.Name
+		}
+		paths = append(paths, fieldValuePaths(child, path)...)
+	}
+	return paths
+}
+
+// fieldValuePaths returns path when field holds strings, directly or as
+// array elements, followed by the string paths below it
+func fieldValuePaths(field *schemaField, path string) []string {
+	var paths []string
+	if field.Types["string"] > 0 || (field.Items != nil && field.Items.Types["string"] > 0) {
+		paths = append(paths, path)
+	}
+	paths = append(paths, stringFieldPaths(field, path)...)
+	if field.Items != nil {
+		paths = append(paths, stringFieldPaths(field.Items, path)...)
+	}
+	return paths
+}
+
+// searchPattern converts search input to a server-side regex and its options
+// for the search mode. Whole words use PCRE lookarounds rather than \b so
+// queries starting or ending with punctuation behave like findWholeWords.
+func searchPattern(mode SearchMode, query string) (pattern, options string) {
+	switch mode {
+	case SearchRegex:
+		return query, ""
+	case SearchWholeWord:
+		return `(?<!\w)` + regexp.QuoteMeta(query) + `(?!\w)`, "i"
+	default:
+		return regexp.QuoteMeta(query), "i"
+	}
+}
+
+// hasTextIndex reports whether the collection has a text index
+func hasTextIndex(ctx context.Context, coll *mongo.Collection) (bool, error) {
+	cursor, err := coll.Indexes().List(ctx)
+	if err != nil {
+		return false, err
+	}
+	var indexes []bson.M
+	if err := cursor.All(ctx, &indexes); err != nil {
+		return false, err
+	}
+	for _, index := range indexes {
+		if key, ok := index["key"].(bson.M); ok {
+			for _, kind := range key {
+				if kind == "text" {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// serverSearchFilter builds the filter matching query anywhere in the
+// collection: $text when a text index exists (except for regex searches),
+// otherwise an $or of regex matches across the string fields of a sample
+func serverSearchFilter(ctx context.Context, client *mongo.Client, dbName, collName string, mode SearchMode, query string, sampleSize int, version serverVersion) (bson.M, error) {
+	coll := client.Database(dbName).Collection(collName)
+	if mode != SearchRegex {
+		text, err := hasTextIndex(ctx, coll)
+		if err != nil {
+			return nil, err
+		}
+		if text {
+			return bson.M{"$text": bson.M{"$search": query}}, nil
+		}
+	}
+
+	docs, err := sampleDocuments(ctx, client, dbName, collName, sampleSize, version)
+	if err != nil {
+		return nil, err
+	}
+	paths := stringFieldPaths(analyzeSchema(docs), "")
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no string fields found in %d sampled documents", len(docs))
+	}
+	pattern, options := searchPattern(mode, query)
+	var or bson.A
+	for _, path := range paths {
+		or = append(or, bson.M{path: bson.M{"$regex": pattern, "$options": options}})
+	}
+	return bson.M{"$or": or}, nil
+}
+
+// runServerSearch builds the filter for a search across all pages
+func runServerSearch(client *mongo.Client, dbName, collName string, mode SearchMode, query string, sampleSize int, version serverVersion) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		filter, err := serverSearchFilter(ctx, client, dbName, collName, mode, query, sampleSize, version)
+		return serverSearchMsg{query: query, filter: filter, err: err}
+	}
+}
+
+// startServerSearch searches the whole collection for the document search
+// input instead of only the loaded page
+func (m *Model) startServerSearch() tea.Cmd {
+	query := m.docSearchInput.Value()
+	if query == "" || m.docSearchErr != "" || m.client == nil || m.selectedCollection == "" || m.schemaActive || m.pinboardActive {
+		return nil
+	}
+	m.queryLoading = true
+	return runServerSearch(m.client, m.selectedDatabase, m.selectedCollection, m.docSearchMode, query, m.schemaSampleSize, m.serverVersion)
+}
+
+// handleServerSearch replaces the results with the documents matching the
+// search filter, remembering the query it replaced
+func (m *Model) handleServerSearch(msg serverSearchMsg) tea.Cmd {
+	if msg.err != nil {
+		m.queryLoading = false
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Search across all pages failed: %v", msg.err)
+		return nil
+	}
+	if m.serverSearchPrev == nil {
+		m.serverSearchPrev = &savedQuery{filter: m.queryFilter, text: m.queryText, page: m.currentPage}
+	}
+	m.serverSearchQuery = msg.query
+	m.docSearchActive = false
+	m.docSearchInput.SetValue("")
+	m.docSearchMatches = []int{}
+	m.docSearchCurrent = -1
+
+	m.queryFilter = msg.filter
+	if text, err := bson.MarshalExtJSON(msg.filter, false, false); err == nil {
+		m.queryText = string(text)
+		m.queryCursor = len(m.queryText)
+	}
+	m.currentPage = 0
+	m.docCursor = 0
+	m.docScrollOffset = 0
+	return tea.Batch(
+		m.setStatus("searching all documents • esc: previous results"),
+		loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, 0, m.docsPerPage, msg.filter, m.sortSpec()),
+	)
+}
+
+// restoreSearchedQuery goes back to the filter and page a search across all
+// pages replaced
+func (m *Model) restoreSearchedQuery() tea.Cmd {
+	prev := m.serverSearchPrev
+	m.serverSearchPrev = nil
+	m.serverSearchQuery = ""
+	m.queryFilter = prev.filter
+	m.queryText = prev.text
+	m.queryCursor = len(m.queryText)
+	m.currentPage = prev.page
+	m.queryLoading = true
+	m.docCursor = 0
+	m.docScrollOffset = 0
+	return loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, prev.page, m.docsPerPage, prev.filter, m.sortSpec())
+}
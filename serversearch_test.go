@@ -0,0 +1,55 @@
+package main
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestStringFieldPaths(t *testing.T) {
+	docs := []bson.M{
+		{"_id": 1, "name": "Ada", "age": 36, "tags": bson.A{"math"}, "address": bson.M{"city": "London", "zip": 1}},
+		{"_id": 2, "items": bson.A{bson.M{"sku": "a", "qty": 2}}, "a.b": "dotted", "$weird": "x"},
+	}
+	got := stringFieldPaths(analyzeSchema(docs), "")
+	want := []string{"address.city", "items.sku", "name", "tags"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("stringFieldPaths = %v, want %v", got, want)
+	}
+}
+
+func TestSearchPattern(t *testing.T) {
+	pattern, options := searchPattern(SearchPlain, "$4.50")
+	if !regexp.MustCompile(pattern).MatchString("price: $4.50 (net)") || options != "i" {
+		t.Errorf("plain pattern %q /%s doesn't match the literal text", pattern, options)
+	}
+	// Lookarounds are PCRE-only, so compare the whole-word pattern as text
+	if pattern, _ := searchPattern(SearchWholeWord, "$4.50"); pattern != `(?<!\w)\$4\.50(?!\w)` {
+		t.Errorf("whole-word pattern = %q", pattern)
+	}
+	if pattern, options := searchPattern(SearchRegex, "^A.a$"); pattern != "^A.a$" || options != "" {
+		t.Errorf("regex pattern = %q /%s, want the input unchanged", pattern, options)
+	}
+}
+
+func TestServerSearchRestoresPreviousQuery(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.queryFilter = bson.M{"status": "paid"}
+	m.queryText = `{"status": "paid"}`
+	m.currentPage = 3
+	m.docSearchActive = true
+	m.docSearchInput.SetValue("ada")
+
+	m.handleServerSearch(serverSearchMsg{query: "ada", filter: bson.M{"$text": bson.M{"$search": "ada"}}})
+	if m.docSearchActive || m.currentPage != 0 || m.queryFilter["$text"] == nil {
+		t.Fatalf("search not applied: active=%v page=%d filter=%v", m.docSearchActive, m.currentPage, m.queryFilter)
+	}
+	m.handleServerSearch(serverSearchMsg{query: "lovelace", filter: bson.M{"$text": bson.M{"$search": "lovelace"}}})
+
+	m.restoreSearchedQuery()
+	if m.queryFilter["status"] != "paid" || m.queryText != `{"status": "paid"}` || m.currentPage != 3 || m.serverSearchPrev != nil {
+		t.Errorf("restored filter=%v text=%q page=%d, want the query from before the first search", m.queryFilter, m.queryText, m.currentPage)
+	}
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// snapshotScopes are the scopes the HTML snapshot prompt offers
+var snapshotScopes = []BulkScope{ScopeDocument, ScopePage, ScopePinned}
+
+// snapshot is what an HTML snapshot shows
+type snapshot struct {
+	Namespace string // connection ▸ database ▸ collection
+	Filter    string // Query text the documents were found with
+	Scope     BulkScope
+	Docs      []bson.M
+	Generated time.Time
+}
+
+// snapshotCSS colors values like the documents panel (jsonKeyStyle and
+// friends in styles.go), translated from the 256-color palette
+const snapshotCSS = `body { background: #1c1c1c; color: #d0d0d0; font: 14px/1.5 ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; margin: 0; padding: 1.5em; }
+header { border: 1px solid #5f5fd7; border-radius: 6px; padding: 0.5em 1em; margin-bottom: 1.5em; }
+h1 { color: #ff5faf; font-size: 1.2em; margin: 0.3em 0; }
+header p { margin: 0.3em 0; }
+.meta { color: #626262; font-style: italic; }
+button { background: #303030; color: #d0d0d0; border: 1px solid #5f5fd7; border-radius: 4px; font: inherit; cursor: pointer; margin: 0.3em 0.5em 0.3em 0; }
+details.doc { border-top: 1px solid #3a3a3a; padding: 0.5em 0; }
+details > summary { cursor: pointer; list-style: none; }
+details > summary::-webkit-details-marker { display: none; }
+details > summary::before { content: "▶ "; color: #808080; }
+details[open] > summary::before { content: "▼ "; }
+details[open] > summary .count { display: none; }
+.children { padding-left: 2ch; }
+.leaf { padding-left: 2ch; white-space: pre-wrap; word-break: break-all; }
+.key { color: #5fd7ff; }
+.index, .count, .null { color: #808080; }
+.string { color: #d7d75f; }
+.number { color: #af87ff; }
+.bool { color: #ff5f5f; }
+.bracket { color: #bcbcbc; }`
+
+// snapshotJS toggles every node at once; the nodes themselves are <details>
+// elements, so the snapshot stays browsable with scripts disabled
+const snapshotJS = `function setAll(open) {
+  document.querySelectorAll("details").forEach(function (d) { d.open = open; });
+}`
+
+// escapeHTML escapes text for element content; the snapshot never puts
+// document text in attributes, so quotes can stay readable
+var escapeHTML = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace
+
+// renderSnapshotHTML renders a snapshot as a standalone HTML page with
+// collapsible nodes. Sensitive fields are masked, and nothing is loaded from
+// outside the file.
+func renderSnapshotHTML(s snapshot) string {
+	var b strings.Builder
+	filter := strings.TrimSpace(s.Filter)
+	if filter == "" {
+		filter = "{}"
+	}
+	count := fmt.Sprintf("%d documents", len(s.Docs))
+	if len(s.Docs) == 1 {
+		count = "1 document"
+	}
+
+	b.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n", escapeHTML(s.Namespace))
+	fmt.Fprintf(&b, "<style>\n%s\n</style>\n<script>\n%s\n</script>\n</head>\n<body>\n", snapshotCSS, snapshotJS)
+	b.WriteString("<header>\n")
+	fmt.Fprintf(&b, "<h1>%s</h1>\n", escapeHTML(s.Namespace))
+	fmt.Fprintf(&b, "<p>Filter: <code class=\"string\">%s</code></p>\n", escapeHTML(filter))
+	fmt.Fprintf(&b, "<p class=\"meta\">%s • %s • sensitive fields masked • exported %s</p>\n",
+		count, s.Scope.label(), s.Generated.UTC().Format("2006-01-02 15:04 MST"))
+	b.WriteString("<button onclick=\"setAll(true)\">Expand all</button><button onclick=\"setAll(false)\">Collapse all</button>\n")
+	b.WriteString("</header>\n<main>\n")
+	for _, doc := range s.Docs {
+		writeSnapshotNode(&b, buildJSONTree(maskSensitiveFields(doc), 0))
+	}
+	b.WriteString("</main>\n</body>\n</html>\n")
+	return b.String()
+}
+
+// writeSnapshotNode writes a node and its children; objects and arrays
+// become <details> elements, open like a fully expanded tree
+func writeSnapshotNode(b *strings.Builder, node *JSONNode) {
+	label := ""
+	if node.Key != "" {
+		label = snapshotKey(node.Key) + ": "
+	}
+	if !node.IsObject && !node.IsArray {
+		fmt.Fprintf(b, "<div class=\"leaf\">%s%s</div>\n", label, snapshotValue(node.Value))
+		return
+	}
+
+	openBracket, closeBracket := "{", "}"
+	if node.IsArray {
+		openBracket, closeBracket = "[", "]"
+	}
+	class := ""
+	if node.Depth == 0 {
+		class = " class=\"doc\""
+	}
+	fmt.Fprintf(b, "<details%s open><summary>%s<span class=\"bracket\">%s</span><span class=\"count\"> %d items </span><span class=\"count bracket\">%s</span></summary>\n",
+		class, label, openBracket, len(node.Children), closeBracket)
+	b.WriteString("<div class=\"children\">\n")
+	for _, child := range node.Children {
+		writeSnapshotNode(b, child)
+	}
+	fmt.Fprintf(b, "</div>\n<span class=\"bracket\">%s</span>\n</details>\n", closeBracket)
+}
+
+// snapshotKey renders a field name, or an array index like [0]
+func snapshotKey(key string) string {
+	if strings.HasPrefix(key, "[") {
+		return "<span class=\"index\">" + escapeHTML(key) + "</span>"
+	}
+	return "<span class=\"key\">" + escapeHTML(strconv.Quote(key)) + "</span>"
+}
+
+// snapshotValue renders a leaf value as formatValue shows it, in the class
+// of the style formatValue picks
+func snapshotValue(value interface{}) string {
+	text := stripANSI(formatValue(value))
+	class := "string"
+	switch value.(type) {
+	case nil:
+		class = "null"
+	case bool:
+		class = "bool"
+	case int, int32, int64, float32, float64, primitive.Timestamp, primitive.Decimal128:
+		class = "number"
+	case string, primitive.ObjectID, primitive.DateTime, primitive.Binary, primitive.Regex:
+	default:
+		if _, err := strconv.ParseFloat(text, 64); err == nil {
+			class = "number"
+		}
+	}
+	return "<span class=\"" + class + "\">" + escapeHTML(text) + "</span>"
+}
+
+// openSnapshotPrompt opens the HTML snapshot prompt with a default file name
+func (m *Model) openSnapshotPrompt() tea.Cmd {
+	if m.selectedCollection == "" || m.client == nil {
+		return nil
+	}
+	m.snapshotPromptActive = true
+	m.snapshotScope = ScopeDocument
+	name := fmt.Sprintf("%s-%s.html", m.selectedCollection, time.Now().Format("20060102-150405"))
+	m.snapshotPathInput.SetValue(name)
+	m.snapshotPathInput.CursorEnd()
+	m.snapshotPathInput.Focus()
+	return textinput.Blink
+}
+
+// handleSnapshotPromptKey handles keyboard input in the HTML snapshot prompt
+func (m *Model) handleSnapshotPromptKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "ctrl+g":
+		m.snapshotPromptActive = false
+		m.snapshotPathInput.Blur()
+		return nil
+	case "tab":
+		m.snapshotScope = cycleScope(snapshotScopes, m.snapshotScope, 1)
+		return nil
+	case "shift+tab":
+		m.snapshotScope = cycleScope(snapshotScopes, m.snapshotScope, -1)
+		return nil
+	case "enter":
+		path := strings.TrimSpace(m.snapshotPathInput.Value())
+		if path == "" {
+			return nil
+		}
+		return m.startSnapshotExport(expandTilde(path))
+	default:
+		var cmd tea.Cmd
+		m.snapshotPathInput, cmd = m.snapshotPathInput.Update(msg)
+		return cmd
+	}
+}
+
+// startSnapshotExport writes the snapshot of the chosen scope to path.
+// Pinned documents are fetched by _id, since most of them are usually not on
+// the loaded page.
+func (m *Model) startSnapshotExport(path string) tea.Cmd {
+	s := snapshot{
+		Namespace: m.targetLabel(m.selectedDatabase, m.selectedCollection),
+		Filter:    m.queryText,
+		Scope:     m.snapshotScope,
+		Generated: time.Now(),
+	}
+	target, err := m.bulkTarget(m.snapshotScope)
+	if err != nil {
+		return m.setStatus(err.Error())
+	}
+	s.Docs = target.docs
+	if target.docs == nil {
+		if jsonBytes, err := bson.MarshalExtJSON(target.filter, false, false); err == nil {
+			s.Filter = string(jsonBytes)
+		}
+	}
+	m.snapshotPromptActive = false
+	m.snapshotPathInput.Blur()
+
+	client, dbName, collName := m.client, m.selectedDatabase, m.selectedCollection
+	return m.startBulkJob("snapshot "+namespaceOf(dbName, collName), target, func(ctx context.Context, report func(string)) (tea.Msg, error) {
+		if target.docs == nil {
+			report("fetching " + target.scope.label())
+			cursor, err := client.Database(dbName).Collection(collName).Find(ctx, target.filter)
+			if err != nil {
+				return nil, err
+			}
+			if err := cursor.All(ctx, &s.Docs); err != nil {
+				return nil, err
+			}
+		}
+		if dir := filepath.Dir(path); dir != "" {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return nil, err
+			}
+		}
+		page := renderSnapshotHTML(s)
+		if err := os.WriteFile(path, []byte(page), 0644); err != nil {
+			return nil, err
+		}
+		return exportDoneMsg{path: path, count: len(s.Docs), size: int64(len(page))}, nil
+	})
+}
+
+// renderSnapshotPrompt renders the HTML snapshot prompt modal
+func (m Model) renderSnapshotPrompt(background string) string {
+	width := m.modalWidth(70)
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render("HTML snapshot of "+truncateMiddle(m.selectedCollection, maxToastNameWidth)),
+		"",
+		"File path:",
+		fitInput(m.snapshotPathInput, width-4),
+		"",
+		m.renderScopeChoice(snapshotScopes, m.snapshotScope, width-4),
+		"",
+		hintStyle.Render("Sensitive fields are masked"),
+		hintStyle.Render("tab: change scope • enter: export • esc: cancel"),
+	)
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("205")).
+		Padding(1, 2).
+		Width(width)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(content),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
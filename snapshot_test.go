@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func testSnapshot() snapshot {
+	docs := testDocuments()
+	docs[0]["apiKey"] = "sk-live-123"
+	docs[0]["bio"] = `<script>alert("x")</script> & more`
+	return snapshot{
+		Namespace: "prod ▸ shop ▸ orders",
+		Filter:    `{active: true, name: {$regex: "<a"}}`,
+		Scope:     ScopePage,
+		Docs:      docs,
+		Generated: time.Date(2024, 3, 2, 11, 45, 0, 0, time.UTC),
+	}
+}
+
+func TestGoldenHTMLSnapshot(t *testing.T) {
+	assertGolden(t, "html_snapshot", renderSnapshotHTML(testSnapshot()))
+}
+
+func TestHTMLSnapshotIsSelfContainedAndMasked(t *testing.T) {
+	page := renderSnapshotHTML(testSnapshot())
+	for _, leaked := range []string{"sk-live-123", "<script>alert", "src=", "href=", "@import"} {
+		if strings.Contains(page, leaked) {
+			t.Errorf("snapshot contains %q", leaked)
+		}
+	}
+	if !strings.Contains(page, "&lt;script&gt;alert") {
+		t.Error("snapshot doesn't contain the escaped bio")
+	}
+
+	one := renderSnapshotHTML(snapshot{Namespace: "shop ▸ orders", Scope: ScopeDocument, Docs: []bson.M{{"_id": int32(1)}}})
+	if !strings.Contains(one, "Filter: <code class=\"string\">{}</code>") || !strings.Contains(one, "1 document • current document") {
+		t.Errorf("header of a one-document snapshot:\n%s", one)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// sortSpec returns the active sort for loadDocuments, or nil for natural order
+func (m Model) sortSpec() bson.D {
+	if m.sortField == "" {
+		return nil
+	}
+	direction := 1
+	if m.sortDesc {
+		direction = -1
+	}
+	return bson.D{{Key: m.sortField, Value: direction}}
+}
+
+// sortLabel describes the active sort for the documents panel header
+func (m Model) sortLabel() string {
+	if m.sortField == "" {
+		return ""
+	}
+	if m.sortDesc {
+		return m.sortField + " ↓"
+	}
+	return m.sortField + " ↑"
+}
+
+// sortByCursorField sorts the result set by the field under the cursor,
+// ascending first and flipping direction when it's already the sort field.
+// Array indexes are dropped from the path, so sorting on an array element
+// sorts by the array's values as Mongo does.
+func (m *Model) sortByCursorField() tea.Cmd {
+	node := m.nodeAtCursor()
+	if node == nil || node.Parent == nil || node.Foreign || node.RawText != "" || node.More > 0 {
+		return nil
+	}
+	field := referencePattern(node)
+	if field == "" {
+		return nil
+	}
+	if strings.Contains(field, "[") {
+		return m.setStatus("can't sort by a field whose name contains a dot")
+	}
+
+	if field == m.sortField {
+		m.sortDesc = !m.sortDesc
+	} else {
+		m.sortField = field
+		m.sortDesc = false
+	}
+
+	m.loadingDocs = true
+	m.currentPage = 0
+	m.docCursor = 0
+	m.docScrollOffset = 0
+	return tea.Batch(
+		m.setStatus(fmt.Sprintf("sorted by %s", m.sortLabel())),
+		loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, 0, m.docsPerPage, m.queryFilter, m.sortSpec()),
+	)
+}
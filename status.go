@@ -0,0 +1,24 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// statusMessageDuration is how long a transient status message stays visible
+const statusMessageDuration = 3 * time.Second
+
+// setStatus shows a transient message in the help line and returns a command
+// that clears it again after statusMessageDuration
+func (m *Model) setStatus(text string) tea.Cmd {
+	if m.suspended {
+		m.queuedToasts = append(m.queuedToasts, text)
+	}
+	m.statusMessage = text
+	m.statusSeq++
+	seq := m.statusSeq
+	return tea.Tick(statusMessageDuration, func(time.Time) tea.Msg {
+		return statusClearMsg{seq: seq}
+	})
+}
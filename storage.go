@@ -2,8 +2,10 @@ package main
 
 import (
 	"database/sql"
+	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -23,7 +25,9 @@ func getDBPath() (string, error) {
 	return filepath.Join(mbongoDir, "mbongo.db"), nil
 }
 
-// initDB initializes the SQLite database
+// initDB opens the SQLite database and brings its schema up to date. When
+// the schema is newer than this binary it returns a *schemaTooNewError with
+// the database left open, so the caller may go on read-only.
 func initDB() error {
 	dbPath, err := getDBPath()
 	if err != nil {
@@ -34,36 +38,13 @@ func initDB() error {
 	if err != nil {
 		return err
 	}
-
-	// Create connections table if it doesn't exist
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS connections (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT NOT NULL,
-			connection_string TEXT NOT NULL,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		)
-	`)
-	if err != nil {
-		return err
-	}
-
-	// Migration: Add ssh_alias column if it doesn't exist
-	_, err = db.Exec(`ALTER TABLE connections ADD COLUMN ssh_alias TEXT DEFAULT ''`)
-	if err != nil {
-		// Ignore error if column already exists
-		if err.Error() != "duplicate column name: ssh_alias" {
-			// Check if it's actually a "column already exists" error (SQLite varies)
-			// We'll just ignore alter table errors since the column may exist
-		}
-	}
-
-	return nil
+	return migrateDB(db)
 }
 
-// loadConnections loads all connections from the database
+// loadConnections loads all connections from the database, decrypting
+// their connection strings when they are stored encrypted
 func loadConnections() ([]Connection, error) {
-	rows, err := db.Query("SELECT name, connection_string, COALESCE(ssh_alias, '') FROM connections ORDER BY name")
+	rows, err := db.Query("SELECT name, connection_string, COALESCE(ssh_alias, ''), COALESCE(environment, ''), COALESCE(color, ''), COALESCE(default_database, ''), last_used_at FROM connections ORDER BY name")
 	if err != nil {
 		return nil, err
 	}
@@ -72,9 +53,17 @@ func loadConnections() ([]Connection, error) {
 	var connections []Connection
 	for rows.Next() {
 		var conn Connection
-		if err := rows.Scan(&conn.Name, &conn.ConnectionString, &conn.SSHAlias); err != nil {
+		var stored string
+		var lastUsed sql.NullTime
+		if err := rows.Scan(&conn.Name, &stored, &conn.SSHAlias, &conn.Environment, &conn.Color, &conn.DefaultDatabase, &lastUsed); err != nil {
 			return nil, err
 		}
+		conn.LastUsed = lastUsed.Time
+		connStr, err := openConnectionString(stored)
+		if err != nil {
+			return nil, fmt.Errorf("reading connection %s: %w", conn.Name, err)
+		}
+		conn.ConnectionString = connStr
 		connections = append(connections, conn)
 	}
 
@@ -83,24 +72,261 @@ func loadConnections() ([]Connection, error) {
 
 // saveConnection saves a new connection to the database
 func saveConnection(conn Connection) error {
-	_, err := db.Exec(
-		"INSERT INTO connections (name, connection_string, ssh_alias) VALUES (?, ?, ?)",
-		conn.Name, conn.ConnectionString, conn.SSHAlias,
+	stored, err := sealConnectionString(conn.ConnectionString)
+	if err != nil {
+		return err
+	}
+	_, err = execWrite(
+		"INSERT INTO connections (name, connection_string, ssh_alias, environment, color, default_database) VALUES (?, ?, ?, ?, ?, ?)",
+		conn.Name, stored, conn.SSHAlias, conn.Environment, conn.Color, conn.DefaultDatabase,
 	)
 	return err
 }
 
 // deleteConnection deletes a connection from the database by name
 func deleteConnection(name string) error {
-	_, err := db.Exec("DELETE FROM connections WHERE name = ?", name)
+	_, err := execWrite("DELETE FROM connections WHERE name = ?", name)
 	return err
 }
 
 // updateConnection updates an existing connection in the database
 func updateConnection(oldName string, conn Connection) error {
-	_, err := db.Exec(
-		"UPDATE connections SET name = ?, connection_string = ?, ssh_alias = ? WHERE name = ?",
-		conn.Name, conn.ConnectionString, conn.SSHAlias, oldName,
+	stored, err := sealConnectionString(conn.ConnectionString)
+	if err != nil {
+		return err
+	}
+	_, err = execWrite(
+		"UPDATE connections SET name = ?, connection_string = ?, ssh_alias = ?, environment = ?, color = ?, default_database = ? WHERE name = ?",
+		conn.Name, stored, conn.SSHAlias, conn.Environment, conn.Color, conn.DefaultDatabase, oldName,
+	)
+	return err
+}
+
+// touchConnection records when a saved connection was selected
+func touchConnection(name string, at time.Time) error {
+	if db == nil {
+		return nil
+	}
+	_, err := execWrite("UPDATE connections SET last_used_at = ? WHERE name = ?", at.UTC(), name)
+	return err
+}
+
+// loadWatches loads the watched namespaces for a connection
+func loadWatches(connName string) ([]Watch, error) {
+	rows, err := db.Query("SELECT namespace, delta FROM watches WHERE connection_name = ? ORDER BY namespace", connName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var watches []Watch
+	for rows.Next() {
+		var w Watch
+		if err := rows.Scan(&w.Namespace, &w.Delta); err != nil {
+			return nil, err
+		}
+		watches = append(watches, w)
+	}
+
+	return watches, rows.Err()
+}
+
+// saveWatch adds or updates a watched namespace for a connection
+func saveWatch(connName string, w Watch) error {
+	_, err := execWrite(
+		"INSERT INTO watches (connection_name, namespace, delta) VALUES (?, ?, ?) ON CONFLICT(connection_name, namespace) DO UPDATE SET delta = excluded.delta",
+		connName, w.Namespace, w.Delta,
+	)
+	return err
+}
+
+// deleteWatch removes a watched namespace for a connection
+func deleteWatch(connName, namespace string) error {
+	_, err := execWrite("DELETE FROM watches WHERE connection_name = ? AND namespace = ?", connName, namespace)
+	return err
+}
+
+// loadPageSize returns the saved page size for a namespace, or 0 if none is saved
+func loadPageSize(connName, namespace string) (int, error) {
+	if db == nil {
+		return 0, nil
+	}
+	var size int
+	err := db.QueryRow("SELECT page_size FROM collection_settings WHERE connection_name = ? AND namespace = ?", connName, namespace).Scan(&size)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return size, err
+}
+
+// savePageSize saves the page size for a namespace
+func savePageSize(connName, namespace string, size int) error {
+	if db == nil {
+		return nil
+	}
+	_, err := execWrite(
+		"INSERT INTO collection_settings (connection_name, namespace, page_size) VALUES (?, ?, ?) ON CONFLICT(connection_name, namespace) DO UPDATE SET page_size = excluded.page_size",
+		connName, namespace, size,
+	)
+	return err
+}
+
+// loadReferenceTargets returns the configured target collection per field path of a namespace
+func loadReferenceTargets(connName, namespace string) (map[string]string, error) {
+	targets := map[string]string{}
+	if db == nil {
+		return targets, nil
+	}
+	rows, err := db.Query("SELECT field_path, target_collection FROM reference_targets WHERE connection_name = ? AND namespace = ?", connName, namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var path, target string
+		if err := rows.Scan(&path, &target); err != nil {
+			return nil, err
+		}
+		targets[path] = target
+	}
+
+	return targets, rows.Err()
+}
+
+// saveReferenceTarget sets the target collection of a field, or removes it when target is empty
+func saveReferenceTarget(connName, namespace, path, target string) error {
+	if db == nil {
+		return nil
+	}
+	if target == "" {
+		_, err := execWrite("DELETE FROM reference_targets WHERE connection_name = ? AND namespace = ? AND field_path = ?", connName, namespace, path)
+		return err
+	}
+	_, err := execWrite(
+		"INSERT INTO reference_targets (connection_name, namespace, field_path, target_collection) VALUES (?, ?, ?, ?) ON CONFLICT(connection_name, namespace, field_path) DO UPDATE SET target_collection = excluded.target_collection",
+		connName, namespace, path, target,
+	)
+	return err
+}
+
+// loadSetting returns an app-wide setting, or "" if it was never saved
+func loadSetting(key string) (string, error) {
+	if db == nil {
+		return "", nil
+	}
+	var value string
+	err := db.QueryRow("SELECT value FROM settings WHERE key = ?", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return value, err
+}
+
+// saveSetting saves an app-wide setting
+func saveSetting(key, value string) error {
+	if db == nil {
+		return nil
+	}
+	_, err := execWrite("INSERT INTO settings (key, value) VALUES (?, ?) ON CONFLICT(key) DO UPDATE SET value = excluded.value", key, value)
+	return err
+}
+
+// loadEpochSuppressions returns the field paths of a namespace whose numbers
+// are never decoded as dates
+func loadEpochSuppressions(connName, namespace string) (map[string]bool, error) {
+	paths := map[string]bool{}
+	if db == nil {
+		return paths, nil
+	}
+	rows, err := db.Query("SELECT field_path FROM epoch_suppressions WHERE connection_name = ? AND namespace = ?", connName, namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		paths[path] = true
+	}
+
+	return paths, rows.Err()
+}
+
+// saveEpochSuppression stops or resumes decoding a field's numbers as dates
+func saveEpochSuppression(connName, namespace, path string, suppressed bool) error {
+	if db == nil {
+		return nil
+	}
+	if !suppressed {
+		_, err := execWrite("DELETE FROM epoch_suppressions WHERE connection_name = ? AND namespace = ? AND field_path = ?", connName, namespace, path)
+		return err
+	}
+	_, err := execWrite(
+		"INSERT INTO epoch_suppressions (connection_name, namespace, field_path) VALUES (?, ?, ?) ON CONFLICT(connection_name, namespace, field_path) DO NOTHING",
+		connName, namespace, path,
+	)
+	return err
+}
+
+// loadTemplate returns the generator template saved for a collection, or ""
+func loadTemplate(connName, namespace string) (string, error) {
+	if db == nil {
+		return "", nil
+	}
+	var template string
+	err := db.QueryRow("SELECT template FROM templates WHERE connection_name = ? AND namespace = ?", connName, namespace).Scan(&template)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return template, err
+}
+
+// saveTemplate saves the generator template of a collection
+func saveTemplate(connName, namespace, template string) error {
+	if db == nil {
+		return nil
+	}
+	_, err := execWrite(
+		"INSERT INTO templates (connection_name, namespace, template) VALUES (?, ?, ?) ON CONFLICT(connection_name, namespace) DO UPDATE SET template = excluded.template",
+		connName, namespace, template,
+	)
+	return err
+}
+
+// loadLastBenchmark returns the latest benchmark of a query, or nil if it
+// was never benchmarked
+func loadLastBenchmark(connName, namespace, query string) (*benchmarkSummary, error) {
+	if db == nil {
+		return nil, nil
+	}
+	var s benchmarkSummary
+	var minUS, medianUS, maxUS int64
+	err := db.QueryRow(
+		"SELECT runs, min_us, median_us, max_us, docs_examined, keys_examined, plan, created_at FROM benchmarks WHERE connection_name = ? AND namespace = ? AND query = ? ORDER BY id DESC LIMIT 1",
+		connName, namespace, query,
+	).Scan(&s.runs, &minUS, &medianUS, &maxUS, &s.docsExamined, &s.keysExamined, &s.plan, &s.at)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.min, s.median, s.max = time.Duration(minUS)*time.Microsecond, time.Duration(medianUS)*time.Microsecond, time.Duration(maxUS)*time.Microsecond
+	return &s, nil
+}
+
+// saveBenchmark records a benchmark of a query for the next one to compare with
+func saveBenchmark(connName, namespace, query string, s benchmarkSummary) error {
+	if db == nil {
+		return nil
+	}
+	_, err := execWrite(
+		"INSERT INTO benchmarks (connection_name, namespace, query, runs, min_us, median_us, max_us, docs_examined, keys_examined, plan) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		connName, namespace, query, s.runs, s.min.Microseconds(), s.median.Microseconds(), s.max.Microseconds(), s.docsExamined, s.keysExamined, s.plan,
 	)
 	return err
 }
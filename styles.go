@@ -74,4 +74,24 @@ var (
 					Background(lipgloss.Color("208")).
 					Foreground(lipgloss.Color("0")).
 					Bold(true)
+
+	// Style for the matched text itself within matching lines
+	docSearchSubstringStyle = lipgloss.NewStyle().
+				Background(lipgloss.Color("226")).
+				Foreground(lipgloss.Color("0")).
+				Bold(true)
+
+	// Style for the BSON type shown after leaf values
+	typeAnnotationStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("241")).
+				Italic(true)
+
+	// Style for nodes of a referenced document grafted into the tree
+	foreignStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("141")).
+			Italic(true)
+
+	// Style for transient status messages in the help line
+	statusMessageStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("86"))
 )
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// subEditPath returns the path of the node under the cursor for editing it
+// on its own with $set, or why it can't be
+func (m Model) subEditPath(node *JSONNode) ([]string, error) {
+	if node == nil || node.Parent == nil || node.RawText != "" || node.More > 0 {
+		return nil, errors.New("nothing to edit here")
+	}
+	if node.Foreign {
+		return nil, errors.New("referenced documents can't be edited from here")
+	}
+	switch m.docProvenance {
+	case ProvenanceAggregated:
+		return nil, errors.New("aggregation results can't be edited: they have no 1:1 source document")
+	case ProvenanceProjected:
+		return nil, errors.New("projected results may show only part of a field: edit the whole document")
+	}
+	path := nodePathSegments(node)
+	if path[0] == "_id" {
+		return nil, errors.New("_id can't be changed")
+	}
+	for _, segment := range path {
+		if segment == "" || strings.Contains(segment, ".") || strings.HasPrefix(segment, "$") {
+			return nil, fmt.Errorf("%s can't be set on its own: edit the whole document", formatFieldPath(path))
+		}
+	}
+	return path, nil
+}
+
+// startSubEdit edits the node under the cursor on its own: objects and
+// arrays in $EDITOR, other values in a one-line prompt
+func (m *Model) startSubEdit() tea.Cmd {
+	node := m.nodeAtCursor()
+	docIndex := m.getDocumentIndexAtCursor()
+	path, err := m.subEditPath(node)
+	if err != nil || docIndex < 0 || docIndex >= len(m.documents) {
+		if err == nil {
+			err = errors.New("nothing to edit here")
+		}
+		return m.setStatus(err.Error())
+	}
+	doc := m.documents[docIndex]
+	if !node.IsObject && !node.IsArray {
+		return m.openValuePrompt(doc, path, node.Value)
+	}
+
+	edit := editorFinishedMsg{docID: doc["_id"], path: path, document: doc}
+	jsonBytes, err := editorValueJSON(node.Value)
+	if err != nil {
+		edit.err = err
+		return func() tea.Msg { return edit }
+	}
+	m.editorActive = true
+	return editInEditor(edit, jsonBytes, nil)
+}
+
+// valueAtPath returns the value at path, whose array elements are given by
+// index, and whether it exists
+func valueAtPath(doc bson.M, path []string) (interface{}, bool) {
+	var current interface{} = doc
+	for _, segment := range path {
+		switch container := current.(type) {
+		case bson.M:
+			value, ok := container[segment]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case bson.A:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(container) {
+				return nil, false
+			}
+			current = container[i]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// withValueAt returns a copy of doc with the value at path replaced, as
+// $set would leave it
+func withValueAt(doc bson.M, path []string, value interface{}) (bson.M, error) {
+	copied, err := copyDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	parent, ok := valueAtPath(copied, path[:len(path)-1])
+	last := path[len(path)-1]
+	switch container := parent.(type) {
+	case bson.M:
+		container[last] = value
+		return copied, nil
+	case bson.A:
+		if i, err := strconv.Atoi(last); err == nil && i >= 0 && i < len(container) {
+			container[i] = value
+			return copied, nil
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("%s is no longer in the document", formatFieldPath(path))
+	}
+	return nil, fmt.Errorf("%s can't be set", formatFieldPath(path))
+}
+
+// extJSONValue returns value as Extended JSON. Only documents marshal on
+// their own, so the value is marshalled as a field and taken back out.
+func extJSONValue(value interface{}, canonical bool) (json.RawMessage, error) {
+	data, err := bson.MarshalExtJSON(bson.D{{Key: "v", Value: sortedDocument(value)}}, canonical, false)
+	if err != nil {
+		return nil, err
+	}
+	var wrapper struct {
+		V json.RawMessage `json:"v"`
+	}
+	err = json.Unmarshal(data, &wrapper)
+	return wrapper.V, err
+}
+
+// editorValueJSON formats an object or array for editing, the way
+// editorJSON formats a whole document
+func editorValueJSON(value interface{}) ([]byte, error) {
+	raw, err := extJSONValue(value, true)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err := json.Indent(&b, raw, "", "  "); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// parseEditedValue reads an object or array back from the editor
+func parseEditedValue(data []byte) (interface{}, error) {
+	var wrapper bson.M
+	if err := bson.UnmarshalExtJSON(append(append([]byte(`{"v": `), data...), '}'), false, &wrapper); err != nil {
+		return nil, err
+	}
+	return wrapper["v"], nil
+}
+
+// confirmEditedValue shows what an edit of a sub-document changes and sets
+// it once confirmed
+func (m *Model) confirmEditedValue(msg editorFinishedMsg, value interface{}) tea.Cmd {
+	after, err := withValueAt(msg.document, msg.path, value)
+	if err != nil {
+		os.Remove(msg.tempFile)
+		return m.setStatus(err.Error())
+	}
+	diff := documentDiff(msg.document, after)
+	if len(diff) == 0 {
+		os.Remove(msg.tempFile)
+		return m.setStatus("no changes: document was not saved")
+	}
+	if len(diff) > maxEditDiffLines {
+		diff = append(diff[:maxEditDiffLines], fmt.Sprintf("  … and %d more changes", len(diff)-maxEditDiffLines))
+	}
+
+	save := documentSave{docID: msg.docID, original: msg.document, path: msg.path, value: value, tempFile: msg.tempFile}
+	return m.openConfirm(&confirmation{
+		title:       "Save Document",
+		message:     fmt.Sprintf("Set %s of _id=%s in %s?\n\n%s", formatFieldPath(msg.path), shortID(msg.docID), m.targetLabel(m.selectedDatabase, m.selectedCollection), strings.Join(diff, "\n")),
+		severity:    SeverityWarning,
+		destructive: true,
+		onConfirm: func(m *Model) tea.Cmd {
+			return m.saveDocument(save, false)
+		},
+		onCancel: func(m *Model) tea.Cmd {
+			os.Remove(msg.tempFile)
+			return m.setStatus("edit abandoned: document was NOT saved")
+		},
+	})
+}
+
+// newValueInput creates the input of the prompt editing a single value
+func newValueInput() textinput.Model {
+	ti := textinput.New()
+	ti.CharLimit = 10000
+	ti.Width = 40
+	return ti
+}
+
+// valuePromptText returns a value as typed in the value prompt: relaxed
+// Extended JSON, except for 64-bit integers, which would read back as
+// 32-bit ones when small
+func valuePromptText(value interface{}) (string, error) {
+	_, isLong := value.(int64)
+	raw, err := extJSONValue(value, isLong)
+	return string(raw), err
+}
+
+// parseValuePrompt reads a value typed in the value prompt, in the same
+// relaxed syntax as the query panel
+func parseValuePrompt(text string) (interface{}, error) {
+	wrapper, err := parseQueryFilter(`{"v": ` + text + `}`)
+	if err != nil {
+		return nil, err
+	}
+	return wrapper["v"], nil
+}
+
+// openValuePrompt opens the prompt setting the value at path
+func (m *Model) openValuePrompt(doc bson.M, path []string, value interface{}) tea.Cmd {
+	text, err := valuePromptText(value)
+	if err != nil {
+		return m.setStatus(err.Error())
+	}
+	m.valueEdit = documentSave{docID: doc["_id"], original: doc, path: path, value: value}
+	m.valuePromptActive = true
+	m.valueErr = ""
+	m.valueInput.SetValue(text)
+	m.valueInput.CursorEnd()
+	m.valueInput.Focus()
+	return textinput.Blink
+}
+
+// closeValuePrompt closes the value prompt
+func (m *Model) closeValuePrompt() {
+	m.valuePromptActive = false
+	m.valueInput.Blur()
+	m.valueErr = ""
+}
+
+// handleValuePromptKey handles keyboard input in the value prompt
+func (m *Model) handleValuePromptKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "ctrl+g":
+		m.closeValuePrompt()
+		return nil
+	case "enter":
+		value, err := parseValuePrompt(strings.TrimSpace(m.valueInput.Value()))
+		if err != nil {
+			m.valueErr = err.Error()
+			return nil
+		}
+		m.closeValuePrompt()
+		if reflect.DeepEqual(value, m.valueEdit.value) {
+			return m.setStatus("no changes: document was not saved")
+		}
+		save := m.valueEdit
+		save.value = value
+		return m.guardProduction("Save Document", func(m *Model) tea.Cmd {
+			return m.saveDocument(save, false)
+		}, nil)
+	default:
+		var cmd tea.Cmd
+		m.valueInput, cmd = m.valueInput.Update(msg)
+		m.valueErr = ""
+		return cmd
+	}
+}
+
+// renderValuePrompt renders the value prompt overlay
+func (m Model) renderValuePrompt(background string) string {
+	width := m.modalWidth(70)
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+
+	lines := []string{
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render("Set " + truncate(formatFieldPath(m.valueEdit.path), width-8)),
+		"",
+		truncate(fmt.Sprintf("_id=%s in %s", shortID(m.valueEdit.docID), m.targetLabel(m.selectedDatabase, m.selectedCollection)), width-4),
+		"",
+		fitInput(m.valueInput, width-4),
+	}
+	if m.valueErr != "" {
+		lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Width(width-4).Render(m.valueErr))
+	}
+	lines = append(lines, "", hintStyle.Render(`enter: save • esc: cancel • "text", 42, 1.5, true, null, ObjectId("…"), {$date: "…"}`))
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("205")).
+		Padding(1, 2).
+		Width(width)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
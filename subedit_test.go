@@ -0,0 +1,130 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestValueAtArrayPaths(t *testing.T) {
+	doc := bson.M{"orders": bson.A{bson.M{"items": bson.A{bson.M{"sku": "a"}, bson.M{"sku": "b"}}}}}
+	path := []string{"orders", "0", "items", "1", "sku"}
+	if value, ok := valueAtPath(doc, path); !ok || value != "b" {
+		t.Errorf("valueAtPath = %v, %v", value, ok)
+	}
+	if _, ok := valueAtPath(doc, []string{"orders", "3"}); ok {
+		t.Error("index past the end found")
+	}
+
+	updated, err := withValueAt(doc, path, "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := valueAtPath(updated, path); value != "c" {
+		t.Errorf("updated value = %v", value)
+	}
+	if value, _ := valueAtPath(doc, path); value != "b" {
+		t.Error("withValueAt modified the original")
+	}
+	if diff := documentDiff(doc, updated); len(diff) != 1 || !strings.Contains(diff[0], `"sku":"c"`) {
+		t.Errorf("diff = %q", diff)
+	}
+}
+
+func TestEditedValueRoundTrip(t *testing.T) {
+	value := bson.A{
+		bson.M{"at": primitive.NewDateTimeFromTime(time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)), "n": int64(3)},
+		1.5,
+		"x",
+	}
+	data, err := editorValueJSON(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "\n  ") || !strings.Contains(string(data), `"$numberLong": "3"`) {
+		t.Errorf("editor JSON:\n%s", data)
+	}
+	got, err := parseEditedValue(data)
+	if err != nil || !reflect.DeepEqual(got, value) {
+		t.Errorf("parseEditedValue = %#v, %v, want %#v", got, err, value)
+	}
+}
+
+func TestValuePromptRoundTrip(t *testing.T) {
+	id, _ := primitive.ObjectIDFromHex("65ab12cd34ef56ab78cd90ef")
+	for _, value := range []interface{}{
+		"Ada", int32(36), int64(5), 2.5, true, nil, id,
+		primitive.NewDateTimeFromTime(time.Date(2024, 3, 2, 11, 45, 0, 0, time.UTC)),
+	} {
+		text, err := valuePromptText(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := parseValuePrompt(text)
+		if err != nil || !reflect.DeepEqual(got, value) {
+			t.Errorf("%T: %s reads back as %#v, %v", value, text, got, err)
+		}
+	}
+}
+
+func TestSubEditPathRefusals(t *testing.T) {
+	m := newTestModel(120, 30)
+	root := &JSONNode{IsObject: true}
+	for key, want := range map[string]string{"_id": "can't be changed", "a.b": "edit the whole document", "$x": "edit the whole document"} {
+		if _, err := m.subEditPath(&JSONNode{Key: key, Parent: root}); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: error %v, want %q", key, err, want)
+		}
+	}
+	m.docProvenance = ProvenanceProjected
+	if _, err := m.subEditPath(&JSONNode{Key: "name", Parent: root}); err == nil {
+		t.Error("projected field editable on its own")
+	}
+}
+
+func TestEditLeafInPrompt(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	for i, node := range m.flattenedTree {
+		if node.Key == "name" {
+			m.docCursor = i
+			break
+		}
+	}
+	m = pressKey(m, "e")
+	if m.pendingKey != "e" || !strings.Contains(m.statusMessage, "s=name only") {
+		t.Fatalf("pending = %q, status = %q", m.pendingKey, m.statusMessage)
+	}
+	m = pressKey(m, "s")
+	if !m.valuePromptActive || m.valueInput.Value() != `"Ada Lovelace"` {
+		t.Fatalf("prompt = %v, input %q", m.valuePromptActive, m.valueInput.Value())
+	}
+	if view := normalizeRender(m.View()); !strings.Contains(view, "Set name") {
+		t.Errorf("prompt view:\n%s", view)
+	}
+
+	m.valueInput.SetValue(`"Ada`)
+	m, _ = update(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.valuePromptActive || m.valueErr == "" {
+		t.Errorf("bad value: active = %v, err = %q", m.valuePromptActive, m.valueErr)
+	}
+	m.valueInput.SetValue(`"Ada King"`)
+	m, cmd := update(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.valuePromptActive || cmd == nil {
+		t.Errorf("good value: active = %v, cmd = %v", m.valuePromptActive, cmd)
+	}
+}
+
+func TestSavedFieldUpdatesTreeInPlace(t *testing.T) {
+	m := newTestModel(120, 30)
+	saved, _ := copyDocument(m.documents[0])
+	saved["address"].(bson.M)["city"] = "Paris"
+	m, _ = update(t, m, documentSavedMsg{docID: saved["_id"], newDoc: saved})
+	if value, _ := valueAtPath(m.documents[0], []string{"address", "city"}); value != "Paris" {
+		t.Errorf("city = %v", value)
+	}
+}
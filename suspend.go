@@ -0,0 +1,38 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// suspend hands the terminal back to the shell on ctrl+z. The model is left
+// as it is, so fg brings back the same screen, focus, scroll positions and
+// overlays.
+//
+// Background jobs can't keep running while suspended: the shell only gets
+// the terminal back once the process has stopped, and stopping it stops
+// every goroutine with it. Jobs pick up where they were after fg, and the
+// toasts raised between ctrl+z and the stop are queued for the resume.
+func (m *Model) suspend() tea.Cmd {
+	m.suspended = true
+	m.pendingKey = ""
+	m.countPrefix = 0
+	m.queuedToasts = nil
+	if m.statusMessage != "" {
+		m.queuedToasts = append(m.queuedToasts, m.statusMessage)
+	}
+	return tea.Suspend
+}
+
+// resume runs after fg. Toasts set before or while suspended are shown
+// again together, for the full duration rather than expiring unseen.
+func (m *Model) resume() tea.Cmd {
+	m.suspended = false
+	toasts := m.queuedToasts
+	m.queuedToasts = nil
+	if len(toasts) == 0 {
+		return nil
+	}
+	return m.setStatus(strings.Join(toasts, " • "))
+}
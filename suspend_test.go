@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSuspendKeepsToastUntilResume(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.setStatus("exported 3 documents")
+	seq := m.statusSeq
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlZ})
+	m = updated.(Model)
+	if cmd == nil || !m.suspended {
+		t.Fatal("ctrl+z didn't suspend")
+	}
+	if _, ok := cmd().(tea.SuspendMsg); !ok {
+		t.Fatal("ctrl+z didn't return tea.Suspend")
+	}
+
+	// The toast's timer fires while suspended
+	updated, _ = m.Update(statusClearMsg{seq: seq})
+	m = updated.(Model)
+	if m.statusMessage == "" {
+		t.Fatal("toast cleared while suspended")
+	}
+
+	updated, cmd = m.Update(tea.ResumeMsg{})
+	m = updated.(Model)
+	if m.suspended || cmd == nil || m.statusSeq == seq {
+		t.Fatalf("resume didn't restart the toast timer (suspended=%v seq=%d)", m.suspended, m.statusSeq)
+	}
+}
+
+func TestToastsRaisedWhileSuspendingAreQueued(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.setStatus("exported 3 documents")
+	m.suspend()
+
+	// Jobs finishing between ctrl+z and the stop
+	m.setStatus("import done")
+	m.setStatus("find \"ada\" in shop done")
+
+	m.resume()
+	if want := `exported 3 documents • import done • find "ada" in shop done`; m.statusMessage != want {
+		t.Errorf("status after resume = %q, want %q", m.statusMessage, want)
+	}
+	if len(m.queuedToasts) != 0 {
+		t.Errorf("queue kept: %v", m.queuedToasts)
+	}
+}
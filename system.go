@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// hideSystemSetting is "off" when the server's own databases and the
+// system.* collections are listed like any other
+const hideSystemSetting = "hide_system"
+
+// hidesSystem reports whether system databases and collections are left
+// out of the lists right now
+func (m Model) hidesSystem() bool {
+	return m.hideSystem && !m.revealSystem
+}
+
+// hiddenDatabase reports whether dbName is left out of the databases list.
+// The open database stays listed, e.g. admin opened from the connection string.
+func (m Model) hiddenDatabase(dbName string) bool {
+	return m.hidesSystem() && isSystemDatabase(dbName) && dbName != m.selectedDatabase
+}
+
+// hiddenCollection reports whether collName is left out of the collections
+// list. The open collection stays listed.
+func (m Model) hiddenCollection(collName string) bool {
+	return m.hidesSystem() && strings.HasPrefix(collName, "system.") && collName != m.selectedCollection
+}
+
+// hiddenSuffix returns the panel title info counting the entries of list
+// that are hidden, or "" when none are
+func hiddenSuffix(list []string, hidden func(string) bool) string {
+	n := 0
+	for _, entry := range list {
+		if hidden(entry) {
+			n++
+		}
+	}
+	if n == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d hidden", n)
+}
+
+// refilterLists applies a change of what is hidden to both lists, keeping
+// the cursors on the same entries
+func (m *Model) refilterLists() {
+	dbName := entryAt(m.dbFiltered, m.dbCursor)
+	collName := entryAt(m.collFiltered, m.collCursor)
+	m.updateFilteredDatabases()
+	m.updateFilteredCollections()
+	if i := indexOfString(m.dbFiltered, dbName); i >= 0 {
+		m.dbCursor = i
+	}
+	if i := indexOfString(m.collFiltered, collName); i >= 0 {
+		m.collCursor = i
+	}
+}
+
+// toggleSystemReveal shows the hidden system databases and collections, or
+// hides them again, for the rest of the session
+func (m *Model) toggleSystemReveal() tea.Cmd {
+	if !m.hideSystem {
+		return m.setStatus("system databases and collections are listed: alt+. hides them")
+	}
+	m.revealSystem = !m.revealSystem
+	m.refilterLists()
+	if m.revealSystem {
+		return m.setStatus("system databases and collections shown until . or restart")
+	}
+	return m.setStatus("system databases and collections hidden")
+}
+
+// toggleHideSystem changes whether system databases and collections are
+// hidden by default, and saves it
+func (m *Model) toggleHideSystem() tea.Cmd {
+	m.hideSystem = !m.hideSystem
+	m.revealSystem = false
+	m.refilterLists()
+	value := "on"
+	if !m.hideSystem {
+		value = "off"
+	}
+	if err := saveSetting(hideSystemSetting, value); err != nil {
+		m.errorModal = true
+		m.errorMessage = "Failed to save whether system entries are hidden: " + err.Error()
+		return nil
+	}
+	if m.hideSystem {
+		return m.setStatus("system databases and collections hidden by default: . shows them")
+	}
+	return m.setStatus("system databases and collections listed by default")
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSystemEntriesHidden(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.databases = []string{"admin", "config", "local", "shop", "analytics"}
+	m.collections = []string{"system.views", "customers", "orders"}
+	m.hideSystem = true
+	m.refilterLists()
+
+	if strings.Join(m.dbFiltered, ",") != "shop,analytics" || m.dbFiltered[m.dbCursor] != "shop" {
+		t.Fatalf("databases %v, cursor on %q", m.dbFiltered, entryAt(m.dbFiltered, m.dbCursor))
+	}
+	if strings.Join(m.collFiltered, ",") != "customers,orders" || m.collFiltered[m.collCursor] != "orders" {
+		t.Fatalf("collections %v, cursor on %q", m.collFiltered, entryAt(m.collFiltered, m.collCursor))
+	}
+	// Indices still map to the full lists, with fuzzy search on top
+	m.dbSearchInput.SetValue("a")
+	m.updateFilteredDatabases()
+	if strings.Join(m.dbFiltered, ",") != "analytics" || m.dbFilteredIndices[0] != 4 {
+		t.Errorf("search: %v at %v", m.dbFiltered, m.dbFilteredIndices)
+	}
+	m.dbSearchInput.SetValue("")
+	m.updateFilteredDatabases()
+	if view := normalizeRender(m.View()); !strings.Contains(view, "3 hidden") || !strings.Contains(view, "1 hidden") {
+		t.Errorf("hidden counts not shown:\n%s", view)
+	}
+
+	m.focus = FocusCollections
+	m = pressKey(m, ".")
+	if len(m.dbFiltered) != 5 || len(m.collFiltered) != 3 || m.collFiltered[m.collCursor] != "orders" {
+		t.Errorf("revealed: databases %v, collections %v, cursor on %q", m.dbFiltered, m.collFiltered, m.collFiltered[m.collCursor])
+	}
+	m = pressKey(m, ".")
+	if len(m.dbFiltered) != 2 {
+		t.Errorf("hidden again: databases %v", m.dbFiltered)
+	}
+}
+
+func TestOpenSystemDatabaseStaysListed(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.hideSystem = true
+	m.selectedDatabase = "admin"
+	m.refilterLists()
+	if strings.Join(m.dbFiltered, ",") != "admin,shop,analytics" {
+		t.Errorf("databases %v", m.dbFiltered)
+	}
+}
+
+func TestHideSystemIsSaved(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := initDB(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		closeDB()
+		db = nil
+	}()
+
+	m := newTestModel(120, 30)
+	m.hideSystem = true
+	m.focus = FocusDatabases
+
+	m = pressKey(m, "alt+.")
+	if value, _ := loadSetting(hideSystemSetting); m.hideSystem || value != "off" {
+		t.Errorf("hide = %v, saved %q", m.hideSystem, value)
+	}
+	if !strings.Contains(m.statusMessage, "listed by default") {
+		t.Errorf("status %q", m.statusMessage)
+	}
+}
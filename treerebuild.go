@@ -0,0 +1,60 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// treeRebuildDelay is how long expand/collapse keys are coalesced before the
+// flattened tree is rebuilt, about one frame
+const treeRebuildDelay = 16 * time.Millisecond
+
+// markTreeDirty defers rebuilding the flattened tree after a node was
+// expanded or collapsed, so a burst of repeated toggle keys costs a single
+// rebuild. The rebuild happens on the next key that isn't a toggle, or after
+// treeRebuildDelay when input is idle.
+func (m *Model) markTreeDirty() tea.Cmd {
+	m.treeDirty = true
+	if m.treeRebuildScheduled {
+		return nil
+	}
+	m.treeRebuildScheduled = true
+	return tea.Tick(treeRebuildDelay, func(time.Time) tea.Msg {
+		return treeRebuildMsg{}
+	})
+}
+
+// flushTree rebuilds a dirty flattened tree and keeps the cursor on the node
+// it was on, at the same screen row
+func (m *Model) flushTree() {
+	if !m.treeDirty {
+		return
+	}
+	m.treeDirty = false
+
+	// The stale tree still has the cursor node at the cursor index
+	node := m.nodeAtCursor()
+	row := m.docCursor - m.docScrollOffset
+	m.rebuildFlattenedTree()
+	if node != nil && node.Depth >= 0 {
+		m.anchorCursor(node, row)
+	}
+	m.docCursor = clampIndex(m.docCursor, len(m.flattenedTree))
+	m.adjustScrollForCursor()
+}
+
+// isTreeToggleKey reports whether msg is an expand/collapse key that can be
+// applied to a dirty tree without rebuilding it first. Overlays and searches
+// are opened by other keys, which flush the tree, so they never see it dirty.
+func (m Model) isTreeToggleKey(msg tea.Msg) bool {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok || m.screen != ScreenMain || m.focus != FocusDocuments || m.pendingKey != "" {
+		return false
+	}
+	switch key.String() {
+	case "right", "l", "left", "h", " ", "enter":
+		return true
+	}
+	return false
+}
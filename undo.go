@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// maxUndoLevels is how many saves of one document can be undone
+const maxUndoLevels = 10
+
+// savedVersion is a save of a document that U can revert
+type savedVersion struct {
+	before bson.M // Document as it was loaded for editing
+	after  bson.M // Document as the save left it
+	at     time.Time
+}
+
+// undoKey identifies a document among the collections of the connection
+func undoKey(namespace string, id interface{}) string {
+	return namespace + " " + idKey(id)
+}
+
+// recordSave remembers a save so it can be undone, forgetting the oldest
+// one of the document beyond maxUndoLevels
+func (m *Model) recordSave(namespace string, id interface{}, before, after bson.M) {
+	key := undoKey(namespace, id)
+	history := append(m.saveHistory[key], savedVersion{before: before, after: after, at: time.Now()})
+	if len(history) > maxUndoLevels {
+		history = history[len(history)-maxUndoLevels:]
+	}
+	m.saveHistory[key] = history
+}
+
+// dropLastSave forgets the latest save of a document once it was undone
+func (m *Model) dropLastSave(namespace string, id interface{}) {
+	key := undoKey(namespace, id)
+	history := m.saveHistory[key]
+	if len(history) <= 1 {
+		delete(m.saveHistory, key)
+		return
+	}
+	m.saveHistory[key] = history[:len(history)-1]
+}
+
+// undoSave asks to revert the latest save of the document under the cursor,
+// showing what reverting changes. The revert is a save like any other, so it
+// is refused if the document changed on the server since.
+func (m *Model) undoSave() tea.Cmd {
+	docIndex := m.getDocumentIndexAtCursor()
+	if docIndex < 0 || docIndex >= len(m.documents) {
+		return nil
+	}
+	id := m.documents[docIndex]["_id"]
+	history := m.saveHistory[undoKey(m.currentNamespace(), id)]
+	if len(history) == 0 {
+		return m.setStatus("no saves of this document to undo")
+	}
+	version := history[len(history)-1]
+	restored, err := copyDocument(version.before)
+	if err != nil {
+		return m.setStatus(err.Error())
+	}
+
+	diff := documentDiff(version.after, version.before)
+	if len(diff) == 0 {
+		diff = []string{"  (field order or types only)"}
+	}
+	if len(diff) > maxEditDiffLines {
+		diff = append(diff[:maxEditDiffLines], fmt.Sprintf("  … and %d more changes", len(diff)-maxEditDiffLines))
+	}
+	message := fmt.Sprintf("Revert _id=%s in %s to how it was before your save at %s?\n\n%s",
+		shortID(id), m.targetLabel(m.selectedDatabase, m.selectedCollection), version.at.Format("15:04:05"), strings.Join(diff, "\n"))
+	if earlier := len(history) - 1; earlier > 0 {
+		message += fmt.Sprintf("\n\n%d earlier %s can be undone after this one.", earlier, pluralize(int64(earlier), "save", "saves"))
+	}
+
+	save := documentSave{docID: id, original: version.after, newDoc: restored, undo: true}
+	return m.openConfirm(&confirmation{
+		title:       "Undo Save",
+		message:     message,
+		severity:    SeverityWarning,
+		destructive: true,
+		onConfirm: func(m *Model) tea.Cmd {
+			return m.saveDocument(save, false)
+		},
+	})
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestSavesAreRecordedAndUndone(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	id := m.documents[0]["_id"]
+	before := m.documents[0]
+	after := bson.M{"_id": id, "name": "Ada King"}
+
+	m, _ = update(t, m, documentSavedMsg{docID: id, newDoc: after, namespace: "shop.orders", before: before})
+	history := m.saveHistory[undoKey("shop.orders", id)]
+	if len(history) != 1 || history[0].after["name"] != "Ada King" || history[0].before["name"] != "Ada Lovelace" {
+		t.Fatalf("history = %+v", history)
+	}
+
+	m.docCursor = 0
+	m, _ = update(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("U")})
+	if m.confirm == nil || m.confirm.title != "Undo Save" {
+		t.Fatalf("confirm = %+v", m.confirm)
+	}
+	for _, want := range []string{"Revert _id=", `name: "Ada King" → "Ada Lovelace"`, "+ age"} {
+		if !strings.Contains(m.confirm.message, want) {
+			t.Errorf("message lacks %q:\n%s", want, m.confirm.message)
+		}
+	}
+
+	// The revert doesn't become a save to undo itself
+	m.confirm = nil
+	m, _ = update(t, m, documentSavedMsg{docID: id, newDoc: before, namespace: "shop.orders", before: after, undo: true})
+	if len(m.saveHistory) != 0 || !strings.Contains(m.statusMessage, "reverted") {
+		t.Errorf("after undo: history = %v, status = %q", m.saveHistory, m.statusMessage)
+	}
+	m = pressKey(m, "U")
+	if m.confirm != nil || !strings.Contains(m.statusMessage, "no saves of this document to undo") {
+		t.Errorf("nothing to undo: confirm = %v, status = %q", m.confirm != nil, m.statusMessage)
+	}
+}
+
+func TestUndoHistoryIsBounded(t *testing.T) {
+	m := newTestModel(120, 30)
+	for i := 0; i < maxUndoLevels+3; i++ {
+		m.recordSave("shop.orders", int32(1), bson.M{"n": int32(i)}, bson.M{"n": int32(i + 1)})
+	}
+	history := m.saveHistory[undoKey("shop.orders", int32(1))]
+	if len(history) != maxUndoLevels || history[0].before["n"] != int32(3) {
+		t.Errorf("%d levels, oldest before = %v", len(history), history[0].before)
+	}
+	m.dropLastSave("shop.orders", int32(1))
+	if got := m.saveHistory[undoKey("shop.orders", int32(1))]; len(got) != maxUndoLevels-1 || got[len(got)-1].after["n"] != int32(maxUndoLevels+2) {
+		t.Errorf("after dropping: %+v", got[len(got)-1])
+	}
+
+	// Leaving the connection forgets every save
+	m = pressKey(m, "b")
+	if len(m.saveHistory) != 0 {
+		t.Errorf("history kept across connections: %v", m.saveHistory)
+	}
+}
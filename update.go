@@ -0,0 +1,805 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// updateTemplate is what the update editor starts from
+const updateTemplate = `{
+  "$set": {}
+}
+`
+
+// updatePreviewSampleSize is how many matching documents a dry run previews
+const updatePreviewSampleSize = 5
+
+// previewOperators are the update operators the dry run simulates locally
+var previewOperators = map[string]bool{"$set": true, "$unset": true, "$inc": true, "$rename": true}
+
+// bulkUpdate is an UpdateMany waiting for its dry run or confirmation
+type bulkUpdate struct {
+	filter     bson.M
+	filterText string
+	update     interface{} // Operator document (bson.M) or update pipeline (bson.A)
+	updateText string
+}
+
+// updatePreviewMsg carries the sampled documents of a dry run before and
+// after the update. after is nil when the update can't be simulated.
+type updatePreviewMsg struct {
+	update      *bulkUpdate
+	before      []bson.M
+	after       []bson.M
+	errs        []error  // Per document: why the update couldn't be applied
+	unsupported []string // Operators the dry run can't simulate
+	err         error
+}
+
+// updateEditedMsg is sent when $EDITOR closes on an update
+type updateEditedMsg struct {
+	tempFile string
+	original string // What the file held before editing
+	err      error
+}
+
+// bulkUpdateDoneMsg reports the outcome of an UpdateMany
+type bulkUpdateDoneMsg struct {
+	matched  int64
+	modified int64
+	err      error
+}
+
+// newUpdateInput creates the update expression input of the bulk update prompt
+func newUpdateInput() textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = `{$set: {status: "archived"}}`
+	ti.CharLimit = 2000
+	ti.Width = 40
+	return ti
+}
+
+// parseUpdateExpression parses an update operator document or an update
+// pipeline typed in the same relaxed syntax as the query panel
+func parseUpdateExpression(text string) (interface{}, error) {
+	wrapper, err := parseQueryFilter(`{"u": ` + text + `}`)
+	if err != nil {
+		return nil, err
+	}
+	switch update := wrapper["u"].(type) {
+	case bson.M:
+		if len(update) == 0 {
+			return nil, errors.New("the update is empty")
+		}
+		for key, fields := range update {
+			if !strings.HasPrefix(key, "$") {
+				return nil, fmt.Errorf("%q isn't an update operator; use operators such as $set", key)
+			}
+			if fields, ok := fields.(bson.M); ok && len(fields) == 0 {
+				return nil, fmt.Errorf("%s has no fields to update", key)
+			}
+		}
+		return update, nil
+	case bson.A:
+		if len(update) == 0 {
+			return nil, errors.New("the update pipeline is empty")
+		}
+		for _, stage := range update {
+			stage, ok := stage.(bson.M)
+			if !ok || len(stage) != 1 {
+				return nil, errors.New("each pipeline stage must be a document with one stage operator")
+			}
+			for key := range stage {
+				if key == "$merge" || key == "$out" {
+					return nil, fmt.Errorf("%s isn't allowed in an update pipeline", key)
+				}
+			}
+		}
+		return update, nil
+	default:
+		return nil, errors.New("the update must be a document of operators or a pipeline array")
+	}
+}
+
+// unsupportedOperators returns the operators of an update the dry run can't
+// simulate, sorted
+func unsupportedOperators(update interface{}) []string {
+	ops, ok := update.(bson.M)
+	if !ok {
+		return nil // Pipelines run on the server
+	}
+	var unsupported []string
+	for op := range ops {
+		if !previewOperators[op] {
+			unsupported = append(unsupported, op)
+		}
+	}
+	sort.Strings(unsupported)
+	return unsupported
+}
+
+// copyDocument returns a deep copy of doc
+func copyDocument(doc bson.M) (bson.M, error) {
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var copied bson.M
+	err = bson.Unmarshal(data, &copied)
+	return copied, err
+}
+
+// lookupPath returns the value at a dotted path and whether it exists
+func lookupPath(doc bson.M, path string) (interface{}, bool) {
+	segments := strings.Split(path, ".")
+	current := doc
+	for _, segment := range segments[:len(segments)-1] {
+		next, ok := current[segment].(bson.M)
+		if !ok {
+			return nil, false
+		}
+		current = next
+	}
+	value, ok := current[segments[len(segments)-1]]
+	return value, ok
+}
+
+// parentForPath returns the document holding the last segment of path,
+// creating missing documents on the way when create is set
+func parentForPath(doc bson.M, path string, create bool) (bson.M, string, error) {
+	segments := strings.Split(path, ".")
+	current := doc
+	for _, segment := range segments[:len(segments)-1] {
+		switch next := current[segment].(type) {
+		case bson.M:
+			current = next
+		case nil:
+			if _, exists := current[segment]; exists || !create {
+				return nil, "", fmt.Errorf("can't preview %s: %s isn't a document", path, segment)
+			}
+			created := bson.M{}
+			current[segment] = created
+			current = created
+		default:
+			return nil, "", fmt.Errorf("can't preview %s: %s isn't a document", path, segment)
+		}
+	}
+	return current, segments[len(segments)-1], nil
+}
+
+// addNumbers adds an $inc amount to a value the way the server does:
+// integers stay integers, widening to 64 bits on overflow, and anything
+// with a double becomes a double
+func addNumbers(value, amount interface{}) (interface{}, error) {
+	toInt := func(v interface{}) (int64, bool) {
+		switch n := v.(type) {
+		case int32:
+			return int64(n), true
+		case int64:
+			return n, true
+		}
+		return 0, false
+	}
+	toFloat := func(v interface{}) (float64, bool) {
+		if n, ok := toInt(v); ok {
+			return float64(n), true
+		}
+		f, ok := v.(float64)
+		return f, ok
+	}
+
+	a, aInt := toInt(value)
+	b, bInt := toInt(amount)
+	if aInt && bInt {
+		sum := a + b
+		_, wasInt32 := value.(int32)
+		_, incInt32 := amount.(int32)
+		if wasInt32 && incInt32 && sum >= math.MinInt32 && sum <= math.MaxInt32 {
+			return int32(sum), nil
+		}
+		return sum, nil
+	}
+	fa, aNum := toFloat(value)
+	fb, bNum := toFloat(amount)
+	if !aNum || !bNum {
+		return nil, fmt.Errorf("can't preview $inc of %s by %s", bsonTypeName(value), bsonTypeName(amount))
+	}
+	return fa + fb, nil
+}
+
+// applyUpdate simulates an update of $set, $unset, $inc and $rename on a
+// copy of doc
+func applyUpdate(doc bson.M, update bson.M) (bson.M, error) {
+	result, err := copyDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	fields := func(op string) (bson.M, error) {
+		if update[op] == nil {
+			return nil, nil
+		}
+		args, ok := update[op].(bson.M)
+		if !ok {
+			return nil, fmt.Errorf("%s takes a document", op)
+		}
+		return args, nil
+	}
+
+	// Renames first, so the other operators see the renamed fields
+	renames, err := fields("$rename")
+	if err != nil {
+		return nil, err
+	}
+	for from, to := range renames {
+		target, ok := to.(string)
+		if !ok {
+			return nil, fmt.Errorf("$rename target of %s must be a string", from)
+		}
+		value, exists := lookupPath(result, from)
+		if !exists {
+			continue
+		}
+		parent, key, err := parentForPath(result, from, false)
+		if err != nil {
+			return nil, err
+		}
+		delete(parent, key)
+		if parent, key, err = parentForPath(result, target, true); err != nil {
+			return nil, err
+		}
+		parent[key] = value
+	}
+
+	unsets, err := fields("$unset")
+	if err != nil {
+		return nil, err
+	}
+	for path := range unsets {
+		if parent, key, err := parentForPath(result, path, false); err == nil {
+			delete(parent, key)
+		}
+	}
+
+	sets, err := fields("$set")
+	if err != nil {
+		return nil, err
+	}
+	for path, value := range sets {
+		parent, key, err := parentForPath(result, path, true)
+		if err != nil {
+			return nil, err
+		}
+		parent[key] = value
+	}
+
+	incs, err := fields("$inc")
+	if err != nil {
+		return nil, err
+	}
+	for path, amount := range incs {
+		parent, key, err := parentForPath(result, path, true)
+		if err != nil {
+			return nil, err
+		}
+		current, exists := parent[key]
+		if !exists {
+			current = int32(0)
+		}
+		sum, err := addNumbers(current, amount)
+		if err != nil {
+			return nil, err
+		}
+		parent[key] = sum
+	}
+	return result, nil
+}
+
+// pipelinePreview runs an update pipeline as an aggregation over the
+// sampled documents. Nothing is written: $merge and $out were rejected when
+// the pipeline was parsed.
+func pipelinePreview(ctx context.Context, coll *mongo.Collection, docs []bson.M, pipeline bson.A) ([]bson.M, error) {
+	ids := bson.A{}
+	for _, doc := range docs {
+		ids = append(ids, doc["_id"])
+	}
+	stages := append(bson.A{bson.M{"$match": bson.M{"_id": bson.M{"$in": ids}}}}, pipeline...)
+	cursor, err := coll.Aggregate(ctx, stages)
+	if err != nil {
+		return nil, err
+	}
+	var results []bson.M
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	// Line the results up with the sample; a pipeline may drop _id
+	after := make([]bson.M, len(docs))
+	for i, doc := range docs {
+		for _, result := range results {
+			if reflect.DeepEqual(result["_id"], doc["_id"]) {
+				after[i] = result
+			}
+		}
+		if after[i] == nil && i < len(results) {
+			after[i] = results[i]
+		}
+	}
+	return after, nil
+}
+
+// runUpdatePreview samples documents matching the update's filter and
+// computes what the update would turn them into
+func runUpdatePreview(client *mongo.Client, dbName, collName string, update *bulkUpdate) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		msg := updatePreviewMsg{update: update}
+		coll := client.Database(dbName).Collection(collName)
+		cursor, err := coll.Find(ctx, update.filter, options.Find().SetLimit(updatePreviewSampleSize))
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		if err := cursor.All(ctx, &msg.before); err != nil {
+			msg.err = err
+			return msg
+		}
+
+		switch u := update.update.(type) {
+		case bson.A:
+			msg.after, msg.err = pipelinePreview(ctx, coll, msg.before, u)
+		case bson.M:
+			if msg.unsupported = unsupportedOperators(u); len(msg.unsupported) > 0 {
+				return msg
+			}
+			msg.after = make([]bson.M, len(msg.before))
+			msg.errs = make([]error, len(msg.before))
+			for i, doc := range msg.before {
+				msg.after[i], msg.errs[i] = applyUpdate(doc, u)
+			}
+		}
+		return msg
+	}
+}
+
+// runBulkUpdate runs the confirmed UpdateMany as a job
+func runBulkUpdate(ctx context.Context, coll *mongo.Collection, update *bulkUpdate) (tea.Msg, error) {
+	result, err := coll.UpdateMany(ctx, update.filter, update.update)
+	if err != nil {
+		return bulkUpdateDoneMsg{err: err}, err
+	}
+	return bulkUpdateDoneMsg{matched: result.MatchedCount, modified: result.ModifiedCount}, nil
+}
+
+// describeWriteError formats a failed write for the error modal. Document
+// validation failures list the paths that broke the collection's schema.
+func describeWriteError(err error) string {
+	var writeErr mongo.WriteException
+	if !errors.As(err, &writeErr) {
+		return err.Error()
+	}
+	lines := []string{err.Error()}
+	var paths []string
+	for _, we := range writeErr.WriteErrors {
+		var details bson.M
+		if len(we.Details) == 0 || bson.Unmarshal(we.Details, &details) != nil {
+			continue
+		}
+		collectValidationPaths(details, "", &paths)
+	}
+	if len(paths) > 0 {
+		lines = append(lines, "", "Failing paths:")
+		for _, path := range paths {
+			lines = append(lines, "  "+path)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// collectValidationPaths walks the details of a document validation error
+// ($jsonSchema's propertiesNotSatisfied and missingProperties) and records
+// each failing field path with the reason, once
+func collectValidationPaths(value interface{}, path string, paths *[]string) {
+	add := func(line string) {
+		for _, existing := range *paths {
+			if existing == line {
+				return
+			}
+		}
+		*paths = append(*paths, line)
+	}
+	join := func(name string) string {
+		if path == "" {
+			return name
+		}
+		return path + "." + name
+	}
+
+	switch v := value.(type) {
+	case bson.M:
+		if name, ok := v["propertyName"].(string); ok {
+			path = join(name)
+		}
+		if missing, ok := v["missingProperties"].(bson.A); ok {
+			for _, name := range missing {
+				if name, ok := name.(string); ok {
+					add(join(name) + ": required but missing")
+				}
+			}
+		}
+		if reason, ok := v["reason"].(string); ok && path != "" {
+			if op, ok := v["operatorName"].(string); ok {
+				reason += " (" + op + ")"
+			}
+			add(path + ": " + reason)
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			collectValidationPaths(v[key], path, paths)
+		}
+	case bson.A:
+		for _, item := range v {
+			collectValidationPaths(item, path, paths)
+		}
+	}
+}
+
+// flattenPaths collects the leaf values of doc by dotted path. Arrays are
+// compared as whole values.
+func flattenPaths(doc bson.M, prefix string, paths map[string]interface{}) {
+	for key, value := range doc {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if nested, ok := value.(bson.M); ok && len(nested) > 0 {
+			flattenPaths(nested, path, paths)
+			continue
+		}
+		paths[path] = value
+	}
+}
+
+// previewValue renders a value on one line for the dry run diff
+func previewValue(value interface{}) string {
+	switch value.(type) {
+	case bson.M, bson.A:
+		text, err := bson.MarshalExtJSON(bson.M{"v": value}, false, false)
+		if err != nil {
+			return fmt.Sprintf("%v", value)
+		}
+		return strings.TrimSuffix(strings.TrimPrefix(string(text), `{"v":`), "}")
+	}
+	return stripANSI(formatValue(value))
+}
+
+// documentDiff returns one line per field the update adds (+), removes (-)
+// or changes (~), sorted by path
+func documentDiff(before, after bson.M) []string {
+	old, updated := map[string]interface{}{}, map[string]interface{}{}
+	flattenPaths(before, "", old)
+	flattenPaths(after, "", updated)
+
+	paths := make([]string, 0, len(old)+len(updated))
+	for path := range old {
+		paths = append(paths, path)
+	}
+	for path := range updated {
+		if _, ok := old[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	var lines []string
+	for _, path := range paths {
+		was, hadIt := old[path]
+		now, hasIt := updated[path]
+		switch {
+		case !hadIt:
+			lines = append(lines, fmt.Sprintf("  + %s: %s", path, previewValue(now)))
+		case !hasIt:
+			lines = append(lines, fmt.Sprintf("  - %s: %s", path, previewValue(was)))
+		case !reflect.DeepEqual(was, now):
+			lines = append(lines, fmt.Sprintf("  ~ %s: %s → %s", path, previewValue(was), previewValue(now)))
+		}
+	}
+	return lines
+}
+
+// updatePreviewText renders the dry run as the text of the preview viewer
+func updatePreviewText(msg updatePreviewMsg) string {
+	lines := []string{
+		"Filter: " + msg.update.filterText,
+		"Update: " + msg.update.updateText,
+		"",
+	}
+	if len(msg.before) == 0 {
+		return strings.Join(append(lines, "No documents match the filter; the update would change nothing."), "\n")
+	}
+	if len(msg.unsupported) > 0 {
+		lines = append(lines,
+			fmt.Sprintf("Can't preview %s: only $set, $unset, $inc, $rename and pipeline updates are simulated.", strings.Join(msg.unsupported, ", ")),
+			"The update can still be run, but its effect isn't shown here.",
+		)
+		return strings.Join(lines, "\n")
+	}
+	lines = append(lines, fmt.Sprintf("Changes to %d sampled matching documents:", len(msg.before)))
+	for i, before := range msg.before {
+		lines = append(lines, "", "_id: "+previewValue(before["_id"]))
+		if i < len(msg.errs) && msg.errs[i] != nil {
+			lines = append(lines, "  "+msg.errs[i].Error())
+			continue
+		}
+		diff := documentDiff(before, msg.after[i])
+		if len(diff) == 0 {
+			diff = []string{"  (unchanged)"}
+		}
+		lines = append(lines, diff...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// openUpdatePrompt opens the prompt for an update of every document matching
+// the current query
+func (m *Model) openUpdatePrompt() tea.Cmd {
+	if m.client == nil || m.selectedCollection == "" || m.schemaActive || m.pinboardActive {
+		return nil
+	}
+	if m.docProvenance == ProvenanceAggregated {
+		return m.setStatus("can't update the output of a pipeline")
+	}
+	if cmd := m.guardWritable(m.selectedCollection); cmd != nil {
+		return cmd
+	}
+	m.updatePromptActive = true
+	m.updateInput.Focus()
+	return textinput.Blink
+}
+
+// handleUpdatePromptKey handles keyboard input in the bulk update prompt
+func (m *Model) handleUpdatePromptKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "ctrl+g":
+		m.updatePromptActive = false
+		m.updateInput.Blur()
+		return nil
+	case "enter":
+		text := strings.TrimSpace(m.updateInput.Value())
+		if _, err := parseUpdateExpression(text); err != nil {
+			m.errorModal = true
+			m.errorMessage = fmt.Sprintf("Invalid update: %v", err)
+			return nil
+		}
+		m.updatePromptActive = false
+		m.updateInput.Blur()
+		return m.previewUpdate(text)
+	case "ctrl+e":
+		// Continue in $EDITOR, for updates too long for one line
+		m.updatePromptActive = false
+		m.updateInput.Blur()
+		return m.openUpdateEditor(strings.TrimSpace(m.updateInput.Value()))
+	default:
+		var cmd tea.Cmd
+		m.updateInput, cmd = m.updateInput.Update(msg)
+		return cmd
+	}
+}
+
+// previewUpdate starts the dry run of an update of every document matching
+// the current query; confirming the preview runs it
+func (m *Model) previewUpdate(text string) tea.Cmd {
+	update, err := parseUpdateExpression(text)
+	if err != nil {
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Invalid update: %v", err)
+		return nil
+	}
+	if _, pipeline := update.(bson.A); pipeline {
+		if err := m.serverVersion.check(FeaturePipelineUpdate); err != nil {
+			m.errorModal = true
+			m.errorMessage = fmt.Sprintf("Can't run this update: %v. Use update operators such as $set instead.", err)
+			return nil
+		}
+	}
+	m.pendingUpdate = &bulkUpdate{
+		filter:     m.queryFilter,
+		filterText: m.queryText,
+		update:     update,
+		updateText: text,
+	}
+	return tea.Batch(
+		m.setStatus("previewing update..."),
+		runUpdatePreview(m.client, m.selectedDatabase, m.selectedCollection, m.pendingUpdate),
+	)
+}
+
+// openUpdateEditor writes an update to a temp file and opens it in $EDITOR,
+// starting from the $set template when text is empty
+func (m *Model) openUpdateEditor(text string) tea.Cmd {
+	if m.client == nil || m.selectedCollection == "" || m.schemaActive || m.pinboardActive {
+		return nil
+	}
+	if m.docProvenance == ProvenanceAggregated {
+		return m.setStatus("can't update the output of a pipeline")
+	}
+	if cmd := m.guardWritable(m.selectedCollection); cmd != nil {
+		return cmd
+	}
+	if text == "" {
+		text = updateTemplate
+	}
+	tmpFile, err := os.CreateTemp("", "mbongo-update-*.json")
+	if err != nil {
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Failed to create the update file: %v", err)
+		return nil
+	}
+	_, err = tmpFile.WriteString(text)
+	tmpFile.Close()
+	if err != nil {
+		os.Remove(tmpFile.Name())
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Failed to write the update file: %v", err)
+		return nil
+	}
+
+	m.editorActive = true
+	path := tmpFile.Name()
+	return tea.ExecProcess(editorCommand(path), func(err error) tea.Msg {
+		return updateEditedMsg{tempFile: path, original: text, err: err}
+	})
+}
+
+// handleUpdateEdited validates the update written in $EDITOR and starts its
+// dry run. Leaving the template or the file unchanged cancels.
+func (m *Model) handleUpdateEdited(msg updateEditedMsg) tea.Cmd {
+	m.editorActive = false
+	defer os.Remove(msg.tempFile)
+	if msg.err != nil {
+		if exit, ok := editorExit(msg.err); ok {
+			return m.setStatus(fmt.Sprintf("editor %s — update discarded, nothing was updated", exit))
+		}
+		m.errorModal = true
+		m.errorMessage = editorErrorMessage(msg.err)
+		return nil
+	}
+	data, err := os.ReadFile(msg.tempFile)
+	if err != nil {
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Failed to read the update file: %v", err)
+		return nil
+	}
+	text := strings.TrimSpace(string(data))
+	if text == "" || text == strings.TrimSpace(updateTemplate) || text == strings.TrimSpace(msg.original) {
+		return m.setStatus("update cancelled: nothing was changed")
+	}
+	if _, err := parseUpdateExpression(text); err != nil {
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Invalid update: %v\n\nNothing was updated.", err)
+		return nil
+	}
+	// Keep it in the prompt so a failed update can be retried and adjusted
+	m.updateInput.SetValue(strings.Join(strings.Fields(text), " "))
+	m.updateInput.CursorEnd()
+	return m.previewUpdate(text)
+}
+
+// handleUpdatePreview shows the dry run of the pending update
+func (m *Model) handleUpdatePreview(msg updatePreviewMsg) {
+	if msg.update != m.pendingUpdate {
+		return
+	}
+	m.statusMessage = ""
+	if msg.err != nil {
+		m.pendingUpdate = nil
+		m.errorModal = true
+		m.errorMessage = fmt.Sprintf("Update preview failed: %v", msg.err)
+		return
+	}
+	m.openViewer(ViewerUpdatePreview, "Dry run of update", updatePreviewText(msg))
+}
+
+// updateScopes are the scopes a bulk update can be confirmed for
+var updateScopes = []BulkScope{ScopePage, ScopePinned, ScopeFilter}
+
+// confirmBulkUpdate asks to confirm the previewed update, naming the
+// collection and its scope, every matching document unless switched to the
+// page or the pinned documents, with how many documents that matches
+func (m *Model) confirmBulkUpdate() tea.Cmd {
+	update := m.pendingUpdate
+	if update == nil {
+		return nil
+	}
+	c := &confirmation{
+		title:       "Update Documents",
+		message:     fmt.Sprintf("Apply %s to documents of %s?", update.updateText, m.targetLabel(m.selectedDatabase, m.selectedCollection)),
+		severity:    SeverityWarning,
+		scopes:      updateScopes,
+		destructive: true,
+	}
+	c.onConfirm = func(m *Model) tea.Cmd {
+		m.pendingUpdate = nil
+		target := c.target
+		scoped := *update
+		scoped.filter = target.filter
+		coll := m.client.Database(m.selectedDatabase).Collection(m.selectedCollection)
+		return m.startBulkJob("update "+namespaceOf(m.selectedDatabase, m.selectedCollection), target, func(ctx context.Context, report func(string)) (tea.Msg, error) {
+			report("updating " + target.describe())
+			return runBulkUpdate(ctx, coll, &scoped)
+		})
+	}
+	target := bulkTarget{scope: ScopeFilter, count: m.totalDocs, filter: update.filter}
+	if target.filter == nil {
+		target.filter = bson.M{}
+	}
+	return tea.Batch(m.openConfirm(c), m.setConfirmTarget(c, target))
+}
+
+// handleBulkUpdateDone reports the outcome of an UpdateMany and reloads the page
+func (m *Model) handleBulkUpdateDone(msg bulkUpdateDoneMsg) tea.Cmd {
+	if msg.err != nil {
+		m.errorModal = true
+		m.errorMessage = "Update failed: " + describeWriteError(msg.err)
+		return nil
+	}
+	return tea.Batch(
+		m.setStatus(fmt.Sprintf("updated %d of %d matching documents", msg.modified, msg.matched)),
+		loadDocuments(m.client, m.selectedDatabase, m.selectedCollection, m.currentPage, m.docsPerPage, m.queryFilter, m.sortSpec()),
+	)
+}
+
+// renderUpdatePrompt renders the bulk update prompt modal
+func (m Model) renderUpdatePrompt(background string) string {
+	width := m.modalWidth(70)
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render("Update documents in "+m.selectedCollection),
+		"",
+		truncate("Filter: "+m.queryText, width-4),
+		"",
+		"Update operators or pipeline:",
+		fitInput(m.updateInput, width-4),
+		"",
+		hintStyle.Render("enter: dry run • ctrl+e: edit in $EDITOR • esc: cancel"),
+	)
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("205")).
+		Padding(1, 2).
+		Width(width)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(content),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
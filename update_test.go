@@ -0,0 +1,147 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestParseUpdateExpression(t *testing.T) {
+	if _, err := parseUpdateExpression(`{$set: {status: 'archived'}}`); err != nil {
+		t.Errorf("operator update: %v", err)
+	}
+	if u, err := parseUpdateExpression(`[{$set: {total: {$add: ["$a", "$b"]}}}]`); err != nil {
+		t.Errorf("pipeline update: %v", err)
+	} else if _, ok := u.(bson.A); !ok {
+		t.Errorf("pipeline parsed as %T", u)
+	}
+	for _, text := range []string{`{status: "archived"}`, `{}`, `{$set: {}}`, `[{$merge: {into: "x"}}]`, `"x"`} {
+		if _, err := parseUpdateExpression(text); err == nil {
+			t.Errorf("parseUpdateExpression(%s) accepted", text)
+		}
+	}
+}
+
+func TestApplyUpdate(t *testing.T) {
+	doc := bson.M{"_id": int32(1), "status": "new", "count": int32(2), "tmp": true, "old": "x", "address": bson.M{"city": "Paris"}}
+	update := bson.M{
+		"$set":    bson.M{"status": "archived", "address.zip": "75001", "meta.by": "me"},
+		"$unset":  bson.M{"tmp": ""},
+		"$inc":    bson.M{"count": int32(3), "visits": int64(1)},
+		"$rename": bson.M{"old": "legacy"},
+	}
+	got, err := applyUpdate(doc, update)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := bson.M{
+		"_id": int32(1), "status": "archived", "count": int32(5), "visits": int64(1), "legacy": "x",
+		"address": bson.M{"city": "Paris", "zip": "75001"}, "meta": bson.M{"by": "me"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("applyUpdate = %v, want %v", got, want)
+	}
+	if doc["status"] != "new" {
+		t.Error("applyUpdate changed the original document")
+	}
+	if _, err := applyUpdate(doc, bson.M{"$inc": bson.M{"status": 1}}); err == nil {
+		t.Error("$inc of a string was previewed")
+	}
+
+	diff := documentDiff(doc, got)
+	wantDiff := []string{
+		`  + address.zip: "75001"`,
+		`  ~ count: 2 → 5`,
+		`  + legacy: "x"`,
+		`  + meta.by: "me"`,
+		`  - old: "x"`,
+		`  ~ status: "new" → "archived"`,
+		`  - tmp: true`,
+		`  + visits: 1`,
+	}
+	if !reflect.DeepEqual(diff, wantDiff) {
+		t.Errorf("documentDiff =\n%s\nwant\n%s", strings.Join(diff, "\n"), strings.Join(wantDiff, "\n"))
+	}
+}
+
+func TestUpdatePreviewNamesUnsupportedOperators(t *testing.T) {
+	msg := updatePreviewMsg{
+		update:      &bulkUpdate{filterText: "{}", updateText: `{$push: {tags: "x"}}`},
+		before:      []bson.M{{"_id": 1}},
+		unsupported: unsupportedOperators(bson.M{"$push": bson.M{"tags": "x"}, "$set": bson.M{"a": 1}}),
+	}
+	if text := updatePreviewText(msg); !strings.Contains(text, "Can't preview $push") || strings.Contains(text, "_id:") {
+		t.Errorf("preview of an unsupported operator:\n%s", text)
+	}
+}
+
+func TestDescribeWriteErrorListsValidationPaths(t *testing.T) {
+	details, _ := bson.Marshal(bson.M{
+		"operatorName": "$jsonSchema",
+		"schemaRulesNotSatisfied": bson.A{
+			bson.M{"operatorName": "properties", "propertiesNotSatisfied": bson.A{
+				bson.M{"propertyName": "age", "details": bson.A{
+					bson.M{"operatorName": "minimum", "reason": "comparison failed", "consideredValue": int32(-1)},
+				}},
+				bson.M{"propertyName": "address", "details": bson.A{
+					bson.M{"operatorName": "required", "missingProperties": bson.A{"zip"}},
+				}},
+			}},
+			bson.M{"operatorName": "required", "missingProperties": bson.A{"email"}},
+		},
+	})
+	err := mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 121, Message: "Document failed validation", Details: details}}}
+
+	got := describeWriteError(err)
+	for _, want := range []string{
+		"Document failed validation",
+		"Failing paths:",
+		"  age: comparison failed (minimum)",
+		"  address.zip: required but missing",
+		"  email: required but missing",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	if plain := describeWriteError(errors.New("boom")); plain != "boom" {
+		t.Errorf("plain error = %q", plain)
+	}
+}
+
+func TestUpdateEditorStartsDryRun(t *testing.T) {
+	m := newTestModel(120, 30)
+	m.client = offlineClient(t)
+	edited := func(text, original string) Model {
+		path := filepath.Join(t.TempDir(), "update.json")
+		os.WriteFile(path, []byte(text), 0600)
+		m := m
+		m.editorActive = true
+		m.handleUpdateEdited(updateEditedMsg{tempFile: path, original: original})
+		return m
+	}
+
+	if m := edited(updateTemplate, updateTemplate); m.pendingUpdate != nil || m.editorActive || !strings.Contains(m.statusMessage, "cancelled") {
+		t.Errorf("unchanged template: pending = %v, status = %q", m.pendingUpdate, m.statusMessage)
+	}
+	if m := edited(`{"status": "archived"}`, updateTemplate); m.pendingUpdate != nil || !strings.Contains(m.errorMessage, "isn't an update operator") || !strings.Contains(m.errorMessage, "Nothing was updated") {
+		t.Errorf("replacement document: pending = %v, error = %q", m.pendingUpdate, m.errorMessage)
+	}
+
+	m = edited("{\n  \"$set\": {\"status\": \"archived\"}\n}\n", updateTemplate)
+	if m.pendingUpdate == nil || m.errorModal {
+		t.Fatalf("valid update: pending = %v, error = %q", m.pendingUpdate, m.errorMessage)
+	}
+	if got := m.pendingUpdate.update; !reflect.DeepEqual(got, bson.M{"$set": bson.M{"status": "archived"}}) {
+		t.Errorf("update = %v", got)
+	}
+	if m.updateInput.Value() != `{ "$set": {"status": "archived"} }` {
+		t.Errorf("prompt keeps %q", m.updateInput.Value())
+	}
+}
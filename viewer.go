@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ViewerKind identifies what the text viewer overlay is showing, which
+// determines the extra keys it accepts
+type ViewerKind int
+
+const (
+	ViewerNone ViewerKind = iota
+	ViewerReproBundle
+	ViewerImportSummary
+	ViewerSchemaMarkdown
+	ViewerUpdatePreview
+	ViewerBinary
+	ViewerDatabaseSummary
+	ViewerServerDocument
+	ViewerBenchmark
+	ViewerCollectionInfo
+	ViewerServerInfo
+)
+
+// openViewer shows a scrollable text overlay
+func (m *Model) openViewer(kind ViewerKind, title, text string) {
+	m.viewerKind = kind
+	m.viewerTitle = title
+	m.viewerText = text
+	m.viewerLines = strings.Split(strings.TrimRight(text, "\n"), "\n")
+	m.viewerScroll = 0
+}
+
+// closeViewer hides the text viewer overlay
+func (m *Model) closeViewer() {
+	m.viewerKind = ViewerNone
+	m.viewerTitle = ""
+	m.viewerText = ""
+	m.viewerLines = nil
+	m.viewerScroll = 0
+	m.viewerSavePath = ""
+	m.viewerBinary = nil
+}
+
+// getViewerSize returns the modal width and the number of visible content lines
+func (m Model) getViewerSize() (int, int) {
+	width := m.width - 10
+	if width < 30 {
+		width = 30
+	}
+	// Border (2) + padding (2) + title and blank (2) + blank and hint (2)
+	height := m.height - 4 - 8
+	if height < 3 {
+		height = 3
+	}
+	return width, height
+}
+
+// scrollViewer moves the viewer scroll offset by delta, clamped to the content
+func (m *Model) scrollViewer(delta int) {
+	_, visible := m.getViewerSize()
+	m.viewerScroll = clampScroll(m.viewerScroll+delta, len(m.viewerLines), visible)
+}
+
+// handleViewerKey handles keyboard input while the text viewer is open
+func (m *Model) handleViewerKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	_, visible := m.getViewerSize()
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit, true
+	case "esc", "ctrl+g", "q":
+		if m.viewerKind == ViewerUpdatePreview {
+			m.pendingUpdate = nil
+		}
+		m.closeViewer()
+	case "up", "k", "ctrl+p":
+		m.scrollViewer(-1)
+	case "down", "j", "ctrl+n":
+		m.scrollViewer(1)
+	case "ctrl+v":
+		m.scrollViewer(visible / 2)
+	case "alt+v":
+		m.scrollViewer(-visible / 2)
+	case "pgup", "pgdown", "home", "end":
+		m.viewerScroll, _ = pageKeyTarget(msg.String(), m.viewerScroll, visible, len(m.viewerLines)-visible+1)
+	case "enter", "y":
+		if m.viewerKind == ViewerUpdatePreview {
+			m.closeViewer()
+			return m.confirmBulkUpdate(), true
+		}
+		if m.viewerKind == ViewerReproBundle || m.viewerKind == ViewerSchemaMarkdown {
+			title, text := m.viewerTitle, m.viewerText
+			m.closeViewer()
+			return m.yank(title, text), true
+		}
+	case "r":
+		if m.viewerKind == ViewerServerInfo && m.client != nil {
+			return loadServerInfo(m.client), true
+		}
+	case "w":
+		if m.viewerKind == ViewerSchemaMarkdown {
+			return m.saveViewerText(), true
+		}
+		if m.viewerKind == ViewerBinary {
+			return m.saveViewerBinary(), true
+		}
+	}
+	return nil, true
+}
+
+// viewerHint returns the help line for the current viewer kind
+func (m Model) viewerHint() string {
+	switch m.viewerKind {
+	case ViewerReproBundle:
+		return "enter/y: copy to clipboard • ↑/↓: scroll • esc: cancel"
+	case ViewerUpdatePreview:
+		return "enter/y: run the update • ↑/↓: scroll • esc: cancel"
+	case ViewerSchemaMarkdown:
+		return fmt.Sprintf("enter/y: copy to clipboard • w: write %s • ↑/↓: scroll • esc: close", m.viewerSavePath)
+	case ViewerBinary:
+		return fmt.Sprintf("w: write the bytes to %s • ↑/↓: scroll • esc: close", m.viewerSavePath)
+	case ViewerServerInfo:
+		return "r: refresh • ↑/↓: scroll • esc: close"
+	default:
+		return "↑/↓: scroll • esc: close"
+	}
+}
+
+// renderViewer renders the text viewer overlay centered on screen
+func (m Model) renderViewer(background string) string {
+	width, visible := m.getViewerSize()
+	contentWidth := width - 4
+
+	end := m.viewerScroll + visible
+	if end > len(m.viewerLines) {
+		end = len(m.viewerLines)
+	}
+	var lines []string
+	for _, line := range m.viewerLines[m.viewerScroll:end] {
+		lines = append(lines, truncate(line, contentWidth))
+	}
+
+	title := m.viewerTitle
+	if len(m.viewerLines) > visible {
+		title = fmt.Sprintf("%s (%d-%d of %d)", title, m.viewerScroll+1, end, len(m.viewerLines))
+	}
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render(title),
+		"",
+		strings.Join(lines, "\n"),
+		"",
+		hintStyle.Render(m.viewerHint()),
+	)
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("205")).
+		Padding(1, 2).
+		Width(width)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(content),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// lastConnectionSetting names the connection most recently connected to
+const lastConnectionSetting = "last_connection"
+
+// warmupSetting is "off" when connections shouldn't be opened before they're
+// selected, for metered links or SSH hosts that cost per session
+const warmupSetting = "connection_warmup"
+
+// connectionWarmup is the most recently used connection being opened in the
+// background while the connections screen is showing, so selecting it is
+// instant
+type connectionWarmup struct {
+	seq    int
+	conn   Connection
+	cancel context.CancelFunc
+	done   bool
+	adopt  bool // Selected while still warming up: connect through it once done
+	// Set once done
+	tunnel    *SSHTunnel
+	client    *mongo.Client
+	connStr   string
+	databases []string
+	err       error // Kept quiet unless the connection is selected
+}
+
+// warmupDoneMsg is sent when a warm-up has connected, or failed to
+type warmupDoneMsg struct {
+	seq       int
+	tunnel    *SSHTunnel
+	client    *mongo.Client
+	connStr   string
+	databases []string
+	err       error
+}
+
+// startWarmup starts opening the most recently used connection, unless
+// warm-up is off or the connection asks for its password
+func (m *Model) startWarmup() tea.Cmd {
+	m.cancelWarmup()
+	if m.warmupOff || m.lastConnection == "" {
+		return nil
+	}
+	for _, conn := range m.connections {
+		if conn.Name != m.lastConnection || needsPassword(conn.ConnectionString) {
+			continue
+		}
+		m.warmupSeq++
+		ctx, cancel := context.WithCancel(context.Background())
+		m.warmup = &connectionWarmup{seq: m.warmupSeq, conn: conn, cancel: cancel}
+		return warmUp(ctx, m.warmupSeq, conn)
+	}
+	return nil
+}
+
+// warmUp opens the tunnel and client and lists the databases, the way
+// selecting the connection does. Whatever it opened is closed again if the
+// warm-up is cancelled meanwhile.
+func warmUp(ctx context.Context, seq int, conn Connection) tea.Cmd {
+	return func() tea.Msg {
+		client, tunnel, connStr, err := openConnection(ctx, conn)
+		if err != nil {
+			return warmupDoneMsg{seq: seq, err: err}
+		}
+		databases, err := client.ListDatabaseNames(ctx, map[string]interface{}{})
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			closeConnection(client, tunnel)
+			return warmupDoneMsg{seq: seq, err: err}
+		}
+		return warmupDoneMsg{seq: seq, tunnel: tunnel, client: client, connStr: connStr, databases: databases}
+	}
+}
+
+// cancelWarmup stops the warm-up in progress and closes what it opened
+func (m *Model) cancelWarmup() {
+	w := m.warmup
+	if w == nil {
+		return
+	}
+	w.cancel()
+	if w.done {
+		closeConnection(w.client, w.tunnel)
+	}
+	m.warmup = nil
+}
+
+// handleWarmupDone keeps a finished warm-up for when its connection is
+// selected, or connects through it if it already was
+func (m *Model) handleWarmupDone(msg warmupDoneMsg) tea.Cmd {
+	w := m.warmup
+	if w == nil || w.seq != msg.seq {
+		// Cancelled after it finished connecting
+		closeConnection(msg.client, msg.tunnel)
+		return nil
+	}
+	w.done = true
+	w.tunnel, w.client, w.connStr, w.databases, w.err = msg.tunnel, msg.client, msg.connStr, msg.databases, msg.err
+	if !w.adopt {
+		return nil
+	}
+	return m.adoptWarmup()
+}
+
+// takeWarmup connects to the selected connection through the warm-up when
+// it is for that connection, and cancels it otherwise. It reports whether
+// the warm-up is used.
+func (m *Model) takeWarmup() (tea.Cmd, bool) {
+	w := m.warmup
+	if w == nil {
+		return nil, false
+	}
+	if w.conn.Name != m.connectionName || w.conn.ConnectionString != m.connectionString || w.conn.SSHAlias != m.sshAlias {
+		m.cancelWarmup()
+		return nil, false
+	}
+	if !w.done {
+		w.adopt = true
+		return nil, true
+	}
+	if w.err != nil {
+		// Try again rather than report what may have been a passing failure
+		m.cancelWarmup()
+		return nil, false
+	}
+	return m.adoptWarmup(), true
+}
+
+// adoptWarmup hands the finished warm-up's tunnel and client over to the
+// main screen
+func (m *Model) adoptWarmup() tea.Cmd {
+	w := m.warmup
+	m.warmup = nil
+	if w.err != nil {
+		m.loading = false
+		m.err = w.err
+		return nil
+	}
+	m.sshTunnel = w.tunnel
+	m.activeConnString = w.connStr
+	return func() tea.Msg {
+		return databasesLoadedMsg{databases: w.databases, client: w.client}
+	}
+}
+
+// toggleWarmup turns connection warm-up on or off and remembers the choice
+func (m *Model) toggleWarmup() tea.Cmd {
+	m.warmupOff = !m.warmupOff
+	if m.warmupOff {
+		saveSetting(warmupSetting, "off")
+		m.cancelWarmup()
+		return nil
+	}
+	saveSetting(warmupSetting, "on")
+	return m.startWarmup()
+}
+
+// warmupLabel returns the warm-up state shown next to a connection
+func (m Model) warmupLabel(conn Connection) string {
+	w := m.warmup
+	if w == nil || w.conn.Name != conn.Name {
+		return ""
+	}
+	switch {
+	case !w.done:
+		return "connecting…"
+	case w.err == nil:
+		return "ready"
+	}
+	return ""
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func newWarmupModel(t *testing.T) Model {
+	t.Setenv("HOME", t.TempDir())
+	if err := initDB(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		closeDB()
+		db = nil
+	})
+	m := newTestModel(120, 30)
+	m.screen = ScreenConnections
+	m.connections = mergeConnections([]Connection{{Name: "prod", ConnectionString: "mongodb://prod.example.com"}})
+	m.updateFilteredConnections()
+	m.lastConnection = "prod"
+	return m
+}
+
+// fakeWarmup stands in for a warm-up of the named connection, returning the
+// context it would run under
+func fakeWarmup(m *Model, name string) context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.warmup = nil
+	for _, conn := range m.connections {
+		if conn.Name == name {
+			m.warmupSeq++
+			m.warmup = &connectionWarmup{seq: m.warmupSeq, conn: conn, cancel: cancel}
+			return ctx
+		}
+	}
+	cancel()
+	return ctx
+}
+
+func selectConnection(t *testing.T, m Model, name string) (Model, tea.Cmd) {
+	for i, conn := range m.connFiltered {
+		if conn.Name == name {
+			m.connCursor = i
+		}
+	}
+	return update(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+}
+
+func TestWarmupStartsForLastConnection(t *testing.T) {
+	m := newWarmupModel(t)
+	if cmd := m.startWarmup(); cmd == nil || m.warmup == nil || m.warmup.conn.Name != "prod" {
+		t.Fatalf("warmup = %+v", m.warmup)
+	}
+	if view := normalizeRender(m.View()); !strings.Contains(view, "prod (connecting…)") {
+		t.Errorf("connections view:\n%s", view)
+	}
+	m.cancelWarmup()
+
+	m.warmupOff = true
+	if cmd := m.startWarmup(); cmd != nil || m.warmup != nil {
+		t.Error("warm-up started while turned off")
+	}
+}
+
+func TestSelectingAnotherConnectionCancelsWarmup(t *testing.T) {
+	m := newWarmupModel(t)
+	ctx := fakeWarmup(&m, "prod")
+	m, _ = selectConnection(t, m, "localhost")
+	if m.warmup != nil || ctx.Err() == nil {
+		t.Errorf("warm-up not cancelled: %+v", m.warmup)
+	}
+	if last, _ := loadSetting(lastConnectionSetting); last != "localhost" {
+		t.Errorf("last connection = %q", last)
+	}
+
+	// Its result arriving afterwards is dropped
+	m, _ = update(t, m, warmupDoneMsg{seq: m.warmupSeq, client: offlineClient(t), databases: []string{"shop"}})
+	if m.warmup != nil || m.client != nil {
+		t.Errorf("cancelled warm-up used: client = %v", m.client)
+	}
+}
+
+func TestSelectingWarmingConnectionWaitsForIt(t *testing.T) {
+	m := newWarmupModel(t)
+	fakeWarmup(&m, "prod")
+	m, cmd := selectConnection(t, m, "prod")
+	if cmd != nil || m.warmup == nil || !m.warmup.adopt || !m.loading {
+		t.Fatalf("selecting the warming connection: cmd = %v, warmup = %+v", cmd, m.warmup)
+	}
+
+	client := offlineClient(t)
+	m, cmd = update(t, m, warmupDoneMsg{seq: m.warmupSeq, client: client, connStr: "mongodb://prod.example.com", databases: []string{"shop"}})
+	if cmd == nil {
+		t.Fatal("finished warm-up not taken over")
+	}
+	m, _ = update(t, m, cmd())
+	if m.client != client || m.activeConnString != "mongodb://prod.example.com" || len(m.databases) != 1 {
+		t.Errorf("client = %v, conn string %q, databases %v", m.client, m.activeConnString, m.databases)
+	}
+}
+
+func TestWarmupFailureIsQuietUntilSelected(t *testing.T) {
+	m := newWarmupModel(t)
+	fakeWarmup(&m, "prod")
+	m, _ = update(t, m, warmupDoneMsg{seq: m.warmupSeq, err: errors.New("connection refused")})
+	if m.err != nil || strings.Contains(normalizeRender(m.View()), "refused") {
+		t.Errorf("warm-up failure shown before selecting: %v", m.err)
+	}
+
+	fakeWarmup(&m, "prod")
+	m, _ = selectConnection(t, m, "prod")
+	m, _ = update(t, m, warmupDoneMsg{seq: m.warmupSeq, err: errors.New("connection refused")})
+	if m.err == nil || m.loading {
+		t.Errorf("failure of the selected connection: err = %v, loading = %v", m.err, m.loading)
+	}
+}
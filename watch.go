@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// watchPollInterval is how often watched namespaces are counted
+const watchPollInterval = 30 * time.Second
+
+// Watch is a namespace subscribed to for count-change notifications
+type Watch struct {
+	Namespace string // "db.collection"
+	Delta     int64  // Notify when the count changes by more than this
+}
+
+// namespaceOf joins a database and collection name
+func namespaceOf(dbName, collName string) string {
+	return dbName + "." + collName
+}
+
+// splitNamespace splits "db.collection" at the first dot
+func splitNamespace(ns string) (string, string) {
+	dbName, collName, _ := strings.Cut(ns, ".")
+	return dbName, collName
+}
+
+// findWatch returns the index of the watch for namespace, or -1
+func (m Model) findWatch(ns string) int {
+	for i, w := range m.watches {
+		if w.Namespace == ns {
+			return i
+		}
+	}
+	return -1
+}
+
+// startWatchPolling loads the watches for the active connection and starts the poll loop
+func (m *Model) startWatchPolling() tea.Cmd {
+	watches, err := loadWatches(m.connectionName)
+	if err != nil {
+		return nil
+	}
+	m.watches = watches
+	m.watchBaselines = make(map[string]int64)
+	m.watchBadges = make(map[string]int64)
+	m.watchPollSeq++
+	return tea.Batch(pollWatches(m.client, m.pollableWatches()), scheduleWatchPoll(m.watchPollSeq))
+}
+
+// scheduleWatchPoll schedules the next poll tick
+func scheduleWatchPoll(seq int) tea.Cmd {
+	return tea.Tick(watchPollInterval, func(time.Time) tea.Msg {
+		return watchTickMsg{seq: seq}
+	})
+}
+
+// watchPollPaused reports whether polling should be skipped because the user
+// is busy in a modal or the editor
+func (m Model) watchPollPaused() bool {
+	return m.errorModal || m.authPromptActive || m.watchPromptActive ||
+		m.viewerKind != ViewerNone || m.editorActive || serverCommands.isPaused()
+}
+
+// pollableWatches returns the watches on collections that are readable, so
+// polling doesn't keep hitting collections access was denied on
+func (m Model) pollableWatches() []Watch {
+	var watches []Watch
+	for _, w := range m.watches {
+		if !m.deniedNamespaces[w.Namespace] {
+			watches = append(watches, w)
+		}
+	}
+	return watches
+}
+
+// pollWatches fetches the estimated count of every watched namespace, each
+// in a slot of the server limiter. Namespaces that don't get one in time are
+// left for the next poll.
+func pollWatches(client *mongo.Client, watches []Watch) tea.Cmd {
+	if client == nil || len(watches) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		counts := make(map[string]int64)
+		for _, w := range watches {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			release, err := serverCommands.acquire(ctx, "watch "+w.Namespace)
+			if err != nil {
+				cancel()
+				continue
+			}
+			dbName, collName := splitNamespace(w.Namespace)
+			count, err := client.Database(dbName).Collection(collName).EstimatedDocumentCount(ctx)
+			release()
+			cancel()
+			if err == nil {
+				counts[w.Namespace] = count
+			}
+		}
+		return watchCountsMsg{counts: counts}
+	}
+}
+
+// applyWatchCounts compares polled counts against their baselines, updating
+// badges and returning a toast for namespaces that crossed their delta
+func (m *Model) applyWatchCounts(counts map[string]int64) tea.Cmd {
+	var changed []string
+	for _, w := range m.watches {
+		count, ok := counts[w.Namespace]
+		if !ok {
+			continue
+		}
+		baseline, seen := m.watchBaselines[w.Namespace]
+		if !seen {
+			m.watchBaselines[w.Namespace] = count
+			continue
+		}
+		diff := count - baseline
+		abs := diff
+		if abs < 0 {
+			abs = -abs
+		}
+		if abs > w.Delta {
+			if m.watchBadges[w.Namespace] != diff {
+				changed = append(changed, fmt.Sprintf("%s %+d", truncateMiddle(w.Namespace, maxToastNameWidth), diff))
+			}
+			m.watchBadges[w.Namespace] = diff
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	return m.setStatus("watch: " + strings.Join(changed, ", "))
+}
+
+// acknowledgeWatch clears the badge for a namespace and resets its baseline
+func (m *Model) acknowledgeWatch(ns string) {
+	if diff, ok := m.watchBadges[ns]; ok {
+		m.watchBaselines[ns] += diff
+		delete(m.watchBadges, ns)
+	}
+}
+
+// watchSuffix returns the badge shown next to a collection in the Collections panel
+func (m Model) watchSuffix(collName string) string {
+	ns := namespaceOf(m.selectedDatabase, collName)
+	if diff, ok := m.watchBadges[ns]; ok {
+		return fmt.Sprintf("● %+d", diff)
+	}
+	if m.findWatch(ns) >= 0 {
+		return "◦"
+	}
+	return ""
+}
+
+// toggleWatch unwatches the collection under the cursor, or opens the delta
+// prompt to start watching it
+func (m *Model) toggleWatch() tea.Cmd {
+	if len(m.collFiltered) == 0 || m.selectedDatabase == "" {
+		return nil
+	}
+	ns := namespaceOf(m.selectedDatabase, m.collFiltered[m.collCursor])
+	if i := m.findWatch(ns); i >= 0 {
+		if err := deleteWatch(m.connectionName, ns); err != nil {
+			m.errorModal = true
+			m.errorMessage = fmt.Sprintf("Failed to remove watch: %v", err)
+			return nil
+		}
+		m.watches = append(m.watches[:i], m.watches[i+1:]...)
+		delete(m.watchBadges, ns)
+		delete(m.watchBaselines, ns)
+		return m.setStatus("stopped watching " + truncateMiddle(ns, maxToastNameWidth))
+	}
+
+	m.watchPromptActive = true
+	m.watchPromptNamespace = ns
+	m.watchInput.SetValue("0")
+	m.watchInput.CursorEnd()
+	m.watchInput.Focus()
+	return textinput.Blink
+}
+
+// newWatchInput creates the textinput for the watch delta prompt
+func newWatchInput() textinput.Model {
+	ti := textinput.New()
+	ti.CharLimit = 12
+	ti.Width = 12
+	return ti
+}
+
+// handleWatchPromptKey handles keyboard input in the watch delta prompt
+func (m *Model) handleWatchPromptKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "ctrl+g":
+		m.watchPromptActive = false
+		m.watchInput.Blur()
+		return nil
+	case "enter":
+		delta, err := strconv.ParseInt(strings.TrimSpace(m.watchInput.Value()), 10, 64)
+		if err != nil || delta < 0 {
+			return m.setStatus("delta must be a non-negative number")
+		}
+		w := Watch{Namespace: m.watchPromptNamespace, Delta: delta}
+		m.watchPromptActive = false
+		m.watchInput.Blur()
+		if err := saveWatch(m.connectionName, w); err != nil {
+			m.errorModal = true
+			m.errorMessage = fmt.Sprintf("Failed to save watch: %v", err)
+			return nil
+		}
+		m.watches = append(m.watches, w)
+		return tea.Batch(
+			m.setStatus("watching "+truncateMiddle(w.Namespace, maxToastNameWidth)),
+			pollWatches(m.client, []Watch{w}),
+		)
+	default:
+		var cmd tea.Cmd
+		m.watchInput, cmd = m.watchInput.Update(msg)
+		return cmd
+	}
+}
+
+// renderWatchPrompt renders the watch delta prompt modal
+func (m Model) renderWatchPrompt(background string) string {
+	width := m.modalWidth(60)
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true)
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render("Watch "+m.watchPromptNamespace),
+		"",
+		"Notify when the document count changes by more than:",
+		fitInput(m.watchInput, width-4),
+		"",
+		hintStyle.Render("enter: watch • esc: cancel"),
+	)
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("205")).
+		Padding(1, 2).
+		Width(width)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		modalStyle.Render(content),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
+	)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// wrapIndent indents continuation lines of a wrapped value past the key
+const wrapIndent = "    "
+
+// minWrapWidth keeps wrapped lines readable on very narrow panels
+const minWrapWidth = 10
+
+// wrapLineIndentWidth returns the columns taken by the indentation of a
+// continuation line
+func wrapLineIndentWidth(node *JSONNode) int {
+	return lipgloss.Width(nodeIndent(node) + wrapIndent)
+}
+
+// wrapChunks splits text into a first piece of at most first runes and
+// further pieces of at most rest runes
+func wrapChunks(text string, first, rest int) []string {
+	runes := []rune(text)
+	var chunks []string
+	width := first
+	for len(runes) > width {
+		chunks = append(chunks, string(runes[:width]))
+		runes = runes[width:]
+		width = rest
+	}
+	return append(chunks, string(runes))
+}
+
+// wrapFlattened inserts the continuation lines of wrapped string values
+// after their leaves, so cursor movement and scrolling count every line
+// a wrapped value takes. It returns nodes as they are when none is wrapped.
+func (m Model) wrapFlattened(nodes []*JSONNode) []*JSONNode {
+	var result []*JSONNode
+	for i, node := range nodes {
+		if !node.Wrapped {
+			if result != nil {
+				result = append(result, node)
+			}
+			continue
+		}
+		if result == nil {
+			result = append(make([]*JSONNode, 0, len(nodes)+8), nodes[:i]...)
+		}
+		result = append(result, m.wrapNode(node)...)
+	}
+	if result == nil {
+		return nodes
+	}
+	return result
+}
+
+// wrapNode splits the value of a wrapped leaf across lines as wide as the
+// documents panel, returning the leaf followed by its continuation lines
+func (m Model) wrapNode(node *JSONNode) []*JSONNode {
+	value, _ := node.Value.(string)
+	width := m.getDocPanelWidth() - 2
+	first := width - lipgloss.Width(m.leafPrefix(node, nodeIndent(node)))
+	if m.showTypes {
+		first -= lipgloss.Width(bsonTypeName(node.Value)) + 1
+	}
+	rest := width - wrapLineIndentWidth(node)
+	if first < minWrapWidth {
+		first = minWrapWidth
+	}
+	if rest < minWrapWidth {
+		rest = minWrapWidth
+	}
+
+	chunks := wrapChunks(fmt.Sprintf("%q", value), first, rest)
+	node.WrapText = chunks[0]
+	lines := []*JSONNode{node}
+	for _, chunk := range chunks[1:] {
+		lines = append(lines, &JSONNode{
+			Parent:   node,
+			Depth:    node.Depth,
+			Foreign:  node.Foreign,
+			WrapOf:   node,
+			WrapText: chunk,
+		})
+	}
+	return lines
+}
+
+// hasWrappedNodes reports whether any visible value is wrapped
+func (m Model) hasWrappedNodes() bool {
+	for _, node := range m.flattenedTree {
+		if node.Wrapped {
+			return true
+		}
+	}
+	return false
+}
+
+// toggleWrap wraps the string value under the cursor across several lines,
+// or truncates it to one line again
+func (m *Model) toggleWrap() tea.Cmd {
+	node := m.nodeAtCursor()
+	if node == nil || node.Depth < 0 || node.RawText != "" || m.schemaActive {
+		return nil
+	}
+	if _, ok := node.Value.(string); !ok || node.IsObject || node.IsArray {
+		return m.setStatus("only string values wrap")
+	}
+	row := m.docCursor - m.docScrollOffset
+	node.Wrapped = !node.Wrapped
+	node.WrapText = ""
+	m.rebuildFlattenedTree()
+	m.anchorCursor(node, row)
+	return nil
+}
+
+// rewrap splits wrapped values again after the documents panel changed width
+func (m *Model) rewrap() {
+	if !m.hasWrappedNodes() {
+		return
+	}
+	node := m.nodeAtCursor()
+	row := m.docCursor - m.docScrollOffset
+	m.rebuildFlattenedTree()
+	if node != nil {
+		m.anchorCursor(node, row)
+	}
+}